@@ -0,0 +1,207 @@
+package alarmDevice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+	"github.com/koestler/go-iotdevice/v3/types"
+)
+
+// delayCheckInterval defines how often rules waiting for their delay to pass are checked
+// when no new values arrive.
+const delayCheckInterval = time.Second
+
+type Config interface {
+	Rules() []Rule
+}
+
+type Rule interface {
+	Name() string
+	DeviceName() string
+	RegisterName() string
+	Condition() types.AlarmCondition
+	Warning() *float64
+	Alarm() *float64
+	Hysteresis() float64
+	Delay() time.Duration
+	Category() string
+	Description() string
+	Sort() int
+}
+
+type DeviceStruct struct {
+	device.State
+	alarmConfig Config
+
+	commandStorage *dataflow.ValueStorage
+
+	// rules are kept across restarts of Run such that acknowledgements are not lost
+	rules []*rule
+}
+
+func NewDevice(
+	deviceConfig device.Config,
+	alarmConfig Config,
+	stateStorage *dataflow.ValueStorage,
+	commandStorage *dataflow.ValueStorage,
+) *DeviceStruct {
+	cfgRules := alarmConfig.Rules()
+	rules := make([]*rule, len(cfgRules))
+	for i, r := range cfgRules {
+		rules[i] = newRule(r)
+	}
+
+	return &DeviceStruct{
+		State: device.NewState(
+			deviceConfig,
+			stateStorage,
+		),
+		alarmConfig:    alarmConfig,
+		commandStorage: commandStorage,
+		rules:          rules,
+	}
+}
+
+func (d *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool) {
+	dName := d.Config().Name()
+	ss := d.StateStorage()
+
+	// setup registers
+	cfgRules := d.alarmConfig.Rules()
+	ruleRegisters, ackRegister := addToRegisterDb(d.State.RegisterDb(), cfgRules) //nolint:staticcheck
+
+	publish := func(i int) {
+		ss.Fill(dataflow.NewEnumRegisterValue(dName, ruleRegisters[i], d.rules[i].state()))
+	}
+
+	// send connected now, disconnected when this routine stops
+	d.SetAvailable(true)
+	defer func() {
+		d.SetAvailable(false)
+	}()
+
+	for i, r := range d.rules {
+		r.interrupt()
+		publish(i)
+	}
+	ss.Fill(dataflow.NewEnumRegisterValue(dName, ackRegister, acknowledgeNone))
+
+	// subscribe to the monitored registers
+	sub := ss.SubscribeSendInitialWithPolicy(ctx, func(v dataflow.Value) bool {
+		for _, r := range cfgRules {
+			if v.DeviceName() == r.DeviceName() && v.Register().Name() == r.RegisterName() {
+				return true
+			}
+		}
+		return false
+	}, dataflow.OverflowCoalesce)
+
+	// subscribe to acknowledgements; the null values resetting the command are not commands themselves
+	commandFilter := dataflow.DeviceNonNullValueFilter(dName)
+	_, commandSub := d.commandStorage.SubscribeReturnInitial(ctx, func(v dataflow.Value) bool {
+		return commandFilter(v) && v.Register().Name() == AcknowledgeRegisterName
+	})
+
+	delayTicker := time.NewTicker(delayCheckInterval)
+	defer delayTicker.Stop()
+
+	values := sub.Drain()
+	commands := commandSub.Drain()
+	for {
+		select {
+		case v, ok := <-values:
+			if !ok {
+				// the subscription is closed when ctx is cancelled
+				return nil, false
+			}
+			if v.Restored() {
+				// never raise alarms on stale values
+				continue
+			}
+			now := time.Now()
+			for i, r := range cfgRules {
+				if v.DeviceName() != r.DeviceName() || v.Register().Name() != r.RegisterName() {
+					continue
+				}
+				nv, ok := v.(dataflow.NumericRegisterValue)
+				if !ok {
+					// the register was removed or is not numeric; keep the current state
+					d.rules[i].interrupt()
+					continue
+				}
+				if d.rules[i].update(nv.Value(), now) {
+					d.logChange(i)
+					publish(i)
+				}
+			}
+		case t := <-delayTicker.C:
+			for i, r := range d.rules {
+				if r.check(t) {
+					d.logChange(i)
+					publish(i)
+				}
+			}
+		case v, ok := <-commands:
+			if !ok {
+				return nil, false
+			}
+			d.execAcknowledge(v, publish)
+		}
+	}
+}
+
+func (d *DeviceStruct) execAcknowledge(v dataflow.Value, publish func(i int)) {
+	dName := d.Config().Name()
+
+	// reset the command such that the same acknowledgement can be sent again
+	defer d.commandStorage.Fill(dataflow.NewNullRegisterValue(dName, v.Register()))
+
+	ev, ok := v.(dataflow.EnumRegisterValue)
+	if !ok {
+		d.commandStorage.CommandDone(v, errors.New("not an enum value"))
+		return
+	}
+
+	idx := ev.EnumIdx()
+	if idx == acknowledgeNone {
+		d.commandStorage.CommandDone(v, nil)
+		return
+	}
+	if idx < acknowledgeNone || idx > acknowledgeAll+len(d.rules) {
+		d.commandStorage.CommandDone(v, fmt.Errorf("invalid index %d", idx))
+		return
+	}
+
+	acked := false
+	for i, r := range d.rules {
+		if idx != acknowledgeAll && idx != acknowledgeAll+1+i {
+			continue
+		}
+		if r.acknowledge() {
+			acked = true
+			d.logChange(i)
+			publish(i)
+		}
+	}
+
+	if !acked {
+		d.commandStorage.CommandDone(v, errors.New("no active alarm to acknowledge"))
+		return
+	}
+	d.commandStorage.CommandDone(v, nil)
+}
+
+func (d *DeviceStruct) logChange(i int) {
+	if d.Config().LogDebug() {
+		log.Printf("alarmDevice[%s]: %s is %s", d.Name(), d.alarmConfig.Rules()[i].Name(), stateEnum[d.rules[i].state()])
+	}
+}
+
+func (d *DeviceStruct) Model() string {
+	return "Alarm Engine"
+}
@@ -0,0 +1,38 @@
+package alarmDevice
+
+import (
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+const (
+	AcknowledgeRegisterName = "Acknowledge"
+	acknowledgeNone         = 0
+	acknowledgeAll          = 1
+)
+
+// addToRegisterDb adds one read-only enum register per rule and the writable acknowledge register.
+// The acknowledge register lists none, all and the rules in their sort order.
+func addToRegisterDb(rdb *dataflow.RegisterDb, rules []Rule) (ruleRegisters []dataflow.RegisterStruct, acknowledge dataflow.RegisterStruct) {
+	ackEnum := map[int]string{
+		acknowledgeNone: "none",
+		acknowledgeAll:  "all",
+	}
+
+	ruleRegisters = make([]dataflow.RegisterStruct, len(rules))
+	for i, r := range rules {
+		ruleRegisters[i] = dataflow.NewRegisterStruct(
+			r.Category(), r.Name(), r.Description(),
+			dataflow.EnumRegister, stateEnum, "", r.Sort(), false,
+		)
+		ackEnum[acknowledgeAll+1+i] = r.Name()
+	}
+
+	acknowledge = dataflow.NewRegisterStruct(
+		"Acknowledge", AcknowledgeRegisterName, "Acknowledge alarms",
+		dataflow.EnumRegister, ackEnum, "", 0, true,
+	)
+
+	rdb.AddStruct(ruleRegisters...)
+	rdb.AddStruct(acknowledge)
+	return
+}
@@ -0,0 +1,155 @@
+package alarmDevice
+
+import (
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/types"
+)
+
+// level is the severity a rule is in; the order matters since a level is only raised after the delay.
+type level int
+
+const (
+	levelOk level = iota
+	levelWarning
+	levelAlarm
+)
+
+// The values of the enum registers published for every rule.
+const (
+	stateOk = iota
+	stateWarning
+	stateAlarm
+	stateAcknowledged
+)
+
+var stateEnum = map[int]string{
+	stateOk:           "ok",
+	stateWarning:      "warning",
+	stateAlarm:        "alarm",
+	stateAcknowledged: "acknowledged",
+}
+
+// rule evaluates the values of one register against a warning and / or an alarm threshold.
+// A level is raised once the threshold was exceeded for longer than the delay; it is left as soon as the
+// value is back by more than the hysteresis. An acknowledged rule stays acknowledged until its level rises again.
+type rule struct {
+	above      bool
+	warning    *float64
+	alarm      *float64
+	hysteresis float64
+	delay      time.Duration
+
+	level level
+	acked bool
+	// since holds for every level above ok since when the value continuously exceeds its threshold
+	since [levelAlarm + 1]time.Time
+}
+
+func newRule(r Rule) *rule {
+	return &rule{
+		above:      r.Condition() == types.AlarmConditionAbove,
+		warning:    r.Warning(),
+		alarm:      r.Alarm(),
+		hysteresis: r.Hysteresis(),
+		delay:      r.Delay(),
+	}
+}
+
+// state returns the value of the enum register.
+func (r *rule) state() int {
+	if r.acked && r.level > levelOk {
+		return stateAcknowledged
+	}
+	return int(r.level)
+}
+
+// exceeds returns true when the value is beyond the threshold of the given level. While the rule is at or above
+// that level, the threshold is moved by the hysteresis such that the level is only left once the value recovered.
+func (r *rule) exceeds(v float64, l level) bool {
+	threshold := r.warning
+	if l == levelAlarm {
+		threshold = r.alarm
+	}
+	if threshold == nil {
+		return false
+	}
+
+	t := *threshold
+	if r.level >= l {
+		if r.above {
+			t -= r.hysteresis
+		} else {
+			t += r.hysteresis
+		}
+	}
+
+	if r.above {
+		return v > t
+	}
+	return v < t
+}
+
+// update evaluates a new value. It returns true when the state changed.
+func (r *rule) update(v float64, now time.Time) bool {
+	prev := r.state()
+
+	target := levelOk
+	for l := levelWarning; l <= levelAlarm; l++ {
+		if r.exceeds(v, l) {
+			target = l
+			if r.since[l].IsZero() {
+				r.since[l] = now
+			}
+		} else {
+			r.since[l] = time.Time{}
+		}
+	}
+
+	if target < r.level {
+		// recover immediately
+		r.setLevel(target)
+	} else {
+		r.raise(now)
+	}
+
+	return prev != r.state()
+}
+
+// check raises the level once the delay has passed; it must be called periodically.
+// It returns true when the state changed.
+func (r *rule) check(now time.Time) bool {
+	prev := r.state()
+	r.raise(now)
+	return prev != r.state()
+}
+
+// interrupt is called when no valid value is available. The current level is kept but pending raises are cancelled.
+func (r *rule) interrupt() {
+	r.since = [levelAlarm + 1]time.Time{}
+}
+
+// acknowledge returns true when the rule was active and not yet acknowledged.
+func (r *rule) acknowledge() bool {
+	if r.level == levelOk || r.acked {
+		return false
+	}
+	r.acked = true
+	return true
+}
+
+func (r *rule) raise(now time.Time) {
+	for l := levelAlarm; l > r.level; l-- {
+		if !r.since[l].IsZero() && now.Sub(r.since[l]) >= r.delay {
+			r.setLevel(l)
+			return
+		}
+	}
+}
+
+func (r *rule) setLevel(l level) {
+	if l > r.level || l == levelOk {
+		r.acked = false
+	}
+	r.level = l
+}
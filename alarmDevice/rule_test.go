@@ -0,0 +1,120 @@
+package alarmDevice
+
+import (
+	"testing"
+	"time"
+)
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestRule(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time {
+		return start.Add(time.Duration(s) * time.Second)
+	}
+
+	expectState := func(t *testing.T, r *rule, expect int) {
+		t.Helper()
+		if got := r.state(); expect != got {
+			t.Errorf("expect state %s but got %s", stateEnum[expect], stateEnum[got])
+		}
+	}
+
+	t.Run("belowWithHysteresis", func(t *testing.T) {
+		r := &rule{warning: ptr(12), alarm: ptr(11.8), hysteresis: 0.2}
+
+		r.update(12.5, at(0))
+		expectState(t, r, stateOk)
+		if !r.update(11.9, at(1)) {
+			t.Error("expect a change")
+		}
+		expectState(t, r, stateWarning)
+		r.update(11.7, at(2))
+		expectState(t, r, stateAlarm)
+
+		// recovery requires the value to pass the threshold plus hysteresis
+		r.update(11.9, at(3))
+		expectState(t, r, stateAlarm)
+		r.update(12.05, at(4))
+		expectState(t, r, stateWarning)
+		r.update(12.3, at(5))
+		expectState(t, r, stateOk)
+	})
+
+	t.Run("aboveWithDelay", func(t *testing.T) {
+		r := &rule{above: true, alarm: ptr(45), delay: 60 * time.Second}
+
+		r.update(46, at(0))
+		expectState(t, r, stateOk)
+		if r.check(at(59)) {
+			t.Error("did not expect a change before the delay passed")
+		}
+		if !r.check(at(60)) {
+			t.Error("expect a change after the delay passed")
+		}
+		expectState(t, r, stateAlarm)
+
+		// a short violation restarts the delay
+		r.update(44, at(61))
+		expectState(t, r, stateOk)
+		r.update(46, at(62))
+		r.update(44, at(100))
+		r.update(46, at(101))
+		r.check(at(150))
+		expectState(t, r, stateOk)
+		r.check(at(161))
+		expectState(t, r, stateAlarm)
+	})
+
+	t.Run("escalateDuringDelay", func(t *testing.T) {
+		r := &rule{above: true, warning: ptr(40), alarm: ptr(45), delay: 10 * time.Second}
+
+		r.update(41, at(0))
+		r.update(46, at(5))
+		r.check(at(10))
+		expectState(t, r, stateWarning)
+		r.check(at(15))
+		expectState(t, r, stateAlarm)
+	})
+
+	t.Run("interrupt", func(t *testing.T) {
+		r := &rule{alarm: ptr(10), delay: 10 * time.Second}
+
+		r.update(9, at(0))
+		r.interrupt()
+		r.check(at(20))
+		expectState(t, r, stateOk)
+	})
+
+	t.Run("acknowledge", func(t *testing.T) {
+		r := &rule{warning: ptr(12), alarm: ptr(11.8)}
+
+		if r.acknowledge() {
+			t.Error("did not expect an inactive rule to be acknowledged")
+		}
+
+		r.update(11.9, at(0))
+		if !r.acknowledge() {
+			t.Error("expect an active rule to be acknowledged")
+		}
+		expectState(t, r, stateAcknowledged)
+		if r.acknowledge() {
+			t.Error("did not expect a second acknowledgement")
+		}
+
+		// rising clears the acknowledgement, falling back keeps it
+		r.update(11.7, at(1))
+		expectState(t, r, stateAlarm)
+		r.acknowledge()
+		r.update(11.9, at(2))
+		expectState(t, r, stateAcknowledged)
+
+		// recovery clears it
+		r.update(12.5, at(3))
+		expectState(t, r, stateOk)
+		r.update(11.9, at(4))
+		expectState(t, r, stateWarning)
+	})
+}
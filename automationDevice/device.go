@@ -0,0 +1,265 @@
+package automationDevice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+	"github.com/koestler/go-iotdevice/v3/expression"
+)
+
+// evaluateInterval defines how often all rules are evaluated when no new values arrive,
+// e.g. to switch once the minimum on / off time has passed.
+const evaluateInterval = time.Second
+
+type Config interface {
+	Rules() []Rule
+}
+
+type Rule interface {
+	Name() string
+	On() string
+	Off() string
+	DeviceName() string
+	RegisterName() string
+	OnValue() string
+	OffValue() string
+	MinOnTime() time.Duration
+	MinOffTime() time.Duration
+	Enabled() bool
+	Category() string
+	Description() string
+	Sort() int
+}
+
+type RegisterDbOfDeviceFunc func(deviceName string) *dataflow.RegisterDb
+
+type DeviceStruct struct {
+	device.State
+	automationConfig Config
+
+	commandStorage     *dataflow.ValueStorage
+	commands           *dataflow.CommandTracker
+	registerDbOfDevice RegisterDbOfDeviceFunc
+
+	// rules are kept across restarts of Run such that the enabled state and the minimum on / off times are not lost
+	rules []*rule
+}
+
+func NewDevice(
+	deviceConfig device.Config,
+	automationConfig Config,
+	stateStorage *dataflow.ValueStorage,
+	commandStorage *dataflow.ValueStorage,
+	commands *dataflow.CommandTracker,
+	registerDbOfDevice RegisterDbOfDeviceFunc,
+) *DeviceStruct {
+	cfgRules := automationConfig.Rules()
+	rules := make([]*rule, len(cfgRules))
+	for i, r := range cfgRules {
+		rules[i] = newRule(r)
+	}
+
+	return &DeviceStruct{
+		State: device.NewState(
+			deviceConfig,
+			stateStorage,
+		),
+		automationConfig:   automationConfig,
+		commandStorage:     commandStorage,
+		commands:           commands,
+		registerDbOfDevice: registerDbOfDevice,
+		rules:              rules,
+	}
+}
+
+type conditions struct {
+	on, off *expression.Expression
+}
+
+func (d *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool) {
+	dName := d.Config().Name()
+	ss := d.StateStorage()
+
+	cfgRules := d.automationConfig.Rules()
+	conds := make([]conditions, len(cfgRules))
+	inputRefs := make(map[expression.Reference]struct{})
+	for i, r := range cfgRules {
+		if conds[i].on, err = expression.Parse(r.On()); err != nil {
+			return fmt.Errorf("automationDevice[%s]: %s: invalid on condition: %s", dName, r.Name(), err), true
+		}
+		if conds[i].off, err = expression.Parse(r.Off()); err != nil {
+			return fmt.Errorf("automationDevice[%s]: %s: invalid off condition: %s", dName, r.Name(), err), true
+		}
+		for _, ref := range conds[i].on.References() {
+			inputRefs[ref] = struct{}{}
+		}
+		for _, ref := range conds[i].off.References() {
+			inputRefs[ref] = struct{}{}
+		}
+	}
+
+	// setup registers
+	registers := addToRegisterDb(d.State.RegisterDb(), cfgRules) //nolint:staticcheck
+	publish := func(i int) {
+		ss.Fill(dataflow.NewEnumRegisterValue(dName, registers[i], enableIdx(d.rules[i].enabled)))
+	}
+
+	inputs := make(map[expression.Reference]float64)
+	holds := func(expr *expression.Expression) bool {
+		v, err := expr.Eval(func(ref expression.Reference) (float64, bool) {
+			v, ok := inputs[ref]
+			return v, ok
+		})
+		// a condition whose inputs are not available does not hold
+		return err == nil && v != 0
+	}
+	evaluate := func(i int, now time.Time) {
+		r := d.rules[i]
+		target, ok := r.evaluate(holds(conds[i].on), holds(conds[i].off), now)
+		if ok && d.send(cfgRules[i], target) {
+			r.switched(target, now)
+		}
+	}
+
+	// send connected now, disconnected when this routine stops
+	d.SetAvailable(true)
+	defer func() {
+		d.SetAvailable(false)
+	}()
+
+	for i := range d.rules {
+		publish(i)
+	}
+
+	sub := ss.SubscribeSendInitialWithPolicy(ctx, func(v dataflow.Value) bool {
+		_, ok := inputRefs[expression.Reference{DeviceName: v.DeviceName(), RegisterName: v.Register().Name()}]
+		return ok
+	}, dataflow.OverflowCoalesce)
+
+	_, commandSub := d.commandStorage.SubscribeReturnInitial(ctx, dataflow.DeviceNonNullValueFilter(dName))
+
+	evaluateTicker := time.NewTicker(evaluateInterval)
+	defer evaluateTicker.Stop()
+
+	values := sub.Drain()
+	commands := commandSub.Drain()
+	for {
+		select {
+		case v, ok := <-values:
+			if !ok {
+				// the subscription is closed when ctx is cancelled
+				return nil, false
+			}
+			if v.Restored() {
+				// never switch based on stale values
+				continue
+			}
+
+			ref := expression.Reference{DeviceName: v.DeviceName(), RegisterName: v.Register().Name()}
+			if input, ok := inputValue(v); ok {
+				inputs[ref] = input
+			} else {
+				delete(inputs, ref)
+			}
+
+			now := time.Now()
+			for i := range cfgRules {
+				if dependsOn(conds[i], ref) {
+					evaluate(i, now)
+				}
+			}
+		case t := <-evaluateTicker.C:
+			for i := range cfgRules {
+				evaluate(i, t)
+			}
+		case v, ok := <-commands:
+			if !ok {
+				return nil, false
+			}
+			d.execEnable(v, cfgRules, publish)
+		}
+	}
+}
+
+// send writes the on / off value to the target register. It returns false when the target register is not yet known,
+// e.g. because the target device did not connect so far; the rule is evaluated again later.
+func (d *DeviceStruct) send(r Rule, target output) bool {
+	dName := d.Config().Name()
+
+	register, ok := d.registerDbOfDevice(r.DeviceName()).GetByName(r.RegisterName())
+	if !ok {
+		if d.Config().LogDebug() {
+			log.Printf("automationDevice[%s]: %s: register %s of device %s not found", dName, r.Name(), r.RegisterName(), r.DeviceName())
+		}
+		return false
+	}
+
+	s := r.OffValue()
+	if target == outputOn {
+		s = r.OnValue()
+	}
+
+	v, err := dataflow.ParseRegisterValue(r.DeviceName(), register, s)
+	if err == nil {
+		err = dataflow.ValidateCommand(v)
+	}
+	if err != nil {
+		// retrying does not help; consider the output switched to not repeat the error
+		log.Printf("automationDevice[%s]: %s: cannot switch %s: %s", dName, r.Name(), target, err)
+		return true
+	}
+
+	status := d.commands.Fill(v)
+	log.Printf("automationDevice[%s]: %s: switch %s, id=%s: %s", dName, r.Name(), target, status.Id, v)
+	return true
+}
+
+func (d *DeviceStruct) execEnable(v dataflow.Value, cfgRules []Rule, publish func(i int)) {
+	// reset the command; the enabled state is published in the state storage
+	defer d.commandStorage.Fill(dataflow.NewNullRegisterValue(d.Config().Name(), v.Register()))
+
+	ev, ok := v.(dataflow.EnumRegisterValue)
+	if !ok {
+		d.commandStorage.CommandDone(v, errors.New("not an enum value"))
+		return
+	}
+
+	for i, r := range cfgRules {
+		if r.Name() != v.Register().Name() {
+			continue
+		}
+		if d.rules[i].setEnabled(ev.EnumIdx() == enableEnabled) {
+			log.Printf("automationDevice[%s]: %s: %s", d.Config().Name(), r.Name(), enableEnum[ev.EnumIdx()])
+			publish(i)
+		}
+		d.commandStorage.CommandDone(v, nil)
+		return
+	}
+
+	d.commandStorage.CommandDone(v, fmt.Errorf("unknown rule %s", v.Register().Name()))
+}
+
+func inputValue(v dataflow.Value) (float64, bool) {
+	switch v := v.(type) {
+	case dataflow.NumericRegisterValue:
+		return v.Value(), true
+	case dataflow.EnumRegisterValue:
+		return float64(v.EnumIdx()), true
+	default:
+		return 0, false
+	}
+}
+
+func dependsOn(c conditions, ref expression.Reference) bool {
+	return slices.Contains(c.on.References(), ref) || slices.Contains(c.off.References(), ref)
+}
+
+func (d *DeviceStruct) Model() string {
+	return "Automation"
+}
@@ -0,0 +1,35 @@
+package automationDevice
+
+import (
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+const (
+	enableDisabled = 0
+	enableEnabled  = 1
+)
+
+var enableEnum = map[int]string{
+	enableDisabled: "disabled",
+	enableEnabled:  "enabled",
+}
+
+// addToRegisterDb adds one writable enum register per rule used to enable / disable it.
+func addToRegisterDb(rdb *dataflow.RegisterDb, rules []Rule) []dataflow.RegisterStruct {
+	registers := make([]dataflow.RegisterStruct, len(rules))
+	for i, r := range rules {
+		registers[i] = dataflow.NewRegisterStruct(
+			r.Category(), r.Name(), r.Description(),
+			dataflow.EnumRegister, enableEnum, "", r.Sort(), true,
+		)
+	}
+	rdb.AddStruct(registers...)
+	return registers
+}
+
+func enableIdx(enabled bool) int {
+	if enabled {
+		return enableEnabled
+	}
+	return enableDisabled
+}
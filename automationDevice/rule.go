@@ -0,0 +1,92 @@
+package automationDevice
+
+import (
+	"time"
+)
+
+type output int
+
+const (
+	outputUnknown output = iota
+	outputOff
+	outputOn
+)
+
+func (o output) String() string {
+	switch o {
+	case outputOff:
+		return "off"
+	case outputOn:
+		return "on"
+	default:
+		return "unknown"
+	}
+}
+
+// rule decides when the target of an automation is switched. The output is switched on when the on condition holds
+// and off when the off condition holds; while neither holds, the output is kept which provides the hysteresis.
+// After switching, the output is kept for at least the minimum on / off time.
+type rule struct {
+	minOnTime  time.Duration
+	minOffTime time.Duration
+	enabled    bool
+
+	output output
+	since  time.Time
+}
+
+func newRule(r Rule) *rule {
+	return &rule{
+		minOnTime:  r.MinOnTime(),
+		minOffTime: r.MinOffTime(),
+		enabled:    r.Enabled(),
+	}
+}
+
+// evaluate returns the output to switch to; ok is false when the output must be kept.
+func (r *rule) evaluate(on, off bool, now time.Time) (target output, ok bool) {
+	if !r.enabled || on == off {
+		// conflicting or no condition holds
+		return r.output, false
+	}
+
+	target = outputOff
+	if on {
+		target = outputOn
+	}
+	if target == r.output {
+		return r.output, false
+	}
+
+	switch r.output {
+	case outputOn:
+		if now.Sub(r.since) < r.minOnTime {
+			return r.output, false
+		}
+	case outputOff:
+		if now.Sub(r.since) < r.minOffTime {
+			return r.output, false
+		}
+	}
+
+	return target, true
+}
+
+// switched must be called once the command for the given output was sent.
+func (r *rule) switched(o output, now time.Time) {
+	r.output = o
+	r.since = now
+}
+
+// setEnabled returns true when the enabled state changed. After enabling, the output is unknown such that
+// the current conditions are applied immediately.
+func (r *rule) setEnabled(enabled bool) bool {
+	if r.enabled == enabled {
+		return false
+	}
+	r.enabled = enabled
+	if enabled {
+		r.output = outputUnknown
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package automationDevice
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRule(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time {
+		return start.Add(time.Duration(s) * time.Second)
+	}
+
+	step := func(t *testing.T, r *rule, on, off bool, now time.Time, expectOk bool, expect output) {
+		t.Helper()
+		target, ok := r.evaluate(on, off, now)
+		if expectOk != ok {
+			t.Fatalf("expect ok to be %t but got %t", expectOk, ok)
+		}
+		if ok {
+			if expect != target {
+				t.Fatalf("expect target %s but got %s", expect, target)
+			}
+			r.switched(target, now)
+		}
+	}
+
+	t.Run("hysteresis", func(t *testing.T) {
+		r := &rule{enabled: true}
+
+		// nothing is sent while neither condition holds initially
+		step(t, r, false, false, at(0), false, outputUnknown)
+		step(t, r, true, false, at(1), true, outputOn)
+		step(t, r, true, false, at(2), false, outputOn)
+		// between the thresholds, the output is kept
+		step(t, r, false, false, at(3), false, outputOn)
+		step(t, r, false, true, at(4), true, outputOff)
+		step(t, r, false, false, at(5), false, outputOff)
+	})
+
+	t.Run("minTimes", func(t *testing.T) {
+		r := &rule{enabled: true, minOnTime: 60 * time.Second, minOffTime: 30 * time.Second}
+
+		step(t, r, true, false, at(0), true, outputOn)
+		step(t, r, false, true, at(59), false, outputOn)
+		step(t, r, false, true, at(60), true, outputOff)
+		step(t, r, true, false, at(89), false, outputOff)
+		step(t, r, true, false, at(90), true, outputOn)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		r := &rule{enabled: true}
+		step(t, r, true, true, at(0), false, outputUnknown)
+	})
+
+	t.Run("enable", func(t *testing.T) {
+		r := &rule{enabled: false}
+
+		step(t, r, true, false, at(0), false, outputUnknown)
+		if !r.setEnabled(true) {
+			t.Error("expect a change")
+		}
+		step(t, r, true, false, at(1), true, outputOn)
+
+		r.setEnabled(false)
+		step(t, r, false, true, at(2), false, outputOn)
+
+		// after enabling again, the current conditions are applied even if they did not change
+		r.setEnabled(true)
+		step(t, r, true, false, at(3), true, outputOn)
+	})
+}
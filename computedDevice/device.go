@@ -0,0 +1,204 @@
+package computedDevice
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+	"github.com/koestler/go-iotdevice/v3/expression"
+	"github.com/koestler/go-iotdevice/v3/types"
+)
+
+type Config interface {
+	Registers() []Register
+}
+
+type Register interface {
+	Name() string
+	Expression() string
+	Type() types.ComputedRegisterType
+	Category() string
+	Description() string
+	Unit() string
+	Sort() int
+}
+
+type DeviceStruct struct {
+	device.State
+	computedConfig Config
+}
+
+func NewDevice(
+	deviceConfig device.Config,
+	computedConfig Config,
+	stateStorage *dataflow.ValueStorage,
+) *DeviceStruct {
+	return &DeviceStruct{
+		State: device.NewState(
+			deviceConfig,
+			stateStorage,
+		),
+		computedConfig: computedConfig,
+	}
+}
+
+// computedRegister is a register of this device together with the expression computing its value.
+type computedRegister struct {
+	register   dataflow.RegisterStruct
+	expression *expression.Expression
+	boolean    bool
+	ref        expression.Reference
+	available  bool
+}
+
+func (d *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool) {
+	dName := d.Config().Name()
+	ss := d.StateStorage()
+
+	// setup registers
+	registers, err := d.computedRegisters()
+	if err != nil {
+		return fmt.Errorf("computedDevice[%s]: %s", dName, err), true
+	}
+	for _, r := range registers {
+		d.State.RegisterDb().AddStruct(r.register) //nolint:staticcheck
+	}
+
+	// inputs of other devices; registers of this device are added once computed
+	inputs := make(map[expression.Reference]float64)
+	lookup := func(ref expression.Reference) (float64, bool) {
+		v, ok := inputs[ref]
+		return v, ok
+	}
+
+	inputRefs := make(map[expression.Reference]struct{})
+	for _, r := range registers {
+		for _, ref := range r.expression.References() {
+			if ref.DeviceName != dName {
+				inputRefs[ref] = struct{}{}
+			}
+		}
+	}
+	sub := ss.SubscribeSendInitialWithPolicy(ctx, func(v dataflow.Value) bool {
+		_, ok := inputRefs[expression.Reference{DeviceName: v.DeviceName(), RegisterName: v.Register().Name()}]
+		return ok
+	}, dataflow.OverflowCoalesce)
+
+	// send connected now, disconnected when this routine stops
+	d.SetAvailable(true)
+	defer func() {
+		d.SetAvailable(false)
+	}()
+
+	// routine will return when ctx of the subscription is cancelled
+	for v := range sub.Drain() {
+		if v.Restored() {
+			// never compute from stale values
+			continue
+		}
+
+		ref := expression.Reference{DeviceName: v.DeviceName(), RegisterName: v.Register().Name()}
+		if input, ok := inputValue(v); ok {
+			inputs[ref] = input
+		} else {
+			delete(inputs, ref)
+		}
+
+		// update all registers depending on the changed input; registers of this device are evaluated
+		// in sort order, hence a register can use the result of a register sorted before it
+		changed := map[expression.Reference]struct{}{ref: {}}
+		for i := range registers {
+			r := &registers[i]
+			if !dependsOn(r.expression, changed) {
+				continue
+			}
+			changed[r.ref] = struct{}{}
+
+			result, err := r.expression.Eval(lookup)
+			if err != nil {
+				delete(inputs, r.ref)
+				if d.Config().LogDebug() {
+					log.Printf("computedDevice[%s]: %s='%s' cannot be computed: %s", dName, r.register.Name(), r.expression, err)
+				}
+				if r.available {
+					r.available = false
+					ss.Fill(dataflow.WithTime(dataflow.NewNullRegisterValue(dName, r.register), v.Time()))
+				}
+				continue
+			}
+
+			inputs[r.ref] = result
+			r.available = true
+			ss.Fill(dataflow.WithTime(r.value(dName, result), v.Time()))
+		}
+	}
+
+	return nil, false
+}
+
+func (d *DeviceStruct) computedRegisters() ([]computedRegister, error) {
+	dName := d.Config().Name()
+	cfgRegisters := d.computedConfig.Registers()
+	registers := make([]computedRegister, 0, len(cfgRegisters))
+
+	for _, r := range cfgRegisters {
+		expr, err := expression.Parse(r.Expression())
+		if err != nil {
+			return nil, fmt.Errorf("register %s: cannot parse expression: %s", r.Name(), err)
+		}
+
+		cr := computedRegister{
+			expression: expr,
+			boolean:    r.Type() == types.ComputedRegisterBoolType,
+			ref:        expression.Reference{DeviceName: dName, RegisterName: r.Name()},
+		}
+		if cr.boolean {
+			cr.register = dataflow.NewRegisterStruct(
+				r.Category(), r.Name(), r.Description(),
+				dataflow.EnumRegister, OnOffEnum, r.Unit(), r.Sort(), false,
+			)
+		} else {
+			cr.register = dataflow.NewRegisterStruct(
+				r.Category(), r.Name(), r.Description(),
+				dataflow.NumberRegister, nil, r.Unit(), r.Sort(), false,
+			)
+		}
+		registers = append(registers, cr)
+	}
+
+	return registers, nil
+}
+
+func (r computedRegister) value(deviceName string, result float64) dataflow.Value {
+	if r.boolean {
+		return dataflow.NewEnumRegisterValue(deviceName, r.register, boolToOnOff(result != 0))
+	}
+	return dataflow.NewNumericRegisterValue(deviceName, r.register, result)
+}
+
+// inputValue converts a value of another device into a number; enums are represented by their index.
+func inputValue(v dataflow.Value) (float64, bool) {
+	switch v := v.(type) {
+	case dataflow.NumericRegisterValue:
+		return v.Value(), true
+	case dataflow.EnumRegisterValue:
+		return float64(v.EnumIdx()), true
+	default:
+		return 0, false
+	}
+}
+
+func dependsOn(expr *expression.Expression, refs map[expression.Reference]struct{}) bool {
+	for _, r := range expr.References() {
+		if _, ok := refs[r]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *DeviceStruct) Model() string {
+	return "Computed Device"
+}
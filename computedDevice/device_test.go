@@ -0,0 +1,103 @@
+package computedDevice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/types"
+)
+
+type testDeviceConfig struct {
+	name string
+}
+
+func (c testDeviceConfig) Name() string                        { return c.name }
+func (c testDeviceConfig) Filter() dataflow.RegisterFilterConf { return nil }
+func (c testDeviceConfig) LogDebug() bool                      { return false }
+func (c testDeviceConfig) LogComDebug() bool                   { return false }
+
+type testRegister struct {
+	name, expression string
+	registerType     types.ComputedRegisterType
+}
+
+func (r testRegister) Name() string                     { return r.name }
+func (r testRegister) Expression() string               { return r.expression }
+func (r testRegister) Type() types.ComputedRegisterType { return r.registerType }
+func (r testRegister) Category() string                 { return "Computed" }
+func (r testRegister) Description() string              { return r.name }
+func (r testRegister) Unit() string                     { return "" }
+func (r testRegister) Sort() int                        { return 0 }
+
+type testComputedConfig []Register
+
+func (c testComputedConfig) Registers() []Register { return c }
+
+func TestDevice(t *testing.T) {
+	storage := dataflow.NewValueStorage()
+
+	voltage := dataflow.NewRegisterStruct("", "Voltage", "", dataflow.NumberRegister, nil, "V", 0, false)
+	current := dataflow.NewRegisterStruct("", "Current", "", dataflow.NumberRegister, nil, "A", 0, false)
+
+	dev := NewDevice(testDeviceConfig{"power0"}, testComputedConfig{
+		testRegister{"Power", "bmv0.Voltage * bmv0.Current", types.ComputedRegisterNumberType},
+		testRegister{"Charging", "power0.Power > 0", types.ComputedRegisterBoolType},
+	}, storage)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err, _ := dev.Run(ctx); err != nil {
+			t.Errorf("did not expect an error, got: %s", err)
+		}
+	}()
+
+	get := func(registerName string) (dataflow.Value, bool) {
+		for _, v := range storage.GetState() {
+			if v.DeviceName() == "power0" && v.Register().Name() == registerName {
+				return v, true
+			}
+		}
+		return nil, false
+	}
+
+	waitFor := func(registerName, expect string) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			v, ok := get(registerName)
+			if (ok && v.String() == expect) || (!ok && expect == "") {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		v, _ := get(registerName)
+		t.Errorf("expect %s to be '%s' but got %v", registerName, expect, v)
+	}
+
+	storage.Fill(dataflow.NewNumericRegisterValue("bmv0", voltage, 12.5))
+	waitFor("Power", "")
+
+	storage.Fill(dataflow.NewNumericRegisterValue("bmv0", current, 2))
+	waitFor("Power", "Power=25.000000")
+	waitFor("Charging", "Charging=1:On")
+
+	if _, ok := dev.RegisterDb().GetByName("Power"); !ok {
+		t.Error("expect register Power to be added to the register db")
+	}
+
+	storage.Fill(dataflow.NewNumericRegisterValue("bmv0", current, -4))
+	waitFor("Power", "Power=-50.000000")
+	waitFor("Charging", "Charging=0:Off")
+
+	// a missing input removes the computed values
+	storage.Fill(dataflow.NewNullRegisterValue("bmv0", current))
+	waitFor("Power", "")
+	waitFor("Charging", "")
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,13 @@
+package computedDevice
+
+func boolToOnOff(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var OnOffEnum = map[int]string{
+	boolToOnOff(false): "Off",
+	boolToOnOff(true):  "On",
+}
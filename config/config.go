@@ -1,7 +1,6 @@
 package config
 
 import (
-	"bytes"
 	"cmp"
 	"fmt"
 	"log"
@@ -9,10 +8,14 @@ import (
 	"os"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/koestler/go-iotdevice/v3/expression"
+	"github.com/koestler/go-iotdevice/v3/namePattern"
+	"github.com/koestler/go-iotdevice/v3/schedule"
 	"github.com/koestler/go-iotdevice/v3/types"
 	"github.com/pkg/errors"
 	"golang.org/x/exp/maps"
@@ -23,10 +26,13 @@ const NameRegexp = "^[a-zA-Z0-9\\-]{1,32}$"
 
 var nameMatcher = regexp.MustCompile(NameRegexp)
 
+var gpioInputOptions = []string{"WithBiasDisabled", "WithPullDown", "WithPullUp"}
+var gpioOutputOptions = []string{"AsOpenDrain", "AsOpenSource", "AsPushPull"}
+
 func ReadConfigFile(exe, source string, bypassFileCheck bool) (config Config, err []error) {
 	yamlStr, e := os.ReadFile(source)
 	if e != nil {
-		return config, []error{fmt.Errorf("cannot read configuration: %v; use see `%s --help`", err, exe)}
+		return config, []error{fmt.Errorf("cannot read configuration: %v; use see `%s --help`", e, exe)}
 	}
 
 	return ReadConfig(yamlStr, bypassFileCheck)
@@ -35,20 +41,16 @@ func ReadConfigFile(exe, source string, bypassFileCheck bool) (config Config, er
 func ReadConfig(yamlStr []byte, bypassFileCheck bool) (config Config, err []error) {
 	var configRead configRead
 
-	yamlStr = []byte(os.ExpandEnv(string(yamlStr)))
-
-	d := yaml.NewDecoder(bytes.NewReader(yamlStr))
-	d.KnownFields(true)
-
-	if e := d.Decode(&configRead); e != nil {
-		return config, []error{fmt.Errorf("cannot parse yaml: %s", e)}
+	if e := decodeYaml(yamlStr, &configRead, true); len(e) > 0 {
+		return config, e
 	}
 
 	return configRead.TransformAndValidate(bypassFileCheck)
 }
 
+// PrintConfig logs the configuration with all passwords and secrets redacted.
 func (c Config) PrintConfig() (err error) {
-	newYamlStr, err := yaml.Marshal(c)
+	newYamlStr, err := yaml.Marshal(c.redacted())
 	if err != nil {
 		return fmt.Errorf("cannot encode yaml again: %s", err)
 	}
@@ -64,6 +66,7 @@ func (c configRead) TransformAndValidate(bypassFileCheck bool) (ret Config, err
 	ret = Config{
 		logConfig:      true,
 		logWorkerStart: true,
+		source:         c,
 	}
 
 	var e []error
@@ -105,6 +108,12 @@ func (c configRead) TransformAndValidate(bypassFileCheck bool) (ret Config, err
 	ret.authentication, e = c.Authentication.TransformAndValidate(bypassFileCheck)
 	err = append(err, e...)
 
+	ret.persistence, e = c.Persistence.TransformAndValidate()
+	err = append(err, e...)
+
+	ret.history, e = c.History.TransformAndValidate()
+	err = append(err, e...)
+
 	ret.modbus, e = TransformAndValidateMapToList(
 		c.Modbus,
 		func(inp modbusConfigRead, name string) (ModbusConfig, []error) {
@@ -153,13 +162,27 @@ func (c configRead) TransformAndValidate(bypassFileCheck bool) (ret Config, err
 	)
 	err = append(err, e...)
 
+	ret.replayDevices, e = TransformAndValidateMapToList(
+		c.ReplayDevices,
+		func(inp replayDeviceConfigRead, name string) (ReplayDeviceConfig, []error) {
+			return inp.TransformAndValidate(name)
+		},
+	)
+	err = append(err, e...)
+
 	ret.devices = make([]DeviceConfig, 0,
 		len(ret.victronDevices)+
 			len(ret.modbusDevices)+
 			len(ret.gpioDevices)+
 			len(ret.httpDevices)+
 			len(ret.mqttDevices)+
-			len(c.GensetDevices),
+			len(ret.replayDevices)+
+			len(c.GensetDevices)+
+			len(c.ComputedDevices)+
+			len(c.EnergyDevices)+
+			len(c.AlarmDevices)+
+			len(c.AutomationDevices)+
+			len(c.SchedulerDevices),
 	)
 	for _, d := range ret.victronDevices {
 		ret.devices = append(ret.devices, d.DeviceConfig)
@@ -176,6 +199,9 @@ func (c configRead) TransformAndValidate(bypassFileCheck bool) (ret Config, err
 	for _, d := range ret.mqttDevices {
 		ret.devices = append(ret.devices, d.DeviceConfig)
 	}
+	for _, d := range ret.replayDevices {
+		ret.devices = append(ret.devices, d.DeviceConfig)
+	}
 
 	ret.gensetDevices, e = TransformAndValidateMapToList(
 		c.GensetDevices,
@@ -189,6 +215,71 @@ func (c configRead) TransformAndValidate(bypassFileCheck bool) (ret Config, err
 		ret.devices = append(ret.devices, d.DeviceConfig)
 	}
 
+	ret.computedDevices, e = TransformAndValidateMapToList(
+		c.ComputedDevices,
+		func(inp computedDeviceConfigRead, name string) (ComputedDeviceConfig, []error) {
+			return inp.TransformAndValidate(name, ret.devices)
+		},
+	)
+	err = append(err, e...)
+
+	for _, d := range ret.computedDevices {
+		ret.devices = append(ret.devices, d.DeviceConfig)
+	}
+
+	ret.energyDevices, e = TransformAndValidateMapToList(
+		c.EnergyDevices,
+		func(inp energyDeviceConfigRead, name string) (EnergyDeviceConfig, []error) {
+			return inp.TransformAndValidate(name, ret.devices)
+		},
+	)
+	err = append(err, e...)
+
+	for _, d := range ret.energyDevices {
+		ret.devices = append(ret.devices, d.DeviceConfig)
+	}
+
+	ret.alarmDevices, e = TransformAndValidateMapToList(
+		c.AlarmDevices,
+		func(inp alarmDeviceConfigRead, name string) (AlarmDeviceConfig, []error) {
+			return inp.TransformAndValidate(name, ret.devices)
+		},
+	)
+	err = append(err, e...)
+
+	for _, d := range ret.alarmDevices {
+		ret.devices = append(ret.devices, d.DeviceConfig)
+	}
+
+	ret.automationDevices, e = TransformAndValidateMapToList(
+		c.AutomationDevices,
+		func(inp automationDeviceConfigRead, name string) (AutomationDeviceConfig, []error) {
+			return inp.TransformAndValidate(name, ret.devices)
+		},
+	)
+	err = append(err, e...)
+
+	for _, d := range ret.automationDevices {
+		ret.devices = append(ret.devices, d.DeviceConfig)
+	}
+
+	ret.schedulerDevices, e = TransformAndValidateMapToList(
+		c.SchedulerDevices,
+		func(inp schedulerDeviceConfigRead, name string) (SchedulerDeviceConfig, []error) {
+			return inp.TransformAndValidate(name, ret.devices)
+		},
+	)
+	err = append(err, e...)
+
+	for _, d := range ret.schedulerDevices {
+		ret.devices = append(ret.devices, d.DeviceConfig)
+	}
+
+	err = append(err, validateDependencies(ret.devices)...)
+
+	ret.recorder, e = c.Recorder.TransformAndValidate(ret.devices)
+	err = append(err, e...)
+
 	ret.mqttClients, e = TransformAndValidateMapToList(
 		c.MqttClients,
 		func(inp mqttClientConfigRead, name string) (MqttClientConfig, []error) {
@@ -291,9 +382,11 @@ func (c *httpServerConfigRead) TransformAndValidate() (ret HttpServerConfig, err
 func (c *authenticationConfigRead) TransformAndValidate(bypassFileCheck bool) (ret AuthenticationConfig, err []error) {
 	ret.enabled = false
 	ret.jwtValidityPeriod = time.Hour
+	ret.adminUsers = make(map[string]struct{})
 
 	if randString, e := randomString(64); err == nil {
 		ret.jwtSecret = []byte(randString)
+		ret.jwtSecretGenerated = true
 	} else {
 		err = append(err, fmt.Errorf("Authentication->JwtSecret: error while generating random secret: %s", e))
 	}
@@ -304,11 +397,14 @@ func (c *authenticationConfigRead) TransformAndValidate(bypassFileCheck bool) (r
 
 	ret.enabled = true
 
-	if c.JwtSecret != nil {
-		if len(*c.JwtSecret) < 32 {
+	jwtSecret, e := readSecret("Authentication", "JwtSecret", c.JwtSecret, c.JwtSecretFile)
+	err = append(err, e...)
+	if jwtSecret != nil {
+		if len(*jwtSecret) < 32 {
 			err = append(err, fmt.Errorf("Authentication->JwtSecret must be empty or >= 32 chars"))
 		} else {
-			ret.jwtSecret = []byte(*c.JwtSecret)
+			ret.jwtSecret = []byte(*jwtSecret)
+			ret.jwtSecretGenerated = false
 		}
 	}
 
@@ -344,6 +440,130 @@ func (c *authenticationConfigRead) TransformAndValidate(bypassFileCheck bool) (r
 		err = append(err, errors.New("Authentication->HtaccessFile must not be empty"))
 	}
 
+	for _, user := range c.AdminUsers {
+		ret.adminUsers[user] = struct{}{}
+	}
+
+	return
+}
+
+func (c *persistenceConfigRead) TransformAndValidate() (ret PersistenceConfig, err []error) {
+	ret.enabled = false
+	ret.writeInterval = time.Minute
+
+	if c == nil {
+		return
+	}
+
+	ret.enabled = true
+	ret.stateFile = c.StateFile
+	ret.commandFile = c.CommandFile
+
+	if len(c.StateFile) < 1 && len(c.CommandFile) < 1 {
+		err = append(err, errors.New("Persistence->StateFile or Persistence->CommandFile must be set or the whole section must be missing"))
+	} else if len(c.StateFile) > 0 && c.StateFile == c.CommandFile {
+		err = append(err, fmt.Errorf("Persistence->StateFile and Persistence->CommandFile must not point to the same file='%s'", c.StateFile))
+	}
+
+	if len(c.WriteInterval) < 1 {
+		// use default 1m
+	} else if writeInterval, e := time.ParseDuration(c.WriteInterval); e != nil {
+		err = append(err, fmt.Errorf("Persistence->WriteInterval='%s' parse error: %s", c.WriteInterval, e))
+	} else if writeInterval <= 0 {
+		err = append(err, fmt.Errorf("Persistence->WriteInterval='%s' must be positive", c.WriteInterval))
+	} else {
+		ret.writeInterval = writeInterval
+	}
+
+	if c.LogDebug != nil && *c.LogDebug {
+		ret.logDebug = true
+	}
+
+	return
+}
+
+func (c *recorderConfigRead) TransformAndValidate(devices []DeviceConfig) (ret RecorderConfig, err []error) {
+	ret.enabled = false
+	ret.flushInterval = time.Second
+
+	if c == nil {
+		return
+	}
+
+	ret.enabled = true
+	ret.file = c.File
+	ret.devices = c.Devices
+
+	if len(c.File) < 1 {
+		err = append(err, errors.New("Recorder->File must be set or the whole section must be missing"))
+	}
+
+	for _, name := range c.Devices {
+		if !existsByName(name, devices) {
+			err = append(err, fmt.Errorf("Recorder->Devices->%s is not defined", name))
+		}
+	}
+
+	if len(c.FlushInterval) < 1 {
+		// use default 1s
+	} else if flushInterval, e := time.ParseDuration(c.FlushInterval); e != nil {
+		err = append(err, fmt.Errorf("Recorder->FlushInterval='%s' parse error: %s", c.FlushInterval, e))
+	} else if flushInterval <= 0 {
+		err = append(err, fmt.Errorf("Recorder->FlushInterval='%s' must be positive", c.FlushInterval))
+	} else {
+		ret.flushInterval = flushInterval
+	}
+
+	if c.LogDebug != nil && *c.LogDebug {
+		ret.logDebug = true
+	}
+
+	return
+}
+
+func (c *historyConfigRead) TransformAndValidate() (ret HistoryConfig, err []error) {
+	ret.enabled = false
+	ret.rawRetention = time.Hour
+	ret.minuteRetention = 24 * time.Hour
+	ret.quarterRetention = 7 * 24 * time.Hour
+	ret.writeInterval = 5 * time.Minute
+
+	if c == nil {
+		return
+	}
+
+	ret.enabled = true
+	ret.file = c.File
+
+	parseRetention := func(field, inp string, ret *time.Duration) {
+		if len(inp) < 1 {
+			// use default
+		} else if retention, e := time.ParseDuration(inp); e != nil {
+			err = append(err, fmt.Errorf("History->%s='%s' parse error: %s", field, inp, e))
+		} else if retention < 0 {
+			err = append(err, fmt.Errorf("History->%s='%s' must not be negative", field, inp))
+		} else {
+			*ret = retention
+		}
+	}
+	parseRetention("RawRetention", c.RawRetention, &ret.rawRetention)
+	parseRetention("MinuteRetention", c.MinuteRetention, &ret.minuteRetention)
+	parseRetention("QuarterRetention", c.QuarterRetention, &ret.quarterRetention)
+
+	if len(c.WriteInterval) < 1 {
+		// use default 5m
+	} else if writeInterval, e := time.ParseDuration(c.WriteInterval); e != nil {
+		err = append(err, fmt.Errorf("History->WriteInterval='%s' parse error: %s", c.WriteInterval, e))
+	} else if writeInterval <= 0 {
+		err = append(err, fmt.Errorf("History->WriteInterval='%s' must be positive", c.WriteInterval))
+	} else {
+		ret.writeInterval = writeInterval
+	}
+
+	if c.LogDebug != nil && *c.LogDebug {
+		ret.logDebug = true
+	}
+
 	return
 }
 
@@ -353,9 +573,8 @@ func (c mqttClientConfigRead) TransformAndValidate(
 	mqttDevices []MqttDeviceConfig,
 ) (ret MqttClientConfig, err []error) {
 	ret = MqttClientConfig{
-		name:     name,
-		user:     c.User,
-		password: c.Password,
+		name: name,
+		user: c.User,
 	}
 
 	errPrefix := fmt.Sprintf("MqttClients->%s", ret.name)
@@ -364,6 +583,12 @@ func (c mqttClientConfigRead) TransformAndValidate(
 		err = append(err, fmt.Errorf("%s name does not match %s", errPrefix, NameRegexp))
 	}
 
+	if password, e := readPassword(errPrefix, c.Password, c.PasswordFile); len(e) > 0 {
+		err = append(err, e...)
+	} else {
+		ret.password = password
+	}
+
 	if len(c.Broker) < 1 {
 		err = append(err, fmt.Errorf("%s->Broker must not be empty", errPrefix))
 	} else {
@@ -386,6 +611,7 @@ func (c mqttClientConfigRead) TransformAndValidate(
 
 	if c.ClientId == nil {
 		ret.clientId = "go-iotdevice-" + uuid.New().String()
+		ret.clientIdGenerated = true
 	} else {
 		ret.clientId = *c.ClientId
 	}
@@ -589,6 +815,22 @@ func (c mqttClientConfigRead) TransformAndValidate(
 	)
 	err = append(err, e...)
 
+	ret.commandResponse, e = c.CommandResponse.TransformAndValidate(
+		fmt.Sprintf("%s->CommandResponse->", errPrefix),
+		nonLoopMqttDevices,
+		ret.readOnly,
+		false,
+		"%Prefix%resp/%DeviceName%/%RegisterName%",
+		0,
+		false,
+		true,
+		true,
+		false,
+		true,
+		false,
+	)
+	err = append(err, e...)
+
 	if c.LogDebug != nil && *c.LogDebug {
 		ret.logDebug = true
 	}
@@ -738,7 +980,7 @@ func (c mqttDeviceSectionConfigRead) TransformAndValidate(
 		c.Filter = &filterConfigRead{}
 	}
 	var e []error
-	ret.filter, e = c.Filter.TransformAndValidate()
+	ret.filter, e = c.Filter.TransformAndValidate(logPrefix)
 	err = append(err, e...)
 
 	return
@@ -795,7 +1037,7 @@ func (c deviceConfigRead) TransformAndValidate(name string) (ret DeviceConfig, e
 	}
 
 	var e []error
-	ret.filter, e = c.Filter.TransformAndValidate()
+	ret.filter, e = c.Filter.TransformAndValidate(fmt.Sprintf("Devices->%s->", name))
 	err = append(err, e...)
 
 	if len(c.RestartInterval) < 1 {
@@ -828,6 +1070,73 @@ func (c deviceConfigRead) TransformAndValidate(name string) (ret DeviceConfig, e
 		ret.restartIntervalMaxBackoff = restartIntervalMaxBackoff
 	}
 
+	if len(c.MaxAge) < 1 {
+		// use default 0s -> values never expire
+	} else if maxAge, e := time.ParseDuration(c.MaxAge); e != nil {
+		err = append(err, fmt.Errorf("Devices->%s->MaxAge='%s' parse error: %s",
+			name, c.MaxAge, e,
+		))
+	} else if maxAge < 0 {
+		err = append(err, fmt.Errorf("Devices->%s->MaxAge='%s' must not be negative",
+			name, c.MaxAge,
+		))
+	} else {
+		ret.maxAge = maxAge
+	}
+
+	ret.registerMaxAge = make(map[string]time.Duration, len(c.RegisterMaxAge))
+	for registerName, s := range c.RegisterMaxAge {
+		if maxAge, e := time.ParseDuration(s); e != nil {
+			err = append(err, fmt.Errorf("Devices->%s->RegisterMaxAge->%s='%s' parse error: %s",
+				name, registerName, s, e,
+			))
+		} else if maxAge < 0 {
+			err = append(err, fmt.Errorf("Devices->%s->RegisterMaxAge->%s='%s' must not be negative",
+				name, registerName, s,
+			))
+		} else {
+			ret.registerMaxAge[registerName] = maxAge
+		}
+	}
+
+	ret.deadband, e = c.Deadband.TransformAndValidate(name)
+	err = append(err, e...)
+
+	ret.transform = make(map[string]RegisterTransformConfig, len(c.Transform))
+	for registerName, t := range c.Transform {
+		var tc RegisterTransformConfig
+		tc, e = t.TransformAndValidate(name, registerName)
+		err = append(err, e...)
+		ret.transform[registerName] = tc
+	}
+
+	ret.limits = make(map[string]RegisterLimitsConfig, len(c.Limits))
+	for registerName, l := range c.Limits {
+		var lc RegisterLimitsConfig
+		lc, e = l.TransformAndValidate(name, registerName)
+		err = append(err, e...)
+		ret.limits[registerName] = lc
+	}
+
+	for deviceName, registerNames := range c.DependsOn {
+		ret.dependsOn = addDependency(ret.dependsOn, deviceName, registerNames...)
+	}
+
+	if len(c.DependencyTimeout) < 1 {
+		// use default 30s
+		ret.dependencyTimeout = 30 * time.Second
+	} else if dependencyTimeout, e := time.ParseDuration(c.DependencyTimeout); e != nil {
+		err = append(err, fmt.Errorf("Devices->%s->DependencyTimeout='%s' parse error: %s",
+			name, c.DependencyTimeout, e,
+		))
+	} else if dependencyTimeout <= 0 {
+		err = append(err, fmt.Errorf("Devices->%s->DependencyTimeout='%s' must be positive",
+			name, c.DependencyTimeout,
+		))
+	} else {
+		ret.dependencyTimeout = dependencyTimeout
+	}
+
 	if c.LogDebug != nil && *c.LogDebug {
 		ret.logDebug = true
 	}
@@ -839,6 +1148,59 @@ func (c deviceConfigRead) TransformAndValidate(name string) (ret DeviceConfig, e
 	return
 }
 
+// addDependency adds the given device and registers to the dependencies. The list is kept sorted by device name and
+// the registers of a device are merged.
+func addDependency(deps []DependencyConfig, deviceName string, registerNames ...string) []DependencyConfig {
+	i, found := slices.BinarySearchFunc(deps, deviceName, func(d DependencyConfig, name string) int {
+		return cmp.Compare(d.deviceName, name)
+	})
+	if !found {
+		deps = slices.Insert(deps, i, DependencyConfig{deviceName: deviceName, registerNames: []string{}})
+	}
+	for _, registerName := range registerNames {
+		if !slices.Contains(deps[i].registerNames, registerName) {
+			deps[i].registerNames = append(deps[i].registerNames, registerName)
+		}
+	}
+	slices.Sort(deps[i].registerNames)
+	return deps
+}
+
+// validateDependencies checks that the dependencies of a device exist and are not circular.
+// This can only be done after all devices are known.
+func validateDependencies(devices []DeviceConfig) (err []error) {
+	byName := make(map[string]DeviceConfig, len(devices))
+	for _, d := range devices {
+		byName[d.name] = d
+	}
+
+	for _, d := range devices {
+		for _, dep := range d.dependsOn {
+			if dep.deviceName == d.name {
+				err = append(err, fmt.Errorf("Devices->%s->DependsOn->%s: a device must not depend on itself", d.name, dep.deviceName))
+			} else if _, ok := byName[dep.deviceName]; !ok {
+				err = append(err, fmt.Errorf("Devices->%s->DependsOn->%s is not defined", d.name, dep.deviceName))
+			} else if dependsOnTransitively(byName, dep.deviceName, d.name, map[string]bool{}) {
+				err = append(err, fmt.Errorf("Devices->%s->DependsOn->%s is circular", d.name, dep.deviceName))
+			}
+		}
+	}
+	return
+}
+
+func dependsOnTransitively(byName map[string]DeviceConfig, from, target string, visited map[string]bool) bool {
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+	for _, dep := range byName[from].dependsOn {
+		if dep.deviceName == target || dependsOnTransitively(byName, dep.deviceName, target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c victronDeviceConfigRead) TransformAndValidate(name string) (ret VictronDeviceConfig, err []error) {
 	ret = VictronDeviceConfig{
 		kind:   types.VictronDeviceKindFromString(c.Kind),
@@ -853,7 +1215,7 @@ func (c victronDeviceConfigRead) TransformAndValidate(name string) (ret VictronD
 		err = append(err, fmt.Errorf("VictronDevices->%s->Kind='%s' is invalid", name, c.Kind))
 	}
 
-	if ret.kind == types.VictronVedirectKind && len(c.Device) < 1 {
+	if (ret.kind == types.VictronVedirectKind || ret.kind == types.VictronReplayKind) && len(c.Device) < 1 {
 		err = append(err, fmt.Errorf("VictronDevices->%s->Device must not be empty", name))
 	}
 
@@ -861,6 +1223,15 @@ func (c victronDeviceConfigRead) TransformAndValidate(name string) (ret VictronD
 		ret.ioLog = *c.IoLog
 	}
 
+	ret.replaySpeed = 1
+	if c.ReplaySpeed != nil {
+		if *c.ReplaySpeed < 0 {
+			err = append(err, fmt.Errorf("VictronDevices->%s->ReplaySpeed=%g must not be negative", name, *c.ReplaySpeed))
+		} else {
+			ret.replaySpeed = *c.ReplaySpeed
+		}
+	}
+
 	if len(c.PollInterval) < 1 {
 		// use default 100ms
 		ret.pollInterval = 500 * time.Millisecond
@@ -932,6 +1303,9 @@ func (c modbusDeviceConfigRead) TransformAndValidate(
 		ret.pollInterval = pollInterval
 	}
 
+	ret.commandReadback, e = c.CommandReadback.TransformAndValidate("ModbusDevices->" + name)
+	err = append(err, e...)
+
 	return
 }
 
@@ -977,10 +1351,9 @@ func (c gpioDeviceConfigRead) TransformAndValidate(deviceName string) (ret GpioD
 
 	ret.inputOptions = make([]string, 0)
 	for _, opt := range c.InputOptions {
-		switch opt {
-		case "WithBiasDisabled", "WithPullDown", "WithPullUp":
+		if slices.Contains(gpioInputOptions, opt) {
 			ret.inputOptions = append(ret.inputOptions, opt)
-		default:
+		} else {
 			err = append(err, fmt.Errorf("GpioDevices->%s->InputOptions='%s' is invalid", deviceName, opt))
 		}
 	}
@@ -990,10 +1363,9 @@ func (c gpioDeviceConfigRead) TransformAndValidate(deviceName string) (ret GpioD
 
 	ret.outputOptions = make([]string, 0)
 	for _, opt := range c.OutputOptions {
-		switch opt {
-		case "AsOpenDrain", "AsOpenSource", "AsPushPull":
+		if slices.Contains(gpioOutputOptions, opt) {
 			ret.outputOptions = append(ret.outputOptions, opt)
-		default:
+		} else {
 			err = append(err, fmt.Errorf("GpioDevices->%s->OutputOptions='%s' is invalid", deviceName, opt))
 		}
 	}
@@ -1056,13 +1428,15 @@ func (c httpDeviceConfigRead) TransformAndValidate(name string) (ret HttpDeviceC
 	ret = HttpDeviceConfig{
 		kind:     types.HttpDeviceKindFromString(c.Kind),
 		username: c.Username,
-		password: c.Password,
 	}
 
 	var e []error
 	ret.DeviceConfig, e = c.deviceConfigRead.TransformAndValidate(name)
 	err = append(err, e...)
 
+	ret.password, e = readPassword("HttpDevices->"+name, c.Password, c.PasswordFile)
+	err = append(err, e...)
+
 	if len(c.Url) < 1 {
 		err = append(err, fmt.Errorf("HttpDevices->%s->Url must not be empty", name))
 	} else {
@@ -1094,6 +1468,41 @@ func (c httpDeviceConfigRead) TransformAndValidate(name string) (ret HttpDeviceC
 		ret.pollInterval = pollInterval
 	}
 
+	ret.commandReadback, e = c.CommandReadback.TransformAndValidate("HttpDevices->" + name)
+	err = append(err, e...)
+
+	return
+}
+
+func (c *commandReadbackConfigRead) TransformAndValidate(logPrefix string) (ret CommandReadbackConfig, err []error) {
+	ret.enabled = false
+	ret.retries = 3
+	ret.backoff = time.Second
+
+	if c == nil {
+		return
+	}
+
+	ret.enabled = true
+
+	if c.Retries == nil {
+		// use default 3
+	} else if *c.Retries < 0 {
+		err = append(err, fmt.Errorf("%s->CommandReadback->Retries=%d must not be negative", logPrefix, *c.Retries))
+	} else {
+		ret.retries = *c.Retries
+	}
+
+	if len(c.Backoff) < 1 {
+		// use default 1s
+	} else if backoff, e := time.ParseDuration(c.Backoff); e != nil {
+		err = append(err, fmt.Errorf("%s->CommandReadback->Backoff='%s' parse error: %s", logPrefix, c.Backoff, e))
+	} else if backoff <= 0 {
+		err = append(err, fmt.Errorf("%s->CommandReadback->Backoff='%s' must be positive", logPrefix, c.Backoff))
+	} else {
+		ret.backoff = backoff
+	}
+
 	return
 }
 
@@ -1113,6 +1522,41 @@ func (c mqttDeviceConfigRead) TransformAndValidate(name string) (ret MqttDeviceC
 	return
 }
 
+func (c replayDeviceConfigRead) TransformAndValidate(name string) (ret ReplayDeviceConfig, err []error) {
+	ret = ReplayDeviceConfig{
+		file:       c.File,
+		deviceName: name,
+		speed:      1,
+		loop:       true,
+	}
+
+	if len(c.File) < 1 {
+		err = append(err, fmt.Errorf("ReplayDevices->%s->File must not be empty", name))
+	}
+
+	if len(c.Device) > 0 {
+		ret.deviceName = c.Device
+	}
+
+	if c.Speed != nil {
+		if *c.Speed <= 0 {
+			err = append(err, fmt.Errorf("ReplayDevices->%s->Speed=%g must be positive", name, *c.Speed))
+		} else {
+			ret.speed = *c.Speed
+		}
+	}
+
+	if c.Loop != nil && !*c.Loop {
+		ret.loop = false
+	}
+
+	var e []error
+	ret.DeviceConfig, e = c.deviceConfigRead.TransformAndValidate(name)
+	err = append(err, e...)
+
+	return
+}
+
 func (c gensetDeviceConfigRead) TransformAndValidate(name string, devices []DeviceConfig) (ret GensetDeviceConfig, err []error) {
 	var e []error
 	ret.DeviceConfig, e = c.deviceConfigRead.TransformAndValidate(name)
@@ -1124,6 +1568,13 @@ func (c gensetDeviceConfigRead) TransformAndValidate(name string, devices []Devi
 	ret.outputBindings, e = c.OutputBindings.TransformAndValidate(devices)
 	err = append(err, e...)
 
+	// the bindings are set up when the genset device starts, hence it depends on all bound devices and registers
+	for _, b := range slices.Concat(ret.inputBindings, ret.outputBindings) {
+		if existsByName(b.deviceName, devices) {
+			ret.dependsOn = addDependency(ret.dependsOn, b.deviceName, b.registerName)
+		}
+	}
+
 	if len(c.PrimingTimeout) < 1 {
 		// use default 10s
 		ret.primingTimeout = 10 * time.Second
@@ -1391,6 +1842,484 @@ func (c gensetDeviceBindingConfigRead) TransformAndValidate(devices []DeviceConf
 	return
 }
 
+func (c computedDeviceConfigRead) TransformAndValidate(name string, devices []DeviceConfig) (ret ComputedDeviceConfig, err []error) {
+	var e []error
+	ret.DeviceConfig, e = c.deviceConfigRead.TransformAndValidate(name)
+	err = append(err, e...)
+
+	if len(c.Registers) < 1 {
+		err = append(err, fmt.Errorf("ComputedDevices->%s->Registers must not be empty", name))
+	}
+
+	ownReferences := make(map[string][]string)
+	for registerName, r := range c.Registers {
+		reg := ComputedRegisterConfig{
+			name:         registerName,
+			expression:   r.Expression,
+			registerType: types.ComputedRegisterNumberType,
+			category:     "Computed",
+			description:  registerName,
+			unit:         r.Unit,
+		}
+
+		if len(r.Type) > 0 {
+			reg.registerType = types.ComputedRegisterTypeFromString(r.Type)
+			if reg.registerType == types.ComputedRegisterUndefinedType {
+				err = append(err, fmt.Errorf("ComputedDevices->%s->Registers->%s->Type='%s' is invalid, must be Number or Bool",
+					name, registerName, r.Type,
+				))
+			}
+		}
+
+		if len(r.Category) > 0 {
+			reg.category = r.Category
+		}
+
+		if len(r.Description) > 0 {
+			reg.description = r.Description
+		}
+
+		if r.Sort != nil {
+			reg.sort = *r.Sort
+		}
+
+		if expr, e := expression.Parse(r.Expression); e != nil {
+			err = append(err, fmt.Errorf("ComputedDevices->%s->Registers->%s->Expression='%s' parse error: %s",
+				name, registerName, r.Expression, e,
+			))
+		} else {
+			for _, ref := range expr.References() {
+				if ref.DeviceName == name {
+					ownReferences[registerName] = append(ownReferences[registerName], ref.RegisterName)
+				} else if !existsByName(ref.DeviceName, devices) {
+					err = append(err, fmt.Errorf("ComputedDevices->%s->Registers->%s->Expression='%s' device='%s' is not defined",
+						name, registerName, r.Expression, ref.DeviceName,
+					))
+				}
+			}
+		}
+
+		ret.registers = append(ret.registers, reg)
+	}
+	slices.SortFunc(ret.registers, func(i, j ComputedRegisterConfig) int {
+		return cmp.Or(
+			cmp.Compare(i.sort, j.sort),
+			cmp.Compare(i.name, j.name),
+		)
+	})
+
+	// registers are evaluated in sort order; hence a register can only use the registers sorted before it
+	position := make(map[string]int, len(ret.registers))
+	for i, reg := range ret.registers {
+		position[reg.name] = i
+	}
+	for i, reg := range ret.registers {
+		for _, refName := range ownReferences[reg.name] {
+			if p, ok := position[refName]; !ok {
+				err = append(err, fmt.Errorf("ComputedDevices->%s->Registers->%s->Expression='%s' register='%s' is not defined",
+					name, reg.name, reg.expression, refName,
+				))
+			} else if p >= i {
+				err = append(err, fmt.Errorf("ComputedDevices->%s->Registers->%s->Expression='%s' register='%s' is not sorted before it",
+					name, reg.name, reg.expression, refName,
+				))
+			}
+		}
+	}
+
+	return
+}
+
+func (c energyDeviceConfigRead) TransformAndValidate(name string, devices []DeviceConfig) (ret EnergyDeviceConfig, err []error) {
+	var e []error
+	ret.DeviceConfig, e = c.deviceConfigRead.TransformAndValidate(name)
+	err = append(err, e...)
+
+	if len(c.Inputs) < 1 {
+		err = append(err, fmt.Errorf("EnergyDevices->%s->Inputs must not be empty", name))
+	}
+
+	for inputName, inp := range c.Inputs {
+		if !existsByName(inp.Device, devices) {
+			err = append(err, fmt.Errorf("EnergyDevices->%s->Inputs->%s->Device='%s' is not defined",
+				name, inputName, inp.Device,
+			))
+		}
+		if len(inp.Register) < 1 {
+			err = append(err, fmt.Errorf("EnergyDevices->%s->Inputs->%s->Register must not be empty",
+				name, inputName,
+			))
+		}
+
+		ret.inputs = append(ret.inputs, EnergyDeviceInputConfig{
+			name:         inputName,
+			deviceName:   inp.Device,
+			registerName: inp.Register,
+		})
+	}
+	slices.SortFunc(ret.inputs, func(i, j EnergyDeviceInputConfig) int {
+		return cmp.Compare(i.name, j.name)
+	})
+
+	if len(c.MaxGap) < 1 {
+		// use default 5m
+		ret.maxGap = 5 * time.Minute
+	} else if maxGap, e := time.ParseDuration(c.MaxGap); e != nil {
+		err = append(err, fmt.Errorf("EnergyDevices->%s->MaxGap='%s' parse error: %s",
+			name, c.MaxGap, e,
+		))
+	} else if maxGap <= 0 {
+		err = append(err, fmt.Errorf("EnergyDevices->%s->MaxGap='%s' must be positive",
+			name, c.MaxGap,
+		))
+	} else {
+		ret.maxGap = maxGap
+	}
+
+	ret.file = c.File
+
+	if len(c.WriteInterval) < 1 {
+		// use default 1m
+		ret.writeInterval = time.Minute
+	} else if writeInterval, e := time.ParseDuration(c.WriteInterval); e != nil {
+		err = append(err, fmt.Errorf("EnergyDevices->%s->WriteInterval='%s' parse error: %s",
+			name, c.WriteInterval, e,
+		))
+	} else if writeInterval <= 0 {
+		err = append(err, fmt.Errorf("EnergyDevices->%s->WriteInterval='%s' must be positive",
+			name, c.WriteInterval,
+		))
+	} else {
+		ret.writeInterval = writeInterval
+	}
+
+	return
+}
+
+func (c alarmDeviceConfigRead) TransformAndValidate(name string, devices []DeviceConfig) (ret AlarmDeviceConfig, err []error) {
+	var e []error
+	ret.DeviceConfig, e = c.deviceConfigRead.TransformAndValidate(name)
+	err = append(err, e...)
+
+	if len(c.Rules) < 1 {
+		err = append(err, fmt.Errorf("AlarmDevices->%s->Rules must not be empty", name))
+	}
+
+	for ruleName, r := range c.Rules {
+		rule, e := r.TransformAndValidate(fmt.Sprintf("AlarmDevices->%s->Rules->%s->", name, ruleName), ruleName, devices)
+		err = append(err, e...)
+		ret.rules = append(ret.rules, rule)
+	}
+	slices.SortFunc(ret.rules, func(i, j AlarmRuleConfig) int {
+		return cmp.Or(
+			cmp.Compare(i.sort, j.sort),
+			cmp.Compare(i.name, j.name),
+		)
+	})
+
+	return
+}
+
+func (c alarmRuleConfigRead) TransformAndValidate(logPrefix, name string, devices []DeviceConfig) (ret AlarmRuleConfig, err []error) {
+	ret = AlarmRuleConfig{
+		name:         name,
+		deviceName:   c.Device,
+		registerName: c.Register,
+		condition:    types.AlarmConditionFromString(c.Condition),
+		warning:      c.Warning,
+		alarm:        c.Alarm,
+		category:     "Alarms",
+		description:  name,
+	}
+
+	if name == "Acknowledge" {
+		err = append(err, fmt.Errorf("%sname is reserved for the acknowledge register", logPrefix))
+	}
+
+	if !existsByName(c.Device, devices) {
+		err = append(err, fmt.Errorf("%sDevice='%s' is not defined", logPrefix, c.Device))
+	}
+	if len(c.Register) < 1 {
+		err = append(err, fmt.Errorf("%sRegister must not be empty", logPrefix))
+	}
+
+	if ret.condition == types.AlarmConditionUndefined {
+		err = append(err, fmt.Errorf("%sCondition='%s' is invalid, must be Below or Above", logPrefix, c.Condition))
+	}
+
+	if c.Warning == nil && c.Alarm == nil {
+		err = append(err, fmt.Errorf("%sWarning and / or Alarm must be set", logPrefix))
+	} else if c.Warning != nil && c.Alarm != nil {
+		if ret.condition == types.AlarmConditionBelow && *c.Alarm > *c.Warning {
+			err = append(err, fmt.Errorf("%sAlarm=%g must not be above Warning=%g", logPrefix, *c.Alarm, *c.Warning))
+		}
+		if ret.condition == types.AlarmConditionAbove && *c.Alarm < *c.Warning {
+			err = append(err, fmt.Errorf("%sAlarm=%g must not be below Warning=%g", logPrefix, *c.Alarm, *c.Warning))
+		}
+	}
+
+	if c.Hysteresis != nil {
+		if *c.Hysteresis < 0 {
+			err = append(err, fmt.Errorf("%sHysteresis=%g must not be negative", logPrefix, *c.Hysteresis))
+		} else {
+			ret.hysteresis = *c.Hysteresis
+		}
+	}
+
+	if len(c.Delay) > 0 {
+		if delay, e := time.ParseDuration(c.Delay); e != nil {
+			err = append(err, fmt.Errorf("%sDelay='%s' parse error: %s", logPrefix, c.Delay, e))
+		} else if delay < 0 {
+			err = append(err, fmt.Errorf("%sDelay='%s' must not be negative", logPrefix, c.Delay))
+		} else {
+			ret.delay = delay
+		}
+	}
+
+	if len(c.Category) > 0 {
+		ret.category = c.Category
+	}
+
+	if len(c.Description) > 0 {
+		ret.description = c.Description
+	}
+
+	if c.Sort != nil {
+		ret.sort = *c.Sort
+	}
+
+	return
+}
+
+func (c automationDeviceConfigRead) TransformAndValidate(name string, devices []DeviceConfig) (ret AutomationDeviceConfig, err []error) {
+	var e []error
+	ret.DeviceConfig, e = c.deviceConfigRead.TransformAndValidate(name)
+	err = append(err, e...)
+
+	if len(c.Rules) < 1 {
+		err = append(err, fmt.Errorf("AutomationDevices->%s->Rules must not be empty", name))
+	}
+
+	for ruleName, r := range c.Rules {
+		rule, e := r.TransformAndValidate(fmt.Sprintf("AutomationDevices->%s->Rules->%s->", name, ruleName), ruleName, devices)
+		err = append(err, e...)
+		ret.rules = append(ret.rules, rule)
+	}
+	slices.SortFunc(ret.rules, func(i, j AutomationRuleConfig) int {
+		return cmp.Or(
+			cmp.Compare(i.sort, j.sort),
+			cmp.Compare(i.name, j.name),
+		)
+	})
+
+	return
+}
+
+func (c automationRuleConfigRead) TransformAndValidate(logPrefix, name string, devices []DeviceConfig) (ret AutomationRuleConfig, err []error) {
+	ret = AutomationRuleConfig{
+		name:         name,
+		on:           c.On,
+		off:          c.Off,
+		deviceName:   c.Device,
+		registerName: c.Register,
+		onValue:      c.OnValue,
+		offValue:     c.OffValue,
+		enabled:      true,
+		category:     "Automations",
+		description:  name,
+	}
+
+	validateExpression := func(field, source string) {
+		if len(source) < 1 {
+			err = append(err, fmt.Errorf("%s%s must not be empty", logPrefix, field))
+			return
+		}
+		expr, e := expression.Parse(source)
+		if e != nil {
+			err = append(err, fmt.Errorf("%s%s='%s' parse error: %s", logPrefix, field, source, e))
+			return
+		}
+		for _, ref := range expr.References() {
+			if !existsByName(ref.DeviceName, devices) {
+				err = append(err, fmt.Errorf("%s%s='%s' device='%s' is not defined",
+					logPrefix, field, source, ref.DeviceName,
+				))
+			}
+		}
+	}
+	validateExpression("On", c.On)
+	validateExpression("Off", c.Off)
+
+	if !existsByName(c.Device, devices) {
+		err = append(err, fmt.Errorf("%sDevice='%s' is not defined", logPrefix, c.Device))
+	}
+	if len(c.Register) < 1 {
+		err = append(err, fmt.Errorf("%sRegister must not be empty", logPrefix))
+	}
+	if len(c.OnValue) < 1 {
+		err = append(err, fmt.Errorf("%sOnValue must not be empty", logPrefix))
+	}
+	if len(c.OffValue) < 1 {
+		err = append(err, fmt.Errorf("%sOffValue must not be empty", logPrefix))
+	}
+
+	if len(c.MinOnTime) > 0 {
+		if d, e := time.ParseDuration(c.MinOnTime); e != nil {
+			err = append(err, fmt.Errorf("%sMinOnTime='%s' parse error: %s", logPrefix, c.MinOnTime, e))
+		} else if d < 0 {
+			err = append(err, fmt.Errorf("%sMinOnTime='%s' must not be negative", logPrefix, c.MinOnTime))
+		} else {
+			ret.minOnTime = d
+		}
+	}
+
+	if len(c.MinOffTime) > 0 {
+		if d, e := time.ParseDuration(c.MinOffTime); e != nil {
+			err = append(err, fmt.Errorf("%sMinOffTime='%s' parse error: %s", logPrefix, c.MinOffTime, e))
+		} else if d < 0 {
+			err = append(err, fmt.Errorf("%sMinOffTime='%s' must not be negative", logPrefix, c.MinOffTime))
+		} else {
+			ret.minOffTime = d
+		}
+	}
+
+	if c.Enabled != nil {
+		ret.enabled = *c.Enabled
+	}
+
+	if len(c.Category) > 0 {
+		ret.category = c.Category
+	}
+
+	if len(c.Description) > 0 {
+		ret.description = c.Description
+	}
+
+	if c.Sort != nil {
+		ret.sort = *c.Sort
+	}
+
+	return
+}
+
+func (c schedulerDeviceConfigRead) TransformAndValidate(name string, devices []DeviceConfig) (ret SchedulerDeviceConfig, err []error) {
+	var e []error
+	ret.DeviceConfig, e = c.deviceConfigRead.TransformAndValidate(name)
+	err = append(err, e...)
+
+	if c.Latitude != nil {
+		if *c.Latitude < -90 || *c.Latitude > 90 {
+			err = append(err, fmt.Errorf("SchedulerDevices->%s->Latitude=%g must be between -90 and 90", name, *c.Latitude))
+		}
+		ret.latitude = *c.Latitude
+	}
+	if c.Longitude != nil {
+		if *c.Longitude < -180 || *c.Longitude > 180 {
+			err = append(err, fmt.Errorf("SchedulerDevices->%s->Longitude=%g must be between -180 and 180", name, *c.Longitude))
+		}
+		ret.longitude = *c.Longitude
+	}
+
+	if len(c.Schedules) < 1 {
+		err = append(err, fmt.Errorf("SchedulerDevices->%s->Schedules must not be empty", name))
+	}
+
+	for scheduleName, sc := range c.Schedules {
+		sched, e := sc.TransformAndValidate(fmt.Sprintf("SchedulerDevices->%s->Schedules->%s->", name, scheduleName), scheduleName, devices)
+		err = append(err, e...)
+		if sched.sun != types.SunEventUndefined && (c.Latitude == nil || c.Longitude == nil) {
+			err = append(err, fmt.Errorf("SchedulerDevices->%s->Latitude and Longitude must be set when using Sun in Schedules->%s",
+				name, scheduleName,
+			))
+		}
+		ret.schedules = append(ret.schedules, sched)
+	}
+	slices.SortFunc(ret.schedules, func(i, j ScheduleConfig) int {
+		return cmp.Or(
+			cmp.Compare(i.sort, j.sort),
+			cmp.Compare(i.name, j.name),
+		)
+	})
+
+	return
+}
+
+func (c scheduleConfigRead) TransformAndValidate(logPrefix, name string, devices []DeviceConfig) (ret ScheduleConfig, err []error) {
+	ret = ScheduleConfig{
+		name:         name,
+		cron:         c.Cron,
+		deviceName:   c.Device,
+		registerName: c.Register,
+		value:        c.Value,
+		maxDelay:     5 * time.Minute,
+		enabled:      true,
+		category:     "Schedules",
+		description:  name,
+	}
+
+	if len(c.Cron) > 0 && len(c.Sun) > 0 {
+		err = append(err, fmt.Errorf("%sCron and Sun must not be set both", logPrefix))
+	} else if len(c.Cron) > 0 {
+		if _, e := schedule.ParseCron(c.Cron); e != nil {
+			err = append(err, fmt.Errorf("%sCron='%s' parse error: %s", logPrefix, c.Cron, e))
+		}
+		if len(c.Offset) > 0 {
+			err = append(err, fmt.Errorf("%sOffset can only be used with Sun", logPrefix))
+		}
+	} else if len(c.Sun) > 0 {
+		if ret.sun = types.SunEventFromString(c.Sun); ret.sun == types.SunEventUndefined {
+			err = append(err, fmt.Errorf("%sSun='%s' is invalid, must be Sunrise or Sunset", logPrefix, c.Sun))
+		}
+		if len(c.Offset) > 0 {
+			if offset, e := time.ParseDuration(c.Offset); e != nil {
+				err = append(err, fmt.Errorf("%sOffset='%s' parse error: %s", logPrefix, c.Offset, e))
+			} else {
+				ret.offset = offset
+			}
+		}
+	} else {
+		err = append(err, fmt.Errorf("%sCron or Sun must be set", logPrefix))
+	}
+
+	if !existsByName(c.Device, devices) {
+		err = append(err, fmt.Errorf("%sDevice='%s' is not defined", logPrefix, c.Device))
+	}
+	if len(c.Register) < 1 {
+		err = append(err, fmt.Errorf("%sRegister must not be empty", logPrefix))
+	}
+	if len(c.Value) < 1 {
+		err = append(err, fmt.Errorf("%sValue must not be empty", logPrefix))
+	}
+
+	if len(c.MaxDelay) > 0 {
+		if maxDelay, e := time.ParseDuration(c.MaxDelay); e != nil {
+			err = append(err, fmt.Errorf("%sMaxDelay='%s' parse error: %s", logPrefix, c.MaxDelay, e))
+		} else if maxDelay < 0 {
+			err = append(err, fmt.Errorf("%sMaxDelay='%s' must not be negative", logPrefix, c.MaxDelay))
+		} else {
+			ret.maxDelay = maxDelay
+		}
+	}
+
+	if c.Enabled != nil {
+		ret.enabled = *c.Enabled
+	}
+
+	if len(c.Category) > 0 {
+		ret.category = c.Category
+	}
+
+	if len(c.Description) > 0 {
+		ret.description = c.Description
+	}
+
+	if c.Sort != nil {
+		ret.sort = *c.Sort
+	}
+
+	return
+}
+
 func (c viewConfigRead) TransformAndValidate(devices []DeviceConfig) (ret ViewConfig, err []error) {
 	ret = ViewConfig{
 		name:         c.Name,
@@ -1413,7 +2342,7 @@ func (c viewConfigRead) TransformAndValidate(devices []DeviceConfig) (ret ViewCo
 		ret.devices, devicesErr = TransformAndValidateListUnique(
 			c.Devices,
 			func(inp viewDeviceConfigRead) (ViewDeviceConfig, []error) {
-				return inp.TransformAndValidate(devices)
+				return inp.TransformAndValidate(c.Name, devices)
 			},
 			func(needle ViewDeviceConfig, haystack []ViewDeviceConfig) (err []error) {
 				if existsByName(needle.Name(), haystack) {
@@ -1444,6 +2373,7 @@ func (c viewConfigRead) TransformAndValidate(devices []DeviceConfig) (ret ViewCo
 }
 
 func (c viewDeviceConfigRead) TransformAndValidate(
+	viewName string,
 	devices []DeviceConfig,
 ) (ret ViewDeviceConfig, err []error) {
 	ret = ViewDeviceConfig{
@@ -1456,20 +2386,33 @@ func (c viewDeviceConfigRead) TransformAndValidate(
 	}
 
 	var e []error
-	ret.filter, e = c.Filter.TransformAndValidate()
+	ret.filter, e = c.Filter.TransformAndValidate(fmt.Sprintf("Views->%s->Devices->%s->", viewName, c.Name))
 	err = append(err, e...)
 
 	return
 }
 
-func (c filterConfigRead) TransformAndValidate() (ret FilterConfig, err []error) {
+func (c filterConfigRead) TransformAndValidate(logPrefix string) (ret FilterConfig, err []error) {
 	ret = FilterConfig{
 		includeRegisters:  c.IncludeRegisters,
 		skipRegisters:     c.SkipRegisters,
 		includeCategories: c.IncludeCategories,
 		skipCategories:    c.SkipCategories,
+		logPrefix:         logPrefix + "Filter",
 	}
 
+	// entries may be glob patterns or regular expressions; make sure they compile
+	validate := func(field string, entries []string) {
+		_, e := namePattern.CompileList(entries)
+		for _, ce := range e {
+			err = append(err, fmt.Errorf("%sFilter->%s%s", logPrefix, field, ce))
+		}
+	}
+	validate("IncludeRegisters", c.IncludeRegisters)
+	validate("SkipRegisters", c.SkipRegisters)
+	validate("IncludeCategories", c.IncludeCategories)
+	validate("SkipCategories", c.SkipCategories)
+
 	if c.DefaultInclude == nil {
 		ret.defaultInclude = true
 	} else {
@@ -1479,6 +2422,131 @@ func (c filterConfigRead) TransformAndValidate() (ret FilterConfig, err []error)
 	return
 }
 
+func (c deadbandConfigRead) TransformAndValidate(deviceName string) (ret DeadbandConfig, err []error) {
+	ret = DeadbandConfig{
+		registers:  make(map[string]DeadbandValue, len(c.Registers)),
+		categories: make(map[string]DeadbandValue, len(c.Categories)),
+	}
+
+	for registerName, s := range c.Registers {
+		if v, e := parseDeadbandValue(s); e != nil {
+			err = append(err, fmt.Errorf("Devices->%s->Deadband->Registers->%s='%s' parse error: %s",
+				deviceName, registerName, s, e,
+			))
+		} else {
+			ret.registers[registerName] = v
+		}
+	}
+
+	for category, s := range c.Categories {
+		if v, e := parseDeadbandValue(s); e != nil {
+			err = append(err, fmt.Errorf("Devices->%s->Deadband->Categories->%s='%s' parse error: %s",
+				deviceName, category, s, e,
+			))
+		} else {
+			ret.categories[category] = v
+		}
+	}
+
+	if len(c.MaxSilence) < 1 {
+		// use default 1min
+		ret.maxSilence = time.Minute
+	} else if maxSilence, e := time.ParseDuration(c.MaxSilence); e != nil {
+		err = append(err, fmt.Errorf("Devices->%s->Deadband->MaxSilence='%s' parse error: %s",
+			deviceName, c.MaxSilence, e,
+		))
+	} else if maxSilence < 0 {
+		err = append(err, fmt.Errorf("Devices->%s->Deadband->MaxSilence='%s' must not be negative",
+			deviceName, c.MaxSilence,
+		))
+	} else {
+		ret.maxSilence = maxSilence
+	}
+
+	return
+}
+
+func (c registerTransformConfigRead) TransformAndValidate(deviceName, registerName string) (ret RegisterTransformConfig, err []error) {
+	ret = RegisterTransformConfig{
+		scale:     1,
+		unit:      c.Unit,
+		precision: -1,
+		enum:      c.Enum,
+	}
+
+	if c.Scale != nil {
+		if *c.Scale == 0 {
+			err = append(err, fmt.Errorf("Devices->%s->Transform->%s->Scale must not be 0",
+				deviceName, registerName,
+			))
+		} else {
+			ret.scale = *c.Scale
+		}
+	}
+
+	if c.Offset != nil {
+		ret.offset = *c.Offset
+	}
+
+	if c.Precision != nil {
+		if *c.Precision < -1 {
+			err = append(err, fmt.Errorf("Devices->%s->Transform->%s->Precision='%d' must be >=-1",
+				deviceName, registerName, *c.Precision,
+			))
+		} else {
+			ret.precision = *c.Precision
+		}
+	}
+
+	return
+}
+
+func (c registerLimitsConfigRead) TransformAndValidate(deviceName, registerName string) (ret RegisterLimitsConfig, err []error) {
+	ret = RegisterLimitsConfig{
+		min:  c.Min,
+		max:  c.Max,
+		step: c.Step,
+	}
+
+	if c.Min != nil && c.Max != nil && *c.Min > *c.Max {
+		err = append(err, fmt.Errorf("Devices->%s->Limits->%s->Min='%g' must not be greater than Max='%g'",
+			deviceName, registerName, *c.Min, *c.Max,
+		))
+	}
+
+	if c.Step != nil && *c.Step <= 0 {
+		err = append(err, fmt.Errorf("Devices->%s->Limits->%s->Step='%g' must be positive",
+			deviceName, registerName, *c.Step,
+		))
+	}
+
+	return
+}
+
+// parseDeadbandValue parses an absolute deadband like "0.05" or a relative one like "2%".
+func parseDeadbandValue(s string) (ret DeadbandValue, err error) {
+	s = strings.TrimSpace(s)
+	relative := strings.HasSuffix(s, "%")
+	if relative {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return
+	}
+	if v < 0 {
+		return ret, fmt.Errorf("must not be negative")
+	}
+
+	if relative {
+		ret.relative = v / 100
+	} else {
+		ret.absolute = v
+	}
+	return
+}
+
 func TransformAndValidateMapToList[I any, O any](
 	inp map[string]I,
 	transformer func(inp I, name string) (ret O, err []error),
@@ -42,6 +42,29 @@ Authentication:                                            # optional, when miss
   JwtSecret: 'aiziax9Hied0ier9Yo0Lo6bi3xahth7o'            # optional, default random, used to sign the JWT tokens
   JwtValidityPeriod: 2h                                    # optional, default 1h, users are logged out after this time
   HtaccessFile: ./my-auth.passwd                           # mandatory, where the file generated by htpasswd can be found
+  AdminUsers:                                              # optional, default empty, users allowed to use the admin api and to reload the configuration
+    - admin0
+
+Persistence:                                               # optional, when missing: values and commands are lost on restart
+  StateFile: ./my-state.json                               # optional, default empty, where to persist the current values
+  CommandFile: ./my-command.json                           # optional, default empty, where to persist the last command per register
+  WriteInterval: 30s                                       # optional, default 1m, how often changes are written to disk
+  LogDebug: true                                           # optional, default false, output debug messages related to persistence
+
+History:                                                   # optional, when missing: no history is kept
+  RawRetention: 2h                                         # optional, default 1h
+  MinuteRetention: 48h                                     # optional, default 24h
+  QuarterRetention: 0s                                     # optional, default 168h
+  File: ./my-history.json                                  # optional, default empty
+  WriteInterval: 10m                                       # optional, default 5m
+  LogDebug: true                                           # optional, default false
+
+Recorder:                                                  # optional, when missing: the values are not recorded
+  File: ./my-recording.jsonl                               # mandatory
+  Devices:                                                 # optional, default empty
+    - bmv0
+  FlushInterval: 5s                                        # optional, default 1s
+  LogDebug: true                                           # optional, default false
 
 MqttClients:                                               # optional, when empty, no mqtt connection is made
   0-local:                                                 # mandatory, an arbitrary name used for logging and for referencing in other config sections
@@ -141,6 +164,13 @@ MqttClients:                                               # optional, when empt
       Enabled: false                                 # optional, default false, whether to enable sending realtime messages
       TopicTemplate: '%Prefix%cmnd-X/go-iotdevice/%DeviceName%/%RegisterName%' # optional, what topic to use for realtime messages
 
+    CommandResponse:
+      Enabled: true                                  # optional, default false, whether to publish the status of commands
+      TopicTemplate: '%Prefix%resp-X/go-iotdevice/%DeviceName%/%RegisterName%' # optional, what topic to use for command responses
+      Retain: true                                   # optional, default false, the mqtt retain flag for command responses
+      Devices:
+        modbus-rtu0:
+
     LogDebug: true                                         # optional, default false, very verbose debug log of the mqtt connection
     LogMessages: true                                      # optional, default false, log all incoming mqtt messages
 
@@ -168,12 +198,39 @@ VictronDevices:                                            # optional, a list of
         - Settings                                         # for solar devices it might make sense to not fetch / output the settings
     RestartInterval: 400ms                               # optional, default 200ms, how fast to restart the device if it fails / disconnects
     RestartIntervalMaxBackoff: 2m                        # optional, default 1m; when it fails, the restart interval is exponentially increased up to this maximum
+    MaxAge: 30s                                          # optional, default 0s (disabled), values not updated within this duration are removed as stale
+    RegisterMaxAge:                                      # optional, default empty, overrides MaxAge for single registers
+      BatteryVoltage: 5s
+      ProductId: 0s
+    Deadband:                                            # optional, default disabled
+      Registers:
+        Current: 0.05
+      Categories:
+        Monitor: 2%
+      MaxSilence: 30s
+    Transform:                                           # optional, default empty
+      Current:
+        Scale: 1000
+        Offset: 0.5
+        Unit: mA
+        Precision: 0
+      Relay:
+        Enum:
+          1: Pump running
+    Limits:                                              # optional, default empty
+      Current:
+        Min: -5000
+        Max: 5000
+        Step: 100
+      Setpoint:
+        Max: 30
     LogDebug: true                                       # optional, default false, enable debug log output
     LogComDebug: true                                    # optional, default false, enable a verbose log of the communication with the device
     Device: /dev/serial/by-id/usb-VictronEnergy_BV_VE_Direct_cable_VEHTVQT-if00-port0 # mandatory except if Kind: Random*, the path to the usb-to-serial converter
     Kind: Vedirect                                         # mandatory, possibilities: Vedirect, RandomBmv, RandomSolar, always set to Vedirect expect for development
     PollInterval: 700ms                                   # optional, default 0.1s, how often to fetch the registers
     IoLog: /tmp/bmv0.log                                  # optional, default empty, path to a file where the raw io is logged
+    ReplaySpeed: 10                                        # optional, default 1, Kind: Replay only, 1 replays at the original timing, 0 without any delay
 
 ModbusDevices:                                             # optional, a list of devices connected via ModBus
   modbus-rtu0:                                             # mandatory, an arbitrary name used for logging and for referencing in other config sections
@@ -206,6 +263,9 @@ ModbusDevices:                                             # optional, a list of
         OpenLabel: Off                                     # optional, default "open", a label for the open state
         ClosedLabel: On                                    # optional, default "closed", a label for the closed state
     PollInterval: 1s                                       # optional, default 1s, how often to fetch the device status
+    CommandReadback:                                       # optional, default disabled, confirm commands by reading them back
+      Retries: 5                                           # optional, default 3
+      Backoff: 2s                                          # optional, default 1s
 
 GpioDevices:                                               # optional, a list of devices controlled via gpio
   gpio0:                                                   # mandatory, an arbitrary name used for logging and for referencing in other config sections
@@ -262,12 +322,23 @@ MqttDevices:                                               # optional, a list of
     LogComDebug: true                                    # optional, default false, enable a verbose log of the communication with the device
     Kind: GoIotdeviceV3
 
+ReplayDevices:                                             # optional, a list of devices replaying the values of a recording
+  bmv0-replay:                                             # mandatory, an arbitrary name used for logging and for referencing in other config sections
+    LogDebug: true                                         # optional, default false, enable debug log output
+    File: ./my-recording.jsonl                             # mandatory, the recording to replay
+    Device: bmv0                                           # optional, default the name of this device
+    Speed: 10                                              # optional, default 1
+    Loop: false                                            # optional, default true
+
 GensetDevices:                                             # optional, a list generator set control devices
   genset0:                                                 # mandatory, an arbitrary name used for logging and for referencing in other config sections
     RestartInterval: 60ms                                  # optional, default 200ms, how fast to restart the device if it fails / disconnects
     RestartIntervalMaxBackoff: 3m                          # optional, default 1m; when it fails, the restart interval is exponentially increased up to this maximum
     LogDebug: false                                        # optional, default false, enable debug log output
     LogComDebug: true                                      # optional, default false, enable a verbose log of the communication with the device
+    DependsOn:                                             # optional, the bound devices and registers are added automatically
+      bmv0: [MainVoltage]
+    DependencyTimeout: 1m                                  # optional, default 30s
 
     InputBindings:                                         # mandatory, a list of input bindings
       tcw241:                                              # the device name of the input device
@@ -314,6 +385,86 @@ GensetDevices:                                             # optional, a list ge
     PMax: 240                                              # optional, default 1E6, maximum power the generator must have to not trigger the error state
     PTotMax: 250                                          # optional, default 1E6, maximum total power the generator must have to not trigger the error state
 
+ComputedDevices:                                           # optional, a list of virtual devices whose registers are computed from other devices
+  power0:                                                  # mandatory, an arbitrary name used for logging and for referencing in other config sections
+    RestartInterval: 70ms                                  # optional, default 200ms, how fast to restart the device if it fails / disconnects
+    Registers:                                             # mandatory, the computed registers
+      BatteryPower:                                        # the register name
+        Expression: bmv0.MainVoltage * bmv0.Current        # mandatory, an expression over registers of other devices
+        Category: Battery                                  # optional, default Computed
+        Description: Battery Power                         # optional, default the register name
+        Unit: W                                            # optional, default empty
+        Sort: 10                                           # optional, default 0
+      Charging:
+        Expression: bmv0.Current > 0.5 && {modbus-rtu0.CH0}
+        Type: Bool                                         # optional, default Number; Number or Bool
+
+EnergyDevices:                                             # optional, a list of devices integrating power registers into energy counters
+  energy0:                                                 # mandatory, an arbitrary name used for logging and for referencing in other config sections
+    Inputs:                                                # mandatory, the power registers to integrate
+      Solar:                                               # the prefix of the energy registers
+        Device: bmv0                                       # mandatory, the device providing the power
+        Register: PanelPower                               # mandatory, the power register in W or kW
+      Battery:
+        Device: power0
+        Register: BatteryPower
+    MaxGap: 2m                                             # optional, default 5m, no energy is added between samples further apart
+    File: /tmp/energy0.json                                # optional, default empty, where the counters are persisted
+    WriteInterval: 30s                                     # optional, default 1m, how often the counters are written
+
+AlarmDevices:                                              # optional, a list of devices evaluating alarm rules
+  alarms0:                                                 # mandatory, an arbitrary name used for logging and for referencing in other config sections
+    Rules:                                                 # mandatory, one alarm register per rule
+      BatteryLow:
+        Device: bmv0                                       # mandatory, the device providing the value
+        Register: MainVoltage                              # mandatory, a numeric register
+        Condition: Below                                   # mandatory, Below or Above
+        Warning: 12.0                                      # optional
+        Alarm: 11.8                                        # optional, at least one of Warning / Alarm
+        Hysteresis: 0.2                                    # optional, default 0
+        Delay: 60s                                         # optional, default 0s
+        Description: Battery Voltage Low                   # optional, default the rule name
+        Sort: 10                                           # optional, default 0
+      Temperature:
+        Device: tcw241
+        Register: Temp1
+        Condition: Above
+        Alarm: 45
+
+AutomationDevices:                                         # optional, a list of devices switching registers of other devices
+  automation0:                                             # mandatory, an arbitrary name used for logging and for referencing in other config sections
+    Rules:                                                 # mandatory, one writable enable register per rule
+      ChargeRelay:
+        On: bmv0.SOC < 30                                  # mandatory, switch on when this expression holds
+        Off: bmv0.SOC > 80                                 # mandatory, switch off when this expression holds
+        Device: modbus-rtu0                                # mandatory, the device to switch
+        Register: CH2                                      # mandatory, the writable register to switch
+        OnValue: closed                                    # mandatory, the value written when switching on
+        OffValue: open                                     # mandatory, the value written when switching off
+        MinOnTime: 10m                                     # optional, default 0s
+        MinOffTime: 5m                                     # optional, default 0s
+        Enabled: false                                     # optional, default true
+        Description: Charge Relay                          # optional, default the rule name
+
+SchedulerDevices:                                          # optional, a list of devices writing values at scheduled times
+  scheduler0:                                              # mandatory, an arbitrary name used for logging and for referencing in other config sections
+    Latitude: 46.95                                        # optional, mandatory when Sun is used
+    Longitude: 7.45                                        # optional, mandatory when Sun is used
+    Schedules:                                             # mandatory, creates the registers <name>Enabled and <name>NextRun per schedule
+      Heating:
+        Cron: "0 5 * * *"                                  # Cron or Sun is mandatory
+        Device: gpio0                                      # mandatory, the device to send the value to
+        Register: Heating                                  # mandatory, a writable register
+        Value: "1"                                         # mandatory, the value to write
+        MaxDelay: 30m                                      # optional, default 5m
+      Light:
+        Sun: Sunset                                        # Sunrise or Sunset
+        Offset: -30m                                       # optional, default 0s
+        Device: modbus-rtu0
+        Register: CH3
+        Value: closed
+        Enabled: false                                     # optional, default true
+
 Views:                                                     # optional, a list of views (=categories in the frontend / paths in the api URLs)
   - Name: private                                          # mandatory, a technical name used in the URLs
     Title: Private                                         # mandatory, a nice title displayed in the frontend
@@ -389,6 +540,10 @@ MqttDevices:                                               # optional, a list of
   bmv1:                                                    # mandatory, an arbitrary name used for logging and for referencing in other config sections
     Kind: GoIotdeviceV3
 
+ReplayDevices:                                             # optional, a list of devices replaying the values of a recording
+  bmv0-replay:                                             # mandatory, an arbitrary name used for logging and for referencing in other config sections
+    File: ./my-recording.jsonl                             # mandatory, the recording to replay
+
 GensetDevices:                                             # optional, a list generator set control devices
   genset0:                                                 # mandatory, an arbitrary name used for logging and for referencing in other config sections
 
@@ -409,6 +564,119 @@ Views:                                                     # optional, a list of
 `
 )
 
+const InvalidVirtualDevicesConfig = `
+Version: 2
+VictronDevices:
+  bmv0:
+    Device: /dev/ttyVE0
+    Kind: Vedirect
+    Filter:
+      SkipRegisters:
+        - "/(Min|Max/"
+      IncludeCategories:
+        - "Relays[1-"
+    Transform:
+      Voltage:
+        Scale: 0
+    Limits:
+      Voltage:
+        Min: 10
+        Max: 5
+        Step: 0
+  replay0:
+    Kind: Replay
+    ReplaySpeed: -1
+ReplayDevices:
+  replay1:
+    Speed: 0
+Recorder:
+  Devices: [mppt0]
+  FlushInterval: 0s
+ComputedDevices:
+  power0:
+    Registers:
+      Power:
+        Expression: bmv0.Voltage *
+      Solar:
+        Expression: "{mppt0.Power} + 1"
+        Type: Text
+      Total:
+        Expression: power0.Missing + power0.Later
+        Sort: 1
+      Later:
+        Expression: "1"
+        Sort: 2
+    DependsOn:
+      energy0: []
+EnergyDevices:
+  energy0:
+    DependsOn:
+      power0: []
+      mppt0: []
+      energy0: []
+    DependencyTimeout: 0s
+    Inputs:
+      Solar:
+        Device: mppt0
+        Register: PanelPower
+    MaxGap: -1m
+AlarmDevices:
+  alarms0:
+    Rules:
+      BatteryLow:
+        Device: mppt0
+        Register: MainVoltage
+        Condition: Lower
+        Warning: 11.8
+        Alarm: 12.0
+        Hysteresis: -1
+        Delay: 1x
+      Temperature:
+        Device: tcw241
+        Register: Temp1
+        Condition: Above
+      Acknowledge:
+        Device: bmv0
+        Register: MainVoltage
+        Condition: Below
+        Alarm: 11
+AutomationDevices:
+  automation0:
+    Rules:
+      ChargeRelay:
+        On: bmv0.SOC <
+        Off: mppt0.SOC > 80
+        Device: modbus-rtu0
+        Register: CH2
+        MinOnTime: -1m
+SchedulerDevices:
+  scheduler0:
+    Latitude: 91
+    Schedules:
+      Heating:
+        Cron: "0 25 * * *"
+        Offset: 1m
+        Device: gpio0
+        Register: Heating
+        Value: "1"
+        MaxDelay: -1m
+      Light:
+        Sun: Dusk
+        Device: bmv0
+        Register: CH3
+      Both:
+        Cron: "* * * * *"
+        Sun: Sunset
+        Device: bmv0
+        Register: CH3
+        Value: closed
+      Dawn:
+        Sun: Sunrise
+        Device: bmv0
+        Register: CH3
+        Value: closed
+`
+
 func containsError(needle string, err []error) bool {
 	for _, e := range err {
 		if strings.Contains(e.Error(), needle) {
@@ -441,6 +709,60 @@ func TestReadConfig_InvalidUnknownVersion(t *testing.T) {
 }
 
 // check that a complex example setting all available options is correctly read
+func TestReadConfig_InvalidVirtualDevices(t *testing.T) {
+	_, err := ReadConfig([]byte(InvalidVirtualDevicesConfig), true)
+
+	for _, needle := range []string{
+		"ComputedDevices->power0->Registers->Power->Expression='bmv0.Voltage *' parse error",
+		"ComputedDevices->power0->Registers->Solar->Expression='{mppt0.Power} + 1' device='mppt0' is not defined",
+		"ComputedDevices->power0->Registers->Solar->Type='Text' is invalid",
+		"ComputedDevices->power0->Registers->Total->Expression='power0.Missing + power0.Later' register='Missing' is not defined",
+		"ComputedDevices->power0->Registers->Total->Expression='power0.Missing + power0.Later' register='Later' is not sorted before it",
+		"EnergyDevices->energy0->Inputs->Solar->Device='mppt0' is not defined",
+		"EnergyDevices->energy0->MaxGap='-1m' must be positive",
+		"AlarmDevices->alarms0->Rules->BatteryLow->Device='mppt0' is not defined",
+		"AlarmDevices->alarms0->Rules->BatteryLow->Condition='Lower' is invalid",
+		"AlarmDevices->alarms0->Rules->BatteryLow->Hysteresis=-1 must not be negative",
+		"AlarmDevices->alarms0->Rules->BatteryLow->Delay='1x' parse error",
+		"AlarmDevices->alarms0->Rules->Temperature->Warning and / or Alarm must be set",
+		"AlarmDevices->alarms0->Rules->Acknowledge->name is reserved",
+		"AutomationDevices->automation0->Rules->ChargeRelay->On='bmv0.SOC <' parse error",
+		"AutomationDevices->automation0->Rules->ChargeRelay->Off='mppt0.SOC > 80' device='mppt0' is not defined",
+		"AutomationDevices->automation0->Rules->ChargeRelay->Device='modbus-rtu0' is not defined",
+		"AutomationDevices->automation0->Rules->ChargeRelay->OnValue must not be empty",
+		"AutomationDevices->automation0->Rules->ChargeRelay->MinOnTime='-1m' must not be negative",
+		"SchedulerDevices->scheduler0->Latitude=91 must be between -90 and 90",
+		"SchedulerDevices->scheduler0->Schedules->Heating->Cron='0 25 * * *' parse error: invalid hour '25'",
+		"SchedulerDevices->scheduler0->Schedules->Heating->Offset can only be used with Sun",
+		"SchedulerDevices->scheduler0->Schedules->Heating->MaxDelay='-1m' must not be negative",
+		"SchedulerDevices->scheduler0->Schedules->Light->Sun='Dusk' is invalid",
+		"SchedulerDevices->scheduler0->Schedules->Light->Value must not be empty",
+		"SchedulerDevices->scheduler0->Schedules->Both->Cron and Sun must not be set both",
+		"SchedulerDevices->scheduler0->Latitude and Longitude must be set when using Sun in Schedules->Dawn",
+		"Devices->bmv0->Transform->Voltage->Scale must not be 0",
+		"Devices->bmv0->Limits->Voltage->Min='10' must not be greater than Max='5'",
+		"Devices->bmv0->Limits->Voltage->Step='0' must be positive",
+		"Devices->bmv0->Filter->SkipRegisters[0]='/(Min|Max/': invalid regular expression",
+		"Devices->bmv0->Filter->IncludeCategories[0]='Relays[1-': invalid glob pattern",
+		"Devices->energy0->DependsOn->mppt0 is not defined",
+		"Devices->energy0->DependsOn->energy0: a device must not depend on itself",
+		"Devices->energy0->DependsOn->power0 is circular",
+		"Devices->power0->DependsOn->energy0 is circular",
+		"Devices->energy0->DependencyTimeout='0s' must be positive",
+		"VictronDevices->replay0->Device must not be empty",
+		"VictronDevices->replay0->ReplaySpeed=-1 must not be negative",
+		"ReplayDevices->replay1->File must not be empty",
+		"ReplayDevices->replay1->Speed=0 must be positive",
+		"Recorder->File must be set or the whole section must be missing",
+		"Recorder->Devices->mppt0 is not defined",
+		"Recorder->FlushInterval='0s' must be positive",
+	} {
+		if !containsError(needle, err) {
+			t.Errorf("expect error containing '%s', got: %v", needle, err)
+		}
+	}
+}
+
 func TestReadConfig_Complete(t *testing.T) {
 	config, err := ReadConfig([]byte(ValidCompleteConfig), true)
 	if len(err) > 0 {
@@ -533,6 +855,90 @@ func TestReadConfig_Complete(t *testing.T) {
 		if expect, got := "./my-auth.passwd", a.HtaccessFile(); expect != got {
 			t.Errorf("expect Authentication->HtaccessFile to be '%s' but got '%s'", expect, got)
 		}
+
+		if !a.IsAdmin("admin0") || a.IsAdmin("test0") {
+			t.Errorf("expect only admin0 to be in Authentication->AdminUsers")
+		}
+	}
+
+	{
+		p := config.Persistence()
+
+		if !p.Enabled() {
+			t.Error("expect Persistence->Enabled to be true")
+		}
+
+		if expect, got := "./my-state.json", p.StateFile(); expect != got {
+			t.Errorf("expect Persistence->StateFile to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := "./my-command.json", p.CommandFile(); expect != got {
+			t.Errorf("expect Persistence->CommandFile to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := 30*time.Second, p.WriteInterval(); expect != got {
+			t.Errorf("expect Persistence->WriteInterval to be %s but got %s", expect, got)
+		}
+
+		if !p.LogDebug() {
+			t.Error("expect Persistence->LogDebug to be true")
+		}
+	}
+
+	{
+		h := config.History()
+
+		if !h.Enabled() {
+			t.Error("expect History->Enabled to be true")
+		}
+
+		if expect, got := 2*time.Hour, h.RawRetention(); expect != got {
+			t.Errorf("expect History->RawRetention to be %s but got %s", expect, got)
+		}
+
+		if expect, got := 48*time.Hour, h.MinuteRetention(); expect != got {
+			t.Errorf("expect History->MinuteRetention to be %s but got %s", expect, got)
+		}
+
+		if expect, got := time.Duration(0), h.QuarterRetention(); expect != got {
+			t.Errorf("expect History->QuarterRetention to be %s but got %s", expect, got)
+		}
+
+		if expect, got := "./my-history.json", h.File(); expect != got {
+			t.Errorf("expect History->File to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := 10*time.Minute, h.WriteInterval(); expect != got {
+			t.Errorf("expect History->WriteInterval to be %s but got %s", expect, got)
+		}
+
+		if !h.LogDebug() {
+			t.Error("expect History->LogDebug to be true")
+		}
+	}
+
+	{
+		r := config.Recorder()
+
+		if !r.Enabled() {
+			t.Error("expect Recorder->Enabled to be true")
+		}
+
+		if expect, got := "./my-recording.jsonl", r.File(); expect != got {
+			t.Errorf("expect Recorder->File to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := []string{"bmv0"}, r.Devices(); !reflect.DeepEqual(expect, got) {
+			t.Errorf("expect Recorder->Devices to be %v but got %v", expect, got)
+		}
+
+		if expect, got := 5*time.Second, r.FlushInterval(); expect != got {
+			t.Errorf("expect Recorder->FlushInterval to be %s but got %s", expect, got)
+		}
+
+		if !r.LogDebug() {
+			t.Error("expect Recorder->LogDebug to be true")
+		}
 	}
 
 	if expect, got := 3, len(config.MqttClients()); expect != got {
@@ -834,7 +1240,7 @@ func TestReadConfig_Complete(t *testing.T) {
 					t.Errorf("expect %s->Qos to be %d but got %d", sPrefix, expect, got)
 				}
 
-				if expect, got := []string{"bmv0", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+				if expect, got := []string{"alarms0", "automation0", "bmv0", "bmv0-replay", "energy0", "genset0", "gpio0", "modbus-rtu0", "power0", "scheduler0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
 					t.Errorf("expect %s->Devices to be %v but got %v", sPrefix, expect, got)
 				} else {
 					prefix := sPrefix + "->Devices->bmv0"
@@ -890,7 +1296,7 @@ func TestReadConfig_Complete(t *testing.T) {
 					t.Errorf("expect %s->Qos to be %d but got %d", sPrefix, expect, got)
 				}
 
-				if expect, got := []string{"bmv0", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+				if expect, got := []string{"alarms0", "automation0", "bmv0", "bmv0-replay", "energy0", "genset0", "gpio0", "modbus-rtu0", "power0", "scheduler0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
 					t.Errorf("expect %s->Devices to be %v but got %v", sPrefix, expect, got)
 				} else {
 					prefix := sPrefix + "->Devices->bmv0"
@@ -918,6 +1324,27 @@ func TestReadConfig_Complete(t *testing.T) {
 				}
 			}
 
+			{
+				mcSect := mc.CommandResponse()
+				sPrefix := prefix + "->CommandResponse"
+
+				if expect, got := "my-prefix/resp-X/go-iotdevice/my-dev/my-reg", mc.CommandResponseTopic("my-dev", "my-reg"); expect != got {
+					t.Errorf("expect %s->CommandResponseTopic to be '%s' but got '%s'", prefix, expect, got)
+				}
+
+				if got := mcSect.Enabled(); !got {
+					t.Errorf("expect %s->Enabled to be true", sPrefix)
+				}
+
+				if got := mcSect.Retain(); !got {
+					t.Errorf("expect %s->Retain to be true", sPrefix)
+				}
+
+				if expect, got := []string{"modbus-rtu0"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+					t.Errorf("expect %s->Devices to be %v but got %v", sPrefix, expect, got)
+				}
+			}
+
 			{
 				mcSect := mc.Command()
 				sPrefix := prefix + "->Command"
@@ -946,7 +1373,7 @@ func TestReadConfig_Complete(t *testing.T) {
 					t.Errorf("expect %s->Qos to be %d but got %d", sPrefix, expect, got)
 				}
 
-				if expect, got := []string{"bmv0", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+				if expect, got := []string{"alarms0", "automation0", "bmv0", "bmv0-replay", "energy0", "genset0", "gpio0", "modbus-rtu0", "power0", "scheduler0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
 					t.Errorf("expect %s->Devices to be %v but got %v", sPrefix, expect, got)
 				} else {
 					prefix := sPrefix + "->Devices->bmv0"
@@ -1114,6 +1541,101 @@ func TestReadConfig_Complete(t *testing.T) {
 			t.Errorf("expect VictronDevices->bmv0->General->RestartIntervalMaxBackoff to be %s but got %s", expect, got)
 		}
 
+		if expect, got := 30*time.Second, vd.MaxAge(); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->General->MaxAge to be %s but got %s", expect, got)
+		}
+
+		if expect, got := 5*time.Second, vd.MaxAgeOf("BatteryVoltage"); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->General->MaxAgeOf(BatteryVoltage) to be %s but got %s", expect, got)
+		}
+
+		if expect, got := time.Duration(0), vd.MaxAgeOf("ProductId"); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->General->MaxAgeOf(ProductId) to be %s but got %s", expect, got)
+		}
+
+		if expect, got := 30*time.Second, vd.MaxAgeOf("Power"); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->General->MaxAgeOf(Power) to be %s but got %s", expect, got)
+		}
+
+		if !vd.Deadband().Enabled() {
+			t.Error("expect VictronDevices->bmv0->General->Deadband to be enabled")
+		}
+
+		if db, ok := vd.Deadband().Of("Monitor", "Current"); !ok || db.Absolute() != 0.05 || db.Relative() != 0 {
+			t.Errorf("expect VictronDevices->bmv0->General->Deadband->Current to be 0.05 but got %s", db)
+		}
+
+		if db, ok := vd.Deadband().Of("Monitor", "Power"); !ok || db.Absolute() != 0 || db.Relative() != 0.02 {
+			t.Errorf("expect VictronDevices->bmv0->General->Deadband->Monitor to be 2%% but got %s", db)
+		}
+
+		if _, ok := vd.Deadband().Of("Product", "ProductId"); ok {
+			t.Error("expect VictronDevices->bmv0->General->Deadband not to apply to ProductId")
+		}
+
+		if expect, got := 30*time.Second, vd.Deadband().MaxSilence(); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->General->Deadband->MaxSilence to be %s but got %s", expect, got)
+		}
+
+		if expect, got := 2, len(vd.Transform()); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->General->Transform to have %d items but got %d", expect, got)
+		}
+
+		if tc, ok := vd.Transform()["Current"]; !ok {
+			t.Error("expect VictronDevices->bmv0->General->Transform->Current to be defined")
+		} else {
+			if expect, got := 1000.0, tc.Scale(); expect != got {
+				t.Errorf("expect VictronDevices->bmv0->General->Transform->Current->Scale to be %f but got %f", expect, got)
+			}
+			if expect, got := 0.5, tc.Offset(); expect != got {
+				t.Errorf("expect VictronDevices->bmv0->General->Transform->Current->Offset to be %f but got %f", expect, got)
+			}
+			if expect, got := "mA", tc.Unit(); expect != got {
+				t.Errorf("expect VictronDevices->bmv0->General->Transform->Current->Unit to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := 0, tc.Precision(); expect != got {
+				t.Errorf("expect VictronDevices->bmv0->General->Transform->Current->Precision to be %d but got %d", expect, got)
+			}
+		}
+
+		if tc, ok := vd.Transform()["Relay"]; !ok {
+			t.Error("expect VictronDevices->bmv0->General->Transform->Relay to be defined")
+		} else {
+			if expect, got := 1.0, tc.Scale(); expect != got {
+				t.Errorf("expect VictronDevices->bmv0->General->Transform->Relay->Scale to be %f but got %f", expect, got)
+			}
+			if expect, got := -1, tc.Precision(); expect != got {
+				t.Errorf("expect VictronDevices->bmv0->General->Transform->Relay->Precision to be %d but got %d", expect, got)
+			}
+			if expect, got := "Pump running", tc.Enum()[1]; expect != got {
+				t.Errorf("expect VictronDevices->bmv0->General->Transform->Relay->Enum->1 to be '%s' but got '%s'", expect, got)
+			}
+		}
+
+		if expect, got := 2, len(vd.Limits()); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->General->Limits to have %d items but got %d", expect, got)
+		}
+
+		if lc, ok := vd.Limits()["Current"]; !ok {
+			t.Error("expect VictronDevices->bmv0->General->Limits->Current to be defined")
+		} else {
+			if got := lc.Min(); got == nil || *got != -5000 {
+				t.Errorf("expect VictronDevices->bmv0->General->Limits->Current->Min to be -5000 but got %v", got)
+			}
+			if got := lc.Max(); got == nil || *got != 5000 {
+				t.Errorf("expect VictronDevices->bmv0->General->Limits->Current->Max to be 5000 but got %v", got)
+			}
+			if got := lc.Step(); got == nil || *got != 100 {
+				t.Errorf("expect VictronDevices->bmv0->General->Limits->Current->Step to be 100 but got %v", got)
+			}
+		}
+
+		if lc, ok := vd.Limits()["Setpoint"]; !ok {
+			t.Error("expect VictronDevices->bmv0->General->Limits->Setpoint to be defined")
+		} else if got := lc.Min(); got != nil {
+			t.Errorf("expect VictronDevices->bmv0->General->Limits->Setpoint->Min to be unset but got %v", *got)
+		}
+
 		if !vd.LogDebug() {
 			t.Error("expect VictronDevices->bmv0->General->LogDebug to be true")
 		}
@@ -1137,6 +1659,10 @@ func TestReadConfig_Complete(t *testing.T) {
 		if expect, got := 700*time.Millisecond, vd.PollInterval(); expect != got {
 			t.Errorf("expect VictronDevices->bmv0->PollInterval to be %s but got %s", expect, got)
 		}
+
+		if expect, got := 10.0, vd.ReplaySpeed(); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->ReplaySpeed to be %g but got %g", expect, got)
+		}
 	}
 
 	if expect, got := 1, len(config.ModbusDevices()); expect != got {
@@ -1200,6 +1726,17 @@ func TestReadConfig_Complete(t *testing.T) {
 		if expect, got := byte(0x01), md.Address(); expect != got {
 			t.Errorf("expect ModbusDevices->modbus-rtu0->Address to be 0x%x but got 0x%x", expect, got)
 		}
+
+		if rb := md.CommandReadback(); !rb.Enabled() {
+			t.Error("expect ModbusDevices->modbus-rtu0->CommandReadback to be enabled")
+		} else {
+			if expect, got := 5, rb.Retries(); expect != got {
+				t.Errorf("expect ModbusDevices->modbus-rtu0->CommandReadback->Retries to be %d but got %d", expect, got)
+			}
+			if expect, got := 2*time.Second, rb.Backoff(); expect != got {
+				t.Errorf("expect ModbusDevices->modbus-rtu0->CommandReadback->Backoff to be %s but got %s", expect, got)
+			}
+		}
 	}
 
 	if expect, got := 1, len(config.GpioDevices()); expect != got {
@@ -1390,6 +1927,10 @@ func TestReadConfig_Complete(t *testing.T) {
 		if expect, got := 5*time.Second, hd.PollInterval(); expect != got {
 			t.Errorf("expect HttpDevices->tcw241->PollInterval to be %s but got %s", expect, got)
 		}
+
+		if hd.CommandReadback().Enabled() {
+			t.Error("expect HttpDevices->tcw241->CommandReadback to be disabled")
+		}
 	}
 
 	if expect, got := 1, len(config.MqttDevices()); expect != got {
@@ -1422,6 +1963,36 @@ func TestReadConfig_Complete(t *testing.T) {
 		}
 	}
 
+	if expect, got := 1, len(config.ReplayDevices()); expect != got {
+		t.Errorf("expect length of config.ReplayDevices to be %d but got %d", expect, got)
+	} else {
+		rd := config.ReplayDevices()[0]
+
+		if expect, got := "bmv0-replay", rd.Name(); expect != got {
+			t.Errorf("expect Name of first ReplayDevice to be '%s' but got '%s'", expect, got)
+		}
+
+		if !rd.LogDebug() {
+			t.Error("expect ReplayDevices->bmv0-replay->LogDebug to be true")
+		}
+
+		if expect, got := "./my-recording.jsonl", rd.File(); expect != got {
+			t.Errorf("expect ReplayDevices->bmv0-replay->File to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := "bmv0", rd.DeviceName(); expect != got {
+			t.Errorf("expect ReplayDevices->bmv0-replay->Device to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := 10.0, rd.Speed(); expect != got {
+			t.Errorf("expect ReplayDevices->bmv0-replay->Speed to be %g but got %g", expect, got)
+		}
+
+		if rd.Loop() {
+			t.Error("expect ReplayDevices->bmv0-replay->Loop to be false")
+		}
+	}
+
 	if expect, got := 1, len(config.GensetDevices()); expect != got {
 		t.Errorf("expect length of config.GensetDevices to be %d but got %d", expect, got)
 	} else {
@@ -1447,6 +2018,34 @@ func TestReadConfig_Complete(t *testing.T) {
 			t.Error("expect GensetDevice->genset0->General->LogComDebug to be true")
 		}
 
+		if expect, got := time.Minute, gd.DependencyTimeout(); expect != got {
+			t.Errorf("expect GensetDevice->genset0->DependencyTimeout to be %s but got %s", expect, got)
+		}
+
+		{
+			// the explicit dependencies are merged with the devices and registers used by the bindings
+			deps := gd.DependsOn()
+			if expect, got := 3, len(deps); expect != got {
+				t.Errorf("expect GensetDevice->genset0->DependsOn to have %d items but got %d", expect, got)
+			} else {
+				for i, expect := range []struct {
+					deviceName    string
+					registerNames []string
+				}{
+					{"bmv0", []string{"MainVoltage"}},
+					{"modbus-rtu0", []string{"CH0", "CH1", "CH2", "CH3", "CH4"}},
+					{"tcw241", []string{"Available", "DI0", "DI1", "DI2"}},
+				} {
+					if got := deps[i].DeviceName(); expect.deviceName != got {
+						t.Errorf("expect GensetDevice->genset0->DependsOn->%d->DeviceName to be '%s' but got '%s'", i, expect.deviceName, got)
+					}
+					if got := deps[i].RegisterNames(); !reflect.DeepEqual(expect.registerNames, got) {
+						t.Errorf("expect GensetDevice->genset0->DependsOn->%d->RegisterNames to be %v but got %v", i, expect.registerNames, got)
+					}
+				}
+			}
+		}
+
 		{
 			ib := gd.InputBindings()
 			if expect, got := 4, len(ib); expect != got {
@@ -1616,6 +2215,285 @@ func TestReadConfig_Complete(t *testing.T) {
 		}
 	}
 
+	if expect, got := 1, len(config.ComputedDevices()); expect != got {
+		t.Errorf("expect length of config.ComputedDevices to be %d but got %d", expect, got)
+	} else {
+		cd := config.ComputedDevices()[0]
+
+		if expect, got := "power0", cd.Name(); expect != got {
+			t.Errorf("expect Name of first ComputedDevice to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := 70*time.Millisecond, cd.RestartInterval(); expect != got {
+			t.Errorf("expect ComputedDevices->power0->RestartInterval to be %s but got %s", expect, got)
+		}
+
+		if expect, got := 2, len(cd.Registers()); expect != got {
+			t.Errorf("expect ComputedDevices->power0->Registers to have %d items but got %d", expect, got)
+		} else {
+			{
+				r := cd.Registers()[0]
+				if expect, got := "Charging", r.Name(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->0->Name to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := types.ComputedRegisterBoolType, r.Type(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->Charging->Type to be %s but got %s", expect, got)
+				}
+				if expect, got := "Computed", r.Category(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->Charging->Category to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "Charging", r.Description(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->Charging->Description to be '%s' but got '%s'", expect, got)
+				}
+			}
+			{
+				r := cd.Registers()[1]
+				if expect, got := "BatteryPower", r.Name(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->1->Name to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "bmv0.MainVoltage * bmv0.Current", r.Expression(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->BatteryPower->Expression to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := types.ComputedRegisterNumberType, r.Type(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->BatteryPower->Type to be %s but got %s", expect, got)
+				}
+				if expect, got := "Battery", r.Category(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->BatteryPower->Category to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "Battery Power", r.Description(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->BatteryPower->Description to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "W", r.Unit(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->BatteryPower->Unit to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := 10, r.Sort(); expect != got {
+					t.Errorf("expect ComputedDevices->power0->Registers->BatteryPower->Sort to be %d but got %d", expect, got)
+				}
+			}
+		}
+	}
+
+	if expect, got := 1, len(config.EnergyDevices()); expect != got {
+		t.Errorf("expect length of config.EnergyDevices to be %d but got %d", expect, got)
+	} else {
+		ed := config.EnergyDevices()[0]
+
+		if expect, got := "energy0", ed.Name(); expect != got {
+			t.Errorf("expect Name of first EnergyDevice to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := 2, len(ed.Inputs()); expect != got {
+			t.Errorf("expect EnergyDevices->energy0->Inputs to have %d items but got %d", expect, got)
+		} else {
+			inp := ed.Inputs()[1]
+			if expect, got := "Solar", inp.Name(); expect != got {
+				t.Errorf("expect EnergyDevices->energy0->Inputs->1->Name to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := "bmv0", inp.DeviceName(); expect != got {
+				t.Errorf("expect EnergyDevices->energy0->Inputs->Solar->Device to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := "PanelPower", inp.RegisterName(); expect != got {
+				t.Errorf("expect EnergyDevices->energy0->Inputs->Solar->Register to be '%s' but got '%s'", expect, got)
+			}
+		}
+
+		if expect, got := 2*time.Minute, ed.MaxGap(); expect != got {
+			t.Errorf("expect EnergyDevices->energy0->MaxGap to be %s but got %s", expect, got)
+		}
+
+		if expect, got := "/tmp/energy0.json", ed.File(); expect != got {
+			t.Errorf("expect EnergyDevices->energy0->File to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := 30*time.Second, ed.WriteInterval(); expect != got {
+			t.Errorf("expect EnergyDevices->energy0->WriteInterval to be %s but got %s", expect, got)
+		}
+	}
+
+	if expect, got := 1, len(config.AlarmDevices()); expect != got {
+		t.Errorf("expect length of config.AlarmDevices to be %d but got %d", expect, got)
+	} else {
+		ad := config.AlarmDevices()[0]
+
+		if expect, got := "alarms0", ad.Name(); expect != got {
+			t.Errorf("expect Name of first AlarmDevice to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := 2, len(ad.Rules()); expect != got {
+			t.Errorf("expect AlarmDevices->alarms0->Rules to have %d items but got %d", expect, got)
+		} else {
+			{
+				r := ad.Rules()[0]
+				if expect, got := "Temperature", r.Name(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->0->Name to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := types.AlarmConditionAbove, r.Condition(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->Temperature->Condition to be %s but got %s", expect, got)
+				}
+				if r.Warning() != nil {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->Temperature->Warning to be nil but got %f", *r.Warning())
+				}
+				if expect, got := "Alarms", r.Category(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->Temperature->Category to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "Temperature", r.Description(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->Temperature->Description to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := time.Duration(0), r.Delay(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->Temperature->Delay to be %s but got %s", expect, got)
+				}
+			}
+			{
+				r := ad.Rules()[1]
+				if expect, got := "BatteryLow", r.Name(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->1->Name to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "bmv0", r.DeviceName(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->BatteryLow->Device to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "MainVoltage", r.RegisterName(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->BatteryLow->Register to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := types.AlarmConditionBelow, r.Condition(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->BatteryLow->Condition to be %s but got %s", expect, got)
+				}
+				if r.Warning() == nil || *r.Warning() != 12.0 {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->BatteryLow->Warning to be 12.0 but got %v", r.Warning())
+				}
+				if r.Alarm() == nil || *r.Alarm() != 11.8 {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->BatteryLow->Alarm to be 11.8 but got %v", r.Alarm())
+				}
+				if expect, got := 0.2, r.Hysteresis(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->BatteryLow->Hysteresis to be %f but got %f", expect, got)
+				}
+				if expect, got := time.Minute, r.Delay(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->BatteryLow->Delay to be %s but got %s", expect, got)
+				}
+				if expect, got := "Battery Voltage Low", r.Description(); expect != got {
+					t.Errorf("expect AlarmDevices->alarms0->Rules->BatteryLow->Description to be '%s' but got '%s'", expect, got)
+				}
+			}
+		}
+	}
+
+	if expect, got := 1, len(config.AutomationDevices()); expect != got {
+		t.Errorf("expect length of config.AutomationDevices to be %d but got %d", expect, got)
+	} else {
+		ad := config.AutomationDevices()[0]
+
+		if expect, got := "automation0", ad.Name(); expect != got {
+			t.Errorf("expect Name of first AutomationDevice to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := 1, len(ad.Rules()); expect != got {
+			t.Errorf("expect AutomationDevices->automation0->Rules to have %d items but got %d", expect, got)
+		} else {
+			r := ad.Rules()[0]
+			if expect, got := "ChargeRelay", r.Name(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->0->Name to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := "bmv0.SOC < 30", r.On(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->ChargeRelay->On to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := "bmv0.SOC > 80", r.Off(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->ChargeRelay->Off to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := "modbus-rtu0", r.DeviceName(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->ChargeRelay->Device to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := "CH2", r.RegisterName(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->ChargeRelay->Register to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := "closed", r.OnValue(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->ChargeRelay->OnValue to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := "open", r.OffValue(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->ChargeRelay->OffValue to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := 10*time.Minute, r.MinOnTime(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->ChargeRelay->MinOnTime to be %s but got %s", expect, got)
+			}
+			if expect, got := 5*time.Minute, r.MinOffTime(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->ChargeRelay->MinOffTime to be %s but got %s", expect, got)
+			}
+			if r.Enabled() {
+				t.Error("expect AutomationDevices->automation0->Rules->ChargeRelay->Enabled to be false")
+			}
+			if expect, got := "Automations", r.Category(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->ChargeRelay->Category to be '%s' but got '%s'", expect, got)
+			}
+			if expect, got := "Charge Relay", r.Description(); expect != got {
+				t.Errorf("expect AutomationDevices->automation0->Rules->ChargeRelay->Description to be '%s' but got '%s'", expect, got)
+			}
+		}
+	}
+
+	if expect, got := 1, len(config.SchedulerDevices()); expect != got {
+		t.Errorf("expect length of config.SchedulerDevices to be %d but got %d", expect, got)
+	} else {
+		sd := config.SchedulerDevices()[0]
+
+		if expect, got := "scheduler0", sd.Name(); expect != got {
+			t.Errorf("expect Name of first SchedulerDevice to be '%s' but got '%s'", expect, got)
+		}
+		if expect, got := 46.95, sd.Latitude(); expect != got {
+			t.Errorf("expect SchedulerDevices->scheduler0->Latitude to be %f but got %f", expect, got)
+		}
+		if expect, got := 7.45, sd.Longitude(); expect != got {
+			t.Errorf("expect SchedulerDevices->scheduler0->Longitude to be %f but got %f", expect, got)
+		}
+
+		if expect, got := 2, len(sd.Schedules()); expect != got {
+			t.Errorf("expect SchedulerDevices->scheduler0->Schedules to have %d items but got %d", expect, got)
+		} else {
+			{
+				sc := sd.Schedules()[0]
+				if expect, got := "Heating", sc.Name(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->0->Name to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "0 5 * * *", sc.Cron(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->Heating->Cron to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "gpio0", sc.DeviceName(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->Heating->Device to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "Heating", sc.RegisterName(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->Heating->Register to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := "1", sc.Value(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->Heating->Value to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := 30*time.Minute, sc.MaxDelay(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->Heating->MaxDelay to be %s but got %s", expect, got)
+				}
+				if !sc.Enabled() {
+					t.Error("expect SchedulerDevices->scheduler0->Schedules->Heating->Enabled to be true")
+				}
+				if expect, got := "Schedules", sc.Category(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->Heating->Category to be '%s' but got '%s'", expect, got)
+				}
+			}
+			{
+				sc := sd.Schedules()[1]
+				if expect, got := "Light", sc.Name(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->1->Name to be '%s' but got '%s'", expect, got)
+				}
+				if expect, got := types.SunEventSunset, sc.Sun(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->Light->Sun to be %s but got %s", expect, got)
+				}
+				if expect, got := -30*time.Minute, sc.Offset(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->Light->Offset to be %s but got %s", expect, got)
+				}
+				if expect, got := 5*time.Minute, sc.MaxDelay(); expect != got {
+					t.Errorf("expect SchedulerDevices->scheduler0->Schedules->Light->MaxDelay to be %s but got %s", expect, got)
+				}
+				if sc.Enabled() {
+					t.Error("expect SchedulerDevices->scheduler0->Schedules->Light->Enabled to be false")
+				}
+			}
+		}
+	}
+
 	if expect, got := 2, len(config.Views()); expect != got {
 		t.Errorf("expect length of config.Views to be %d but got %d", expect, got)
 	} else {
@@ -1785,6 +2663,50 @@ func TestReadConfig_Default(t *testing.T) {
 		}
 	}
 
+	{
+		p := config.Persistence()
+
+		if p.Enabled() {
+			t.Error("expect Persistence->Enabled to be false")
+		}
+
+		if expect, got := time.Minute, p.WriteInterval(); expect != got {
+			t.Errorf("expect Persistence->WriteInterval to be %s but got %s", expect, got)
+		}
+	}
+
+	{
+		h := config.History()
+
+		if h.Enabled() {
+			t.Error("expect History->Enabled to be false")
+		}
+
+		if expect, got := time.Hour, h.RawRetention(); expect != got {
+			t.Errorf("expect History->RawRetention to be %s but got %s", expect, got)
+		}
+
+		if expect, got := 24*time.Hour, h.MinuteRetention(); expect != got {
+			t.Errorf("expect History->MinuteRetention to be %s but got %s", expect, got)
+		}
+
+		if expect, got := 168*time.Hour, h.QuarterRetention(); expect != got {
+			t.Errorf("expect History->QuarterRetention to be %s but got %s", expect, got)
+		}
+	}
+
+	{
+		r := config.Recorder()
+
+		if r.Enabled() {
+			t.Error("expect Recorder->Enabled to be false")
+		}
+
+		if expect, got := time.Second, r.FlushInterval(); expect != got {
+			t.Errorf("expect Recorder->FlushInterval to be %s but got %s", expect, got)
+		}
+	}
+
 	if expect, got := 1, len(config.MqttClients()); expect != got {
 		t.Errorf("expect length of config.MqttClients to be %d but got %d", expect, got)
 	} else {
@@ -1903,7 +2825,7 @@ func TestReadConfig_Default(t *testing.T) {
 				t.Errorf("expect %s->Qos to be %d but got %d", prefix, expect, got)
 			}
 
-			if expect, got := []string{"bmv0", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+			if expect, got := []string{"bmv0", "bmv0-replay", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
 				t.Errorf("expect %s->Devices to be %v but got %v", prefix, expect, got)
 			} else {
 				prefix := prefix + "->Devices->bmv0"
@@ -1959,7 +2881,7 @@ func TestReadConfig_Default(t *testing.T) {
 				t.Errorf("expect %s->Qos to be %d but got %d", prefix, expect, got)
 			}
 
-			if expect, got := []string{"bmv0", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+			if expect, got := []string{"bmv0", "bmv0-replay", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
 				t.Errorf("expect %s->Devices to be %v but got %v", prefix, expect, got)
 			} else {
 				prefix := prefix + "->Devices->bmv0"
@@ -2015,7 +2937,7 @@ func TestReadConfig_Default(t *testing.T) {
 				t.Errorf("expect %s->Qos to be %d but got %d", prefix, expect, got)
 			}
 
-			if expect, got := []string{"bmv0", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+			if expect, got := []string{"bmv0", "bmv0-replay", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
 				t.Errorf("expect %s->Devices to be %v but got %v", prefix, expect, got)
 			} else {
 				prefix := prefix + "->Devices->bmv0"
@@ -2071,7 +2993,7 @@ func TestReadConfig_Default(t *testing.T) {
 				t.Errorf("expect %s->Qos to be %d but got %d", prefix, expect, got)
 			}
 
-			if expect, got := []string{"bmv0", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+			if expect, got := []string{"bmv0", "bmv0-replay", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
 				t.Errorf("expect %s->Devices to be %v but got %v", prefix, expect, got)
 			} else {
 				prefix := prefix + "->Devices->bmv0"
@@ -2127,7 +3049,7 @@ func TestReadConfig_Default(t *testing.T) {
 				t.Errorf("expect %s->Qos to be %d but got %d", prefix, expect, got)
 			}
 
-			if expect, got := []string{"bmv0", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+			if expect, got := []string{"bmv0", "bmv0-replay", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
 				t.Errorf("expect %s->Devices to be %v but got %v", prefix, expect, got)
 			} else {
 				prefix := prefix + "->Devices->bmv0"
@@ -2155,6 +3077,27 @@ func TestReadConfig_Default(t *testing.T) {
 			}
 		}
 
+		{
+			mcSect := mc.CommandResponse()
+			prefix := "MqttClients->0-local->CommandResponse"
+
+			if expect, got := "go-iotdevice/resp/my-dev/reg-name", mc.CommandResponseTopic("my-dev", "reg-name"); expect != got {
+				t.Errorf("expect %s->CommandResponseTopic to be '%s' but got '%s'", prefix, expect, got)
+			}
+
+			if got := mcSect.Enabled(); got {
+				t.Errorf("expect %s->Enabled to be false", prefix)
+			}
+
+			if got := mcSect.Retain(); got {
+				t.Errorf("expect %s->Retain to be false", prefix)
+			}
+
+			if expect, got := []string{"bmv0", "bmv0-replay", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+				t.Errorf("expect %s->Devices to be %v but got %v", prefix, expect, got)
+			}
+		}
+
 		{
 			mcSect := mc.Command()
 			prefix := "MqttClients->0-local->Command"
@@ -2183,7 +3126,7 @@ func TestReadConfig_Default(t *testing.T) {
 				t.Errorf("expect %s->Qos to be %d but got %d", prefix, expect, got)
 			}
 
-			if expect, got := []string{"bmv0", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
+			if expect, got := []string{"bmv0", "bmv0-replay", "genset0", "gpio0", "modbus-rtu0", "tcw241"}, getNames(mcSect.Devices()); !reflect.DeepEqual(expect, got) {
 				t.Errorf("expect %s->Devices to be %v but got %v", prefix, expect, got)
 			} else {
 				prefix := prefix + "->Devices->bmv0"
@@ -2263,6 +3206,30 @@ func TestReadConfig_Default(t *testing.T) {
 			t.Errorf("expect VictronDevices->bmv0->General->RestartIntervalMaxBackoff to be %s but got %s", expect, got)
 		}
 
+		if expect, got := time.Duration(0), vd.MaxAge(); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->General->MaxAge to be %s but got %s", expect, got)
+		}
+
+		if expect, got := 0, len(vd.DependsOn()); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->DependsOn to be empty but got %d items", got)
+		}
+
+		if expect, got := 30*time.Second, vd.DependencyTimeout(); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->DependencyTimeout to be %s but got %s", expect, got)
+		}
+
+		if vd.Deadband().Enabled() {
+			t.Error("expect VictronDevices->bmv0->General->Deadband to be disabled")
+		}
+
+		if expect, got := time.Minute, vd.Deadband().MaxSilence(); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->General->Deadband->MaxSilence to be %s but got %s", expect, got)
+		}
+
+		if expect, got := 0, len(vd.Transform()); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->General->Transform to be empty but got %d items", got)
+		}
+
 		if vd.LogDebug() {
 			t.Error("expect VictronDevices->bmv0->General->LogDebug to be false")
 		}
@@ -2286,6 +3253,10 @@ func TestReadConfig_Default(t *testing.T) {
 		if expect, got := 500*time.Millisecond, vd.PollInterval(); expect != got {
 			t.Errorf("expect VictronDevices->bmv0->PollInterval to be %s but got %s", expect, got)
 		}
+
+		if expect, got := 1.0, vd.ReplaySpeed(); expect != got {
+			t.Errorf("expect VictronDevices->bmv0->ReplaySpeed to be %g but got %g", expect, got)
+		}
 	}
 
 	if expect, got := 1, len(config.ModbusDevices()); expect != got {
@@ -2324,6 +3295,10 @@ func TestReadConfig_Default(t *testing.T) {
 		if expect, got := byte(0x02), md.Address(); expect != got {
 			t.Errorf("expect ModbusDevices->modbus-rtu0->Address to be 0x%x but got 0x%x", expect, got)
 		}
+
+		if md.CommandReadback().Enabled() {
+			t.Error("expect ModbusDevices->modbus-rtu0->CommandReadback to be disabled")
+		}
 	}
 
 	if expect, got := 1, len(config.GpioDevices()); expect != got {
@@ -2470,6 +3445,36 @@ func TestReadConfig_Default(t *testing.T) {
 		}
 	}
 
+	if expect, got := 1, len(config.ReplayDevices()); expect != got {
+		t.Errorf("expect length of config.ReplayDevices to be %d but got %d", expect, got)
+	} else {
+		rd := config.ReplayDevices()[0]
+
+		if expect, got := "bmv0-replay", rd.Name(); expect != got {
+			t.Errorf("expect Name of first ReplayDevice to be '%s' but got '%s'", expect, got)
+		}
+
+		if rd.LogDebug() {
+			t.Error("expect ReplayDevices->bmv0-replay->LogDebug to be false")
+		}
+
+		if expect, got := "./my-recording.jsonl", rd.File(); expect != got {
+			t.Errorf("expect ReplayDevices->bmv0-replay->File to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := "bmv0-replay", rd.DeviceName(); expect != got {
+			t.Errorf("expect ReplayDevices->bmv0-replay->Device to be '%s' but got '%s'", expect, got)
+		}
+
+		if expect, got := 1.0, rd.Speed(); expect != got {
+			t.Errorf("expect ReplayDevices->bmv0-replay->Speed to be %g but got %g", expect, got)
+		}
+
+		if !rd.Loop() {
+			t.Error("expect ReplayDevices->bmv0-replay->Loop to be true")
+		}
+	}
+
 	if expect, got := 1, len(config.GensetDevices()); expect != got {
 		t.Errorf("expect length of config.GensetDevices to be %d but got %d", expect, got)
 	} else {
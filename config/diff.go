@@ -0,0 +1,165 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Diff lists what differs between the running and a newly read configuration.
+type Diff struct {
+	Devices     []string // devices which were added, removed or changed, sorted by name
+	MqttClients []string // mqtt clients which were added, removed or changed, sorted by name
+	// MqttForwarders lists the mqtt clients which did not change except for the sections configuring the forwarders
+	MqttForwarders []string
+	HttpServer     bool     // the HttpServer section changed, the server must be restarted
+	HttpRoutes     bool     // ProjectTitle, Authentication or Views changed, the routes must be set up again
+	Restart        []string // sections which can only be changed by restarting the process
+}
+
+// Empty returns true when both configurations are equivalent.
+func (d Diff) Empty() bool {
+	return len(d.Devices) < 1 && len(d.MqttClients) < 1 && len(d.MqttForwarders) < 1 &&
+		!d.HttpServer && !d.HttpRoutes && len(d.Restart) < 1
+}
+
+// Compare computes which parts of the running configuration old need to be restarted to apply the configuration updated.
+// Mqtt devices are also listed as changed when one of the mqtt clients they receive their values from changed.
+func Compare(old, updated Config) (d Diff) {
+	if old.version != updated.version {
+		d.Restart = append(d.Restart, "Version")
+	}
+	if old.logStateStorageDebug != updated.logStateStorageDebug {
+		d.Restart = append(d.Restart, "LogStateStorageDebug")
+	}
+	if old.logCommandStorageDebug != updated.logCommandStorageDebug {
+		d.Restart = append(d.Restart, "LogCommandStorageDebug")
+	}
+	if !reflect.DeepEqual(
+		convertEnableableToRead[PersistenceConfig, persistenceConfigRead](old.persistence),
+		convertEnableableToRead[PersistenceConfig, persistenceConfigRead](updated.persistence),
+	) {
+		d.Restart = append(d.Restart, "Persistence")
+	}
+	if !reflect.DeepEqual(
+		convertEnableableToRead[HistoryConfig, historyConfigRead](old.history),
+		convertEnableableToRead[HistoryConfig, historyConfigRead](updated.history),
+	) {
+		d.Restart = append(d.Restart, "History")
+	}
+	if !reflect.DeepEqual(
+		convertEnableableToRead[RecorderConfig, recorderConfigRead](old.recorder),
+		convertEnableableToRead[RecorderConfig, recorderConfigRead](updated.recorder),
+	) {
+		d.Restart = append(d.Restart, "Recorder")
+	}
+	if len(diffByName[ModbusConfig, modbusConfigRead](old.modbus, updated.modbus)) > 0 {
+		d.Restart = append(d.Restart, "Modbus")
+	}
+
+	d.HttpServer = !reflect.DeepEqual(
+		convertEnableableToRead[HttpServerConfig, httpServerConfigRead](old.httpServer),
+		convertEnableableToRead[HttpServerConfig, httpServerConfigRead](updated.httpServer),
+	)
+	d.HttpRoutes = old.projectTitle != updated.projectTitle ||
+		!reflect.DeepEqual(
+			convertEnableableToRead[AuthenticationConfig, authenticationConfigRead](old.authentication),
+			convertEnableableToRead[AuthenticationConfig, authenticationConfigRead](updated.authentication),
+		) ||
+		!reflect.DeepEqual(
+			convertListToRead[ViewConfig, viewConfigRead](old.views),
+			convertListToRead[ViewConfig, viewConfigRead](updated.views),
+		)
+
+	oldClients := convertMapToRead[MqttClientConfig, mqttClientConfigRead](old.mqttClients)
+	updatedClients := convertMapToRead[MqttClientConfig, mqttClientConfigRead](updated.mqttClients)
+	d.MqttClients = diffMaps(withoutForwarders(oldClients), withoutForwarders(updatedClients))
+	for _, name := range diffMaps(oldClients, updatedClients) {
+		if !slices.Contains(d.MqttClients, name) {
+			d.MqttForwarders = append(d.MqttForwarders, name)
+		}
+	}
+
+	d.Devices = slices.Concat(
+		diffByName[VictronDeviceConfig, victronDeviceConfigRead](old.victronDevices, updated.victronDevices),
+		diffByName[ModbusDeviceConfig, modbusDeviceConfigRead](old.modbusDevices, updated.modbusDevices),
+		diffByName[GpioDeviceConfig, gpioDeviceConfigRead](old.gpioDevices, updated.gpioDevices),
+		diffByName[HttpDeviceConfig, httpDeviceConfigRead](old.httpDevices, updated.httpDevices),
+		diffByName[MqttDeviceConfig, mqttDeviceConfigRead](old.mqttDevices, updated.mqttDevices),
+		diffByName[ReplayDeviceConfig, replayDeviceConfigRead](old.replayDevices, updated.replayDevices),
+		diffByName[GensetDeviceConfig, gensetDeviceConfigRead](old.gensetDevices, updated.gensetDevices),
+		diffByName[ComputedDeviceConfig, computedDeviceConfigRead](old.computedDevices, updated.computedDevices),
+		diffByName[EnergyDeviceConfig, energyDeviceConfigRead](old.energyDevices, updated.energyDevices),
+		diffByName[AlarmDeviceConfig, alarmDeviceConfigRead](old.alarmDevices, updated.alarmDevices),
+		diffByName[AutomationDeviceConfig, automationDeviceConfigRead](old.automationDevices, updated.automationDevices),
+		diffByName[SchedulerDeviceConfig, schedulerDeviceConfigRead](old.schedulerDevices, updated.schedulerDevices),
+	)
+	for _, mc := range slices.Concat(old.mqttClients, updated.mqttClients) {
+		if !slices.Contains(d.MqttClients, mc.name) {
+			continue
+		}
+		for _, md := range mc.mqttDevices {
+			if existsByName(md.name, updated.mqttDevices) {
+				d.Devices = append(d.Devices, md.name)
+			}
+		}
+	}
+	slices.Sort(d.Devices)
+	d.Devices = slices.Compact(d.Devices)
+
+	return
+}
+
+// KeepGenerated takes over the generated jwt secret and the generated ids of the mqtt clients of the running
+// configuration old. Otherwise, every reload would log out all users and change the clients and their topics.
+func (c *Config) KeepGenerated(old Config) {
+	if c.authentication.jwtSecretGenerated && old.authentication.jwtSecretGenerated {
+		c.authentication.jwtSecret = old.authentication.jwtSecret
+	}
+
+	for i, mc := range c.mqttClients {
+		if !mc.clientIdGenerated {
+			continue
+		}
+		for _, oldMc := range old.mqttClients {
+			if oldMc.name == mc.name && oldMc.clientIdGenerated {
+				c.mqttClients[i].clientId = oldMc.clientId
+			}
+		}
+	}
+}
+
+// diffByName returns the names of the items which only exist in one of the lists or differ between them.
+func diffByName[I mappable[O], O any](old, updated []I) []string {
+	return diffMaps(convertMapToRead[I, O](old), convertMapToRead[I, O](updated))
+}
+
+func diffMaps[O any](oldRead, newRead map[string]O) (changed []string) {
+	for name, o := range oldRead {
+		if n, ok := newRead[name]; !ok || !reflect.DeepEqual(o, n) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range newRead {
+		if _, ok := oldRead[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return
+}
+
+// withoutForwarders clears the sections only used by the forwarders, see mqttForwarders.Config.
+func withoutForwarders(clients map[string]mqttClientConfigRead) map[string]mqttClientConfigRead {
+	ret := make(map[string]mqttClientConfigRead, len(clients))
+	for name, c := range clients {
+		c.AvailabilityDevice = mqttSectionConfigRead{}
+		c.Structure = mqttSectionConfigRead{}
+		c.Telemetry = mqttSectionConfigRead{}
+		c.Realtime = mqttSectionConfigRead{}
+		c.HomeassistantDiscovery = mqttSectionConfigRead{}
+		c.Command = mqttSectionConfigRead{}
+		c.CommandResponse = mqttSectionConfigRead{}
+		ret[name] = c
+	}
+	return ret
+}
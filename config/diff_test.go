@@ -0,0 +1,98 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	readFixture := func(t *testing.T, replacements ...string) Config {
+		t.Helper()
+		yaml := ValidCompleteConfig
+		for i := 0; i+1 < len(replacements); i += 2 {
+			if !strings.Contains(yaml, replacements[i]) {
+				t.Fatalf("fixture does not contain '%s'", replacements[i])
+			}
+			yaml = strings.Replace(yaml, replacements[i], replacements[i+1], 1)
+		}
+		c, err := ReadConfig([]byte(yaml), true)
+		if len(err) > 0 {
+			t.Fatalf("did not expect any error, got: %v", err)
+		}
+		return c
+	}
+
+	running := readFixture(t)
+	read := func(t *testing.T, replacements ...string) Config {
+		t.Helper()
+		c := readFixture(t, replacements...)
+		c.KeepGenerated(running)
+		return c
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		if d := Compare(running, read(t)); !d.Empty() {
+			t.Errorf("expect an empty diff, got: %+v", d)
+		}
+	})
+
+	t.Run("device", func(t *testing.T) {
+		d := Compare(running, read(t, "CrankingTimeout: 19s", "CrankingTimeout: 20s"))
+		if expect, got := []string{"genset0"}, d.Devices; !reflect.DeepEqual(expect, got) {
+			t.Errorf("expect Devices to be %v but got %v", expect, got)
+		}
+		if len(d.MqttClients) > 0 || d.HttpServer || d.HttpRoutes || len(d.Restart) > 0 {
+			t.Errorf("expect only devices to change, got: %+v", d)
+		}
+	})
+
+	t.Run("mqttClient", func(t *testing.T) {
+		d := Compare(running, read(t, "MaxBacklogSize: 42", "MaxBacklogSize: 43"))
+		if expect, got := []string{"0-local"}, d.MqttClients; !reflect.DeepEqual(expect, got) {
+			t.Errorf("expect MqttClients to be %v but got %v", expect, got)
+		}
+		// the mqtt device receiving its values using this client must be restarted as well
+		if expect, got := []string{"bmv1"}, d.Devices; !reflect.DeepEqual(expect, got) {
+			t.Errorf("expect Devices to be %v but got %v", expect, got)
+		}
+	})
+
+	t.Run("mqttForwarders", func(t *testing.T) {
+		d := Compare(running, read(t, "Interval: 2s   ", "Interval: 3s   "))
+		if expect, got := []string{"0-local"}, d.MqttForwarders; !reflect.DeepEqual(expect, got) {
+			t.Errorf("expect MqttForwarders to be %v but got %v", expect, got)
+		}
+		// the connection is kept; neither the client nor its mqtt devices are restarted
+		if len(d.MqttClients) > 0 || len(d.Devices) > 0 {
+			t.Errorf("expect only the forwarders to change, got: %+v", d)
+		}
+	})
+
+	t.Run("httpRoutes", func(t *testing.T) {
+		d := Compare(running, read(t, "ProjectTitle: Configurable Title of Project", "ProjectTitle: Other Title"))
+		if !d.HttpRoutes || d.HttpServer {
+			t.Errorf("expect only HttpRoutes to change, got: %+v", d)
+		}
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		d := Compare(running, read(t, "WriteInterval: 30s", "WriteInterval: 31s", "BaudRate: 9600", "BaudRate: 19200"))
+		if expect, got := []string{"Persistence", "Modbus"}, d.Restart; !reflect.DeepEqual(expect, got) {
+			t.Errorf("expect Restart to be %v but got %v", expect, got)
+		}
+	})
+
+	t.Run("generatedJwtSecret", func(t *testing.T) {
+		noSecret := []string{"JwtSecret: 'aiziax9Hied0ier9Yo0Lo6bi3xahth7o'", ""}
+		old := readFixture(t, noSecret...)
+		c := readFixture(t, noSecret...)
+		c.KeepGenerated(old)
+		if expect, got := string(old.Authentication().JwtSecret()), string(c.Authentication().JwtSecret()); expect != got {
+			t.Errorf("expect the generated JwtSecret to be kept, got '%s' and '%s'", expect, got)
+		}
+		if d := Compare(old, c); d.HttpRoutes {
+			t.Errorf("expect HttpRoutes to be unchanged, got: %+v", d)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeYaml parses the configuration; when expand is set, the environment variables in all its values
+// are substituted first. Unknown fields are rejected.
+func decodeYaml(yamlStr []byte, out *configRead, expand bool) (err []error) {
+	var root yaml.Node
+	if e := yaml.Unmarshal(yamlStr, &root); e != nil {
+		return []error{fmt.Errorf("cannot parse yaml: %s", e)}
+	}
+
+	if expand {
+		if err = expandEnvNode(&root); len(err) > 0 {
+			return
+		}
+	}
+
+	// yaml.Node.Decode does not support rejecting unknown fields; encode the expanded tree again
+	expanded, e := yaml.Marshal(&root)
+	if e != nil {
+		return []error{fmt.Errorf("cannot encode yaml again: %s", e)}
+	}
+
+	d := yaml.NewDecoder(bytes.NewReader(expanded))
+	d.KnownFields(true)
+	if e := d.Decode(out); e != nil {
+		return []error{fmt.Errorf("cannot parse yaml: %s", e)}
+	}
+	return nil
+}
+
+// expandEnvNode substitutes the environment variables in all scalar values of the tree; comments are left untouched.
+func expandEnvNode(n *yaml.Node) (err []error) {
+	if n.Kind == yaml.ScalarNode && strings.Contains(n.Value, "$") {
+		value, missing := expandEnv(n.Value)
+		for _, name := range missing {
+			err = append(err, fmt.Errorf("line %d: environment variable '%s' is not set", n.Line, name))
+		}
+		if n.Value != value && n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			// resolve the type of plain values again, e.g. to allow Port: ${HTTP_PORT}
+			n.Tag = ""
+		}
+		n.Value = value
+	}
+	for _, c := range n.Content {
+		err = append(err, expandEnvNode(c)...)
+	}
+	return
+}
+
+// expandEnv replaces $NAME, ${NAME} and ${NAME:-default} by the value of the environment variable NAME.
+// The default is used when the variable is unset or empty. $$ is replaced by a single $ and
+// a $ not followed by a name is kept as is. The names of unset variables without a default are returned.
+func expandEnv(s string) (ret string, missing []string) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		next := s[i+1:]
+		switch {
+		case next[0] == '$':
+			b.WriteByte('$')
+			i++
+		case next[0] == '{':
+			end := strings.IndexByte(next, '}')
+			name, def, hasDefault := strings.Cut(next[1:max(end, 1)], ":-")
+			if end < 0 || nameLength(name) != len(name) || len(name) < 1 {
+				// not a variable reference
+				b.WriteByte('$')
+				continue
+			}
+			if value, ok := os.LookupEnv(name); ok && (len(value) > 0 || !hasDefault) {
+				b.WriteString(value)
+			} else if hasDefault {
+				b.WriteString(def)
+			} else {
+				missing = append(missing, name)
+			}
+			i += 1 + end
+		default:
+			l := nameLength(next)
+			if l < 1 {
+				b.WriteByte('$')
+				continue
+			}
+			name := next[:l]
+			if value, ok := os.LookupEnv(name); ok {
+				b.WriteString(value)
+			} else {
+				missing = append(missing, name)
+			}
+			i += l
+		}
+	}
+	return b.String(), missing
+}
+
+// nameLength returns the length of the environment variable name at the beginning of s.
+func nameLength(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9' {
+			continue
+		}
+		return i
+	}
+	return len(s)
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("IOTDEVICE_SET", "value")
+	t.Setenv("IOTDEVICE_EMPTY", "")
+
+	tests := []struct {
+		inp     string
+		expect  string
+		missing []string
+	}{
+		{"plain", "plain", nil},
+		{"$IOTDEVICE_SET", "value", nil},
+		{"a${IOTDEVICE_SET}b", "avalueb", nil},
+		{"${IOTDEVICE_EMPTY}", "", nil},
+		{"${IOTDEVICE_EMPTY:-default}", "default", nil},
+		{"${IOTDEVICE_SET:-default}", "value", nil},
+		{"${IOTDEVICE_UNSET:-}", "", nil},
+		{"${IOTDEVICE_UNSET}-$IOTDEVICE_OTHER", "-", []string{"IOTDEVICE_UNSET", "IOTDEVICE_OTHER"}},
+		{"cost$$", "cost$", nil},
+		{"^CH[3-8]$", "^CH[3-8]$", nil},
+		{"$1 ${ x", "$1 ${ x", nil},
+	}
+
+	for _, tc := range tests {
+		got, missing := expandEnv(tc.inp)
+		if got != tc.expect {
+			t.Errorf("expandEnv(%q): expect %q but got %q", tc.inp, tc.expect, got)
+		}
+		if !reflect.DeepEqual(tc.missing, missing) {
+			t.Errorf("expandEnv(%q): expect missing %v but got %v", tc.inp, tc.missing, missing)
+		}
+	}
+}
+
+func TestReadConfigEnv(t *testing.T) {
+	const yaml = `
+Version: 2
+# comments are not expanded: ${IOTDEVICE_IN_COMMENT}
+ProjectTitle: "${IOTDEVICE_TITLE}"
+HttpServer:
+  Bind: "::1"
+  Port: ${IOTDEVICE_PORT}
+MqttClients:
+  local:
+    Broker: tcp://mqtt.example.com:1883
+    User: ${IOTDEVICE_USER:-iotdevice}
+    Password: ${IOTDEVICE_PASSWORD}
+`
+
+	t.Run("set", func(t *testing.T) {
+		t.Setenv("IOTDEVICE_TITLE", "42")
+		t.Setenv("IOTDEVICE_PORT", "8042")
+		t.Setenv("IOTDEVICE_PASSWORD", "secret: #1")
+
+		c, err := ReadConfig([]byte(yaml), true)
+		if len(err) > 0 {
+			t.Fatalf("did not expect any error, got: %v", err)
+		}
+		if expect, got := "42", c.ProjectTitle(); expect != got {
+			t.Errorf("expect ProjectTitle to be '%s' but got '%s'", expect, got)
+		}
+		if expect, got := 8042, c.HttpServer().Port(); expect != got {
+			t.Errorf("expect HttpServer->Port to be %d but got %d", expect, got)
+		}
+		mc := c.MqttClients()[0]
+		if expect, got := "iotdevice", mc.User(); expect != got {
+			t.Errorf("expect MqttClients->local->User to be '%s' but got '%s'", expect, got)
+		}
+		if expect, got := "secret: #1", mc.Password(); expect != got {
+			t.Errorf("expect MqttClients->local->Password to be '%s' but got '%s'", expect, got)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Setenv("IOTDEVICE_PORT", "8042")
+
+		_, err := ReadConfig([]byte(yaml), true)
+		if expect, got := 2, len(err); expect != got {
+			t.Fatalf("expect %d errors but got: %v", expect, err)
+		}
+		if expect, got := "line 4: environment variable 'IOTDEVICE_TITLE' is not set", err[0].Error(); expect != got {
+			t.Errorf("expect error '%s' but got '%s'", expect, got)
+		}
+		if !strings.Contains(err[1].Error(), "'IOTDEVICE_PASSWORD'") {
+			t.Errorf("expect an error for IOTDEVICE_PASSWORD, got: %v", err[1])
+		}
+	})
+}
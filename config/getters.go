@@ -2,6 +2,7 @@ package config
 
 import (
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,6 +43,18 @@ func (c Config) Authentication() AuthenticationConfig {
 	return c.authentication
 }
 
+func (c Config) Persistence() PersistenceConfig {
+	return c.persistence
+}
+
+func (c Config) History() HistoryConfig {
+	return c.history
+}
+
+func (c Config) Recorder() RecorderConfig {
+	return c.recorder
+}
+
 func (c Config) MqttClients() []MqttClientConfig {
 	return c.mqttClients
 }
@@ -74,10 +87,34 @@ func (c Config) MqttDevices() []MqttDeviceConfig {
 	return c.mqttDevices
 }
 
+func (c Config) ReplayDevices() []ReplayDeviceConfig {
+	return c.replayDevices
+}
+
 func (c Config) GensetDevices() []GensetDeviceConfig {
 	return c.gensetDevices
 }
 
+func (c Config) ComputedDevices() []ComputedDeviceConfig {
+	return c.computedDevices
+}
+
+func (c Config) EnergyDevices() []EnergyDeviceConfig {
+	return c.energyDevices
+}
+
+func (c Config) AlarmDevices() []AlarmDeviceConfig {
+	return c.alarmDevices
+}
+
+func (c Config) AutomationDevices() []AutomationDeviceConfig {
+	return c.automationDevices
+}
+
+func (c Config) SchedulerDevices() []SchedulerDeviceConfig {
+	return c.schedulerDevices
+}
+
 func (c Config) Views() []ViewConfig {
 	return c.views
 }
@@ -138,6 +175,90 @@ func (c AuthenticationConfig) HtaccessFile() string {
 	return c.htaccessFile
 }
 
+// IsAdmin returns true when the given user is allowed to use the admin api.
+func (c AuthenticationConfig) IsAdmin(user string) bool {
+	_, ok := c.adminUsers[user]
+	return ok
+}
+
+// Getters for PersistenceConfig struct
+
+func (c PersistenceConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c PersistenceConfig) StateFile() string {
+	return c.stateFile
+}
+
+func (c PersistenceConfig) CommandFile() string {
+	return c.commandFile
+}
+
+func (c PersistenceConfig) WriteInterval() time.Duration {
+	return c.writeInterval
+}
+
+func (c PersistenceConfig) LogDebug() bool {
+	return c.logDebug
+}
+
+// Getters for HistoryConfig struct
+
+func (c HistoryConfig) Enabled() bool {
+	return c.enabled
+}
+
+// RawRetention defines how long every single value is kept; zero disables raw history.
+func (c HistoryConfig) RawRetention() time.Duration {
+	return c.rawRetention
+}
+
+// MinuteRetention defines how long 1-minute aggregates are kept; zero disables them.
+func (c HistoryConfig) MinuteRetention() time.Duration {
+	return c.minuteRetention
+}
+
+// QuarterRetention defines how long 15-minute aggregates are kept; zero disables them.
+func (c HistoryConfig) QuarterRetention() time.Duration {
+	return c.quarterRetention
+}
+
+func (c HistoryConfig) File() string {
+	return c.file
+}
+
+func (c HistoryConfig) WriteInterval() time.Duration {
+	return c.writeInterval
+}
+
+func (c HistoryConfig) LogDebug() bool {
+	return c.logDebug
+}
+
+// Getters for RecorderConfig struct
+
+func (c RecorderConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c RecorderConfig) File() string {
+	return c.file
+}
+
+// Devices lists the recorded devices; all devices are recorded when it is empty.
+func (c RecorderConfig) Devices() []string {
+	return c.devices
+}
+
+func (c RecorderConfig) FlushInterval() time.Duration {
+	return c.flushInterval
+}
+
+func (c RecorderConfig) LogDebug() bool {
+	return c.logDebug
+}
+
 // Getters for MqttClientConfig struct
 
 func (c MqttClientConfig) getTopicTemplateOldNewPairs(oldnew ...string) []string {
@@ -270,6 +391,18 @@ func (c MqttClientConfig) CommandTopic(deviceName, registerName string) string {
 	return r.Replace(c.command.topicTemplate)
 }
 
+func (c MqttClientConfig) CommandResponse() MqttSectionConfig {
+	return c.commandResponse
+}
+
+func (c MqttClientConfig) CommandResponseTopic(deviceName, registerName string) string {
+	r := strings.NewReplacer(c.getTopicTemplateOldNewPairs(
+		"%DeviceName%", deviceName,
+		"%RegisterName%", registerName,
+	)...)
+	return r.Replace(c.commandResponse.topicTemplate)
+}
+
 func (c MqttClientConfig) LogDebug() bool {
 	return c.logDebug
 }
@@ -364,6 +497,45 @@ func (c DeviceConfig) RestartIntervalMaxBackoff() time.Duration {
 	return c.restartIntervalMaxBackoff
 }
 
+func (c DeviceConfig) MaxAge() time.Duration {
+	return c.maxAge
+}
+
+func (c DeviceConfig) RegisterMaxAge() map[string]time.Duration {
+	return c.registerMaxAge
+}
+
+// MaxAgeOf returns the max age of the given register; zero means its values never expire.
+func (c DeviceConfig) MaxAgeOf(registerName string) time.Duration {
+	if maxAge, ok := c.registerMaxAge[registerName]; ok {
+		return maxAge
+	}
+	return c.maxAge
+}
+
+func (c DeviceConfig) Deadband() DeadbandConfig {
+	return c.deadband
+}
+
+// Transform returns the conversions of the device's registers keyed by register name.
+func (c DeviceConfig) Transform() map[string]RegisterTransformConfig {
+	return c.transform
+}
+
+// Limits returns the constraints of commands sent to the device's registers keyed by register name.
+func (c DeviceConfig) Limits() map[string]RegisterLimitsConfig {
+	return c.limits
+}
+
+// DependsOn returns the devices which must be available before this device is started.
+func (c DeviceConfig) DependsOn() []DependencyConfig {
+	return c.dependsOn
+}
+
+func (c DeviceConfig) DependencyTimeout() time.Duration {
+	return c.dependencyTimeout
+}
+
 func (c DeviceConfig) LogDebug() bool {
 	return c.logDebug
 }
@@ -372,6 +544,17 @@ func (c DeviceConfig) LogComDebug() bool {
 	return c.logComDebug
 }
 
+// Getters for DependencyConfig struct
+
+func (c DependencyConfig) DeviceName() string {
+	return c.deviceName
+}
+
+// RegisterNames returns the registers which must be known by the device before the dependent device is started.
+func (c DependencyConfig) RegisterNames() []string {
+	return c.registerNames
+}
+
 // Getters for VictronDeviceConfig struct
 
 func (c VictronDeviceConfig) Device() string {
@@ -390,6 +573,10 @@ func (c VictronDeviceConfig) IoLog() string {
 	return c.ioLog
 }
 
+func (c VictronDeviceConfig) ReplaySpeed() float64 {
+	return c.replaySpeed
+}
+
 // Getters for ModbusDeviceConfig struct
 
 func (c ModbusDeviceConfig) Bus() string {
@@ -429,6 +616,24 @@ func (c ModbusDeviceConfig) PollInterval() time.Duration {
 	return c.pollInterval
 }
 
+func (c ModbusDeviceConfig) CommandReadback() CommandReadbackConfig {
+	return c.commandReadback
+}
+
+// Getters for CommandReadbackConfig struct
+
+func (c CommandReadbackConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c CommandReadbackConfig) Retries() int {
+	return c.retries
+}
+
+func (c CommandReadbackConfig) Backoff() time.Duration {
+	return c.backoff
+}
+
 // Getters for GpioDeviceConfig struct
 func (c GpioDeviceConfig) Chip() string {
 	return c.chip
@@ -498,6 +703,10 @@ func (c HttpDeviceConfig) PollInterval() time.Duration {
 	return c.pollInterval
 }
 
+func (c HttpDeviceConfig) CommandReadback() CommandReadbackConfig {
+	return c.commandReadback
+}
+
 func (c HttpDeviceConfig) LogDebug() bool {
 	return c.logDebug
 }
@@ -508,6 +717,25 @@ func (c MqttDeviceConfig) Kind() types.MqttDeviceKind {
 	return c.kind
 }
 
+// Getter for ReplayDeviceConfig struct
+
+func (c ReplayDeviceConfig) File() string {
+	return c.file
+}
+
+// DeviceName is the name of the replayed device within the recording.
+func (c ReplayDeviceConfig) DeviceName() string {
+	return c.deviceName
+}
+
+func (c ReplayDeviceConfig) Speed() float64 {
+	return c.speed
+}
+
+func (c ReplayDeviceConfig) Loop() bool {
+	return c.loop
+}
+
 // Getter for GensetDeviceConfig struct
 
 func (c GensetDeviceConfig) InputBindings() []GensetDeviceBindingConfig {
@@ -640,6 +868,250 @@ func (c GensetDeviceBindingConfig) RegisterName() string {
 	return c.registerName
 }
 
+// Getters for ComputedDeviceConfig struct
+
+func (c ComputedDeviceConfig) Registers() []ComputedRegisterConfig {
+	return c.registers
+}
+
+// Getters for ComputedRegisterConfig struct
+
+func (c ComputedRegisterConfig) Name() string {
+	return c.name
+}
+
+func (c ComputedRegisterConfig) Expression() string {
+	return c.expression
+}
+
+func (c ComputedRegisterConfig) Type() types.ComputedRegisterType {
+	return c.registerType
+}
+
+func (c ComputedRegisterConfig) Category() string {
+	return c.category
+}
+
+func (c ComputedRegisterConfig) Description() string {
+	return c.description
+}
+
+func (c ComputedRegisterConfig) Unit() string {
+	return c.unit
+}
+
+func (c ComputedRegisterConfig) Sort() int {
+	return c.sort
+}
+
+// Getters for EnergyDeviceConfig struct
+
+func (c EnergyDeviceConfig) Inputs() []EnergyDeviceInputConfig {
+	return c.inputs
+}
+
+func (c EnergyDeviceConfig) MaxGap() time.Duration {
+	return c.maxGap
+}
+
+func (c EnergyDeviceConfig) File() string {
+	return c.file
+}
+
+func (c EnergyDeviceConfig) WriteInterval() time.Duration {
+	return c.writeInterval
+}
+
+// Getters for EnergyDeviceInputConfig struct
+
+func (c EnergyDeviceInputConfig) Name() string {
+	return c.name
+}
+
+func (c EnergyDeviceInputConfig) DeviceName() string {
+	return c.deviceName
+}
+
+func (c EnergyDeviceInputConfig) RegisterName() string {
+	return c.registerName
+}
+
+// Getters for AlarmDeviceConfig struct
+
+func (c AlarmDeviceConfig) Rules() []AlarmRuleConfig {
+	return c.rules
+}
+
+// Getters for AlarmRuleConfig struct
+
+func (c AlarmRuleConfig) Name() string {
+	return c.name
+}
+
+func (c AlarmRuleConfig) DeviceName() string {
+	return c.deviceName
+}
+
+func (c AlarmRuleConfig) RegisterName() string {
+	return c.registerName
+}
+
+func (c AlarmRuleConfig) Condition() types.AlarmCondition {
+	return c.condition
+}
+
+func (c AlarmRuleConfig) Warning() *float64 {
+	return c.warning
+}
+
+func (c AlarmRuleConfig) Alarm() *float64 {
+	return c.alarm
+}
+
+func (c AlarmRuleConfig) Hysteresis() float64 {
+	return c.hysteresis
+}
+
+func (c AlarmRuleConfig) Delay() time.Duration {
+	return c.delay
+}
+
+func (c AlarmRuleConfig) Category() string {
+	return c.category
+}
+
+func (c AlarmRuleConfig) Description() string {
+	return c.description
+}
+
+func (c AlarmRuleConfig) Sort() int {
+	return c.sort
+}
+
+// Getters for AutomationDeviceConfig struct
+
+func (c AutomationDeviceConfig) Rules() []AutomationRuleConfig {
+	return c.rules
+}
+
+// Getters for AutomationRuleConfig struct
+
+func (c AutomationRuleConfig) Name() string {
+	return c.name
+}
+
+func (c AutomationRuleConfig) On() string {
+	return c.on
+}
+
+func (c AutomationRuleConfig) Off() string {
+	return c.off
+}
+
+func (c AutomationRuleConfig) DeviceName() string {
+	return c.deviceName
+}
+
+func (c AutomationRuleConfig) RegisterName() string {
+	return c.registerName
+}
+
+func (c AutomationRuleConfig) OnValue() string {
+	return c.onValue
+}
+
+func (c AutomationRuleConfig) OffValue() string {
+	return c.offValue
+}
+
+func (c AutomationRuleConfig) MinOnTime() time.Duration {
+	return c.minOnTime
+}
+
+func (c AutomationRuleConfig) MinOffTime() time.Duration {
+	return c.minOffTime
+}
+
+func (c AutomationRuleConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c AutomationRuleConfig) Category() string {
+	return c.category
+}
+
+func (c AutomationRuleConfig) Description() string {
+	return c.description
+}
+
+func (c AutomationRuleConfig) Sort() int {
+	return c.sort
+}
+
+// Getters for SchedulerDeviceConfig struct
+
+func (c SchedulerDeviceConfig) Latitude() float64 {
+	return c.latitude
+}
+
+func (c SchedulerDeviceConfig) Longitude() float64 {
+	return c.longitude
+}
+
+func (c SchedulerDeviceConfig) Schedules() []ScheduleConfig {
+	return c.schedules
+}
+
+// Getters for ScheduleConfig struct
+
+func (c ScheduleConfig) Name() string {
+	return c.name
+}
+
+func (c ScheduleConfig) Cron() string {
+	return c.cron
+}
+
+func (c ScheduleConfig) Sun() types.SunEvent {
+	return c.sun
+}
+
+func (c ScheduleConfig) Offset() time.Duration {
+	return c.offset
+}
+
+func (c ScheduleConfig) DeviceName() string {
+	return c.deviceName
+}
+
+func (c ScheduleConfig) RegisterName() string {
+	return c.registerName
+}
+
+func (c ScheduleConfig) Value() string {
+	return c.value
+}
+
+func (c ScheduleConfig) MaxDelay() time.Duration {
+	return c.maxDelay
+}
+
+func (c ScheduleConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c ScheduleConfig) Category() string {
+	return c.category
+}
+
+func (c ScheduleConfig) Description() string {
+	return c.description
+}
+
+func (c ScheduleConfig) Sort() int {
+	return c.sort
+}
+
 // Getters for ViewConfig struct
 
 func (c ViewConfig) Name() string {
@@ -706,3 +1178,92 @@ func (c FilterConfig) SkipCategories() []string {
 func (c FilterConfig) DefaultInclude() bool {
 	return c.defaultInclude
 }
+
+func (c FilterConfig) LogPrefix() string {
+	return c.logPrefix
+}
+
+// Getters for DeadbandConfig struct
+
+func (c DeadbandConfig) Registers() map[string]DeadbandValue {
+	return c.registers
+}
+
+func (c DeadbandConfig) Categories() map[string]DeadbandValue {
+	return c.categories
+}
+
+func (c DeadbandConfig) MaxSilence() time.Duration {
+	return c.maxSilence
+}
+
+func (c DeadbandConfig) Enabled() bool {
+	return len(c.registers) > 0 || len(c.categories) > 0
+}
+
+// Of returns the deadband of the given register; the register setting takes precedence over the category.
+func (c DeadbandConfig) Of(category, registerName string) (v DeadbandValue, ok bool) {
+	if v, ok = c.registers[registerName]; ok {
+		return
+	}
+	v, ok = c.categories[category]
+	return
+}
+
+// Getters for RegisterTransformConfig struct
+
+func (c RegisterTransformConfig) Scale() float64 {
+	return c.scale
+}
+
+func (c RegisterTransformConfig) Offset() float64 {
+	return c.offset
+}
+
+func (c RegisterTransformConfig) Unit() string {
+	return c.unit
+}
+
+// Precision returns the number of decimals to round to; -1 disables rounding.
+func (c RegisterTransformConfig) Precision() int {
+	return c.precision
+}
+
+func (c RegisterTransformConfig) Enum() map[int]string {
+	return c.enum
+}
+
+// Getters for RegisterLimitsConfig struct
+
+// Min returns the lowest value accepted for commands; nil if not limited.
+func (c RegisterLimitsConfig) Min() *float64 {
+	return c.min
+}
+
+// Max returns the highest value accepted for commands; nil if not limited.
+func (c RegisterLimitsConfig) Max() *float64 {
+	return c.max
+}
+
+// Step returns the increment values of commands must be a multiple of; nil if not limited.
+func (c RegisterLimitsConfig) Step() *float64 {
+	return c.step
+}
+
+// Getters for DeadbandValue struct
+
+func (c DeadbandValue) Absolute() float64 {
+	return c.absolute
+}
+
+// Relative returns the deadband as fraction of the last value; e.g. 0.02 for 2%.
+func (c DeadbandValue) Relative() float64 {
+	return c.relative
+}
+
+func (c DeadbandValue) String() string {
+	if c.relative > 0 {
+		return strconv.FormatFloat(c.relative*100, 'g', -1, 64) + "%"
+	}
+	return strconv.FormatFloat(c.absolute, 'g', -1, 64)
+}
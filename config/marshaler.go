@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"slices"
 
 	"golang.org/x/exp/maps"
 )
@@ -16,6 +17,9 @@ func (c Config) MarshalYAML() (interface{}, error) {
 		LogCommandStorageDebug: &c.logCommandStorageDebug,
 		HttpServer:             convertEnableableToRead[HttpServerConfig, httpServerConfigRead](c.httpServer),
 		Authentication:         convertEnableableToRead[AuthenticationConfig, authenticationConfigRead](c.authentication),
+		Persistence:            convertEnableableToRead[PersistenceConfig, persistenceConfigRead](c.persistence),
+		History:                convertEnableableToRead[HistoryConfig, historyConfigRead](c.history),
+		Recorder:               convertEnableableToRead[RecorderConfig, recorderConfigRead](c.recorder),
 		MqttClients:            convertMapToRead[MqttClientConfig, mqttClientConfigRead](c.mqttClients),
 		Modbus:                 convertMapToRead[ModbusConfig, modbusConfigRead](c.modbus),
 		VictronDevices:         convertMapToRead[VictronDeviceConfig, victronDeviceConfigRead](c.victronDevices),
@@ -23,11 +27,24 @@ func (c Config) MarshalYAML() (interface{}, error) {
 		GpioDevices:            convertMapToRead[GpioDeviceConfig, gpioDeviceConfigRead](c.gpioDevices),
 		HttpDevices:            convertMapToRead[HttpDeviceConfig, httpDeviceConfigRead](c.httpDevices),
 		MqttDevices:            convertMapToRead[MqttDeviceConfig, mqttDeviceConfigRead](c.mqttDevices),
+		ReplayDevices:          convertMapToRead[ReplayDeviceConfig, replayDeviceConfigRead](c.replayDevices),
 		GensetDevices:          convertMapToRead[GensetDeviceConfig, gensetDeviceConfigRead](c.gensetDevices),
+		ComputedDevices:        convertMapToRead[ComputedDeviceConfig, computedDeviceConfigRead](c.computedDevices),
+		EnergyDevices:          convertMapToRead[EnergyDeviceConfig, energyDeviceConfigRead](c.energyDevices),
+		AlarmDevices:           convertMapToRead[AlarmDeviceConfig, alarmDeviceConfigRead](c.alarmDevices),
+		AutomationDevices:      convertMapToRead[AutomationDeviceConfig, automationDeviceConfigRead](c.automationDevices),
+		SchedulerDevices:       convertMapToRead[SchedulerDeviceConfig, schedulerDeviceConfigRead](c.schedulerDevices),
 		Views:                  convertListToRead[ViewConfig, viewConfigRead](c.views),
 	}, nil
 }
 
+// sortedKeys is used for sets such that marshalling and comparing configurations gives stable results.
+func sortedKeys(set map[string]struct{}) []string {
+	keys := maps.Keys(set)
+	slices.Sort(keys)
+	return keys
+}
+
 type convertable[O any] interface {
 	convertToRead() O
 }
@@ -94,6 +111,39 @@ func (c AuthenticationConfig) convertToRead() authenticationConfigRead {
 		JwtSecret:         &jwtSecret,
 		JwtValidityPeriod: c.jwtValidityPeriod.String(),
 		HtaccessFile:      &c.htaccessFile,
+		AdminUsers:        sortedKeys(c.adminUsers),
+	}
+}
+
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c PersistenceConfig) convertToRead() persistenceConfigRead {
+	return persistenceConfigRead{
+		StateFile:     c.stateFile,
+		CommandFile:   c.commandFile,
+		WriteInterval: c.writeInterval.String(),
+		LogDebug:      &c.logDebug,
+	}
+}
+
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c HistoryConfig) convertToRead() historyConfigRead {
+	return historyConfigRead{
+		RawRetention:     c.rawRetention.String(),
+		MinuteRetention:  c.minuteRetention.String(),
+		QuarterRetention: c.quarterRetention.String(),
+		File:             c.file,
+		WriteInterval:    c.writeInterval.String(),
+		LogDebug:         &c.logDebug,
+	}
+}
+
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c RecorderConfig) convertToRead() recorderConfigRead {
+	return recorderConfigRead{
+		File:          c.file,
+		Devices:       c.devices,
+		FlushInterval: c.flushInterval.String(),
+		LogDebug:      &c.logDebug,
 	}
 }
 
@@ -123,6 +173,7 @@ func (c MqttClientConfig) convertToRead() mqttClientConfigRead {
 		Realtime:               c.realtime.convertToRead(),
 		HomeassistantDiscovery: c.homeassistantDiscovery.convertToRead(),
 		Command:                c.command.convertToRead(),
+		CommandResponse:        c.commandResponse.convertToRead(),
 
 		LogDebug:    &c.logDebug,
 		LogMessages: &c.logMessages,
@@ -168,15 +219,80 @@ func (c ModbusConfig) convertToRead() modbusConfigRead {
 
 //lint:ignore U1000 linter does not catch that this is used generic code
 func (c DeviceConfig) convertToRead() deviceConfigRead {
+	registerMaxAge := make(map[string]string, len(c.registerMaxAge))
+	for k, v := range c.registerMaxAge {
+		registerMaxAge[k] = v.String()
+	}
+
+	transform := make(map[string]registerTransformConfigRead, len(c.transform))
+	for k, v := range c.transform {
+		transform[k] = v.convertToRead()
+	}
+
+	limits := make(map[string]registerLimitsConfigRead, len(c.limits))
+	for k, v := range c.limits {
+		limits[k] = v.convertToRead()
+	}
+
+	dependsOn := make(map[string][]string, len(c.dependsOn))
+	for _, d := range c.dependsOn {
+		dependsOn[d.deviceName] = d.registerNames
+	}
+
 	return deviceConfigRead{
 		Filter:                    c.filter.convertToRead(),
 		RestartInterval:           c.restartInterval.String(),
 		RestartIntervalMaxBackoff: c.restartIntervalMaxBackoff.String(),
+		MaxAge:                    c.maxAge.String(),
+		RegisterMaxAge:            registerMaxAge,
+		Deadband:                  c.deadband.convertToRead(),
+		Transform:                 transform,
+		Limits:                    limits,
+		DependsOn:                 dependsOn,
+		DependencyTimeout:         c.dependencyTimeout.String(),
 		LogDebug:                  &c.logDebug,
 		LogComDebug:               &c.logComDebug,
 	}
 }
 
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c DeadbandConfig) convertToRead() deadbandConfigRead {
+	registers := make(map[string]string, len(c.registers))
+	for k, v := range c.registers {
+		registers[k] = v.String()
+	}
+	categories := make(map[string]string, len(c.categories))
+	for k, v := range c.categories {
+		categories[k] = v.String()
+	}
+
+	return deadbandConfigRead{
+		Registers:  registers,
+		Categories: categories,
+		MaxSilence: c.maxSilence.String(),
+	}
+}
+
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c RegisterTransformConfig) convertToRead() registerTransformConfigRead {
+	return registerTransformConfigRead{
+		Scale:     &c.scale,
+		Offset:    &c.offset,
+		Unit:      c.unit,
+		Precision: &c.precision,
+		Enum:      c.enum,
+	}
+}
+
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c RegisterLimitsConfig) convertToRead() registerLimitsConfigRead {
+	return registerLimitsConfigRead{
+		Min:  c.min,
+		Max:  c.max,
+		Step: c.step,
+	}
+}
+
 //lint:ignore U1000 linter does not catch that this is used generic code
 func (c VictronDeviceConfig) convertToRead() victronDeviceConfigRead {
 	return victronDeviceConfigRead{
@@ -185,6 +301,7 @@ func (c VictronDeviceConfig) convertToRead() victronDeviceConfigRead {
 		Kind:             c.kind.String(),
 		PollInterval:     c.pollInterval.String(),
 		IoLog:            &c.ioLog,
+		ReplaySpeed:      &c.replaySpeed,
 	}
 }
 
@@ -202,7 +319,16 @@ func (c ModbusDeviceConfig) convertToRead() modbusDeviceConfigRead {
 			}
 			return oup
 		}(c.relays),
-		PollInterval: c.pollInterval.String(),
+		PollInterval:    c.pollInterval.String(),
+		CommandReadback: convertEnableableToRead[CommandReadbackConfig, commandReadbackConfigRead](c.commandReadback),
+	}
+}
+
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c CommandReadbackConfig) convertToRead() commandReadbackConfigRead {
+	return commandReadbackConfigRead{
+		Retries: &c.retries,
+		Backoff: c.backoff.String(),
 	}
 }
 
@@ -247,6 +373,7 @@ func (c HttpDeviceConfig) convertToRead() httpDeviceConfigRead {
 		Username:         c.username,
 		Password:         c.password,
 		PollInterval:     c.pollInterval.String(),
+		CommandReadback:  convertEnableableToRead[CommandReadbackConfig, commandReadbackConfigRead](c.commandReadback),
 	}
 }
 
@@ -258,6 +385,17 @@ func (c MqttDeviceConfig) convertToRead() mqttDeviceConfigRead {
 	}
 }
 
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c ReplayDeviceConfig) convertToRead() replayDeviceConfigRead {
+	return replayDeviceConfigRead{
+		deviceConfigRead: c.DeviceConfig.convertToRead(),
+		File:             c.file,
+		Device:           c.deviceName,
+		Speed:            &c.speed,
+		Loop:             &c.loop,
+	}
+}
+
 //lint:ignore U1000 linter does not catch that this is used generic code
 func (c GensetDeviceConfig) convertToRead() gensetDeviceConfigRead {
 	return gensetDeviceConfigRead{
@@ -307,6 +445,134 @@ func (c GensetDeviceBindingsConfig) convertToRead() map[string]map[string]string
 	return oup
 }
 
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c ComputedDeviceConfig) convertToRead() computedDeviceConfigRead {
+	registers := make(map[string]computedRegisterConfigRead, len(c.registers))
+	for _, r := range c.registers {
+		sort := r.sort
+		registers[r.name] = computedRegisterConfigRead{
+			Expression:  r.expression,
+			Type:        r.registerType.String(),
+			Category:    r.category,
+			Description: r.description,
+			Unit:        r.unit,
+			Sort:        &sort,
+		}
+	}
+
+	return computedDeviceConfigRead{
+		deviceConfigRead: c.DeviceConfig.convertToRead(),
+		Registers:        registers,
+	}
+}
+
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c EnergyDeviceConfig) convertToRead() energyDeviceConfigRead {
+	inputs := make(map[string]energyDeviceInputConfigRead, len(c.inputs))
+	for _, inp := range c.inputs {
+		inputs[inp.name] = energyDeviceInputConfigRead{
+			Device:   inp.deviceName,
+			Register: inp.registerName,
+		}
+	}
+
+	return energyDeviceConfigRead{
+		deviceConfigRead: c.DeviceConfig.convertToRead(),
+		Inputs:           inputs,
+		MaxGap:           c.maxGap.String(),
+		File:             c.file,
+		WriteInterval:    c.writeInterval.String(),
+	}
+}
+
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c AlarmDeviceConfig) convertToRead() alarmDeviceConfigRead {
+	rules := make(map[string]alarmRuleConfigRead, len(c.rules))
+	for _, r := range c.rules {
+		hysteresis := r.hysteresis
+		sort := r.sort
+		rules[r.name] = alarmRuleConfigRead{
+			Device:      r.deviceName,
+			Register:    r.registerName,
+			Condition:   r.condition.String(),
+			Warning:     r.warning,
+			Alarm:       r.alarm,
+			Hysteresis:  &hysteresis,
+			Delay:       r.delay.String(),
+			Category:    r.category,
+			Description: r.description,
+			Sort:        &sort,
+		}
+	}
+
+	return alarmDeviceConfigRead{
+		deviceConfigRead: c.DeviceConfig.convertToRead(),
+		Rules:            rules,
+	}
+}
+
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c AutomationDeviceConfig) convertToRead() automationDeviceConfigRead {
+	rules := make(map[string]automationRuleConfigRead, len(c.rules))
+	for _, r := range c.rules {
+		enabled := r.enabled
+		sort := r.sort
+		rules[r.name] = automationRuleConfigRead{
+			On:          r.on,
+			Off:         r.off,
+			Device:      r.deviceName,
+			Register:    r.registerName,
+			OnValue:     r.onValue,
+			OffValue:    r.offValue,
+			MinOnTime:   r.minOnTime.String(),
+			MinOffTime:  r.minOffTime.String(),
+			Enabled:     &enabled,
+			Category:    r.category,
+			Description: r.description,
+			Sort:        &sort,
+		}
+	}
+
+	return automationDeviceConfigRead{
+		deviceConfigRead: c.DeviceConfig.convertToRead(),
+		Rules:            rules,
+	}
+}
+
+//lint:ignore U1000 linter does not catch that this is used generic code
+func (c SchedulerDeviceConfig) convertToRead() schedulerDeviceConfigRead {
+	schedules := make(map[string]scheduleConfigRead, len(c.schedules))
+	for _, sc := range c.schedules {
+		enabled := sc.enabled
+		sort := sc.sort
+		r := scheduleConfigRead{
+			Cron:        sc.cron,
+			Device:      sc.deviceName,
+			Register:    sc.registerName,
+			Value:       sc.value,
+			MaxDelay:    sc.maxDelay.String(),
+			Enabled:     &enabled,
+			Category:    sc.category,
+			Description: sc.description,
+			Sort:        &sort,
+		}
+		if len(sc.cron) < 1 {
+			r.Sun = sc.sun.String()
+			r.Offset = sc.offset.String()
+		}
+		schedules[sc.name] = r
+	}
+
+	latitude := c.latitude
+	longitude := c.longitude
+	return schedulerDeviceConfigRead{
+		deviceConfigRead: c.DeviceConfig.convertToRead(),
+		Latitude:         &latitude,
+		Longitude:        &longitude,
+		Schedules:        schedules,
+	}
+}
+
 //lint:ignore U1000 linter does not catch that this is used generic code
 func (c ViewConfig) convertToRead() viewConfigRead {
 	return viewConfigRead{
@@ -314,7 +580,7 @@ func (c ViewConfig) convertToRead() viewConfigRead {
 		Title:        c.title,
 		Devices:      convertListToRead[ViewDeviceConfig, viewDeviceConfigRead](c.devices),
 		Autoplay:     &c.autoplay,
-		AllowedUsers: maps.Keys(c.allowedUsers),
+		AllowedUsers: sortedKeys(c.allowedUsers),
 		Hidden:       &c.hidden,
 	}
 }
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/koestler/go-iotdevice/v3/types"
+)
+
+// WithDevices returns a copy of the configuration with the devices of the given yaml fragment added.
+// The fragment uses the same sections as the configuration file, e.g. HttpDevices, and must not contain
+// any other section. Devices with the same name in the same section are replaced.
+// The generated client ids and jwt secret are kept, see KeepGenerated.
+// The fragment usually comes from the admin api: environment variables are not substituted
+// and options reading or writing files are rejected, see runtimeFileErrors.
+func (c Config) WithDevices(fragment []byte, bypassFileCheck bool) (ret Config, err []error) {
+	var add configRead
+
+	if e := decodeYaml(fragment, &add, false); len(e) > 0 {
+		return c, e
+	}
+
+	// check that the fragment only contains devices
+	devices := configRead{
+		VictronDevices:    add.VictronDevices,
+		ModbusDevices:     add.ModbusDevices,
+		GpioDevices:       add.GpioDevices,
+		HttpDevices:       add.HttpDevices,
+		MqttDevices:       add.MqttDevices,
+		ReplayDevices:     add.ReplayDevices,
+		GensetDevices:     add.GensetDevices,
+		ComputedDevices:   add.ComputedDevices,
+		EnergyDevices:     add.EnergyDevices,
+		AlarmDevices:      add.AlarmDevices,
+		AutomationDevices: add.AutomationDevices,
+		SchedulerDevices:  add.SchedulerDevices,
+	}
+	if !reflect.DeepEqual(add, devices) {
+		return c, []error{fmt.Errorf("only device sections like HttpDevices are allowed")}
+	}
+	if reflect.DeepEqual(devices, configRead{}) {
+		return c, []error{fmt.Errorf("no device given")}
+	}
+	if e := runtimeFileErrors(add); len(e) > 0 {
+		return c, e
+	}
+
+	s := c.source
+	s.VictronDevices = mergeDevices(s.VictronDevices, add.VictronDevices)
+	s.ModbusDevices = mergeDevices(s.ModbusDevices, add.ModbusDevices)
+	s.GpioDevices = mergeDevices(s.GpioDevices, add.GpioDevices)
+	s.HttpDevices = mergeDevices(s.HttpDevices, add.HttpDevices)
+	s.MqttDevices = mergeDevices(s.MqttDevices, add.MqttDevices)
+	s.ReplayDevices = mergeDevices(s.ReplayDevices, add.ReplayDevices)
+	s.GensetDevices = mergeDevices(s.GensetDevices, add.GensetDevices)
+	s.ComputedDevices = mergeDevices(s.ComputedDevices, add.ComputedDevices)
+	s.EnergyDevices = mergeDevices(s.EnergyDevices, add.EnergyDevices)
+	s.AlarmDevices = mergeDevices(s.AlarmDevices, add.AlarmDevices)
+	s.AutomationDevices = mergeDevices(s.AutomationDevices, add.AutomationDevices)
+	s.SchedulerDevices = mergeDevices(s.SchedulerDevices, add.SchedulerDevices)
+
+	return c.transformSource(s, bypassFileCheck)
+}
+
+// runtimeFileErrors rejects the options of devices added at runtime which give a path to a file on the host.
+func runtimeFileErrors(add configRead) (err []error) {
+	reject := func(section, name, field string) {
+		err = append(err, fmt.Errorf("%s->%s->%s must not be set for devices added at runtime", section, name, field))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(add.VictronDevices)) {
+		d := add.VictronDevices[name]
+		if d.IoLog != nil && len(*d.IoLog) > 0 {
+			reject("VictronDevices", name, "IoLog")
+		}
+		// the device of a replay is the io log to play back
+		if types.VictronDeviceKindFromString(d.Kind) == types.VictronReplayKind && len(d.Device) > 0 {
+			reject("VictronDevices", name, "Device")
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(add.HttpDevices)) {
+		if len(add.HttpDevices[name].PasswordFile) > 0 {
+			reject("HttpDevices", name, "PasswordFile")
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(add.ReplayDevices)) {
+		if len(add.ReplayDevices[name].File) > 0 {
+			reject("ReplayDevices", name, "File")
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(add.EnergyDevices)) {
+		if len(add.EnergyDevices[name].File) > 0 {
+			reject("EnergyDevices", name, "File")
+		}
+	}
+	return
+}
+
+// WithoutDevice returns a copy of the configuration with the given device removed.
+// Sections referencing the device, e.g. views or mqtt forwarders, need to be changed first.
+func (c Config) WithoutDevice(name string, bypassFileCheck bool) (ret Config, err []error) {
+	s := c.source
+	found := false
+	s.VictronDevices = removeDevice(s.VictronDevices, name, &found)
+	s.ModbusDevices = removeDevice(s.ModbusDevices, name, &found)
+	s.GpioDevices = removeDevice(s.GpioDevices, name, &found)
+	s.HttpDevices = removeDevice(s.HttpDevices, name, &found)
+	s.MqttDevices = removeDevice(s.MqttDevices, name, &found)
+	s.ReplayDevices = removeDevice(s.ReplayDevices, name, &found)
+	s.GensetDevices = removeDevice(s.GensetDevices, name, &found)
+	s.ComputedDevices = removeDevice(s.ComputedDevices, name, &found)
+	s.EnergyDevices = removeDevice(s.EnergyDevices, name, &found)
+	s.AlarmDevices = removeDevice(s.AlarmDevices, name, &found)
+	s.AutomationDevices = removeDevice(s.AutomationDevices, name, &found)
+	s.SchedulerDevices = removeDevice(s.SchedulerDevices, name, &found)
+
+	if !found {
+		return c, []error{fmt.Errorf("Devices->%s is not defined", name)}
+	}
+
+	return c.transformSource(s, bypassFileCheck)
+}
+
+func (c Config) transformSource(s configRead, bypassFileCheck bool) (ret Config, err []error) {
+	ret, err = s.TransformAndValidate(bypassFileCheck)
+	if len(err) > 0 {
+		return c, err
+	}
+	ret.KeepGenerated(c)
+	return
+}
+
+// mergeDevices returns a copy of existing with all devices of add added; existing is not modified.
+func mergeDevices[R any](existing, add map[string]R) map[string]R {
+	if len(add) < 1 {
+		return existing
+	}
+	ret := maps.Clone(existing)
+	if ret == nil {
+		ret = make(map[string]R, len(add))
+	}
+	maps.Copy(ret, add)
+	return ret
+}
+
+// removeDevice returns a copy of existing without the given device; existing is not modified.
+func removeDevice[R any](existing map[string]R, name string, found *bool) map[string]R {
+	if _, ok := existing[name]; !ok {
+		return existing
+	}
+	*found = true
+	ret := maps.Clone(existing)
+	delete(ret, name)
+	return ret
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithDevices(t *testing.T) {
+	running, err := ReadConfig([]byte(ValidCompleteConfig), true)
+	if len(err) > 0 {
+		t.Fatalf("did not expect any error, got: %v", err)
+	}
+
+	t.Run("add", func(t *testing.T) {
+		c, err := running.WithDevices([]byte("HttpDevices:\n  shelly0:\n    Url: http://shelly0/\n    Kind: ShellyEm3\n"), true)
+		if len(err) > 0 {
+			t.Fatalf("did not expect any error, got: %v", err)
+		}
+		if !existsByName("shelly0", c.HttpDevices()) {
+			t.Errorf("expect shelly0 to be added")
+		}
+		if expect, got := len(running.Devices())+1, len(c.Devices()); expect != got {
+			t.Errorf("expect %d devices but got %d", expect, got)
+		}
+		if existsByName("shelly0", running.HttpDevices()) {
+			t.Errorf("expect the running configuration to be unchanged")
+		}
+		if d := Compare(running, c); len(d.Devices) != 1 || d.Devices[0] != "shelly0" || d.HttpRoutes {
+			t.Errorf("expect only shelly0 to change, got: %+v", d)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c, err := running.WithDevices([]byte("HttpDevices:\n  shelly0:\n    Kind: ShellyEm3\n"), true)
+		if len(err) < 1 {
+			t.Errorf("expect an error for a device without url")
+		}
+		if existsByName("shelly0", c.HttpDevices()) {
+			t.Errorf("expect the running configuration to be returned on error")
+		}
+	})
+
+	t.Run("noEnv", func(t *testing.T) {
+		t.Setenv("IOTDEVICE_TEST_SECRET", "secret")
+		c, err := running.WithDevices([]byte("HttpDevices:\n  shelly0:\n    Url: http://shelly0/${IOTDEVICE_TEST_SECRET}\n    Kind: ShellyEm3\n"), true)
+		if len(err) > 0 {
+			t.Fatalf("did not expect any error, got: %v", err)
+		}
+		for _, d := range c.HttpDevices() {
+			if d.Name() != "shelly0" {
+				continue
+			}
+			if got := d.Url().String(); strings.Contains(got, "secret") {
+				t.Errorf("expect environment variables not to be substituted but got url '%s'", got)
+			}
+		}
+	})
+
+	t.Run("files", func(t *testing.T) {
+		fragments := map[string]string{
+			"HttpDevices->shelly0->PasswordFile": "HttpDevices:\n  shelly0:\n    Url: http://shelly0/\n    Kind: ShellyEm3\n    PasswordFile: /etc/shadow\n",
+			"VictronDevices->bmv9->IoLog":        "VictronDevices:\n  bmv9:\n    Kind: RandomBmv\n    IoLog: /tmp/bmv9.log\n",
+			"VictronDevices->bmv9->Device":       "VictronDevices:\n  bmv9:\n    Kind: Replay\n    Device: /etc/shadow\n",
+			"ReplayDevices->replay9->File":       "ReplayDevices:\n  replay9:\n    File: /etc/shadow\n",
+			"EnergyDevices->energy9->File":       "EnergyDevices:\n  energy9:\n    Inputs:\n      Solar:\n        Device: bmv1\n        Register: Power\n    File: /etc/passwd\n",
+		}
+		for field, fragment := range fragments {
+			c, err := running.WithDevices([]byte(fragment), true)
+			if len(err) != 1 || !strings.Contains(err[0].Error(), field+" must not be set for devices added at runtime") {
+				t.Errorf("expect an error for %s, got: %v", field, err)
+			}
+			if expect, got := len(running.Devices()), len(c.Devices()); expect != got {
+				t.Errorf("expect the running configuration to be returned for %s", field)
+			}
+		}
+	})
+
+	t.Run("otherSection", func(t *testing.T) {
+		_, err := running.WithDevices([]byte("ProjectTitle: foo\n"), true)
+		if len(err) != 1 || !strings.Contains(err[0].Error(), "only device sections") {
+			t.Errorf("expect an error for a non device section, got: %v", err)
+		}
+	})
+}
+
+func TestWithoutDevice(t *testing.T) {
+	running, err := ReadConfig([]byte(ValidCompleteConfig), true)
+	if len(err) > 0 {
+		t.Fatalf("did not expect any error, got: %v", err)
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := running.WithoutDevice("foo", true)
+		if len(err) != 1 || !strings.Contains(err[0].Error(), "Devices->foo is not defined") {
+			t.Errorf("expect an error for an unknown device, got: %v", err)
+		}
+	})
+
+	t.Run("referenced", func(t *testing.T) {
+		if _, err := running.WithoutDevice("bmv1", true); len(err) < 1 {
+			t.Errorf("expect an error when removing a device used by other sections")
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/koestler/go-iotdevice/v3/types"
+)
+
+// The schema struct tag of the read types adds information to the generated JSON Schema.
+// It is a comma separated list of the following options; default must be the last one:
+//   - required: the field is mandatory
+//   - name: the field, or the keys of the map, must match NameRegexp
+//   - duration: the value is parsed using time.ParseDuration
+//   - scalar: the value is read as a string but numbers and booleans are accepted as well
+//   - enum=<name>: the value must be one of schemaEnums
+//
+// The options describing the value apply to the items of lists and maps.
+//   - default=<value>: the default used when the field is missing
+//
+// e.g. `yaml:"Kind" schema:"required,enum=victronDeviceKind"`
+
+const durationPattern = `^(0|-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+
+var schemaEnums = map[string][]string{
+	"victronDeviceKind":    stringsOf(types.VictronDeviceKinds),
+	"modbusDeviceKind":     stringsOf(types.ModbusDeviceKinds),
+	"httpDeviceKind":       stringsOf(types.HttpDeviceKinds),
+	"mqttDeviceKind":       stringsOf(types.MqttDeviceKinds),
+	"alarmCondition":       stringsOf(types.AlarmConditions),
+	"computedRegisterType": stringsOf(types.ComputedRegisterTypes),
+	"sunEvent":             stringsOf(types.SunEvents),
+	"gpioInputOption":      gpioInputOptions,
+	"gpioOutputOption":     gpioOutputOptions,
+}
+
+type jsonSchema map[string]any
+
+type schemaGenerator struct {
+	definitions map[string]jsonSchema
+}
+
+// JsonSchema returns a JSON Schema of the configuration file generated from the read types,
+// e.g. to let editors validate and autocomplete the configuration.
+func JsonSchema() ([]byte, error) {
+	g := schemaGenerator{definitions: make(map[string]jsonSchema)}
+
+	root := g.object(reflect.TypeFor[configRead]())
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["title"] = "go-iotdevice configuration"
+	root["definitions"] = g.definitions
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+func (g *schemaGenerator) typeSchema(t reflect.Type) jsonSchema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.typeSchema(t.Elem()))
+	case reflect.Struct:
+		name := definitionName(t)
+		if _, ok := g.definitions[name]; !ok {
+			g.definitions[name] = nil // reserve the name while generating recursive types
+			g.definitions[name] = g.object(t)
+		}
+		return jsonSchema{"$ref": "#/definitions/" + name}
+	case reflect.Map:
+		s := jsonSchema{"type": []string{"object", "null"}, "additionalProperties": g.typeSchema(t.Elem())}
+		if t.Key().Kind() == reflect.Int {
+			s["propertyNames"] = jsonSchema{"pattern": "^-?[0-9]+$"}
+		}
+		return s
+	case reflect.Slice:
+		return jsonSchema{"type": []string{"array", "null"}, "items": g.typeSchema(t.Elem())}
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int:
+		return jsonSchema{"type": "integer"}
+	case reflect.Uint8:
+		return jsonSchema{"type": "integer", "minimum": 0, "maximum": 255}
+	case reflect.Float64:
+		return jsonSchema{"type": "number"}
+	default:
+		panic(fmt.Sprintf("config: type %s is not supported by the schema generator", t))
+	}
+}
+
+func (g *schemaGenerator) object(t reflect.Type) jsonSchema {
+	properties := make(jsonSchema)
+	var required []string
+	g.addProperties(t, properties, &required)
+
+	s := jsonSchema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		slices.Sort(required)
+		s["required"] = required
+	}
+	return s
+}
+
+func (g *schemaGenerator) addProperties(t reflect.Type, properties jsonSchema, required *[]string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if opts == "inline" {
+			g.addProperties(f.Type, properties, required)
+			continue
+		}
+
+		p := g.typeSchema(f.Type)
+		if applySchemaTag(p, f.Type, f.Tag.Get("schema")) {
+			*required = append(*required, name)
+		}
+		properties[name] = p
+	}
+}
+
+// applySchemaTag adds the options of the schema struct tag to the schema of a field and returns whether it is required.
+func applySchemaTag(p jsonSchema, t reflect.Type, tag string) (required bool) {
+	for len(tag) > 0 {
+		var opt string
+		if strings.HasPrefix(tag, "default=") {
+			opt, tag = tag, ""
+		} else {
+			opt, tag, _ = strings.Cut(tag, ",")
+		}
+
+		// the options describing values apply to the items of lists and maps
+		v := p
+		if t.Kind() == reflect.Slice {
+			v = p["items"].(jsonSchema)
+		} else if t.Kind() == reflect.Map {
+			v = p["additionalProperties"].(jsonSchema)
+		}
+
+		key, value, _ := strings.Cut(opt, "=")
+		switch key {
+		case "required":
+			required = true
+		case "name":
+			if t.Kind() == reflect.Map {
+				p["propertyNames"] = jsonSchema{"pattern": NameRegexp}
+			} else {
+				p["pattern"] = NameRegexp
+			}
+		case "duration":
+			v["pattern"] = durationPattern
+		case "scalar":
+			v["type"] = []string{"string", "number", "boolean"}
+		case "enum":
+			enum, ok := schemaEnums[value]
+			if !ok {
+				panic(fmt.Sprintf("config: unknown schema enum '%s'", value))
+			}
+			v["enum"] = enum
+		case "default":
+			p["default"] = schemaDefault(t, value)
+		default:
+			panic(fmt.Sprintf("config: unknown schema tag option '%s'", opt))
+		}
+	}
+	return
+}
+
+func schemaDefault(t reflect.Type, value string) any {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var v any
+	var err error
+	switch t.Kind() {
+	case reflect.Bool:
+		v, err = strconv.ParseBool(value)
+	case reflect.Int, reflect.Uint8:
+		v, err = strconv.Atoi(value)
+	case reflect.Float64:
+		v, err = strconv.ParseFloat(value, 64)
+	default:
+		v = value
+	}
+	if err != nil {
+		panic(fmt.Sprintf("config: invalid schema default '%s' for type %s", value, t))
+	}
+	return v
+}
+
+// nullable allows fields which yaml can leave empty, e.g. `IoLog:`, to be null.
+func nullable(s jsonSchema) jsonSchema {
+	if t, ok := s["type"].(string); ok {
+		s["type"] = []string{t, "null"}
+	}
+	return s
+}
+
+// definitionName turns e.g. filterConfigRead into Filter.
+func definitionName(t reflect.Type) string {
+	name := strings.TrimSuffix(t.Name(), "ConfigRead")
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func stringsOf[T fmt.Stringer](values []T) []string {
+	ret := make([]string, len(values))
+	for i, v := range values {
+		ret[i] = v.String()
+	}
+	return ret
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestJsonSchema(t *testing.T) {
+	b, err := JsonSchema()
+	if err != nil {
+		t.Fatalf("cannot generate schema: %s", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("cannot parse schema: %s", err)
+	}
+
+	validate := func(t *testing.T, config string) (errs []string) {
+		t.Helper()
+		var root yaml.Node
+		if err := yaml.Unmarshal([]byte(config), &root); err != nil {
+			t.Fatalf("cannot parse yaml: %s", err)
+		}
+		return validateSchema(schema, schema, root.Content[0], "")
+	}
+
+	t.Run("validCompleteConfig", func(t *testing.T) {
+		for _, e := range validate(t, ValidCompleteConfig) {
+			t.Error(e)
+		}
+	})
+
+	t.Run("documentation", func(t *testing.T) {
+		for _, file := range []string{"../documentation/full-config.yaml", "../documentation/config.yaml"} {
+			config, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range validate(t, string(config)) {
+				t.Errorf("%s: %s", file, e)
+			}
+		}
+	})
+
+	t.Run("typo", func(t *testing.T) {
+		config := strings.Replace(ValidCompleteConfig, "    Filter:", "    Filer:", 1)
+		if errs := validate(t, config); len(errs) != 1 || !strings.Contains(errs[0], "Filer is not allowed") {
+			t.Errorf("expect the typo to be reported, got: %v", errs)
+		}
+
+		// unknown keys are reported by ReadConfig as well
+		_, err := ReadConfig([]byte(config), true)
+		if len(err) != 1 || !strings.Contains(err[0].Error(), "field Filer not found") {
+			t.Errorf("expect ReadConfig to fail on the typo, got: %v", err)
+		}
+	})
+
+	t.Run("kind", func(t *testing.T) {
+		config := strings.Replace(ValidCompleteConfig, "Kind: Vedirect", "Kind: VeDirect", 1)
+		if errs := validate(t, config); len(errs) != 1 || !strings.Contains(errs[0], "VeDirect") {
+			t.Errorf("expect the invalid kind to be reported, got: %v", errs)
+		}
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		config := strings.Replace(ValidCompleteConfig, "PollInterval: 700ms", "PollInterval: 700", 1)
+		if errs := validate(t, config); len(errs) != 1 || !strings.Contains(errs[0], "PollInterval") {
+			t.Errorf("expect the invalid duration to be reported, got: %v", errs)
+		}
+	})
+}
+
+// validateSchema checks the keys, enums and patterns of a yaml document; it only supports what JsonSchema generates.
+func validateSchema(root, s map[string]any, n *yaml.Node, path string) (errs []string) {
+	if ref, ok := s["$ref"].(string); ok {
+		s = root["definitions"].(map[string]any)[strings.TrimPrefix(ref, "#/definitions/")].(map[string]any)
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		properties, _ := s["properties"].(map[string]any)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i].Value, n.Content[i+1]
+			if p, ok := properties[key]; ok {
+				errs = append(errs, validateSchema(root, p.(map[string]any), value, path+"->"+key)...)
+			} else if ap, ok := s["additionalProperties"].(map[string]any); ok {
+				if pn, ok := s["propertyNames"].(map[string]any); ok && !regexp.MustCompile(pn["pattern"].(string)).MatchString(key) {
+					errs = append(errs, fmt.Sprintf("%s: name %s does not match %s", path, key, pn["pattern"]))
+				}
+				errs = append(errs, validateSchema(root, ap, value, path+"->"+key)...)
+			} else {
+				errs = append(errs, fmt.Sprintf("%s: %s is not allowed", path, key))
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			errs = append(errs, validateSchema(root, s["items"].(map[string]any), item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" {
+			return
+		}
+		if enum, ok := s["enum"].([]any); ok && !slices.Contains(enum, any(n.Value)) {
+			errs = append(errs, fmt.Sprintf("%s: %s is not one of %v", path, n.Value, enum))
+		}
+		if pattern, ok := s["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(n.Value) {
+			errs = append(errs, fmt.Sprintf("%s: %s does not match %s", path, n.Value, pattern))
+		}
+	}
+	return
+}
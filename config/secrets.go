@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// redactedSecret replaces passwords and secrets when the configuration is printed.
+const redactedSecret = "xxxxx"
+
+// readSecret returns the value of a secret which is either given directly or by a file, e.g. JwtSecret or JwtSecretFile.
+// Trailing newlines of the file are removed.
+func readSecret(errPrefix, field string, value, file *string) (*string, []error) {
+	if file == nil || len(*file) < 1 {
+		return value, nil
+	}
+	if value != nil && len(*value) > 0 {
+		return nil, []error{fmt.Errorf("%s->%s and %sFile must not both be set", errPrefix, field, field)}
+	}
+
+	content, e := os.ReadFile(*file)
+	if e != nil {
+		return nil, []error{fmt.Errorf("%s->%sFile='%s' cannot read file: %s", errPrefix, field, *file, e)}
+	}
+	secret := strings.TrimRight(string(content), "\r\n")
+	return &secret, nil
+}
+
+// readPassword returns the Password or the content of the PasswordFile.
+func readPassword(errPrefix, password, passwordFile string) (string, []error) {
+	secret, err := readSecret(errPrefix, "Password", &password, &passwordFile)
+	if secret == nil {
+		return "", err
+	}
+	return *secret, err
+}
+
+// redacted returns a copy of the configuration with all passwords and secrets replaced, e.g. to print it.
+func (c Config) redacted() Config {
+	c.authentication.jwtSecret = []byte(redactedSecret)
+
+	c.mqttClients = slices.Clone(c.mqttClients)
+	for i, mc := range c.mqttClients {
+		if len(mc.password) > 0 {
+			c.mqttClients[i].password = redactedSecret
+		}
+		c.mqttClients[i].broker = redactedUrl(mc.broker)
+	}
+
+	c.httpDevices = slices.Clone(c.httpDevices)
+	for i, hd := range c.httpDevices {
+		if len(hd.password) > 0 {
+			c.httpDevices[i].password = redactedSecret
+		}
+		c.httpDevices[i].url = redactedUrl(hd.url)
+	}
+
+	return c
+}
+
+// redactedUrl replaces the password contained in the user info of the url.
+func redactedUrl(u *url.URL) *url.URL {
+	if u == nil || u.User == nil {
+		return u
+	}
+	if _, ok := u.User.Password(); !ok {
+		return u
+	}
+	r := *u
+	r.User = url.UserPassword(u.User.Username(), redactedSecret)
+	return &r
+}
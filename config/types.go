@@ -20,6 +20,9 @@ type Config struct {
 	logCommandStorageDebug bool
 	httpServer             HttpServerConfig
 	authentication         AuthenticationConfig
+	persistence            PersistenceConfig
+	history                HistoryConfig
+	recorder               RecorderConfig
 	mqttClients            []MqttClientConfig
 	modbus                 []ModbusConfig
 	devices                []DeviceConfig
@@ -28,8 +31,17 @@ type Config struct {
 	gpioDevices            []GpioDeviceConfig
 	httpDevices            []HttpDeviceConfig
 	mqttDevices            []MqttDeviceConfig
+	replayDevices          []ReplayDeviceConfig
 	gensetDevices          []GensetDeviceConfig
+	computedDevices        []ComputedDeviceConfig
+	energyDevices          []EnergyDeviceConfig
+	alarmDevices           []AlarmDeviceConfig
+	automationDevices      []AutomationDeviceConfig
+	schedulerDevices       []SchedulerDeviceConfig
 	views                  []ViewConfig
+
+	// source is the configuration as read; it is used to add or remove devices at runtime
+	source configRead
 }
 
 type HttpServerConfig struct {
@@ -45,10 +57,38 @@ type HttpServerConfig struct {
 }
 
 type AuthenticationConfig struct {
-	enabled           bool
-	jwtSecret         []byte
-	jwtValidityPeriod time.Duration
-	htaccessFile      string
+	enabled            bool
+	jwtSecret          []byte
+	jwtSecretGenerated bool
+	jwtValidityPeriod  time.Duration
+	htaccessFile       string
+	adminUsers         map[string]struct{}
+}
+
+type PersistenceConfig struct {
+	enabled       bool
+	stateFile     string
+	commandFile   string
+	writeInterval time.Duration
+	logDebug      bool
+}
+
+type HistoryConfig struct {
+	enabled          bool
+	rawRetention     time.Duration
+	minuteRetention  time.Duration
+	quarterRetention time.Duration
+	file             string
+	writeInterval    time.Duration
+	logDebug         bool
+}
+
+type RecorderConfig struct {
+	enabled       bool
+	file          string
+	devices       []string
+	flushInterval time.Duration
+	logDebug      bool
 }
 
 type MqttClientConfig struct {
@@ -56,9 +96,10 @@ type MqttClientConfig struct {
 	broker          *url.URL
 	protocolVersion int
 
-	user     string
-	password string
-	clientId string
+	user              string
+	password          string
+	clientId          string
+	clientIdGenerated bool
 
 	keepAlive         time.Duration
 	connectRetryDelay time.Duration
@@ -76,6 +117,7 @@ type MqttClientConfig struct {
 	realtime               MqttSectionConfig
 	homeassistantDiscovery MqttSectionConfig
 	command                MqttSectionConfig
+	commandResponse        MqttSectionConfig
 
 	logDebug    bool
 	logMessages bool
@@ -113,25 +155,70 @@ type DeviceConfig struct {
 	filter                    FilterConfig
 	restartInterval           time.Duration
 	restartIntervalMaxBackoff time.Duration
+	maxAge                    time.Duration
+	registerMaxAge            map[string]time.Duration
+	deadband                  DeadbandConfig
+	transform                 map[string]RegisterTransformConfig
+	limits                    map[string]RegisterLimitsConfig
+	dependsOn                 []DependencyConfig
+	dependencyTimeout         time.Duration
 	logDebug                  bool
 	logComDebug               bool
 }
 
+type DependencyConfig struct {
+	deviceName    string
+	registerNames []string
+}
+
+type DeadbandConfig struct {
+	registers  map[string]DeadbandValue
+	categories map[string]DeadbandValue
+	maxSilence time.Duration
+}
+
+type RegisterTransformConfig struct {
+	scale     float64
+	offset    float64
+	unit      string
+	precision int
+	enum      map[int]string
+}
+
+type RegisterLimitsConfig struct {
+	min  *float64
+	max  *float64
+	step *float64
+}
+
+type DeadbandValue struct {
+	absolute float64
+	relative float64
+}
+
 type VictronDeviceConfig struct {
 	DeviceConfig
 	device       string
 	kind         types.VictronDeviceKind
 	pollInterval time.Duration
 	ioLog        string
+	replaySpeed  float64
 }
 
 type ModbusDeviceConfig struct {
 	DeviceConfig
-	bus          string
-	kind         types.ModbusDeviceKind
-	address      byte
-	relays       map[string]RelayConfig
-	pollInterval time.Duration
+	bus             string
+	kind            types.ModbusDeviceKind
+	address         byte
+	relays          map[string]RelayConfig
+	pollInterval    time.Duration
+	commandReadback CommandReadbackConfig
+}
+
+type CommandReadbackConfig struct {
+	enabled bool
+	retries int
+	backoff time.Duration
 }
 
 type RelayConfig struct {
@@ -160,11 +247,12 @@ type PinConfig struct {
 
 type HttpDeviceConfig struct {
 	DeviceConfig
-	url          *url.URL
-	kind         types.HttpDeviceKind
-	username     string
-	password     string
-	pollInterval time.Duration
+	url             *url.URL
+	kind            types.HttpDeviceKind
+	username        string
+	password        string
+	pollInterval    time.Duration
+	commandReadback CommandReadbackConfig
 }
 
 type MqttDeviceConfig struct {
@@ -172,6 +260,14 @@ type MqttDeviceConfig struct {
 	kind types.MqttDeviceKind
 }
 
+type ReplayDeviceConfig struct {
+	DeviceConfig
+	file       string
+	deviceName string
+	speed      float64
+	loop       bool
+}
+
 type GensetDeviceConfig struct {
 	DeviceConfig
 
@@ -217,6 +313,97 @@ type GensetDeviceBindingConfig struct {
 	registerName string
 }
 
+type ComputedDeviceConfig struct {
+	DeviceConfig
+	registers []ComputedRegisterConfig
+}
+
+type ComputedRegisterConfig struct {
+	name         string
+	expression   string
+	registerType types.ComputedRegisterType
+	category     string
+	description  string
+	unit         string
+	sort         int
+}
+
+type EnergyDeviceConfig struct {
+	DeviceConfig
+	inputs        []EnergyDeviceInputConfig
+	maxGap        time.Duration
+	file          string
+	writeInterval time.Duration
+}
+
+type EnergyDeviceInputConfig struct {
+	name         string
+	deviceName   string
+	registerName string
+}
+
+type AlarmDeviceConfig struct {
+	DeviceConfig
+	rules []AlarmRuleConfig
+}
+
+type AlarmRuleConfig struct {
+	name         string
+	deviceName   string
+	registerName string
+	condition    types.AlarmCondition
+	warning      *float64
+	alarm        *float64
+	hysteresis   float64
+	delay        time.Duration
+	category     string
+	description  string
+	sort         int
+}
+
+type AutomationDeviceConfig struct {
+	DeviceConfig
+	rules []AutomationRuleConfig
+}
+
+type AutomationRuleConfig struct {
+	name         string
+	on           string
+	off          string
+	deviceName   string
+	registerName string
+	onValue      string
+	offValue     string
+	minOnTime    time.Duration
+	minOffTime   time.Duration
+	enabled      bool
+	category     string
+	description  string
+	sort         int
+}
+
+type SchedulerDeviceConfig struct {
+	DeviceConfig
+	latitude  float64
+	longitude float64
+	schedules []ScheduleConfig
+}
+
+type ScheduleConfig struct {
+	name         string
+	cron         string
+	sun          types.SunEvent
+	offset       time.Duration
+	deviceName   string
+	registerName string
+	value        string
+	maxDelay     time.Duration
+	enabled      bool
+	category     string
+	description  string
+	sort         int
+}
+
 type ViewConfig struct {
 	name         string
 	title        string
@@ -238,4 +425,5 @@ type FilterConfig struct {
 	includeCategories []string
 	skipCategories    []string
 	defaultInclude    bool
+	logPrefix         string
 }
@@ -1,56 +1,91 @@
 package config
 
 type configRead struct {
-	Version                *int                               `yaml:"Version"`
-	ProjectTitle           string                             `yaml:"ProjectTitle"`
-	LogConfig              *bool                              `yaml:"LogConfig"`
-	LogWorkerStart         *bool                              `yaml:"LogWorkerStart"`
-	LogStateStorageDebug   *bool                              `yaml:"LogStateStorageDebug"`
-	LogCommandStorageDebug *bool                              `yaml:"LogCommandStorageDebug"`
-	HttpServer             *httpServerConfigRead              `yaml:"HttpServer"`
-	Authentication         *authenticationConfigRead          `yaml:"Authentication"`
-	MqttClients            map[string]mqttClientConfigRead    `yaml:"MqttClients"`
-	Modbus                 map[string]modbusConfigRead        `yaml:"Modbus"`
-	VictronDevices         map[string]victronDeviceConfigRead `yaml:"VictronDevices"`
-	ModbusDevices          map[string]modbusDeviceConfigRead  `yaml:"ModbusDevices"`
-	GpioDevices            map[string]gpioDeviceConfigRead    `yaml:"GpioDevices"`
-	HttpDevices            map[string]httpDeviceConfigRead    `yaml:"HttpDevices"`
-	MqttDevices            map[string]mqttDeviceConfigRead    `yaml:"MqttDevices"`
-	GensetDevices          map[string]gensetDeviceConfigRead  `yaml:"GensetDevices"`
-	Views                  []viewConfigRead                   `yaml:"Views"`
+	Version                *int                                  `yaml:"Version" schema:"required"`
+	ProjectTitle           string                                `yaml:"ProjectTitle" schema:"default=go-iotdevice"`
+	LogConfig              *bool                                 `yaml:"LogConfig" schema:"default=true"`
+	LogWorkerStart         *bool                                 `yaml:"LogWorkerStart" schema:"default=true"`
+	LogStateStorageDebug   *bool                                 `yaml:"LogStateStorageDebug" schema:"default=false"`
+	LogCommandStorageDebug *bool                                 `yaml:"LogCommandStorageDebug" schema:"default=false"`
+	HttpServer             *httpServerConfigRead                 `yaml:"HttpServer"`
+	Authentication         *authenticationConfigRead             `yaml:"Authentication"`
+	Persistence            *persistenceConfigRead                `yaml:"Persistence"`
+	History                *historyConfigRead                    `yaml:"History"`
+	Recorder               *recorderConfigRead                   `yaml:"Recorder"`
+	MqttClients            map[string]mqttClientConfigRead       `yaml:"MqttClients" schema:"name"`
+	Modbus                 map[string]modbusConfigRead           `yaml:"Modbus" schema:"name"`
+	VictronDevices         map[string]victronDeviceConfigRead    `yaml:"VictronDevices" schema:"name"`
+	ModbusDevices          map[string]modbusDeviceConfigRead     `yaml:"ModbusDevices" schema:"name"`
+	GpioDevices            map[string]gpioDeviceConfigRead       `yaml:"GpioDevices" schema:"name"`
+	HttpDevices            map[string]httpDeviceConfigRead       `yaml:"HttpDevices" schema:"name"`
+	MqttDevices            map[string]mqttDeviceConfigRead       `yaml:"MqttDevices" schema:"name"`
+	ReplayDevices          map[string]replayDeviceConfigRead     `yaml:"ReplayDevices" schema:"name"`
+	GensetDevices          map[string]gensetDeviceConfigRead     `yaml:"GensetDevices" schema:"name"`
+	ComputedDevices        map[string]computedDeviceConfigRead   `yaml:"ComputedDevices" schema:"name"`
+	EnergyDevices          map[string]energyDeviceConfigRead     `yaml:"EnergyDevices" schema:"name"`
+	AlarmDevices           map[string]alarmDeviceConfigRead      `yaml:"AlarmDevices" schema:"name"`
+	AutomationDevices      map[string]automationDeviceConfigRead `yaml:"AutomationDevices" schema:"name"`
+	SchedulerDevices       map[string]schedulerDeviceConfigRead  `yaml:"SchedulerDevices" schema:"name"`
+	Views                  []viewConfigRead                      `yaml:"Views"`
 }
 
 type httpServerConfigRead struct {
-	Bind            string `yaml:"Bind"`
-	Port            *int   `yaml:"Port"`
-	LogRequests     *bool  `yaml:"LogRequests"`
+	Bind            string `yaml:"Bind" schema:"required"`
+	Port            *int   `yaml:"Port" schema:"default=8000"`
+	LogRequests     *bool  `yaml:"LogRequests" schema:"default=true"`
 	FrontendProxy   string `yaml:"FrontendProxy"`
-	FrontendPath    string `yaml:"FrontendPath"`
-	FrontendExpires string `yaml:"FrontendExpires"`
-	ConfigExpires   string `yaml:"ConfigExpires"`
-	LogDebug        *bool  `yaml:"LogDebug"`
+	FrontendPath    string `yaml:"FrontendPath" schema:"default=./frontend-build/"`
+	FrontendExpires string `yaml:"FrontendExpires" schema:"duration,default=5m"`
+	ConfigExpires   string `yaml:"ConfigExpires" schema:"duration,default=1m"`
+	LogDebug        *bool  `yaml:"LogDebug" schema:"default=false"`
 }
 
 type authenticationConfigRead struct {
-	JwtSecret         *string `yaml:"JwtSecret"`
-	JwtValidityPeriod string  `yaml:"JwtValidityPeriod"`
-	HtaccessFile      *string `yaml:"HtaccessFile"`
+	JwtSecret         *string  `yaml:"JwtSecret"`
+	JwtSecretFile     *string  `yaml:"JwtSecretFile"`
+	JwtValidityPeriod string   `yaml:"JwtValidityPeriod" schema:"duration,default=1h"`
+	HtaccessFile      *string  `yaml:"HtaccessFile" schema:"required"`
+	AdminUsers        []string `yaml:"AdminUsers"`
+}
+
+type persistenceConfigRead struct {
+	StateFile     string `yaml:"StateFile"`
+	CommandFile   string `yaml:"CommandFile"`
+	WriteInterval string `yaml:"WriteInterval" schema:"duration,default=1m"`
+	LogDebug      *bool  `yaml:"LogDebug" schema:"default=false"`
+}
+
+type historyConfigRead struct {
+	RawRetention     string `yaml:"RawRetention" schema:"duration,default=1h"`
+	MinuteRetention  string `yaml:"MinuteRetention" schema:"duration,default=24h"`
+	QuarterRetention string `yaml:"QuarterRetention" schema:"duration,default=168h"`
+	File             string `yaml:"File"`
+	WriteInterval    string `yaml:"WriteInterval" schema:"duration,default=5m"`
+	LogDebug         *bool  `yaml:"LogDebug" schema:"default=false"`
+}
+
+type recorderConfigRead struct {
+	File          string   `yaml:"File" schema:"required"`
+	Devices       []string `yaml:"Devices"`
+	FlushInterval string   `yaml:"FlushInterval" schema:"duration,default=1s"`
+	LogDebug      *bool    `yaml:"LogDebug" schema:"default=false"`
 }
 
 type mqttClientConfigRead struct {
-	Broker          string `yaml:"Broker"`
-	ProtocolVersion *int   `yaml:"ProtocolVersion"`
+	Broker          string `yaml:"Broker" schema:"required"`
+	ProtocolVersion *int   `yaml:"ProtocolVersion" schema:"default=5"`
 
-	User     string  `yaml:"User"`
-	Password string  `yaml:"Password"`
-	ClientId *string `yaml:"ClientId"`
+	User         string  `yaml:"User"`
+	Password     string  `yaml:"Password"`
+	PasswordFile string  `yaml:"PasswordFile"`
+	ClientId     *string `yaml:"ClientId"`
 
-	KeepAlive         string  `yaml:"KeepAlive"`
-	ConnectRetryDelay string  `yaml:"ConnectRetryDelay"`
-	ConnectTimeout    string  `yaml:"ConnectTimeout"`
-	TopicPrefix       *string `yaml:"TopicPrefix"`
-	ReadOnly          *bool   `yaml:"ReadOnly"`
-	MaxBacklogSize    *int    `yaml:"MaxBacklogSize"`
+	KeepAlive         string  `yaml:"KeepAlive" schema:"duration,default=1m"`
+	ConnectRetryDelay string  `yaml:"ConnectRetryDelay" schema:"duration,default=10s"`
+	ConnectTimeout    string  `yaml:"ConnectTimeout" schema:"duration,default=5s"`
+	TopicPrefix       *string `yaml:"TopicPrefix" schema:"default=go-iotdevice/"`
+	ReadOnly          *bool   `yaml:"ReadOnly" schema:"default=false"`
+	MaxBacklogSize    *int    `yaml:"MaxBacklogSize" schema:"default=256"`
 
 	MqttDevices map[string]mqttClientDeviceConfigRead `yaml:"MqttDevices"`
 
@@ -61,21 +96,22 @@ type mqttClientConfigRead struct {
 	Realtime               mqttSectionConfigRead `yaml:"Realtime"`
 	HomeassistantDiscovery mqttSectionConfigRead `yaml:"HomeassistantDiscovery"`
 	Command                mqttSectionConfigRead `yaml:"Command"`
+	CommandResponse        mqttSectionConfigRead `yaml:"CommandResponse"`
 
-	LogDebug    *bool `yaml:"LogDebug"`
-	LogMessages *bool `yaml:"LogMessages"`
+	LogDebug    *bool `yaml:"LogDebug" schema:"default=false"`
+	LogMessages *bool `yaml:"LogMessages" schema:"default=false"`
 }
 
 type mqttClientDeviceConfigRead struct {
-	MqttTopics []string `yaml:"MqttTopics"`
+	MqttTopics []string `yaml:"MqttTopics" schema:"required"`
 }
 
 type mqttSectionConfigRead struct {
 	Enabled       *bool                                  `yaml:"Enabled"`
 	TopicTemplate *string                                `yaml:"TopicTemplate"`
-	Interval      string                                 `yaml:"Interval"`
+	Interval      string                                 `yaml:"Interval" schema:"duration"`
 	Retain        *bool                                  `yaml:"Retain"`
-	Qos           *byte                                  `yaml:"Qos"`
+	Qos           *byte                                  `yaml:"Qos" schema:"default=1"`
 	Devices       map[string]mqttDeviceSectionConfigRead `yaml:"Devices"`
 }
 
@@ -84,125 +120,260 @@ type mqttDeviceSectionConfigRead struct {
 }
 
 type modbusConfigRead struct {
-	Device      string `yaml:"Device"`
-	BaudRate    int    `yaml:"BaudRate"`
-	ReadTimeout string `yaml:"ReadTimeout"`
-	LogDebug    *bool  `yaml:"LogDebug"`
+	Device      string `yaml:"Device" schema:"required"`
+	BaudRate    int    `yaml:"BaudRate" schema:"required"`
+	ReadTimeout string `yaml:"ReadTimeout" schema:"duration,default=100ms"`
+	LogDebug    *bool  `yaml:"LogDebug" schema:"default=false"`
 }
 
 type deviceConfigRead struct {
-	Filter                    filterConfigRead `yaml:"Filter"`
-	RestartInterval           string           `yaml:"RestartInterval"`
-	RestartIntervalMaxBackoff string           `yaml:"RestartIntervalMaxBackoff"`
-	LogDebug                  *bool            `yaml:"LogDebug"`
-	LogComDebug               *bool            `yaml:"LogComDebug"`
+	Filter                    filterConfigRead                       `yaml:"Filter"`
+	RestartInterval           string                                 `yaml:"RestartInterval" schema:"duration,default=200ms"`
+	RestartIntervalMaxBackoff string                                 `yaml:"RestartIntervalMaxBackoff" schema:"duration,default=1m"`
+	MaxAge                    string                                 `yaml:"MaxAge" schema:"duration,default=0s"`
+	RegisterMaxAge            map[string]string                      `yaml:"RegisterMaxAge" schema:"duration"`
+	Deadband                  deadbandConfigRead                     `yaml:"Deadband"`
+	Transform                 map[string]registerTransformConfigRead `yaml:"Transform"`
+	Limits                    map[string]registerLimitsConfigRead    `yaml:"Limits"`
+	DependsOn                 map[string][]string                    `yaml:"DependsOn"`
+	DependencyTimeout         string                                 `yaml:"DependencyTimeout" schema:"duration,default=30s"`
+	LogDebug                  *bool                                  `yaml:"LogDebug" schema:"default=false"`
+	LogComDebug               *bool                                  `yaml:"LogComDebug" schema:"default=false"`
+}
+
+type registerTransformConfigRead struct {
+	Scale     *float64       `yaml:"Scale" schema:"default=1"`
+	Offset    *float64       `yaml:"Offset" schema:"default=0"`
+	Unit      string         `yaml:"Unit"`
+	Precision *int           `yaml:"Precision" schema:"default=-1"`
+	Enum      map[int]string `yaml:"Enum"`
+}
+
+type registerLimitsConfigRead struct {
+	Min  *float64 `yaml:"Min"`
+	Max  *float64 `yaml:"Max"`
+	Step *float64 `yaml:"Step"`
+}
+
+type deadbandConfigRead struct {
+	Registers  map[string]string `yaml:"Registers"`
+	Categories map[string]string `yaml:"Categories"`
+	MaxSilence string            `yaml:"MaxSilence" schema:"duration,default=1m"`
 }
 
 type victronDeviceConfigRead struct {
 	deviceConfigRead `yaml:",inline"`
-	Device           string  `yaml:"Device"`
-	Kind             string  `yaml:"Kind"`
-	PollInterval     string  `yaml:"PollInterval"`
-	IoLog            *string `yaml:"IoLog"`
+	Device           string   `yaml:"Device"`
+	Kind             string   `yaml:"Kind" schema:"required,enum=victronDeviceKind"`
+	PollInterval     string   `yaml:"PollInterval" schema:"duration,default=500ms"`
+	IoLog            *string  `yaml:"IoLog"`
+	ReplaySpeed      *float64 `yaml:"ReplaySpeed" schema:"default=1"`
 }
 
 type modbusDeviceConfigRead struct {
 	deviceConfigRead `yaml:",inline"`
-	Bus              string                     `yaml:"Bus"`
-	Kind             string                     `yaml:"Kind"`
-	Address          string                     `yaml:"Address"`
-	Relays           map[string]relayConfigRead `yaml:"Relays"`
-	PollInterval     string                     `yaml:"PollInterval"`
+	Bus              string                     `yaml:"Bus" schema:"required"`
+	Kind             string                     `yaml:"Kind" schema:"required,enum=modbusDeviceKind"`
+	Address          string                     `yaml:"Address" schema:"required,scalar"`
+	Relays           map[string]relayConfigRead `yaml:"Relays" schema:"name"`
+	PollInterval     string                     `yaml:"PollInterval" schema:"duration,default=1s"`
+	CommandReadback  *commandReadbackConfigRead `yaml:"CommandReadback"`
+}
+
+type commandReadbackConfigRead struct {
+	Retries *int   `yaml:"Retries" schema:"default=3"`
+	Backoff string `yaml:"Backoff" schema:"duration,default=1s"`
 }
 
 type relayConfigRead struct {
 	Description *string `yaml:"Description"`
-	OpenLabel   *string `yaml:"OpenLabel"`
-	ClosedLabel *string `yaml:"ClosedLabel"`
+	OpenLabel   *string `yaml:"OpenLabel" schema:"default=open"`
+	ClosedLabel *string `yaml:"ClosedLabel" schema:"default=closed"`
 }
 
 type gpioDeviceConfigRead struct {
 	deviceConfigRead `yaml:",inline"`
-	Chip             *string                  `yaml:"Chip"`
-	InputDebounce    string                   `yaml:"InputDebounce"`
-	InputOptions     []string                 `yaml:"InputOptions"`
-	OutputOptions    []string                 `yaml:"OutputOptions"`
-	Inputs           map[string]pinConfigRead `yaml:"Inputs"`
-	Outputs          map[string]pinConfigRead `yaml:"Outputs"`
+	Chip             *string                  `yaml:"Chip" schema:"default=gpiochip0"`
+	InputDebounce    string                   `yaml:"InputDebounce" schema:"duration,default=100ms"`
+	InputOptions     []string                 `yaml:"InputOptions" schema:"enum=gpioInputOption"`
+	OutputOptions    []string                 `yaml:"OutputOptions" schema:"enum=gpioOutputOption"`
+	Inputs           map[string]pinConfigRead `yaml:"Inputs" schema:"name"`
+	Outputs          map[string]pinConfigRead `yaml:"Outputs" schema:"name"`
 }
 
 type pinConfigRead struct {
-	Pin         string  `yaml:"Pin"`
+	Pin         string  `yaml:"Pin" schema:"required,scalar"`
 	Description *string `yaml:"Description"`
-	LowLabel    *string `yaml:"LowLabel"`
-	HighLabel   *string `yaml:"HighLabel"`
+	LowLabel    *string `yaml:"LowLabel" schema:"default=low"`
+	HighLabel   *string `yaml:"HighLabel" schema:"default=high"`
 }
 
 type httpDeviceConfigRead struct {
 	deviceConfigRead `yaml:",inline"`
-	Url              string `yaml:"Url"`
-	Kind             string `yaml:"Kind"`
-	Username         string `yaml:"Username"`
-	Password         string `yaml:"Password"`
-	PollInterval     string `yaml:"PollInterval"`
+	Url              string                     `yaml:"Url" schema:"required"`
+	Kind             string                     `yaml:"Kind" schema:"required,enum=httpDeviceKind"`
+	Username         string                     `yaml:"Username"`
+	Password         string                     `yaml:"Password"`
+	PasswordFile     string                     `yaml:"PasswordFile"`
+	PollInterval     string                     `yaml:"PollInterval" schema:"duration,default=1s"`
+	CommandReadback  *commandReadbackConfigRead `yaml:"CommandReadback"`
 }
 
 type mqttDeviceConfigRead struct {
 	deviceConfigRead `yaml:",inline"`
-	Kind             string `yaml:"Kind"`
+	Kind             string `yaml:"Kind" schema:"required,enum=mqttDeviceKind"`
+}
+
+type replayDeviceConfigRead struct {
+	deviceConfigRead `yaml:",inline"`
+	File             string   `yaml:"File" schema:"required"`
+	Device           string   `yaml:"Device"`
+	Speed            *float64 `yaml:"Speed" schema:"default=1"`
+	Loop             *bool    `yaml:"Loop" schema:"default=true"`
 }
 
 type gensetDeviceConfigRead struct {
 	deviceConfigRead `yaml:",inline"`
 
-	InputBindings  gensetDeviceBindingConfigRead `yaml:"InputBindings"`
-	OutputBindings gensetDeviceBindingConfigRead `yaml:"OutputBindings"`
-
-	PrimingTimeout           string   `yaml:"PrimingTimeout"`
-	CrankingTimeout          string   `yaml:"CrankingTimeout"`
-	StabilizingTimeout       string   `yaml:"StabilizingTimeout"`
-	WarmUpTimeout            string   `yaml:"WarmUpTimeout"`
-	WarmUpMinTime            string   `yaml:"WarmUpMinTime"`
-	WarmUpTemp               *float64 `yaml:"WarmUpTemp"`
-	EngineCoolDownTimeout    string   `yaml:"EngineCoolDownTimeout"`
-	EngineCoolDownMinTime    string   `yaml:"EngineCoolDownMinTime"`
-	EngineCoolDownTemp       *float64 `yaml:"EngineCoolDownTemp"`
-	EnclosureCoolDownTimeout string   `yaml:"EnclosureCoolDownTimeout"`
-	EnclosureCoolDownMinTime string   `yaml:"EnclosureCoolDownMinTime"`
-	EnclosureCoolDownTemp    *float64 `yaml:"EnclosureCoolDownTemp"`
-
-	EngineTempMin *float64 `yaml:"EngineTempMin"`
-	EngineTempMax *float64 `yaml:"EngineTempMax"`
-	AuxTemp0Min   *float64 `yaml:"AuxTemp0Min"`
-	AuxTemp0Max   *float64 `yaml:"AuxTemp0Max"`
-	AuxTemp1Min   *float64 `yaml:"AuxTemp1Min"`
-	AuxTemp1Max   *float64 `yaml:"AuxTemp1Max"`
-
-	SinglePhase *bool    `yaml:"SinglePhase"`
-	UMin        *float64 `yaml:"UMin"`
-	UMax        *float64 `yaml:"UMax"`
-	UAvgWindow  *int     `yaml:"UAvgWindow"`
-	FMin        *float64 `yaml:"FMin"`
-	FMax        *float64 `yaml:"FMax"`
-	FAvgWindow  *int     `yaml:"FAvgWindow"`
-	PMax        *float64 `yaml:"PMax"`
-	PTotMax     *float64 `yaml:"PTotMax"`
+	InputBindings  gensetDeviceBindingConfigRead `yaml:"InputBindings" schema:"required"`
+	OutputBindings gensetDeviceBindingConfigRead `yaml:"OutputBindings" schema:"required"`
+
+	PrimingTimeout           string   `yaml:"PrimingTimeout" schema:"duration,default=10s"`
+	CrankingTimeout          string   `yaml:"CrankingTimeout" schema:"duration,default=10s"`
+	StabilizingTimeout       string   `yaml:"StabilizingTimeout" schema:"duration,default=3s"`
+	WarmUpTimeout            string   `yaml:"WarmUpTimeout" schema:"duration,default=10m"`
+	WarmUpMinTime            string   `yaml:"WarmUpMinTime" schema:"duration,default=2m"`
+	WarmUpTemp               *float64 `yaml:"WarmUpTemp" schema:"default=50"`
+	EngineCoolDownTimeout    string   `yaml:"EngineCoolDownTimeout" schema:"duration,default=5m"`
+	EngineCoolDownMinTime    string   `yaml:"EngineCoolDownMinTime" schema:"duration,default=2m"`
+	EngineCoolDownTemp       *float64 `yaml:"EngineCoolDownTemp" schema:"default=70"`
+	EnclosureCoolDownTimeout string   `yaml:"EnclosureCoolDownTimeout" schema:"duration,default=10m"`
+	EnclosureCoolDownMinTime string   `yaml:"EnclosureCoolDownMinTime" schema:"duration,default=2m"`
+	EnclosureCoolDownTemp    *float64 `yaml:"EnclosureCoolDownTemp" schema:"default=30"`
+
+	EngineTempMin *float64 `yaml:"EngineTempMin" schema:"default=-20"`
+	EngineTempMax *float64 `yaml:"EngineTempMax" schema:"default=90"`
+	AuxTemp0Min   *float64 `yaml:"AuxTemp0Min" schema:"default=-20"`
+	AuxTemp0Max   *float64 `yaml:"AuxTemp0Max" schema:"default=120"`
+	AuxTemp1Min   *float64 `yaml:"AuxTemp1Min" schema:"default=-20"`
+	AuxTemp1Max   *float64 `yaml:"AuxTemp1Max" schema:"default=120"`
+
+	SinglePhase *bool    `yaml:"SinglePhase" schema:"default=false"`
+	UMin        *float64 `yaml:"UMin" schema:"default=220"`
+	UMax        *float64 `yaml:"UMax" schema:"default=240"`
+	UAvgWindow  *int     `yaml:"UAvgWindow" schema:"default=3"`
+	FMin        *float64 `yaml:"FMin" schema:"default=45"`
+	FMax        *float64 `yaml:"FMax" schema:"default=55"`
+	FAvgWindow  *int     `yaml:"FAvgWindow" schema:"default=3"`
+	PMax        *float64 `yaml:"PMax" schema:"default=1e6"`
+	PTotMax     *float64 `yaml:"PTotMax" schema:"default=1e6"`
 }
 
 type gensetDeviceBindingConfigRead map[string]map[string]string
 
+type computedDeviceConfigRead struct {
+	deviceConfigRead `yaml:",inline"`
+
+	Registers map[string]computedRegisterConfigRead `yaml:"Registers" schema:"required"`
+}
+
+type computedRegisterConfigRead struct {
+	Expression  string `yaml:"Expression" schema:"required"`
+	Type        string `yaml:"Type" schema:"enum=computedRegisterType,default=Number"`
+	Category    string `yaml:"Category" schema:"default=Computed"`
+	Description string `yaml:"Description"`
+	Unit        string `yaml:"Unit"`
+	Sort        *int   `yaml:"Sort" schema:"default=0"`
+}
+
+type energyDeviceConfigRead struct {
+	deviceConfigRead `yaml:",inline"`
+
+	Inputs        map[string]energyDeviceInputConfigRead `yaml:"Inputs" schema:"required"`
+	MaxGap        string                                 `yaml:"MaxGap" schema:"duration,default=5m"`
+	File          string                                 `yaml:"File"`
+	WriteInterval string                                 `yaml:"WriteInterval" schema:"duration,default=1m"`
+}
+
+type energyDeviceInputConfigRead struct {
+	Device   string `yaml:"Device" schema:"required"`
+	Register string `yaml:"Register" schema:"required"`
+}
+
+type alarmDeviceConfigRead struct {
+	deviceConfigRead `yaml:",inline"`
+
+	Rules map[string]alarmRuleConfigRead `yaml:"Rules" schema:"required"`
+}
+
+type alarmRuleConfigRead struct {
+	Device      string   `yaml:"Device" schema:"required"`
+	Register    string   `yaml:"Register" schema:"required"`
+	Condition   string   `yaml:"Condition" schema:"required,enum=alarmCondition"`
+	Warning     *float64 `yaml:"Warning"`
+	Alarm       *float64 `yaml:"Alarm"`
+	Hysteresis  *float64 `yaml:"Hysteresis" schema:"default=0"`
+	Delay       string   `yaml:"Delay" schema:"duration,default=0s"`
+	Category    string   `yaml:"Category" schema:"default=Alarms"`
+	Description string   `yaml:"Description"`
+	Sort        *int     `yaml:"Sort" schema:"default=0"`
+}
+
+type automationDeviceConfigRead struct {
+	deviceConfigRead `yaml:",inline"`
+
+	Rules map[string]automationRuleConfigRead `yaml:"Rules" schema:"required"`
+}
+
+type automationRuleConfigRead struct {
+	On          string `yaml:"On" schema:"required"`
+	Off         string `yaml:"Off" schema:"required"`
+	Device      string `yaml:"Device" schema:"required"`
+	Register    string `yaml:"Register" schema:"required"`
+	OnValue     string `yaml:"OnValue" schema:"required,scalar"`
+	OffValue    string `yaml:"OffValue" schema:"required,scalar"`
+	MinOnTime   string `yaml:"MinOnTime" schema:"duration,default=0s"`
+	MinOffTime  string `yaml:"MinOffTime" schema:"duration,default=0s"`
+	Enabled     *bool  `yaml:"Enabled" schema:"default=true"`
+	Category    string `yaml:"Category" schema:"default=Automations"`
+	Description string `yaml:"Description"`
+	Sort        *int   `yaml:"Sort" schema:"default=0"`
+}
+
+type schedulerDeviceConfigRead struct {
+	deviceConfigRead `yaml:",inline"`
+
+	Latitude  *float64                      `yaml:"Latitude"`
+	Longitude *float64                      `yaml:"Longitude"`
+	Schedules map[string]scheduleConfigRead `yaml:"Schedules" schema:"required"`
+}
+
+type scheduleConfigRead struct {
+	Cron        string `yaml:"Cron"`
+	Sun         string `yaml:"Sun" schema:"enum=sunEvent"`
+	Offset      string `yaml:"Offset" schema:"duration,default=0s"`
+	Device      string `yaml:"Device" schema:"required"`
+	Register    string `yaml:"Register" schema:"required"`
+	Value       string `yaml:"Value" schema:"required,scalar"`
+	MaxDelay    string `yaml:"MaxDelay" schema:"duration,default=5m"`
+	Enabled     *bool  `yaml:"Enabled" schema:"default=true"`
+	Category    string `yaml:"Category" schema:"default=Schedules"`
+	Description string `yaml:"Description"`
+	Sort        *int   `yaml:"Sort" schema:"default=0"`
+}
+
 type viewConfigRead struct {
-	Name         string                 `yaml:"Name"`
-	Title        string                 `yaml:"Title"`
-	Devices      []viewDeviceConfigRead `yaml:"Devices"`
-	Autoplay     *bool                  `yaml:"Autoplay"`
+	Name         string                 `yaml:"Name" schema:"required,name"`
+	Title        string                 `yaml:"Title" schema:"required"`
+	Devices      []viewDeviceConfigRead `yaml:"Devices" schema:"required"`
+	Autoplay     *bool                  `yaml:"Autoplay" schema:"default=true"`
 	AllowedUsers []string               `yaml:"AllowedUsers"`
-	Hidden       *bool                  `yaml:"Hidden"`
+	Hidden       *bool                  `yaml:"Hidden" schema:"default=false"`
 }
 
 type viewDeviceConfigRead struct {
-	Name   string           `yaml:"Name"`
-	Title  string           `yaml:"Title"`
+	Name   string           `yaml:"Name" schema:"required"`
+	Title  string           `yaml:"Title" schema:"required"`
 	Filter filterConfigRead `yaml:"Filter"`
 }
 
@@ -211,5 +382,5 @@ type filterConfigRead struct {
 	SkipRegisters     []string `yaml:"SkipRegisters"`
 	IncludeCategories []string `yaml:"IncludeCategories"`
 	SkipCategories    []string `yaml:"SkipCategories"`
-	DefaultInclude    *bool    `yaml:"DefaultInclude"`
+	DefaultInclude    *bool    `yaml:"DefaultInclude" schema:"default=true"`
 }
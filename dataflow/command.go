@@ -0,0 +1,219 @@
+package dataflow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koestler/go-list"
+)
+
+// CommandState is the stage a command has reached in its lifecycle.
+type CommandState string
+
+const (
+	// CommandPending means the command is stored but not yet picked up by the device, e.g. because it is unavailable.
+	CommandPending CommandState = "pending"
+	// CommandSent means the device is writing the command or has forwarded it without any confirmation.
+	CommandSent CommandState = "sent"
+	// CommandConfirmed means the device has successfully written the command.
+	CommandConfirmed CommandState = "confirmed"
+	// CommandFailed means the command was not written; the reason is given by the error of the status.
+	CommandFailed CommandState = "failed"
+)
+
+// commandRetention defines how long the status of a command is kept after its last update.
+const commandRetention = time.Hour
+
+// CommandStatus describes the current state of a single command.
+type CommandStatus struct {
+	Id           string
+	DeviceName   string
+	RegisterName string
+	Value        interface{}
+	State        CommandState
+	Error        string
+	Created      time.Time
+	Updated      time.Time
+}
+
+type CommandSubscription struct {
+	ctx    context.Context
+	queue  *overflowQueue[string, CommandStatus]
+	filter func(CommandStatus) bool
+}
+
+// CommandTracker assigns an id to every command sent to the command storage and keeps track of its state.
+// Devices report the progress of a command using CommandSent / CommandDone of the command storage.
+type CommandTracker struct {
+	storage *ValueStorage
+
+	commands      map[string]*CommandStatus // key: command id
+	latest        map[StateKey]string       // key: register, value: id of the last command sent to it
+	subscriptions *list.List[CommandSubscription]
+	overflow      overflowCounters
+	mutex         sync.Mutex
+}
+
+// NewCommandTracker creates a tracker for the given command storage and registers it there.
+func NewCommandTracker(commandStorage *ValueStorage) *CommandTracker {
+	t := &CommandTracker{
+		storage:       commandStorage,
+		commands:      make(map[string]*CommandStatus),
+		latest:        make(map[StateKey]string),
+		subscriptions: list.New[CommandSubscription](),
+	}
+
+	commandStorage.mutex.Lock()
+	commandStorage.commands = t
+	commandStorage.mutex.Unlock()
+
+	return t
+}
+
+// Fill sends the command to the command storage and returns its initial status.
+// The id of the value is used when set, otherwise a new id is generated.
+func (t *CommandTracker) Fill(v Value) CommandStatus {
+	id := v.CommandId()
+	if len(id) < 1 {
+		id = newCommandId()
+		v = WithCommandId(v, id)
+	}
+
+	now := time.Now()
+	status := CommandStatus{
+		Id:           id,
+		DeviceName:   v.DeviceName(),
+		RegisterName: v.Register().Name(),
+		Value:        v.GenericValue(),
+		State:        CommandPending,
+		Created:      now,
+		Updated:      now,
+	}
+
+	t.mutex.Lock()
+	t.pruneUnlocked(now)
+
+	// a command not yet picked up by the device is replaced by the new one in the command storage
+	k := valueStateKey(v)
+	if prev, ok := t.commands[t.latest[k]]; ok && prev.State == CommandPending {
+		t.updateUnlocked(prev, CommandFailed, fmt.Errorf("superseded by command %s", id), now)
+	}
+
+	t.latest[k] = id
+	t.commands[id] = &status
+	t.forwardUnlocked(status)
+	t.mutex.Unlock()
+
+	t.storage.Fill(v)
+	return status
+}
+
+// Get returns the status of the command with the given id.
+func (t *CommandTracker) Get(id string) (status CommandStatus, ok bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	s, ok := t.commands[id]
+	if !ok {
+		return
+	}
+	return *s, true
+}
+
+// Subscribe sends every subsequent status change matching the filter to the returned channel.
+// When the subscriber is too slow, only the latest status of each command is kept.
+func (t *CommandTracker) Subscribe(ctx context.Context, filter func(CommandStatus) bool) <-chan CommandStatus {
+	s := CommandSubscription{
+		ctx:    ctx,
+		queue:  newOverflowQueue[string, CommandStatus](ctx, OverflowCoalesce, 16, nil, &t.overflow),
+		filter: filter,
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	elem := t.subscriptions.PushBack(s)
+	go s.queue.run(nil, func() {
+		t.mutex.Lock()
+		t.subscriptions.Remove(elem)
+		t.mutex.Unlock()
+	})
+
+	return s.queue.output
+}
+
+func (t *CommandTracker) update(id string, state CommandState, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if s, ok := t.commands[id]; ok {
+		t.updateUnlocked(s, state, err, time.Now())
+	}
+}
+
+func (t *CommandTracker) updateUnlocked(s *CommandStatus, state CommandState, err error, now time.Time) {
+	s.State = state
+	s.Error = ""
+	if err != nil {
+		s.Error = err.Error()
+	}
+	s.Updated = now
+	t.forwardUnlocked(*s)
+}
+
+// forwardUnlocked sends the status to all subscriptions; this never blocks.
+func (t *CommandTracker) forwardUnlocked(s CommandStatus) {
+	for e := t.subscriptions.Front(); e != nil; e = e.Next() {
+		sub := e.Value
+		if sub.filter(s) {
+			sub.queue.push(s.Id, s)
+		}
+	}
+}
+
+func (t *CommandTracker) pruneUnlocked(now time.Time) {
+	for id, s := range t.commands {
+		if now.Sub(s.Updated) > commandRetention {
+			delete(t.commands, id)
+			k := StateKey{deviceName: s.DeviceName, registerName: s.RegisterName}
+			if t.latest[k] == id {
+				delete(t.latest, k)
+			}
+		}
+	}
+}
+
+func newCommandId() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// CommandSent is called by devices once they start writing the given command.
+func (vs *ValueStorage) CommandSent(v Value) {
+	vs.reportCommand(v, CommandSent, nil)
+}
+
+// CommandDone is called by devices once the given command was written; err is nil on success.
+func (vs *ValueStorage) CommandDone(v Value, err error) {
+	if err != nil {
+		vs.reportCommand(v, CommandFailed, err)
+	} else {
+		vs.reportCommand(v, CommandConfirmed, nil)
+	}
+}
+
+func (vs *ValueStorage) reportCommand(v Value, state CommandState, err error) {
+	vs.mutex.RLock()
+	t := vs.commands
+	vs.mutex.RUnlock()
+
+	if t == nil || len(v.CommandId()) < 1 {
+		return
+	}
+	t.update(v.CommandId(), state, err)
+}
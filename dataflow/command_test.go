@@ -0,0 +1,93 @@
+package dataflow_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func TestCommandTracker(t *testing.T) {
+	storage := dataflow.NewValueStorage()
+	defer storage.Shutdown()
+	tracker := dataflow.NewCommandTracker(storage)
+
+	relay := dataflow.NewRegisterStruct("cat", "Relay", "", dataflow.EnumRegister, map[int]string{0: "off", 1: "on"}, "", 0, true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := tracker.Subscribe(ctx, func(s dataflow.CommandStatus) bool { return s.DeviceName == "dev" })
+
+	expectUpdate := func(id string, state dataflow.CommandState) {
+		t.Helper()
+		select {
+		case s := <-updates:
+			if s.Id != id || s.State != state {
+				t.Errorf("expect command %s to be %s but got %s: %s", id, state, s.Id, s.State)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("expect an update of command %s to %s", id, state)
+		}
+	}
+
+	expectState := func(id string, state dataflow.CommandState, errMsg string) {
+		t.Helper()
+		s, ok := tracker.Get(id)
+		if !ok {
+			t.Fatalf("expect command %s to be known", id)
+		}
+		if s.State != state || s.Error != errMsg {
+			t.Errorf("expect command %s to be %s with error '%s' but got %s with error '%s'", id, state, errMsg, s.State, s.Error)
+		}
+	}
+
+	first := tracker.Fill(dataflow.NewEnumRegisterValue("dev", relay, 1))
+	if len(first.Id) < 1 {
+		t.Fatal("expect the command to get an id")
+	}
+	expectUpdate(first.Id, dataflow.CommandPending)
+
+	// a command not yet picked up is superseded by a newer one
+	second := tracker.Fill(dataflow.NewEnumRegisterValue("dev", relay, 0))
+	expectUpdate(first.Id, dataflow.CommandFailed)
+	expectUpdate(second.Id, dataflow.CommandPending)
+	expectState(first.Id, dataflow.CommandFailed, "superseded by command "+second.Id)
+
+	storage.Wait()
+	state := storage.GetState()
+	if len(state) != 1 || state[0].CommandId() != second.Id {
+		t.Fatalf("expect the command storage to contain command %s, got %v", second.Id, state)
+	}
+
+	// the device reports the progress using the value it received
+	storage.CommandSent(state[0])
+	expectUpdate(second.Id, dataflow.CommandSent)
+	storage.CommandDone(state[0], nil)
+	expectUpdate(second.Id, dataflow.CommandConfirmed)
+	expectState(second.Id, dataflow.CommandConfirmed, "")
+
+	// the same value sent again is a new command
+	third := tracker.Fill(dataflow.WithCommandId(dataflow.NewEnumRegisterValue("dev", relay, 0), "my-id"))
+	if expect, got := "my-id", third.Id; expect != got {
+		t.Errorf("expect the given id %s to be used but got %s", expect, got)
+	}
+	expectUpdate(third.Id, dataflow.CommandPending)
+	storage.Wait()
+	state = storage.GetState()
+	if len(state) != 1 || state[0].CommandId() != third.Id {
+		t.Fatalf("expect the repeated command to be stored, got %v", state)
+	}
+
+	storage.CommandDone(state[0], errors.New("write failed"))
+	expectUpdate(third.Id, dataflow.CommandFailed)
+	expectState(third.Id, dataflow.CommandFailed, "write failed")
+
+	// values without an id are ignored
+	storage.CommandDone(dataflow.NewEnumRegisterValue("dev", relay, 1), nil)
+
+	if _, ok := tracker.Get("unknown"); ok {
+		t.Error("did not expect an unknown command to be found")
+	}
+}
@@ -0,0 +1,173 @@
+package dataflow
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Deadband defines when the change of a numeric value is too small to be published.
+type Deadband struct {
+	// Absolute is the minimal absolute change of a value.
+	Absolute float64
+	// Relative is the minimal change relative to the last published value; e.g. 0.01 for 1%.
+	Relative float64
+	// MaxSilence publishes a value held back by the deadband once the last publish is older; zero disables it.
+	MaxSilence time.Duration
+}
+
+// DeadbandFunc returns the deadband of the given value; ok is false if no deadband applies.
+type DeadbandFunc func(v Value) (deadband Deadband, ok bool)
+
+// Inside returns true when the change from the last published value to the new value must not be published.
+func (d Deadband) Inside(last, new NumericRegisterValue) bool {
+	diff := math.Abs(new.Value() - last.Value())
+	if d.Absolute > 0 && diff < d.Absolute {
+		return true
+	}
+	if d.Relative > 0 && diff < d.Relative*math.Abs(last.Value()) {
+		return true
+	}
+	return false
+}
+
+// SetDeadband configures what numeric changes are too small to be published.
+// The storage itself and all other subscribers still receive every change;
+// only subscriptions created by SubscribeSendInitialWithDeadband apply it.
+func (vs *ValueStorage) SetDeadband(deadband DeadbandFunc) {
+	vs.deadbandMutex.Lock()
+	defer vs.deadbandMutex.Unlock()
+	vs.deadband = deadband
+}
+
+func (vs *ValueStorage) deadbandOf(v Value) (deadband Deadband, ok bool) {
+	vs.deadbandMutex.RLock()
+	defer vs.deadbandMutex.RUnlock()
+	if vs.deadband == nil {
+		return
+	}
+	return vs.deadband(v)
+}
+
+// SubscribeSendInitialWithDeadband works like SubscribeSendInitialWithPolicy but holds back numeric changes
+// inside the deadband configured by SetDeadband. A value held back is sent once MaxSilence has passed since
+// the last value of its register was sent.
+func (vs *ValueStorage) SubscribeSendInitialWithDeadband(
+	ctx context.Context, filter ValueFilterFunc, policy OverflowPolicy,
+) (subscription ValueSubscription) {
+	subscription = vs.SubscribeSendInitialWithPolicy(ctx, filter, policy)
+	output := make(chan Value)
+	go vs.runDeadbandFilter(subscription.ctx, subscription.queue.output, output)
+	subscription.output = output
+	return
+}
+
+func (vs *ValueStorage) runDeadbandFilter(ctx context.Context, input <-chan Value, output chan<- Value) {
+	defer close(output)
+
+	f := newDeadbandFilter(vs.deadbandOf)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	send := func(v Value) {
+		// values arriving after the cancellation are discarded until the input is closed
+		select {
+		case output <- v:
+		case <-ctx.Done():
+		}
+	}
+
+	for {
+		select {
+		case v, ok := <-input:
+			if !ok {
+				return
+			}
+			if f.pass(v, time.Now()) {
+				send(v)
+			}
+		case now := <-timer.C:
+			for _, v := range f.due(now) {
+				send(v)
+			}
+		}
+
+		timer.Stop()
+		if next, ok := f.nextDue(); ok {
+			timer.Reset(time.Until(next))
+		}
+	}
+}
+
+type deadbandState struct {
+	published  NumericRegisterValue
+	at         time.Time
+	pending    *NumericRegisterValue
+	maxSilence time.Duration
+}
+
+// deadbandFilter remembers the last published value per register and the latest value held back.
+type deadbandFilter struct {
+	deadbandOf DeadbandFunc
+	states     map[StateKey]*deadbandState
+}
+
+func newDeadbandFilter(deadbandOf DeadbandFunc) *deadbandFilter {
+	return &deadbandFilter{
+		deadbandOf: deadbandOf,
+		states:     make(map[StateKey]*deadbandState),
+	}
+}
+
+// pass returns true when the given value must be published now.
+func (f *deadbandFilter) pass(v Value, now time.Time) bool {
+	k := valueStateKey(v)
+
+	numeric, ok := v.(NumericRegisterValue)
+	if !ok || numeric.Restored() {
+		// null, restored and non-numeric values are always published and restart the deadband
+		delete(f.states, k)
+		return true
+	}
+
+	deadband, ok := f.deadbandOf(v)
+	if !ok {
+		delete(f.states, k)
+		return true
+	}
+
+	if s, ok := f.states[k]; ok && deadband.Inside(s.published, numeric) {
+		s.pending = &numeric
+		s.maxSilence = deadband.MaxSilence
+		return false
+	}
+
+	f.states[k] = &deadbandState{published: numeric, at: now}
+	return true
+}
+
+// nextDue returns when the next value held back must be published.
+func (f *deadbandFilter) nextDue() (next time.Time, ok bool) {
+	for _, s := range f.states {
+		if s.pending == nil || s.maxSilence <= 0 {
+			continue
+		}
+		if due := s.at.Add(s.maxSilence); !ok || due.Before(next) {
+			next, ok = due, true
+		}
+	}
+	return
+}
+
+// due returns the values held back for MaxSilence or longer and marks them as published.
+func (f *deadbandFilter) due(now time.Time) (values []Value) {
+	for _, s := range f.states {
+		if s.pending == nil || s.maxSilence <= 0 || now.Sub(s.at) < s.maxSilence {
+			continue
+		}
+		values = append(values, *s.pending)
+		s.published, s.at, s.pending = *s.pending, now, nil
+	}
+	return
+}
@@ -0,0 +1,100 @@
+package dataflow_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func TestValueStorageDeadband(t *testing.T) {
+	storage := dataflow.NewValueStorage()
+	defer storage.Shutdown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	absReg := getSimpleTestRegister("cat", "absolute")
+	relReg := getSimpleTestRegister("cat", "relative")
+
+	storage.SetDeadband(func(v dataflow.Value) (dataflow.Deadband, bool) {
+		switch v.Register().Name() {
+		case "absolute":
+			return dataflow.Deadband{Absolute: 0.5, MaxSilence: 200 * time.Millisecond}, true
+		case "relative":
+			return dataflow.Deadband{Relative: 0.1}, true
+		}
+		return dataflow.Deadband{}, false
+	})
+
+	published := storage.SubscribeSendInitialWithDeadband(ctx, dataflow.AllValueFilter, dataflow.OverflowBlock)
+	internal := storage.SubscribeSendInitial(ctx, dataflow.AllValueFilter)
+
+	fill := func(reg dataflow.Register, value float64) {
+		storage.Fill(dataflow.NewNumericRegisterValue("device-0", reg, value))
+	}
+	expectValues := func(subscription dataflow.ValueSubscription, expect ...float64) {
+		t.Helper()
+		for _, e := range expect {
+			select {
+			case v := <-subscription.Drain():
+				if got := v.GenericValue(); e != got {
+					t.Errorf("expect value %v but got %v", e, got)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expect value %v but got nothing", e)
+			}
+		}
+		select {
+		case v := <-subscription.Drain():
+			t.Errorf("did not expect another value but got %s", v)
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+
+	fill(absReg, 10)   // first value: published
+	fill(absReg, 10.2) // inside: held back
+	fill(absReg, 10.4) // inside compared to the published 10
+	fill(absReg, 10.6) // outside: published
+	fill(relReg, 100)  // first value: published
+	fill(relReg, 109)  // inside, no max silence
+	fill(relReg, 111)  // outside: published
+	fill(absReg, 10.8) // inside compared to the published 10.6: held back until max silence has passed
+	storage.Wait()
+
+	// internal subscribers see every change
+	expectValues(internal, 10, 10.2, 10.4, 10.6, 100, 109, 111, 10.8)
+	expectValues(published, 10, 10.6, 100, 111)
+
+	// the value held back is published once max silence has passed without any new value
+	expectValues(published, 10.8)
+
+	// the state always contains the latest value
+	for _, v := range storage.GetState() {
+		switch v.Register().Name() {
+		case "absolute":
+			if expect, got := 10.8, v.GenericValue(); expect != got {
+				t.Errorf("expect state of absolute to be %v but got %v", expect, got)
+			}
+		case "relative":
+			if expect, got := 111.0, v.GenericValue(); expect != got {
+				t.Errorf("expect state of relative to be %v but got %v", expect, got)
+			}
+		}
+	}
+
+	// a null value is always published and restarts the deadband
+	storage.Fill(dataflow.NewNullRegisterValue("device-0", relReg))
+	fill(relReg, 112)
+	storage.Wait()
+	select {
+	case v := <-published.Drain():
+		if _, ok := v.(dataflow.NullRegisterValue); !ok {
+			t.Errorf("expect a null value but got %s", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expect a null value but got nothing")
+	}
+	expectValues(published, 112)
+}
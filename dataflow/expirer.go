@@ -0,0 +1,49 @@
+package dataflow
+
+import (
+	"context"
+	"time"
+)
+
+// MaxAgeFunc returns how long the given value stays valid; zero means the value never expires.
+type MaxAgeFunc func(v Value) time.Duration
+
+// ExpireValues removes all values older than their max age from the state.
+// Subscribers receive a NullRegisterValue for every removed value.
+func (vs *ValueStorage) ExpireValues(now time.Time, maxAge MaxAgeFunc) (expired []Value) {
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+
+	for k, v := range vs.state {
+		ma := maxAge(v)
+		if ma <= 0 || now.Sub(v.Time()) <= ma {
+			continue
+		}
+		delete(vs.state, k)
+		vs.forwardToSubscriptions(WithTime(NewNullRegisterValue(v.DeviceName(), v.Register()), now))
+		expired = append(expired, v)
+	}
+
+	return
+}
+
+// RunValueExpirer periodically calls ExpireValues until the context is cancelled.
+func RunValueExpirer(ctx context.Context, storage *ValueStorage, interval time.Duration, maxAge MaxAgeFunc, onExpire func(v Value)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				for _, v := range storage.ExpireValues(now, maxAge) {
+					if onExpire != nil {
+						onExpire(v)
+					}
+				}
+			}
+		}
+	}()
+}
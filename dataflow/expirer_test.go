@@ -0,0 +1,59 @@
+package dataflow_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func TestValueStorageExpireValues(t *testing.T) {
+	storage := dataflow.NewValueStorage()
+	defer storage.Shutdown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t0 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	regA := getSimpleTestRegister("cat", "register-a")
+	regB := getSimpleTestRegister("cat", "register-b")
+	regC := getSimpleTestRegister("cat", "register-c")
+
+	storage.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue("device-0", regA, 1), t0))
+	storage.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue("device-0", regB, 2), t0.Add(50*time.Second)))
+	storage.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue("device-0", regC, 3), t0))
+	storage.Wait()
+
+	subscription := storage.SubscribeSendInitial(ctx, dataflow.AllValueFilter)
+	for range 3 {
+		<-subscription.Drain() // initial values
+	}
+
+	maxAge := func(v dataflow.Value) time.Duration {
+		if v.Register().Name() == "register-c" {
+			return 0 // never expire
+		}
+		return time.Minute
+	}
+
+	expired := storage.ExpireValues(t0.Add(61*time.Second), maxAge)
+	if expect, got := 1, len(expired); expect != got {
+		t.Fatalf("expect %d expired values but got %d", expect, got)
+	}
+	if expect, got := "register-a", expired[0].Register().Name(); expect != got {
+		t.Errorf("expect %s to be expired but got %s", expect, got)
+	}
+
+	if expect, got := 2, len(storage.GetState()); expect != got {
+		t.Errorf("expect %d remaining values but got %d", expect, got)
+	}
+
+	v := <-subscription.Drain()
+	if _, ok := v.(dataflow.NullRegisterValue); !ok {
+		t.Errorf("expect subscribers to receive a null value but got %s", v)
+	}
+	if expect, got := "register-a", v.Register().Name(); expect != got {
+		t.Errorf("expect null value for %s but got %s", expect, got)
+	}
+}
@@ -0,0 +1,419 @@
+package dataflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HistoryResolution defines whether raw values or aggregates are kept / queried.
+type HistoryResolution int
+
+const (
+	HistoryRaw HistoryResolution = iota
+	HistoryMinute
+	HistoryQuarter
+)
+
+func (r HistoryResolution) String() string {
+	switch r {
+	case HistoryRaw:
+		return "raw"
+	case HistoryMinute:
+		return "1m"
+	case HistoryQuarter:
+		return "15m"
+	default:
+		return ""
+	}
+}
+
+// Interval returns the length of an aggregation bucket; zero for raw values.
+func (r HistoryResolution) Interval() time.Duration {
+	switch r {
+	case HistoryMinute:
+		return time.Minute
+	case HistoryQuarter:
+		return 15 * time.Minute
+	default:
+		return 0
+	}
+}
+
+func HistoryResolutionFromString(s string) (r HistoryResolution, ok bool) {
+	for _, r := range []HistoryResolution{HistoryRaw, HistoryMinute, HistoryQuarter} {
+		if r.String() == s {
+			return r, true
+		}
+	}
+	return HistoryRaw, false
+}
+
+type HistoryConfig struct {
+	// RawRetention, MinuteRetention and QuarterRetention define how long each resolution is kept; zero disables it.
+	RawRetention     time.Duration
+	MinuteRetention  time.Duration
+	QuarterRetention time.Duration
+	// Filter defines what values are recorded; only numeric values are recorded in any case.
+	Filter ValueFilterFunc
+	// File is the path of the json snapshot file; empty means in-memory only.
+	File string
+	// WriteInterval defines how often the history is written to disk.
+	WriteInterval time.Duration
+	LogDebug      bool
+}
+
+// HistoryPoint is a single value (raw resolution) or the aggregate of all values within the bucket beginning at Time.
+type HistoryPoint struct {
+	Time  time.Time `json:"Time"`
+	Min   float64   `json:"Min"`
+	Max   float64   `json:"Max"`
+	Avg   float64   `json:"Avg"`
+	Count int       `json:"Count"`
+}
+
+type HistorySeries struct {
+	DeviceName string
+	Register   Register
+	Points     []HistoryPoint
+}
+
+// History keeps a short-term history of all numeric values of a ValueStorage in memory.
+type History struct {
+	cfg     HistoryConfig
+	storage *ValueStorage
+
+	series map[StateKey]*historySeries
+	mutex  sync.RWMutex
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	done      chan struct{}
+}
+
+type historySeries struct {
+	value   Value // the latest value, used for filtering and to get the register
+	raw     []HistoryPoint
+	minute  historyBuckets
+	quarter historyBuckets
+}
+
+// historyBuckets are the closed aggregates and the currently open one.
+type historyBuckets struct {
+	closed []HistoryPoint
+	open   *HistoryPoint
+}
+
+func NewHistory(cfg HistoryConfig, storage *ValueStorage) *History {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &History{
+		cfg:       cfg,
+		storage:   storage,
+		series:    make(map[StateKey]*historySeries),
+		ctx:       ctx,
+		ctxCancel: cancel,
+		done:      make(chan struct{}),
+	}
+}
+
+// Run starts a routine recording all numeric values of the storage.
+func (h *History) Run() {
+	// the history must not stall the storage; a coalesced value only loses intermediate samples
+	subscription := h.storage.SubscribeSendInitialWithPolicy(h.ctx, h.cfg.Filter, OverflowCoalesce)
+
+	go func() {
+		defer close(h.done)
+
+		pruneTicker := time.NewTicker(time.Minute)
+		defer pruneTicker.Stop()
+
+		var writeTick <-chan time.Time
+		if len(h.cfg.File) > 0 {
+			writeTicker := time.NewTicker(h.cfg.WriteInterval)
+			defer writeTicker.Stop()
+			writeTick = writeTicker.C
+		}
+
+		dirty := false
+		for {
+			select {
+			case v, ok := <-subscription.Drain():
+				if !ok {
+					// subscription is closed when the context is cancelled; write a final snapshot
+					if dirty && len(h.cfg.File) > 0 {
+						h.write()
+					}
+					return
+				}
+				if h.Add(v) {
+					dirty = true
+				}
+			case <-pruneTicker.C:
+				h.prune(time.Now())
+			case <-writeTick:
+				if dirty {
+					h.write()
+					dirty = false
+				}
+			}
+		}
+	}()
+}
+
+// Shutdown stops the history and waits until the final snapshot is written.
+func (h *History) Shutdown() {
+	h.ctxCancel()
+	<-h.done
+}
+
+// Add records the given value; only fresh numeric values are recorded.
+func (h *History) Add(v Value) (added bool) {
+	numeric, ok := v.(NumericRegisterValue)
+	if !ok || numeric.Restored() {
+		return false
+	}
+
+	t := numeric.Time()
+	if t.IsZero() {
+		t = time.Now()
+	}
+	value := numeric.Value()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	k := valueStateKey(v)
+	s, ok := h.series[k]
+	if !ok {
+		s = &historySeries{}
+		h.series[k] = s
+	}
+	s.value = v
+
+	// points must be ordered by time; ignore values older than the latest raw value
+	if h.cfg.RawRetention > 0 && (len(s.raw) == 0 || !t.Before(s.raw[len(s.raw)-1].Time)) {
+		s.raw = append(s.raw, HistoryPoint{Time: t, Min: value, Max: value, Avg: value, Count: 1})
+		s.raw = historyTrim(s.raw, t.Add(-h.cfg.RawRetention))
+	}
+	if h.cfg.MinuteRetention > 0 {
+		s.minute.add(t, value, HistoryMinute.Interval(), h.cfg.MinuteRetention)
+	}
+	if h.cfg.QuarterRetention > 0 {
+		s.quarter.add(t, value, HistoryQuarter.Interval(), h.cfg.QuarterRetention)
+	}
+
+	return true
+}
+
+func (b *historyBuckets) add(t time.Time, value float64, interval, retention time.Duration) {
+	start := t.Truncate(interval)
+
+	if b.open != nil {
+		if start.Before(b.open.Time) {
+			// ignore values older than the current bucket
+			return
+		}
+		if start.After(b.open.Time) {
+			b.closed = append(b.closed, *b.open)
+			b.open = nil
+		}
+	}
+
+	if b.open == nil {
+		b.open = &HistoryPoint{Time: start, Min: value, Max: value, Avg: value, Count: 1}
+	} else {
+		b.open.Min = min(b.open.Min, value)
+		b.open.Max = max(b.open.Max, value)
+		b.open.Count += 1
+		b.open.Avg += (value - b.open.Avg) / float64(b.open.Count)
+	}
+
+	b.closed = historyTrim(b.closed, t.Add(-retention))
+}
+
+func (b *historyBuckets) points() []HistoryPoint {
+	if b.open == nil {
+		return b.closed
+	}
+	return append(slices.Clip(b.closed), *b.open)
+}
+
+// historyTrim removes all points before the given time.
+func historyTrim(points []HistoryPoint, before time.Time) []HistoryPoint {
+	i, _ := slices.BinarySearchFunc(points, before, func(p HistoryPoint, t time.Time) int {
+		return p.Time.Compare(t)
+	})
+	// the underlying array is reallocated by append once its capacity is exhausted; hence it does not grow forever
+	return points[i:]
+}
+
+// prune removes points older than the retention of registers which are not updated anymore.
+func (h *History) prune(now time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.pruneUnlocked(now)
+}
+
+func (h *History) pruneUnlocked(now time.Time) {
+	for k, s := range h.series {
+		s.raw = historyTrim(s.raw, now.Add(-h.cfg.RawRetention))
+		s.minute.closed = historyTrim(s.minute.closed, now.Add(-h.cfg.MinuteRetention))
+		s.quarter.closed = historyTrim(s.quarter.closed, now.Add(-h.cfg.QuarterRetention))
+
+		if s.minute.open != nil && s.minute.open.Time.Before(now.Add(-h.cfg.MinuteRetention)) {
+			s.minute.open = nil
+		}
+		if s.quarter.open != nil && s.quarter.open.Time.Before(now.Add(-h.cfg.QuarterRetention)) {
+			s.quarter.open = nil
+		}
+
+		if len(s.raw) == 0 && len(s.minute.points()) == 0 && len(s.quarter.points()) == 0 {
+			delete(h.series, k)
+		}
+	}
+}
+
+// Query returns the points within [from, to] of all series matching the filter.
+// A zero from / to means no lower / upper limit.
+func (h *History) Query(filter ValueFilterFunc, resolution HistoryResolution, from, to time.Time) (result []HistorySeries) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	result = make([]HistorySeries, 0)
+	for _, s := range h.series {
+		if !filter(s.value) {
+			continue
+		}
+
+		var points []HistoryPoint
+		switch resolution {
+		case HistoryRaw:
+			points = s.raw
+		case HistoryMinute:
+			points = s.minute.points()
+		case HistoryQuarter:
+			points = s.quarter.points()
+		}
+
+		selected := make([]HistoryPoint, 0, len(points))
+		for _, p := range points {
+			if !from.IsZero() && p.Time.Before(from) {
+				continue
+			}
+			if !to.IsZero() && p.Time.After(to) {
+				continue
+			}
+			selected = append(selected, p)
+		}
+
+		result = append(result, HistorySeries{
+			DeviceName: s.value.DeviceName(),
+			Register:   s.value.Register(),
+			Points:     selected,
+		})
+	}
+
+	return
+}
+
+type persistedHistorySeries struct {
+	Device   string            `json:"Device"`
+	Register persistedRegister `json:"Register"`
+	Raw      []HistoryPoint    `json:"Raw,omitempty"`
+	Minute   []HistoryPoint    `json:"Minute,omitempty"`
+	Quarter  []HistoryPoint    `json:"Quarter,omitempty"`
+}
+
+// Load reads the snapshot file. A missing file is not considered an error. Load must be called before Run.
+func (h *History) Load() error {
+	payload, err := os.ReadFile(h.cfg.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot read file: %w", err)
+	}
+
+	var persisted []persistedHistorySeries
+	if err := json.Unmarshal(payload, &persisted); err != nil {
+		return fmt.Errorf("cannot parse file: %w", err)
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, ps := range persisted {
+		// the value is only used to filter and to get the register; the history only contains numeric registers
+		v, err := persistedValue{Device: ps.Device, Register: ps.Register, NumVal: new(float64)}.toValue()
+		if err != nil {
+			log.Printf("history[%s]: skip series of device=%s: %s", h.cfg.File, ps.Device, err)
+			continue
+		}
+		if !h.cfg.Filter(v) {
+			continue
+		}
+
+		s := &historySeries{
+			value:   v,
+			raw:     ps.Raw,
+			minute:  newHistoryBuckets(ps.Minute),
+			quarter: newHistoryBuckets(ps.Quarter),
+		}
+		h.series[valueStateKey(v)] = s
+	}
+	h.pruneUnlocked(time.Now())
+
+	if h.cfg.LogDebug {
+		log.Printf("history[%s]: loaded %d series", h.cfg.File, len(h.series))
+	}
+
+	return nil
+}
+
+// newHistoryBuckets reopens the last bucket such that values of the same interval are added to it.
+func newHistoryBuckets(points []HistoryPoint) historyBuckets {
+	if len(points) == 0 {
+		return historyBuckets{}
+	}
+	last := points[len(points)-1]
+	return historyBuckets{
+		closed: points[:len(points)-1],
+		open:   &last,
+	}
+}
+
+func (h *History) write() {
+	h.mutex.RLock()
+	persisted := make([]persistedHistorySeries, 0, len(h.series))
+	for _, s := range h.series {
+		pv := newPersistedValue(s.value)
+		persisted = append(persisted, persistedHistorySeries{
+			Device:   pv.Device,
+			Register: pv.Register,
+			Raw:      s.raw,
+			Minute:   s.minute.points(),
+			Quarter:  s.quarter.points(),
+		})
+	}
+	payload, err := json.Marshal(persisted)
+	h.mutex.RUnlock()
+
+	if err != nil {
+		log.Printf("history[%s]: cannot generate snapshot: %s", h.cfg.File, err)
+		return
+	}
+
+	if err := WriteFileAtomic(h.cfg.File, payload); err != nil {
+		log.Printf("history[%s]: cannot write file: %s", h.cfg.File, err)
+		return
+	}
+
+	if h.cfg.LogDebug {
+		log.Printf("history[%s]: wrote %d series", h.cfg.File, len(persisted))
+	}
+}
@@ -0,0 +1,127 @@
+package dataflow_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func TestHistoryAggregates(t *testing.T) {
+	t0 := time.Now().Truncate(time.Hour)
+	h := dataflow.NewHistory(dataflow.HistoryConfig{
+		RawRetention:     5 * time.Minute,
+		MinuteRetention:  time.Hour,
+		QuarterRetention: 24 * time.Hour,
+		Filter:           dataflow.AllValueFilter,
+	}, nil)
+
+	reg := getSimpleTestRegister("cat", "num")
+	textReg := dataflow.NewRegisterStruct("cat", "text", "Text", dataflow.TextRegister, nil, "", 20, false)
+
+	// 20 minutes of values every 30s: 0, 1, 2, ...
+	for i := 0; i < 40; i++ {
+		v := dataflow.NewNumericRegisterValue("dev", reg, float64(i))
+		h.Add(dataflow.WithTime(v, t0.Add(time.Duration(i)*30*time.Second)))
+	}
+	if h.Add(dataflow.NewTextRegisterValue("dev", textReg, "foo")) {
+		t.Error("expect text values not to be recorded")
+	}
+
+	query := func(resolution dataflow.HistoryResolution) []dataflow.HistoryPoint {
+		series := h.Query(dataflow.AllValueFilter, resolution, time.Time{}, time.Time{})
+		if expect, got := 1, len(series); expect != got {
+			t.Fatalf("expect %d series but got %d", expect, got)
+		}
+		if expect, got := "num", series[0].Register.Name(); expect != got {
+			t.Errorf("expect register %s but got %s", expect, got)
+		}
+		return series[0].Points
+	}
+
+	t.Run("raw", func(t *testing.T) {
+		points := query(dataflow.HistoryRaw)
+		// the last value is at 19.5 min; values before 14.5 min are removed
+		if expect, got := 11, len(points); expect != got {
+			t.Fatalf("expect %d raw points but got %d", expect, got)
+		}
+		if expect, got := 29.0, points[0].Avg; expect != got {
+			t.Errorf("expect first raw value to be %v but got %v", expect, got)
+		}
+	})
+
+	t.Run("minute", func(t *testing.T) {
+		points := query(dataflow.HistoryMinute)
+		if expect, got := 20, len(points); expect != got {
+			t.Fatalf("expect %d minute points but got %d", expect, got)
+		}
+		p := points[3]
+		if !p.Time.Equal(t0.Add(3*time.Minute)) || p.Min != 6 || p.Max != 7 || p.Avg != 6.5 || p.Count != 2 {
+			t.Errorf("unexpected minute aggregate %+v", p)
+		}
+	})
+
+	t.Run("quarter", func(t *testing.T) {
+		points := query(dataflow.HistoryQuarter)
+		if expect, got := 2, len(points); expect != got {
+			t.Fatalf("expect %d quarter points but got %d", expect, got)
+		}
+		if p := points[0]; p.Min != 0 || p.Max != 29 || p.Avg != 14.5 || p.Count != 30 {
+			t.Errorf("unexpected closed quarter aggregate %+v", p)
+		}
+		if p := points[1]; p.Min != 30 || p.Max != 39 || p.Avg != 34.5 || p.Count != 10 {
+			t.Errorf("unexpected open quarter aggregate %+v", p)
+		}
+	})
+
+	t.Run("range", func(t *testing.T) {
+		series := h.Query(dataflow.AllValueFilter, dataflow.HistoryMinute, t0.Add(5*time.Minute), t0.Add(9*time.Minute))
+		if expect, got := 5, len(series[0].Points); expect != got {
+			t.Errorf("expect %d points but got %d", expect, got)
+		}
+	})
+}
+
+func TestHistoryPersistence(t *testing.T) {
+	cfg := dataflow.HistoryConfig{
+		RawRetention:     time.Hour,
+		MinuteRetention:  time.Hour,
+		QuarterRetention: time.Hour,
+		Filter:           dataflow.AllValueFilter,
+		File:             filepath.Join(t.TempDir(), "history.json"),
+		WriteInterval:    time.Hour,
+	}
+	reg := getSimpleTestRegister("cat", "num")
+	t0 := time.Now().Add(-time.Minute)
+
+	{
+		storage := dataflow.NewValueStorage()
+		h := dataflow.NewHistory(cfg, storage)
+		if err := h.Load(); err != nil {
+			t.Fatalf("expect no error on missing file, got %s", err)
+		}
+		h.Run()
+		storage.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue("dev", reg, 1), t0))
+		storage.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue("dev", reg, 2), t0.Add(time.Second)))
+		storage.Wait()
+		h.Shutdown()
+		storage.Shutdown()
+	}
+
+	h := dataflow.NewHistory(cfg, nil)
+	if err := h.Load(); err != nil {
+		t.Fatalf("did not expect an error, got: %s", err)
+	}
+
+	series := h.Query(dataflow.AllValueFilter, dataflow.HistoryRaw, time.Time{}, time.Time{})
+	if expect, got := 1, len(series); expect != got {
+		t.Fatalf("expect %d series but got %d", expect, got)
+	}
+	if expect, got := 2, len(series[0].Points); expect != got {
+		t.Errorf("expect %d raw points but got %d", expect, got)
+	}
+	if expect, got := "dev", series[0].DeviceName; expect != got {
+		t.Errorf("expect device %s but got %s", expect, got)
+	}
+}
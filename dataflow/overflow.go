@@ -0,0 +1,242 @@
+package dataflow
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy defines what happens when a subscriber does not consume its output channel fast enough.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until the subscriber has space or its subscription is canceled.
+	// Only use it for subscribers that are guaranteed to be fast.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowCoalesce keeps only the latest pending entry per key and sends it once the subscriber catches up.
+	OverflowCoalesce
+	// OverflowDropOldest removes the oldest entry of the output channel to make space for the new one.
+	OverflowDropOldest
+	// OverflowDisconnect cancels the subscription; its output channel is closed.
+	OverflowDisconnect
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowCoalesce:
+		return "coalesce"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowDisconnect:
+		return "disconnect"
+	default:
+		return ""
+	}
+}
+
+// OverflowStats counts how often subscribers did not keep up.
+type OverflowStats struct {
+	Subscriptions int    `json:"subscriptions"`
+	Dropped       uint64 `json:"dropped"`
+	Disconnected  uint64 `json:"disconnected"`
+}
+
+type overflowCounters struct {
+	dropped      atomic.Uint64
+	disconnected atomic.Uint64
+}
+
+// overflowQueue connects a producer that must never block to the output channel of a subscriber.
+type overflowQueue[K comparable, V any] struct {
+	ctx      context.Context
+	policy   OverflowPolicy
+	output   chan V
+	cancel   context.CancelFunc
+	counters *overflowCounters
+	dropped  atomic.Uint64
+
+	// used by OverflowCoalesce only
+	mutex    sync.Mutex
+	pending  map[K]V
+	order    []K
+	inFlight bool
+	notify   chan struct{}
+	unsent   []V
+}
+
+func newOverflowQueue[K comparable, V any](
+	ctx context.Context, policy OverflowPolicy, capacity int, cancel context.CancelFunc, counters *overflowCounters,
+) *overflowQueue[K, V] {
+	return &overflowQueue[K, V]{
+		ctx:      ctx,
+		policy:   policy,
+		output:   make(chan V, capacity),
+		cancel:   cancel,
+		counters: counters,
+		pending:  make(map[K]V),
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// push forwards the given entry to the subscriber; it only blocks when using OverflowBlock.
+// It must not be called anymore once the cleanup function of run was executed.
+func (q *overflowQueue[K, V]) push(k K, v V) {
+	switch q.policy {
+	case OverflowBlock:
+		select {
+		case q.output <- v:
+		case <-q.ctx.Done():
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.output <- v:
+				return
+			default:
+			}
+			select {
+			case <-q.output:
+				q.drop()
+			default:
+			}
+		}
+	case OverflowDisconnect:
+		select {
+		case q.output <- v:
+		default:
+			q.drop()
+			if q.cancel != nil {
+				q.cancel()
+				q.counters.disconnected.Add(1)
+				q.cancel = nil
+			}
+		}
+	default:
+		q.mutex.Lock()
+		defer q.mutex.Unlock()
+
+		// only send directly when nothing older is waiting; otherwise the order per key could change
+		if len(q.pending) == 0 && !q.inFlight {
+			select {
+			case q.output <- v:
+				return
+			default:
+			}
+		}
+
+		if _, ok := q.pending[k]; ok {
+			q.drop()
+		} else {
+			q.order = append(q.order, k)
+		}
+		q.pending[k] = v
+
+		select {
+		case q.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (q *overflowQueue[K, V]) drop() {
+	q.dropped.Add(1)
+	q.counters.dropped.Add(1)
+}
+
+// run sends the initial entries and all pending entries until the context is canceled.
+// Afterward, cleanup is called, what still fits into the output channel is sent and the output channel is closed.
+func (q *overflowQueue[K, V]) run(initial []V, cleanup func()) {
+	ctx := q.ctx
+
+	defer func() {
+		cleanup()
+		q.flushRemaining()
+		close(q.output)
+	}()
+
+	for _, v := range initial {
+		select {
+		case q.output <- v:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.notify:
+			if !q.flush(ctx) {
+				return
+			}
+		}
+	}
+}
+
+func (q *overflowQueue[K, V]) flush(ctx context.Context) bool {
+	for {
+		v, ok := q.pop()
+		if !ok {
+			return true
+		}
+
+		select {
+		case q.output <- v:
+		case <-ctx.Done():
+			q.unsent = append(q.unsent, v)
+			return false
+		}
+
+		q.mutex.Lock()
+		q.inFlight = false
+		q.mutex.Unlock()
+	}
+}
+
+func (q *overflowQueue[K, V]) pop() (v V, ok bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.order) == 0 {
+		return
+	}
+	k := q.order[0]
+	v = q.pending[k]
+	q.order = q.order[1:]
+	delete(q.pending, k)
+	q.inFlight = true
+	return v, true
+}
+
+// overflowFlushTimeout limits how long entries still pending on cancellation are offered to the subscriber.
+const overflowFlushTimeout = time.Second
+
+// flushRemaining sends the entries that were not sent before the cancellation.
+// It gives up after overflowFlushTimeout since the subscriber might not consume the channel anymore.
+func (q *overflowQueue[K, V]) flushRemaining() {
+	for {
+		v, ok := q.pop()
+		if !ok {
+			break
+		}
+		q.unsent = append(q.unsent, v)
+	}
+
+	if len(q.unsent) == 0 {
+		return
+	}
+
+	timeout := time.NewTimer(overflowFlushTimeout)
+	defer timeout.Stop()
+	for _, v := range q.unsent {
+		select {
+		case q.output <- v:
+		case <-timeout.C:
+			return
+		}
+	}
+}
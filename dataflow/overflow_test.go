@@ -0,0 +1,127 @@
+package dataflow_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func TestValueStorageOverflowPolicies(t *testing.T) {
+	const numbRegisters = 4
+	const numbValues = 1000
+
+	fill := func(storage *dataflow.ValueStorage) {
+		for i := 0; i < numbValues; i++ {
+			reg := getSimpleTestRegister("cat", fmt.Sprintf("register-%d", i%numbRegisters))
+			storage.Fill(dataflow.NewNumericRegisterValue("device-0", reg, float64(i)))
+		}
+	}
+
+	// fill must not block although the subscriber does not consume anything until the storage is done
+	waitFill := func(t *testing.T, storage *dataflow.ValueStorage) {
+		done := make(chan struct{})
+		go func() {
+			fill(storage)
+			storage.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("fill was blocked by a slow subscriber")
+		}
+	}
+
+	t.Run("coalesce", func(t *testing.T) {
+		storage := dataflow.NewValueStorage()
+		defer storage.Shutdown()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		subscription := storage.SubscribeSendInitialWithPolicy(ctx, dataflow.AllValueFilter, dataflow.OverflowCoalesce)
+		waitFill(t, storage)
+
+		// the latest value of every register must be received eventually
+		expect := make(map[string]float64)
+		for i := 0; i < numbRegisters; i++ {
+			expect[fmt.Sprintf("register-%d", i)] = float64(numbValues - numbRegisters + i)
+		}
+		latest := make(map[string]float64)
+		timeout := time.After(5 * time.Second)
+		for !maps.Equal(expect, latest) {
+			select {
+			case v := <-subscription.Drain():
+				latest[v.Register().Name()] = v.GenericValue().(float64)
+			case <-timeout:
+				t.Fatalf("expect to receive the latest values %v but got %v", expect, latest)
+			}
+		}
+
+		if subscription.Dropped() == 0 {
+			t.Error("expect some values to be dropped")
+		}
+		if expect, got := subscription.Dropped(), storage.OverflowStats().Dropped; expect != got {
+			t.Errorf("expect storage to count %d dropped values but got %d", expect, got)
+		}
+	})
+
+	t.Run("dropOldest", func(t *testing.T) {
+		storage := dataflow.NewValueStorage()
+		defer storage.Shutdown()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		subscription := storage.SubscribeSendInitialWithPolicy(ctx, dataflow.AllValueFilter, dataflow.OverflowDropOldest)
+		waitFill(t, storage)
+
+		received := uint64(0)
+		var last dataflow.Value
+	loop:
+		for {
+			select {
+			case v := <-subscription.Drain():
+				received += 1
+				last = v
+			default:
+				break loop
+			}
+		}
+
+		if expect, got := uint64(numbValues), received+subscription.Dropped(); expect != got {
+			t.Errorf("expect received + dropped to be %d but got %d", expect, got)
+		}
+		if expect, got := float64(numbValues-1), last.GenericValue(); expect != got {
+			t.Errorf("expect the last value to be %v but got %v", expect, got)
+		}
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		storage := dataflow.NewValueStorage()
+		defer storage.Shutdown()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		subscription := storage.SubscribeSendInitialWithPolicy(ctx, dataflow.AllValueFilter, dataflow.OverflowDisconnect)
+		waitFill(t, storage)
+
+		// the channel must be closed after the buffered values
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case _, ok := <-subscription.Drain():
+				if !ok {
+					if expect, got := uint64(1), storage.OverflowStats().Disconnected; expect != got {
+						t.Errorf("expect %d disconnected subscriptions but got %d", expect, got)
+					}
+					return
+				}
+			case <-timeout:
+				t.Fatal("expect the subscription to be closed")
+			}
+		}
+	})
+}
@@ -0,0 +1,268 @@
+package dataflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+type PersisterConfig struct {
+	// File is the path of the json snapshot file.
+	File string
+	// Interval defines how often changes are written to disk.
+	Interval time.Duration
+	// Filter defines what values are persisted and restored.
+	Filter ValueFilterFunc
+	// KeepOnNull keeps the last value of a register when it is reset to null.
+	// This is used for the command storage where devices reset a command after its execution.
+	KeepOnNull bool
+	LogDebug   bool
+}
+
+// Persister keeps an on-disk snapshot of a ValueStorage such that its content survives a restart.
+type Persister struct {
+	cfg     PersisterConfig
+	storage *ValueStorage
+
+	values map[StateKey]Value
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	done      chan struct{}
+}
+
+type persistedValue struct {
+	Device   string            `json:"Device"`
+	Register persistedRegister `json:"Register"`
+	NumVal   *float64          `json:"NumVal,omitempty"`
+	TextVal  *string           `json:"TextVal,omitempty"`
+	EnumIdx  *int              `json:"EnumIdx,omitempty"`
+	Time     time.Time         `json:"Time"`
+}
+
+type persistedRegister struct {
+	Category    string         `json:"Cat"`
+	Name        string         `json:"Name"`
+	Description string         `json:"Desc"`
+	Type        string         `json:"Type"`
+	Enum        map[int]string `json:"Enum,omitempty"`
+	Unit        string         `json:"Unit,omitempty"`
+	Sort        int            `json:"Sort"`
+	Writable    bool           `json:"Cmnd"`
+}
+
+func NewPersister(cfg PersisterConfig, storage *ValueStorage) *Persister {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Persister{
+		cfg:       cfg,
+		storage:   storage,
+		values:    make(map[StateKey]Value),
+		ctx:       ctx,
+		ctxCancel: cancel,
+		done:      make(chan struct{}),
+	}
+}
+
+// Load reads the snapshot file and returns its values marked as restored.
+// A missing file is not considered an error. Load must be called before Run.
+func (p *Persister) Load() (values []Value, err error) {
+	payload, err := os.ReadFile(p.cfg.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read file: %w", err)
+	}
+
+	var persisted []persistedValue
+	if err := json.Unmarshal(payload, &persisted); err != nil {
+		return nil, fmt.Errorf("cannot parse file: %w", err)
+	}
+
+	values = make([]Value, 0, len(persisted))
+	for _, pv := range persisted {
+		v, err := pv.toValue()
+		if err != nil {
+			log.Printf("persister[%s]: skip value of device=%s: %s", p.cfg.File, pv.Device, err)
+			continue
+		}
+		if !p.cfg.Filter(v) {
+			continue
+		}
+		v = markRestored(WithTime(v, pv.Time))
+		p.values[valueStateKey(v)] = v
+		values = append(values, v)
+	}
+
+	if p.cfg.LogDebug {
+		log.Printf("persister[%s]: loaded %d values", p.cfg.File, len(values))
+	}
+
+	return values, nil
+}
+
+// Restore loads the snapshot file and fills its values into the storage.
+func (p *Persister) Restore() (values []Value, err error) {
+	values, err = p.Load()
+	for _, v := range values {
+		p.storage.Fill(v)
+	}
+	p.storage.Wait()
+	return
+}
+
+// Run starts a routine tracking all changes of the storage and writing them to disk periodically.
+func (p *Persister) Run() {
+	// only the latest value per register is persisted; intermediate values can be skipped when writing is slow
+	subscription := p.storage.SubscribeSendInitialWithPolicy(p.ctx, p.cfg.Filter, OverflowCoalesce)
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.cfg.Interval)
+		defer ticker.Stop()
+
+		dirty := false
+		for {
+			select {
+			case v, ok := <-subscription.Drain():
+				if !ok {
+					// subscription is closed when the context is cancelled; write a final snapshot
+					if dirty {
+						p.write()
+					}
+					return
+				}
+				if p.update(v) {
+					dirty = true
+				}
+			case <-ticker.C:
+				if dirty {
+					p.write()
+					dirty = false
+				}
+			}
+		}
+	}()
+}
+
+// Shutdown stops the persister and waits until the final snapshot is written.
+func (p *Persister) Shutdown() {
+	p.ctxCancel()
+	<-p.done
+}
+
+func (p *Persister) update(v Value) (updated bool) {
+	k := valueStateKey(v)
+	if _, ok := v.(NullRegisterValue); ok {
+		if p.cfg.KeepOnNull {
+			return false
+		}
+		if _, ok := p.values[k]; !ok {
+			return false
+		}
+		delete(p.values, k)
+		return true
+	}
+
+	if current, ok := p.values[k]; ok && current.Equals(v) {
+		return false
+	}
+	p.values[k] = v
+	return true
+}
+
+func (p *Persister) write() {
+	persisted := make([]persistedValue, 0, len(p.values))
+	for _, v := range p.values {
+		persisted = append(persisted, newPersistedValue(v))
+	}
+
+	payload, err := json.Marshal(persisted)
+	if err != nil {
+		log.Printf("persister[%s]: cannot generate snapshot: %s", p.cfg.File, err)
+		return
+	}
+
+	if err := WriteFileAtomic(p.cfg.File, payload); err != nil {
+		log.Printf("persister[%s]: cannot write file: %s", p.cfg.File, err)
+		return
+	}
+
+	if p.cfg.LogDebug {
+		log.Printf("persister[%s]: wrote %d values", p.cfg.File, len(persisted))
+	}
+}
+
+func valueStateKey(v Value) StateKey {
+	return StateKey{
+		deviceName:   v.DeviceName(),
+		registerName: v.Register().Name(),
+	}
+}
+
+func newPersistedValue(v Value) (pv persistedValue) {
+	reg := v.Register()
+	pv = persistedValue{
+		Device: v.DeviceName(),
+		Time:   v.Time(),
+		Register: persistedRegister{
+			Category:    reg.Category(),
+			Name:        reg.Name(),
+			Description: reg.Description(),
+			Type:        reg.RegisterType().String(),
+			Enum:        reg.Enum(),
+			Unit:        reg.Unit(),
+			Sort:        reg.Sort(),
+			Writable:    reg.Writable(),
+		},
+	}
+
+	switch tv := v.(type) {
+	case NumericRegisterValue:
+		val := tv.Value()
+		pv.NumVal = &val
+	case TextRegisterValue:
+		val := tv.Value()
+		pv.TextVal = &val
+	case EnumRegisterValue:
+		val := tv.EnumIdx()
+		pv.EnumIdx = &val
+	}
+
+	return
+}
+
+func (pv persistedValue) toValue() (Value, error) {
+	return pv.toValueOf(pv.Register.toRegister())
+}
+
+func (pv persistedValue) toValueOf(reg RegisterStruct) (Value, error) {
+	switch reg.RegisterType() {
+	case NumberRegister:
+		if pv.NumVal != nil {
+			return NewNumericRegisterValue(pv.Device, reg, *pv.NumVal), nil
+		}
+	case TextRegister:
+		if pv.TextVal != nil {
+			return NewTextRegisterValue(pv.Device, reg, *pv.TextVal), nil
+		}
+	case EnumRegister:
+		if pv.EnumIdx != nil {
+			return NewEnumRegisterValue(pv.Device, reg, *pv.EnumIdx), nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid value for register=%s of type='%s'", reg.Name(), reg.RegisterType())
+}
+
+func (r persistedRegister) toRegister() RegisterStruct {
+	return NewRegisterStruct(
+		r.Category, r.Name, r.Description,
+		RegisterTypeFromString(r.Type),
+		r.Enum, r.Unit, r.Sort, r.Writable,
+	)
+}
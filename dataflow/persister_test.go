@@ -0,0 +1,128 @@
+package dataflow_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func TestPersisterRoundTrip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.json")
+	cfg := dataflow.PersisterConfig{
+		File:     file,
+		Interval: time.Hour,
+		Filter:   dataflow.AllValueFilter,
+	}
+
+	numReg := getSimpleTestRegister("cat", "num")
+	enumReg := dataflow.NewRegisterStruct("cat", "enum", "Enum", dataflow.EnumRegister, map[int]string{0: "OFF", 1: "ON"}, "", 10, true)
+	textReg := dataflow.NewRegisterStruct("cat", "text", "Text", dataflow.TextRegister, nil, "", 20, false)
+
+	// fill a first storage and persist it
+	{
+		storage := dataflow.NewValueStorage()
+		persister := dataflow.NewPersister(cfg, storage)
+		if values, err := persister.Restore(); err != nil || len(values) != 0 {
+			t.Fatalf("expect no values and no error on missing file, got %v, %v", values, err)
+		}
+		persister.Run()
+
+		storage.Fill(dataflow.NewNumericRegisterValue("dev", numReg, 42))
+		storage.Fill(dataflow.NewEnumRegisterValue("dev", enumReg, 1))
+		storage.Fill(dataflow.NewTextRegisterValue("dev", textReg, "foo"))
+		storage.Fill(dataflow.NewTextRegisterValue("dev", textReg, "bar"))
+		storage.Fill(dataflow.NewNullRegisterValue("dev", textReg))
+		storage.Wait()
+
+		persister.Shutdown()
+		storage.Shutdown()
+	}
+
+	// restore into a second storage
+	storage := dataflow.NewValueStorage()
+	persister := dataflow.NewPersister(cfg, storage)
+	values, err := persister.Restore()
+	if err != nil {
+		t.Fatalf("did not expect an error, got: %s", err)
+	}
+	if expect, got := 2, len(values); expect != got {
+		t.Fatalf("expect %d restored values but got %d", expect, got)
+	}
+
+	state := storage.GetState()
+	if expect, got := 2, len(state); expect != got {
+		t.Fatalf("expect %d values in storage but got %d", expect, got)
+	}
+	for _, v := range state {
+		if !v.Restored() {
+			t.Errorf("expect %s to be restored", v)
+		}
+		switch v.Register().Name() {
+		case "num":
+			if expect, got := 42.0, v.GenericValue(); expect != got {
+				t.Errorf("expect num to be %v but got %v", expect, got)
+			}
+		case "enum":
+			if expect, got := 1, v.GenericValue(); expect != got {
+				t.Errorf("expect enum to be %v but got %v", expect, got)
+			}
+			if !v.Register().Writable() {
+				t.Error("expect enum register to be writable")
+			}
+		default:
+			t.Errorf("unexpected value %s", v)
+		}
+	}
+
+	// a fresh but equal value must clear the restored flag
+	storage.Fill(dataflow.NewNumericRegisterValue("dev", numReg, 42))
+	storage.Wait()
+	for _, v := range storage.GetState() {
+		if v.Register().Name() == "num" && v.Restored() {
+			t.Error("expect num not to be restored after a fresh value was filled")
+		}
+	}
+}
+
+func TestPersisterKeepOnNull(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "command.json")
+	cfg := dataflow.PersisterConfig{
+		File:       file,
+		Interval:   time.Hour,
+		Filter:     dataflow.AllValueFilter,
+		KeepOnNull: true,
+	}
+
+	reg := dataflow.NewRegisterStruct("cat", "relay", "Relay", dataflow.EnumRegister, map[int]string{0: "OFF", 1: "ON"}, "", 10, true)
+
+	{
+		storage := dataflow.NewValueStorage()
+		persister := dataflow.NewPersister(cfg, storage)
+		persister.Run()
+
+		storage.Fill(dataflow.NewEnumRegisterValue("dev", reg, 1))
+		storage.Fill(dataflow.NewNullRegisterValue("dev", reg))
+		storage.Wait()
+
+		persister.Shutdown()
+		storage.Shutdown()
+	}
+
+	storage := dataflow.NewValueStorage()
+	persister := dataflow.NewPersister(cfg, storage)
+	values, err := persister.Load()
+	if err != nil {
+		t.Fatalf("did not expect an error, got: %s", err)
+	}
+	if expect, got := 1, len(values); expect != got {
+		t.Fatalf("expect %d loaded values but got %d", expect, got)
+	}
+	if expect, got := 1, values[0].GenericValue(); expect != got {
+		t.Errorf("expect relay command to be %v but got %v", expect, got)
+	}
+	if expect, got := 0, len(storage.GetState()); expect != got {
+		t.Errorf("expect Load not to fill the storage, got %d values", got)
+	}
+}
@@ -0,0 +1,211 @@
+package dataflow
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"slices"
+	"time"
+)
+
+type RecorderConfig struct {
+	// File is the path of the recording; new values are appended.
+	File string
+	// FlushInterval defines how often the buffered values are written to disk.
+	FlushInterval time.Duration
+	// Filter defines what values are recorded.
+	Filter   ValueFilterFunc
+	LogDebug bool
+}
+
+// Recorder appends every value of a ValueStorage to a file with one json object per line.
+// The register is included whenever it is seen for the first time or when it changed such that a recording
+// can be replayed without knowing the devices, see LoadRecording.
+type Recorder struct {
+	cfg     RecorderConfig
+	storage *ValueStorage
+
+	registers map[StateKey]persistedRegister
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	done      chan struct{}
+}
+
+// recordedValue is one line of a recording.
+type recordedValue struct {
+	Time     time.Time          `json:"Time"`
+	Device   string             `json:"Device"`
+	Name     string             `json:"Name"`
+	Register *persistedRegister `json:"Register,omitempty"`
+	NumVal   *float64           `json:"NumVal,omitempty"`
+	TextVal  *string            `json:"TextVal,omitempty"`
+	EnumIdx  *int               `json:"EnumIdx,omitempty"`
+	Null     bool               `json:"Null,omitempty"`
+}
+
+func NewRecorder(cfg RecorderConfig, storage *ValueStorage) *Recorder {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Recorder{
+		cfg:       cfg,
+		storage:   storage,
+		registers: make(map[StateKey]persistedRegister),
+		ctx:       ctx,
+		ctxCancel: cancel,
+		done:      make(chan struct{}),
+	}
+}
+
+// Run opens the file and starts a routine appending all values of the storage.
+func (r *Recorder) Run() error {
+	f, err := os.OpenFile(r.cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open file: %w", err)
+	}
+
+	// a slow disk must not stall the storage; the recording then skips intermediate values
+	// but always contains the latest value of every register
+	subscription := r.storage.SubscribeSendInitialWithPolicy(r.ctx, r.cfg.Filter, OverflowCoalesce)
+
+	go func() {
+		defer close(r.done)
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Printf("recorder[%s]: cannot close file: %s", r.cfg.File, err)
+			}
+		}()
+
+		w := bufio.NewWriter(f)
+		enc := json.NewEncoder(w)
+		flush := func() {
+			if err := w.Flush(); err != nil {
+				log.Printf("recorder[%s]: cannot write file: %s", r.cfg.File, err)
+			}
+		}
+
+		var reported uint64
+		reportDropped := func() {
+			if dropped := subscription.Dropped(); dropped > reported {
+				log.Printf("recorder[%s]: %d values were skipped since writing was too slow", r.cfg.File, dropped-reported)
+				reported = dropped
+			}
+		}
+
+		ticker := time.NewTicker(r.cfg.FlushInterval)
+		defer ticker.Stop()
+
+		count := 0
+		for {
+			select {
+			case v, ok := <-subscription.Drain():
+				if !ok {
+					// subscription is closed when the context is cancelled
+					flush()
+					reportDropped()
+					return
+				}
+				if v.Restored() {
+					// restored values were not measured now
+					continue
+				}
+				if err := enc.Encode(r.newRecordedValue(v)); err != nil {
+					log.Printf("recorder[%s]: cannot encode value: %s", r.cfg.File, err)
+				}
+				count++
+			case <-ticker.C:
+				flush()
+				reportDropped()
+				if r.cfg.LogDebug && count > 0 {
+					log.Printf("recorder[%s]: wrote %d values", r.cfg.File, count)
+				}
+				count = 0
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops the recorder and waits until all values are written.
+func (r *Recorder) Shutdown() {
+	r.ctxCancel()
+	<-r.done
+}
+
+func (r *Recorder) newRecordedValue(v Value) (rv recordedValue) {
+	pv := newPersistedValue(v)
+	rv = recordedValue{
+		Time:    v.Time(),
+		Device:  pv.Device,
+		Name:    pv.Register.Name,
+		NumVal:  pv.NumVal,
+		TextVal: pv.TextVal,
+		EnumIdx: pv.EnumIdx,
+	}
+	if _, ok := v.(NullRegisterValue); ok {
+		rv.Null = true
+	}
+
+	k := valueStateKey(v)
+	if reg, ok := r.registers[k]; !ok || !reflect.DeepEqual(reg, pv.Register) {
+		r.registers[k] = pv.Register
+		rv.Register = &pv.Register
+	}
+	return
+}
+
+// LoadRecording reads a file written by a Recorder and returns its values ordered by their time.
+// Lines which cannot be parsed, e.g. the last line after a crash, are skipped.
+func LoadRecording(file string) (values []Value, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open file: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	registers := make(map[StateKey]RegisterStruct)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var rv recordedValue
+		if err := json.Unmarshal(scanner.Bytes(), &rv); err != nil {
+			log.Printf("recording[%s]: skip line %d: %s", file, line, err)
+			continue
+		}
+
+		k := StateKey{deviceName: rv.Device, registerName: rv.Name}
+		if rv.Register != nil {
+			registers[k] = rv.Register.toRegister()
+		}
+		reg, ok := registers[k]
+		if !ok {
+			log.Printf("recording[%s]: skip line %d: register=%s of device=%s is unknown", file, line, rv.Name, rv.Device)
+			continue
+		}
+
+		var v Value
+		if rv.Null {
+			v = NewNullRegisterValue(rv.Device, reg)
+		} else {
+			v, err = persistedValue{Device: rv.Device, NumVal: rv.NumVal, TextVal: rv.TextVal, EnumIdx: rv.EnumIdx}.toValueOf(reg)
+			if err != nil {
+				log.Printf("recording[%s]: skip line %d: %s", file, line, err)
+				continue
+			}
+		}
+		values = append(values, WithTime(v, rv.Time))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read file: %w", err)
+	}
+
+	// values are recorded in the order they arrived, which can differ from the time they were measured
+	slices.SortStableFunc(values, func(a, b Value) int {
+		return a.Time().Compare(b.Time())
+	})
+	return values, nil
+}
@@ -0,0 +1,95 @@
+package dataflow_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "recording.jsonl")
+	cfg := dataflow.RecorderConfig{
+		File:          file,
+		FlushInterval: time.Hour,
+		Filter:        dataflow.AllValueFilter,
+	}
+
+	numReg := getSimpleTestRegister("cat", "num")
+	textReg := dataflow.NewRegisterStruct("cat", "text", "Text", dataflow.TextRegister, nil, "", 20, false)
+	textRegChanged := dataflow.NewRegisterStruct("cat", "text", "Changed Text", dataflow.TextRegister, nil, "", 20, false)
+
+	t0 := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	{
+		storage := dataflow.NewValueStorage()
+		recorder := dataflow.NewRecorder(cfg, storage)
+		if err := recorder.Run(); err != nil {
+			t.Fatalf("did not expect an error, got: %s", err)
+		}
+
+		storage.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue("dev", numReg, 42), t0))
+		storage.Fill(dataflow.WithTime(dataflow.NewTextRegisterValue("dev", textReg, "foo"), t0.Add(time.Second)))
+		storage.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue("dev", numReg, 43), t0.Add(2*time.Second)))
+		storage.Fill(dataflow.WithTime(dataflow.NewTextRegisterValue("dev", textRegChanged, "bar"), t0.Add(3*time.Second)))
+		storage.Fill(dataflow.WithTime(dataflow.NewNullRegisterValue("dev", textRegChanged), t0.Add(4*time.Second)))
+		storage.Wait()
+
+		recorder.Shutdown()
+		storage.Shutdown()
+	}
+
+	// the register is only written when it is new or changed
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect, got := 3, strings.Count(string(content), `"Register"`); expect != got {
+		t.Errorf("expect %d lines with a register but got %d:\n%s", expect, got, content)
+	}
+
+	// a truncated last line is skipped
+	if err := os.WriteFile(file, append(content, []byte(`{"Time":`)...), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := dataflow.LoadRecording(file)
+	if err != nil {
+		t.Fatalf("did not expect an error, got: %s", err)
+	}
+	if expect, got := 5, len(values); expect != got {
+		t.Fatalf("expect %d values but got %d", expect, got)
+	}
+
+	expect := []struct {
+		name  string
+		value interface{}
+		desc  string
+	}{
+		{"num", 42.0, ""},
+		{"text", "foo", "Text"},
+		{"num", 43.0, ""},
+		{"text", "bar", "Changed Text"},
+		{"text", nil, "Changed Text"},
+	}
+	for i, e := range expect {
+		v := values[i]
+		if v.DeviceName() != "dev" || v.Register().Name() != e.name {
+			t.Errorf("expect value %d to be dev:%s but got %s", i, e.name, v)
+			continue
+		}
+		if _, isNull := v.(dataflow.NullRegisterValue); e.value == nil && !isNull {
+			t.Errorf("expect value %d to be null but got %s", i, v)
+		} else if e.value != nil && e.value != v.GenericValue() {
+			t.Errorf("expect value %d to be %v but got %v", i, e.value, v.GenericValue())
+		}
+		if expect, got := e.desc, v.Register().Description(); expect != got {
+			t.Errorf("expect value %d to have description %s but got %s", i, expect, got)
+		}
+		if expect, got := t0.Add(time.Duration(i)*time.Second), v.Time(); !expect.Equal(got) {
+			t.Errorf("expect value %d at %s but got %s", i, expect, got)
+		}
+	}
+}
@@ -16,6 +16,7 @@ type Register interface {
 	Unit() string
 	Sort() int
 	Writable() bool
+	Limits() RegisterLimits
 }
 
 type RegisterStruct struct {
@@ -27,6 +28,7 @@ type RegisterStruct struct {
 	unit         string
 	sort         int
 	writable     bool
+	limits       RegisterLimits
 }
 
 func NewRegisterStruct(
@@ -59,6 +61,7 @@ func NewRegisterStructByInterface(reg Register) RegisterStruct {
 		unit:         reg.Unit(),
 		sort:         reg.Sort(),
 		writable:     reg.Writable(),
+		limits:       reg.Limits(),
 	}
 }
 
@@ -94,6 +97,16 @@ func (r RegisterStruct) Writable() bool {
 	return r.writable
 }
 
+func (r RegisterStruct) Limits() RegisterLimits {
+	return r.limits
+}
+
+// WithLimits returns a copy of the register with the given limits.
+func (r RegisterStruct) WithLimits(limits RegisterLimits) RegisterStruct {
+	r.limits = limits
+	return r
+}
+
 func FilterRegisters[R Register](input []R, filterConf RegisterFilterConf) (output []R) {
 	output = make([]R, 0, len(input))
 	f := RegisterFilter(filterConf)
@@ -118,6 +131,7 @@ func (r RegisterStruct) Equals(b RegisterStruct) bool {
 		r.unit == b.unit &&
 		r.sort == b.sort &&
 		r.writable == b.writable &&
+		r.limits.Equals(b.limits) &&
 		mapEquals(r.enum, b.enum)
 }
 
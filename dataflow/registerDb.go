@@ -7,14 +7,16 @@ import (
 )
 
 type RegisterSubscription struct {
-	ctx           context.Context
-	outputChannel chan RegisterStruct
-	filter        RegisterFilterFunc
+	ctx    context.Context
+	queue  *overflowQueue[string, RegisterStruct]
+	filter RegisterFilterFunc
 }
 
 type RegisterDb struct {
 	registers     map[string]RegisterStruct // key: register name
 	subscriptions *list.List[RegisterSubscription]
+	overflow      overflowCounters
+	transform     RegisterTransformFunc
 	lock          sync.RWMutex
 }
 
@@ -39,6 +41,10 @@ func (rdb *RegisterDb) AddStruct(registerStructs ...RegisterStruct) {
 	defer rdb.lock.Unlock()
 
 	for _, reg := range registerStructs {
+		if rdb.transform != nil {
+			reg = rdb.transform(reg)
+		}
+
 		// check if present and equal
 		oldReg, ok := rdb.registers[reg.Name()]
 		if ok && reg.Equals(oldReg) {
@@ -48,11 +54,11 @@ func (rdb *RegisterDb) AddStruct(registerStructs ...RegisterStruct) {
 		// save to map
 		rdb.registers[reg.Name()] = reg
 
-		// forward to subscriptions
+		// forward to subscriptions; this never blocks
 		for e := rdb.subscriptions.Front(); e != nil; e = e.Next() {
 			s := e.Value
 			if s.filter(reg) {
-				s.outputChannel <- reg
+				s.queue.push(reg.Name(), reg)
 			}
 		}
 	}
@@ -97,11 +103,13 @@ func (rdb *RegisterDb) GetByName(registerName string) (reg RegisterStruct, ok bo
 	return
 }
 
+// Subscribe sends all current registers and subsequent changes to the returned channel.
+// When the subscriber is too slow, only the latest version of each register is kept.
 func (rdb *RegisterDb) Subscribe(ctx context.Context, filter RegisterFilterFunc) <-chan RegisterStruct {
 	s := RegisterSubscription{
-		ctx:           ctx,
-		outputChannel: make(chan RegisterStruct, 16),
-		filter:        filter,
+		ctx:    ctx,
+		queue:  newOverflowQueue[string, RegisterStruct](ctx, OverflowCoalesce, 16, nil, &rdb.overflow),
+		filter: filter,
 	}
 
 	rdb.lock.Lock()
@@ -114,19 +122,28 @@ func (rdb *RegisterDb) Subscribe(ctx context.Context, filter RegisterFilterFunc)
 	go func(initialRegisters []RegisterStruct) {
 		// sending initial set of registers to the output chan
 		for _, reg := range initialRegisters {
-			s.outputChannel <- reg
+			s.queue.output <- reg
 		}
 
-		<-s.ctx.Done()
+		s.queue.run(nil, func() {
+			// remove from subscriptions list
+			rdb.lock.Lock()
+			rdb.subscriptions.Remove(elem)
+			rdb.lock.Unlock()
+		})
+	}(rdb.getFilteredUnlocked(filter))
 
-		// remove from subscriptions list
-		rdb.lock.Lock()
-		rdb.subscriptions.Remove(elem)
-		rdb.lock.Unlock()
+	return s.queue.output
+}
 
-		// close output channel
-		close(s.outputChannel)
-	}(rdb.getFilteredUnlocked(filter))
+// OverflowStats returns how often subscribers of this register db did not keep up.
+func (rdb *RegisterDb) OverflowStats() OverflowStats {
+	rdb.lock.RLock()
+	defer rdb.lock.RUnlock()
 
-	return s.outputChannel
+	return OverflowStats{
+		Subscriptions: rdb.subscriptions.Len(),
+		Dropped:       rdb.overflow.dropped.Load(),
+		Disconnected:  rdb.overflow.disconnected.Load(),
+	}
 }
@@ -129,6 +129,7 @@ func mockRegister(t *testing.T, name string) dataflow.Register {
 	m.EXPECT().Unit().Return("")
 	m.EXPECT().Sort().Return(0)
 	m.EXPECT().Writable().Return(false)
+	m.EXPECT().Limits().Return(dataflow.RegisterLimits{})
 
 	return m
 }
@@ -1,5 +1,13 @@
 package dataflow
 
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/koestler/go-iotdevice/v3/namePattern"
+)
+
 //go:generate mockgen -source registerFilter.go -destination mock/registerFilter_mock.go
 
 type Filterable interface {
@@ -18,30 +26,62 @@ type RegisterFilterConf interface {
 	DefaultInclude() bool
 }
 
+// RegisterFilterLogConf is optionally implemented by a RegisterFilterConf to name the filter in the log.
+type RegisterFilterLogConf interface {
+	LogPrefix() string
+}
+
+var logFilterPatterns atomic.Bool
+
+// LogFilterPatterns enables logging which glob or regular expression entry decided about a register.
+// Every decision is logged once per filter and register.
+func LogFilterPatterns(enabled bool) {
+	logFilterPatterns.Store(enabled)
+}
+
+// RegisterFilter creates a function deciding whether to include a register.
+// The entries of the lists are matched as described by namePattern.Pattern; invalid entries are ignored.
 func RegisterFilter(registerFilter RegisterFilterConf) RegisterFilterFunc {
-	includeRegistersMap := sliceToMap(registerFilter.IncludeRegisters())
-	skipRegistersMap := sliceToMap(registerFilter.SkipRegisters())
-	includeCategoriesMap := sliceToMap(registerFilter.IncludeCategories())
-	skipCategoriesMap := sliceToMap(registerFilter.SkipCategories())
+	includeRegisters, _ := namePattern.CompileList(registerFilter.IncludeRegisters())
+	skipRegisters, _ := namePattern.CompileList(registerFilter.SkipRegisters())
+	includeCategories, _ := namePattern.CompileList(registerFilter.IncludeCategories())
+	skipCategories, _ := namePattern.CompileList(registerFilter.SkipCategories())
 	defaultInclude := registerFilter.DefaultInclude()
 
+	logPrefix := ""
+	if lc, ok := registerFilter.(RegisterFilterLogConf); ok {
+		logPrefix = lc.LogPrefix()
+	}
+	var logged sync.Map // key: register name
+
+	logMatch := func(reg Filterable, rule string, p namePattern.Pattern, include bool) bool {
+		if !p.Literal() && logFilterPatterns.Load() {
+			if _, loaded := logged.LoadOrStore(reg.Name(), struct{}{}); !loaded {
+				log.Printf("registerFilter[%s]: register=%s category=%s include=%t by %s entry '%s'",
+					logPrefix, reg.Name(), reg.Category(), include, rule, p,
+				)
+			}
+		}
+		return include
+	}
+
 	return func(reg Filterable) bool {
 		regName := reg.Name()
-		if _, ok := includeRegistersMap[regName]; ok {
-			return true
+		if p, ok := includeRegisters.Match(regName); ok {
+			return logMatch(reg, "IncludeRegisters", p, true)
 		}
 
-		if _, ok := skipRegistersMap[regName]; ok {
-			return false
+		if p, ok := skipRegisters.Match(regName); ok {
+			return logMatch(reg, "SkipRegisters", p, false)
 		}
 
 		categoryName := reg.Category()
-		if _, ok := includeCategoriesMap[categoryName]; ok {
-			return true
+		if p, ok := includeCategories.Match(categoryName); ok {
+			return logMatch(reg, "IncludeCategories", p, true)
 		}
 
-		if _, ok := skipCategoriesMap[categoryName]; ok {
-			return false
+		if p, ok := skipCategories.Match(categoryName); ok {
+			return logMatch(reg, "SkipCategories", p, false)
 		}
 
 		return defaultInclude
@@ -51,11 +91,3 @@ func RegisterFilter(registerFilter RegisterFilterConf) RegisterFilterFunc {
 var AllRegisterFilter RegisterFilterFunc = func(Filterable) bool {
 	return true
 }
-
-func sliceToMap[T comparable](inp []T) map[T]struct{} {
-	oup := make(map[T]struct{}, len(inp))
-	for _, v := range inp {
-		oup[v] = struct{}{}
-	}
-	return oup
-}
@@ -0,0 +1,82 @@
+package dataflow
+
+import (
+	"fmt"
+	"math"
+)
+
+// RegisterLimits are the optional constraints of a numeric register; a nil field is not enforced.
+type RegisterLimits struct {
+	Min  *float64
+	Max  *float64
+	Step *float64
+	// Precision is the number of decimals values are displayed with; it is not enforced for commands.
+	Precision *int
+}
+
+// wholeTolerance is the relative rounding error accepted when checking steps of floats.
+const wholeTolerance = 1e-9
+
+func (l RegisterLimits) Empty() bool {
+	return l.Min == nil && l.Max == nil && l.Step == nil && l.Precision == nil
+}
+
+func (l RegisterLimits) Equals(b RegisterLimits) bool {
+	return ptrEquals(l.Min, b.Min) &&
+		ptrEquals(l.Max, b.Max) &&
+		ptrEquals(l.Step, b.Step) &&
+		ptrEquals(l.Precision, b.Precision)
+}
+
+// Check returns an error when the given number violates any of the limits; the precision is not checked.
+func (l RegisterLimits) Check(f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("%g is not a finite number", f)
+	}
+	if l.Min != nil && f < *l.Min {
+		return fmt.Errorf("%g is below the minimum of %g", f, *l.Min)
+	}
+	if l.Max != nil && f > *l.Max {
+		return fmt.Errorf("%g is above the maximum of %g", f, *l.Max)
+	}
+	if l.Step != nil && *l.Step > 0 {
+		// steps are counted from the minimum if there is one
+		base := 0.0
+		if l.Min != nil {
+			base = *l.Min
+		}
+		if !isWhole((f - base) / *l.Step) {
+			return fmt.Errorf("%g is not a multiple of the step %g", f, *l.Step)
+		}
+	}
+	return nil
+}
+
+// ValidateCommand checks a value sent to a writable register against the enum and the limits of the register.
+func ValidateCommand(v Value) error {
+	reg := v.Register()
+	switch tv := v.(type) {
+	case NumericRegisterValue:
+		if err := reg.Limits().Check(tv.Value()); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", reg.Name(), err)
+		}
+	case EnumRegisterValue:
+		if enum := reg.Enum(); len(enum) > 0 {
+			if _, ok := enum[tv.EnumIdx()]; !ok {
+				return fmt.Errorf("invalid value for %s: %d is not a valid enum index", reg.Name(), tv.EnumIdx())
+			}
+		}
+	}
+	return nil
+}
+
+func isWhole(f float64) bool {
+	return math.Abs(f-math.Round(f)) <= wholeTolerance*math.Max(1, math.Abs(f))
+}
+
+func ptrEquals[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
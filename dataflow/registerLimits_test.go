@@ -0,0 +1,73 @@
+package dataflow_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestValidateCommand(t *testing.T) {
+	setpoint := dataflow.NewRegisterStruct("cat", "Setpoint", "", dataflow.NumberRegister, nil, "°C", 0, true).
+		WithLimits(dataflow.RegisterLimits{Min: ptr(5.0), Max: ptr(30.0), Step: ptr(0.5)})
+	current := dataflow.NewRegisterStruct("cat", "Current", "", dataflow.NumberRegister, nil, "A", 0, true).
+		WithLimits(dataflow.RegisterLimits{Precision: ptr(1)})
+	free := dataflow.NewRegisterStruct("cat", "Free", "", dataflow.NumberRegister, nil, "", 0, true)
+	relay := dataflow.NewRegisterStruct("cat", "Relay", "", dataflow.EnumRegister, map[int]string{0: "off", 1: "on"}, "", 0, true)
+	text := dataflow.NewRegisterStruct("cat", "Text", "", dataflow.TextRegister, nil, "", 0, true)
+
+	tests := []struct {
+		name  string
+		value dataflow.Value
+		valid bool
+	}{
+		{"within range", dataflow.NewNumericRegisterValue("dev", setpoint, 21.5), true},
+		{"at minimum", dataflow.NewNumericRegisterValue("dev", setpoint, 5), true},
+		{"at maximum", dataflow.NewNumericRegisterValue("dev", setpoint, 30), true},
+		{"below minimum", dataflow.NewNumericRegisterValue("dev", setpoint, 4.5), false},
+		{"above maximum", dataflow.NewNumericRegisterValue("dev", setpoint, 30.5), false},
+		{"not on step", dataflow.NewNumericRegisterValue("dev", setpoint, 21.2), false},
+		{"precision ok", dataflow.NewNumericRegisterValue("dev", current, 0.3), true},
+		{"more decimals than displayed", dataflow.NewNumericRegisterValue("dev", current, 0.35), true},
+		{"no limits", dataflow.NewNumericRegisterValue("dev", free, -1e9), true},
+		{"not a number", dataflow.NewNumericRegisterValue("dev", free, math.NaN()), false},
+		{"valid enum", dataflow.NewEnumRegisterValue("dev", relay, 1), true},
+		{"invalid enum", dataflow.NewEnumRegisterValue("dev", relay, 2), false},
+		{"text", dataflow.NewTextRegisterValue("dev", text, "anything"), true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := dataflow.ValidateCommand(tc.value)
+			if tc.valid && err != nil {
+				t.Errorf("expect %s to be valid, got: %s", tc.value, err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("expect %s to be rejected", tc.value)
+			}
+		})
+	}
+}
+
+func TestTransformLimits(t *testing.T) {
+	reg := dataflow.NewRegisterStruct("cat", "Voltage", "", dataflow.NumberRegister, nil, "mV", 0, true).
+		WithLimits(dataflow.RegisterLimits{Min: ptr(0.0), Max: ptr(60000.0)})
+
+	keep := dataflow.Transform{Scale: 1, Precision: -1, Limits: dataflow.RegisterLimits{Step: ptr(10.0)}}
+	if l := keep.Register(reg).Limits(); l.Min == nil || *l.Min != 0 || l.Max == nil || *l.Max != 60000 || l.Step == nil || *l.Step != 10 {
+		t.Errorf("expect the limits of the device to be kept and the step to be added, got %+v", l)
+	}
+
+	convert := dataflow.Transform{Scale: 0.001, Precision: 2, Limits: dataflow.RegisterLimits{Max: ptr(48.0)}}
+	once := convert.Register(reg)
+	if l := once.Limits(); l.Min != nil || l.Max == nil || *l.Max != 48 || l.Precision == nil || *l.Precision != 2 {
+		t.Errorf("expect the limits of the device to be replaced, got %+v", l)
+	}
+	if !convert.Register(once).Equals(once) {
+		t.Error("expect the transform of the limits to be idempotent")
+	}
+}
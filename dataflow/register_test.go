@@ -252,6 +252,46 @@ func TestFilterRegisters(t *testing.T) {
 			t.Errorf("expect %#v but got %#v", expect, got)
 		}
 	})
+
+	t.Run("globSkipRegisters", func(t *testing.T) {
+		fc := mock_dataflow.NewMockRegisterFilterConf(ctrl)
+		fc.EXPECT().SkipRegisters().Return([]string{"test-*-register-name"}).AnyTimes()
+		fc.EXPECT().IncludeRegisters().Return([]string{"b"}).AnyTimes()
+		fc.EXPECT().SkipCategories().Return([]string{}).AnyTimes()
+		fc.EXPECT().IncludeCategories().Return([]string{}).AnyTimes()
+		fc.EXPECT().DefaultInclude().Return(true).AnyTimes()
+
+		got := dataflow.FilterRegisters(stimuliRegisters, fc)
+
+		expect := []dataflow.RegisterStruct{
+			getTestTextRegisterWithName("a"),
+			getTestTextRegisterWithName("b"),
+		}
+
+		if !reflect.DeepEqual(expect, got) {
+			t.Errorf("expect %#v but got %#v", expect, got)
+		}
+	})
+
+	t.Run("regexpIncludeCategories", func(t *testing.T) {
+		fc := mock_dataflow.NewMockRegisterFilterConf(ctrl)
+		fc.EXPECT().SkipRegisters().Return([]string{}).AnyTimes()
+		fc.EXPECT().IncludeRegisters().Return([]string{}).AnyTimes()
+		fc.EXPECT().SkipCategories().Return([]string{}).AnyTimes()
+		fc.EXPECT().IncludeCategories().Return([]string{"/^test-(number|enum)-/"}).AnyTimes()
+		fc.EXPECT().DefaultInclude().Return(false).AnyTimes()
+
+		got := dataflow.FilterRegisters(stimuliRegisters, fc)
+
+		expect := []dataflow.RegisterStruct{
+			getTestNumberRegister(),
+			getTestEnumRegister(),
+		}
+
+		if !reflect.DeepEqual(expect, got) {
+			t.Errorf("expect %#v but got %#v", expect, got)
+		}
+	})
 }
 
 func TestSortRegisters(t *testing.T) {
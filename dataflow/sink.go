@@ -6,11 +6,16 @@ import (
 
 func SinkLog(prefix string, input <-chan Value) {
 	for value := range input {
+		suffix := ""
+		if value.Restored() {
+			suffix = " (restored)"
+		}
 		log.Printf(
-			"%s: %s: %s",
+			"%s: %s: %s%s",
 			prefix,
 			value.DeviceName(),
 			value.String(),
+			suffix,
 		)
 	}
 }
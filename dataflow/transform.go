@@ -0,0 +1,141 @@
+package dataflow
+
+import (
+	"maps"
+	"math"
+)
+
+// Transform converts the values of a register from the units of the device into the units presented to the user.
+type Transform struct {
+	// Scale is multiplied with numeric values; it must not be zero.
+	Scale float64
+	// Offset is added to numeric values after scaling.
+	Offset float64
+	// Unit replaces the unit of the register unless it is empty.
+	Unit string
+	// Precision is the number of decimals numeric values are rounded to; a negative value disables rounding.
+	Precision int
+	// Enum overrides the text of single enum values.
+	Enum map[int]string
+	// Limits override the limits of the register; they are given in the converted units.
+	Limits RegisterLimits
+}
+
+// ValueTransformFunc converts a value before it is stored.
+type ValueTransformFunc func(v Value) Value
+
+// RegisterTransformFunc converts a register before it is added to a RegisterDb.
+type RegisterTransformFunc func(reg RegisterStruct) RegisterStruct
+
+// Register returns the register as seen after the transform.
+func (t Transform) Register(reg Register) RegisterStruct {
+	ret := NewRegisterStructByInterface(reg)
+	if len(t.Unit) > 0 {
+		ret.unit = t.Unit
+	}
+	if len(t.Enum) > 0 {
+		enum := make(map[int]string, len(ret.enum)+len(t.Enum))
+		maps.Copy(enum, ret.enum)
+		maps.Copy(enum, t.Enum)
+		ret.enum = enum
+	}
+	ret.limits = t.limits(ret.limits)
+	return ret
+}
+
+// limits applies the overrides to the limits of the register. The limits of the device are dropped when values
+// are converted since they are given in other units. This keeps the transform idempotent, which is needed since
+// registers of values and commands may already be transformed.
+func (t Transform) limits(l RegisterLimits) RegisterLimits {
+	if t.Scale != 1 || t.Offset != 0 {
+		l = RegisterLimits{}
+	}
+	if t.Precision >= 0 {
+		precision := t.Precision
+		l.Precision = &precision
+	}
+
+	if t.Limits.Min != nil {
+		l.Min = t.Limits.Min
+	}
+	if t.Limits.Max != nil {
+		l.Max = t.Limits.Max
+	}
+	if t.Limits.Step != nil {
+		l.Step = t.Limits.Step
+	}
+	if t.Limits.Precision != nil {
+		l.Precision = t.Limits.Precision
+	}
+	return l
+}
+
+// Apply converts a value of the device into the transformed register and units.
+func (t Transform) Apply(v Value) Value {
+	return t.convert(v, func(f float64) float64 {
+		return t.round(f*t.Scale + t.Offset)
+	})
+}
+
+// Revert converts a value given in the transformed units back into the units of the device, e.g. for commands.
+func (t Transform) Revert(v Value) Value {
+	return t.convert(v, func(f float64) float64 {
+		return (f - t.Offset) / t.Scale
+	})
+}
+
+func (t Transform) convert(v Value, numeric func(float64) float64) Value {
+	reg := t.Register(v.Register())
+	var ret Value
+	switch tv := v.(type) {
+	case NumericRegisterValue:
+		ret = NewNumericRegisterValue(v.DeviceName(), reg, numeric(tv.Value()))
+	case TextRegisterValue:
+		ret = NewTextRegisterValue(v.DeviceName(), reg, tv.Value())
+	case EnumRegisterValue:
+		ret = NewEnumRegisterValue(v.DeviceName(), reg, tv.EnumIdx())
+	case NullRegisterValue:
+		ret = NewNullRegisterValue(v.DeviceName(), reg)
+	default:
+		return v
+	}
+	return WithCommandId(WithTime(ret, v.Time()), v.CommandId())
+}
+
+func (t Transform) round(f float64) float64 {
+	if t.Precision < 0 {
+		return f
+	}
+	p := math.Pow10(t.Precision)
+	return math.Round(f*p) / p
+}
+
+// SetTransform configures a conversion applied to every value before it is stored.
+// Restored values are never converted since they were already converted before they were persisted.
+func (vs *ValueStorage) SetTransform(transform ValueTransformFunc) {
+	vs.mutex.Lock()
+	defer vs.mutex.Unlock()
+	vs.transform = transform
+}
+
+// transformValue must be called with the mutex held.
+func (vs *ValueStorage) transformValue(v Value) Value {
+	if vs.transform == nil || v.Restored() {
+		return v
+	}
+	return vs.transform(v)
+}
+
+// SetTransform configures a conversion applied to every register added to the RegisterDb,
+// including the registers already present.
+func (rdb *RegisterDb) SetTransform(transform RegisterTransformFunc) {
+	rdb.lock.Lock()
+	rdb.transform = transform
+	registers := make([]RegisterStruct, 0, len(rdb.registers))
+	for _, r := range rdb.registers {
+		registers = append(registers, r)
+	}
+	rdb.lock.Unlock()
+
+	rdb.AddStruct(registers...)
+}
@@ -0,0 +1,91 @@
+package dataflow_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func TestTransform(t *testing.T) {
+	t0 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mv := dataflow.NewRegisterStruct("cat", "Voltage", "", dataflow.NumberRegister, nil, "mV", 0, true)
+	relay := dataflow.NewRegisterStruct("cat", "Relay", "", dataflow.EnumRegister, map[int]string{0: "off", 1: "on"}, "", 0, true)
+
+	voltage := dataflow.Transform{Scale: 0.001, Offset: 0.5, Unit: "V", Precision: 2}
+	pump := dataflow.Transform{Scale: 1, Precision: -1, Enum: map[int]string{1: "Pump running"}}
+
+	t.Run("apply numeric", func(t *testing.T) {
+		v := voltage.Apply(dataflow.WithTime(dataflow.NewNumericRegisterValue("dev", mv, 12345.6), t0))
+		if expect, got := 12.85, v.GenericValue(); expect != got {
+			t.Errorf("expect value %v but got %v", expect, got)
+		}
+		if expect, got := "V", v.Register().Unit(); expect != got {
+			t.Errorf("expect unit %s but got %s", expect, got)
+		}
+		if !v.Time().Equal(t0) {
+			t.Errorf("expect the time to be kept, got %s", v.Time())
+		}
+	})
+
+	t.Run("revert numeric", func(t *testing.T) {
+		v := voltage.Revert(dataflow.NewNumericRegisterValue("dev", voltage.Register(mv), 12.5))
+		if expect, got := 12000.0, v.GenericValue(); expect != got {
+			t.Errorf("expect value %v but got %v", expect, got)
+		}
+	})
+
+	t.Run("enum", func(t *testing.T) {
+		v := pump.Apply(dataflow.NewEnumRegisterValue("dev", relay, 1))
+		if expect, got := "Pump running", v.(dataflow.EnumRegisterValue).Value(); expect != got {
+			t.Errorf("expect enum text %s but got %s", expect, got)
+		}
+		if expect, got := "off", v.Register().Enum()[0]; expect != got {
+			t.Errorf("expect enum text %s to be kept but got %s", expect, got)
+		}
+		if expect, got := "on", relay.Enum()[1]; expect != got {
+			t.Errorf("expect the original register to be unchanged, got %s", got)
+		}
+	})
+
+	t.Run("storage and registerDb", func(t *testing.T) {
+		storage := dataflow.NewValueStorage()
+		defer storage.Shutdown()
+		storage.SetTransform(func(v dataflow.Value) dataflow.Value {
+			if v.Register().Name() == "Voltage" {
+				return voltage.Apply(v)
+			}
+			return v
+		})
+
+		storage.Fill(dataflow.NewNumericRegisterValue("dev", mv, 1000))
+		storage.Wait()
+		state := storage.GetState()
+		if len(state) != 1 {
+			t.Fatalf("expect one value, got %v", state)
+		}
+		if expect, got := 1.5, state[0].GenericValue(); expect != got {
+			t.Errorf("expect stored value %v but got %v", expect, got)
+		}
+
+		rdb := dataflow.NewRegisterDb()
+		rdb.AddStruct(relay)
+		rdb.SetTransform(func(reg dataflow.RegisterStruct) dataflow.RegisterStruct {
+			switch reg.Name() {
+			case "Voltage":
+				return voltage.Register(reg)
+			case "Relay":
+				return pump.Register(reg)
+			}
+			return reg
+		})
+		rdb.AddStruct(mv)
+
+		if r, ok := rdb.GetByName("Voltage"); !ok || r.Unit() != "V" {
+			t.Errorf("expect registerDb to contain the Voltage register in V, got %v", r)
+		}
+		if r, ok := rdb.GetByName("Relay"); !ok || r.Enum()[1] != "Pump running" {
+			t.Errorf("expect registerDb to contain the transformed Relay register, got %v", r)
+		}
+	})
+}
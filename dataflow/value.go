@@ -1,6 +1,10 @@
 package dataflow
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
 
 type Value interface {
 	DeviceName() string
@@ -8,11 +12,17 @@ type Value interface {
 	String() string
 	GenericValue() interface{}
 	Equals(comp Value) bool
+	Restored() bool
+	Time() time.Time
+	CommandId() string
 }
 
 type RegisterValue struct {
 	deviceName string
 	register   Register
+	restored   bool
+	time       time.Time
+	commandId  string
 }
 
 func (v RegisterValue) DeviceName() string {
@@ -23,6 +33,22 @@ func (v RegisterValue) Register() Register {
 	return v.register
 }
 
+// Time returns when the value was measured.
+func (v RegisterValue) Time() time.Time {
+	return v.time
+}
+
+// Restored is true when the value was read from a persisted snapshot and
+// no fresh value has been delivered by the device since.
+func (v RegisterValue) Restored() bool {
+	return v.restored
+}
+
+// CommandId identifies the command this value was sent with; it is empty for all other values.
+func (v RegisterValue) CommandId() string {
+	return v.commandId
+}
+
 type NumericRegisterValue struct {
 	RegisterValue
 	value float64
@@ -53,6 +79,7 @@ func NewNumericRegisterValue(deviceName string, register Register, value float64
 		RegisterValue: RegisterValue{
 			deviceName: deviceName,
 			register:   register,
+			time:       time.Now(),
 		},
 		value: value,
 	}
@@ -88,6 +115,7 @@ func NewTextRegisterValue(deviceName string, register Register, value string) Te
 		RegisterValue: RegisterValue{
 			deviceName: deviceName,
 			register:   register,
+			time:       time.Now(),
 		},
 		value: value,
 	}
@@ -133,6 +161,7 @@ func NewEnumRegisterValue(deviceName string, register Register, value int) EnumR
 		RegisterValue: RegisterValue{
 			deviceName: deviceName,
 			register:   register,
+			time:       time.Now(),
 		},
 		value: value,
 	}
@@ -160,6 +189,7 @@ func NewNullRegisterValue(deviceName string, register Register) NullRegisterValu
 		RegisterValue: RegisterValue{
 			deviceName: deviceName,
 			register:   register,
+			time:       time.Now(),
 		},
 	}
 }
@@ -176,3 +206,86 @@ func NewRegisterValue(deviceName string, register Register, v Value) Value {
 
 	return NewNullRegisterValue(deviceName, register)
 }
+
+// ParseRegisterValue creates a value of the given register from its textual representation, e.g. from a config file.
+// Enum values are given by their text or by their index.
+func ParseRegisterValue(deviceName string, register Register, s string) (Value, error) {
+	switch register.RegisterType() {
+	case NumberRegister:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number", s)
+		}
+		return NewNumericRegisterValue(deviceName, register, f), nil
+	case TextRegister:
+		return NewTextRegisterValue(deviceName, register, s), nil
+	case EnumRegister:
+		for idx, text := range register.Enum() {
+			if text == s {
+				return NewEnumRegisterValue(deviceName, register, idx), nil
+			}
+		}
+		idx, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a value of the enum", s)
+		}
+		return NewEnumRegisterValue(deviceName, register, idx), nil
+	default:
+		return nil, fmt.Errorf("register type %s cannot be written", register.RegisterType())
+	}
+}
+
+// WithTime returns a copy of the given value with its measurement time set to t.
+// Devices use this when the time of the measurement is known to differ from the time the value was created.
+func WithTime(v Value, t time.Time) Value {
+	switch tv := v.(type) {
+	case NumericRegisterValue:
+		tv.time = t
+		return tv
+	case TextRegisterValue:
+		tv.time = t
+		return tv
+	case EnumRegisterValue:
+		tv.time = t
+		return tv
+	case NullRegisterValue:
+		tv.time = t
+		return tv
+	}
+	return v
+}
+
+// WithCommandId returns a copy of the given value carrying the id of the command it belongs to.
+func WithCommandId(v Value, id string) Value {
+	switch tv := v.(type) {
+	case NumericRegisterValue:
+		tv.commandId = id
+		return tv
+	case TextRegisterValue:
+		tv.commandId = id
+		return tv
+	case EnumRegisterValue:
+		tv.commandId = id
+		return tv
+	case NullRegisterValue:
+		tv.commandId = id
+		return tv
+	}
+	return v
+}
+
+// markRestored returns a copy of the given value flagged as restored.
+func markRestored(v Value) Value {
+	switch tv := v.(type) {
+	case NumericRegisterValue:
+		tv.restored = true
+		return tv
+	case TextRegisterValue:
+		tv.restored = true
+		return tv
+	case EnumRegisterValue:
+		tv.restored = true
+		return tv
+	}
+	return v
+}
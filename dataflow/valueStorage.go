@@ -16,21 +16,36 @@ type ValueStorage struct {
 	ctxCancel context.CancelFunc
 
 	state         map[StateKey]Value
+	transform     ValueTransformFunc
+	commands      *CommandTracker
 	subscriptions *list.List[ValueSubscription]
+	overflow      overflowCounters
 	mutex         sync.RWMutex
 
+	deadband      DeadbandFunc
+	deadbandMutex sync.RWMutex
+
 	inputChannel   chan Value
 	inputWaitGroup sync.WaitGroup
 }
 
 type ValueSubscription struct {
-	ctx           context.Context
-	outputChannel chan Value
-	filter        ValueFilterFunc
+	ctx    context.Context
+	queue  *overflowQueue[StateKey, Value]
+	filter ValueFilterFunc
+	output <-chan Value
 }
 
 func (s *ValueSubscription) Drain() <-chan Value {
-	return s.outputChannel
+	if s.output != nil {
+		return s.output
+	}
+	return s.queue.output
+}
+
+// Dropped returns how many values were not delivered because the subscriber was too slow.
+func (s *ValueSubscription) Dropped() uint64 {
+	return s.queue.dropped.Load()
 }
 
 func NewValueStorage() (valueStorage *ValueStorage) {
@@ -61,6 +76,7 @@ func (vs *ValueStorage) mainStorageRoutine() {
 			return
 		case newValue := <-vs.inputChannel:
 			vs.mutex.Lock()
+			newValue = vs.transformValue(newValue)
 			if vs.updateState(newValue) {
 				vs.forwardToSubscriptions(newValue)
 			}
@@ -78,7 +94,18 @@ func (vs *ValueStorage) updateState(newValue Value) (updated bool) {
 
 	currentValue, ok := vs.state[k]
 
-	if ok && currentValue.Equals(newValue) {
+	// a repeated command is a new command and must be forwarded
+	if ok && currentValue.Equals(newValue) && currentValue.Restored() == newValue.Restored() &&
+		currentValue.CommandId() == newValue.CommandId() {
+		// keep the time of the latest measurement but do not notify subscribers
+		if newValue.Time().After(currentValue.Time()) {
+			vs.state[k] = newValue
+		}
+		return false
+	}
+
+	if ok && !currentValue.Restored() && newValue.Restored() {
+		// never overwrite a fresh value by a restored one
 		return false
 	}
 
@@ -93,16 +120,29 @@ func (vs *ValueStorage) updateState(newValue Value) (updated bool) {
 	return true
 }
 
-// copy the input value to all subscribed output channels
+// copy the input value to all subscribed output channels; this never blocks
 func (vs *ValueStorage) forwardToSubscriptions(newValue Value) {
+	k := valueStateKey(newValue)
 	for e := vs.subscriptions.Front(); e != nil; e = e.Next() {
 		s := e.Value
 		if s.filter(newValue) {
-			s.outputChannel <- newValue
+			s.queue.push(k, newValue)
 		}
 	}
 }
 
+// OverflowStats returns how often subscribers of this storage did not keep up.
+func (vs *ValueStorage) OverflowStats() OverflowStats {
+	vs.mutex.RLock()
+	defer vs.mutex.RUnlock()
+
+	return OverflowStats{
+		Subscriptions: vs.subscriptions.Len(),
+		Dropped:       vs.overflow.dropped.Load(),
+		Disconnected:  vs.overflow.disconnected.Load(),
+	}
+}
+
 func (vs *ValueStorage) GetState() (result []Value) {
 	vs.mutex.RLock()
 	defer vs.mutex.RUnlock()
@@ -146,7 +186,7 @@ func (vs *ValueStorage) Wait() {
 
 const subscriptionDefaultCap = 128
 
-func (vs *ValueStorage) newSubscription(ctx context.Context, filter ValueFilterFunc) (
+func (vs *ValueStorage) newSubscription(ctx context.Context, filter ValueFilterFunc, policy OverflowPolicy) (
 	initial []Value, subscription ValueSubscription, elem *list.Element[ValueSubscription],
 ) {
 	vs.mutex.Lock()
@@ -154,24 +194,41 @@ func (vs *ValueStorage) newSubscription(ctx context.Context, filter ValueFilterF
 
 	initial = vs.getStateFilteredUnlocked(filter)
 
+	ctx, cancel := context.WithCancel(ctx)
 	subscription = ValueSubscription{
-		ctx:           ctx,
-		outputChannel: make(chan Value, subscriptionDefaultCap),
-		filter:        filter,
+		ctx:    ctx,
+		queue:  newOverflowQueue[StateKey, Value](ctx, policy, subscriptionDefaultCap, cancel, &vs.overflow),
+		filter: filter,
 	}
 	elem = vs.subscriptions.PushBack(subscription)
 
 	return
 }
 
+// SubscribeReturnInitial returns the current state and subscribes to all subsequent changes.
+// The storage waits for the subscriber to consume the values; see SubscribeReturnInitialWithPolicy for slow subscribers.
 func (vs *ValueStorage) SubscribeReturnInitial(ctx context.Context, filter ValueFilterFunc) (initial []Value, subscription ValueSubscription) {
-	initial, subscription, elem := vs.newSubscription(ctx, filter)
+	return vs.SubscribeReturnInitialWithPolicy(ctx, filter, OverflowBlock)
+}
+
+func (vs *ValueStorage) SubscribeReturnInitialWithPolicy(
+	ctx context.Context, filter ValueFilterFunc, policy OverflowPolicy,
+) (initial []Value, subscription ValueSubscription) {
+	initial, subscription, elem := vs.newSubscription(ctx, filter, policy)
 	go vs.sendInitialAndCleanupValueSubscription([]Value{}, subscription, elem)
 	return
 }
 
+// SubscribeSendInitial subscribes to the current state and all subsequent changes.
+// The storage waits for the subscriber to consume the values; see SubscribeSendInitialWithPolicy for slow subscribers.
 func (vs *ValueStorage) SubscribeSendInitial(ctx context.Context, filter ValueFilterFunc) (subscription ValueSubscription) {
-	initial, subscription, elem := vs.newSubscription(ctx, filter)
+	return vs.SubscribeSendInitialWithPolicy(ctx, filter, OverflowBlock)
+}
+
+func (vs *ValueStorage) SubscribeSendInitialWithPolicy(
+	ctx context.Context, filter ValueFilterFunc, policy OverflowPolicy,
+) (subscription ValueSubscription) {
+	initial, subscription, elem := vs.newSubscription(ctx, filter, policy)
 	go vs.sendInitialAndCleanupValueSubscription(initial, subscription, elem)
 	return
 }
@@ -181,26 +238,11 @@ func (vs *ValueStorage) sendInitialAndCleanupValueSubscription(
 	subscription ValueSubscription,
 	elem *list.Element[ValueSubscription],
 ) {
-	// cleanup subscription
-	defer func() {
+	// send the initial values and pending values until the subscription context is cancelled
+	subscription.queue.run(initial, func() {
 		// remove from subscriptions list
 		vs.mutex.Lock()
 		vs.subscriptions.Remove(elem)
 		vs.mutex.Unlock()
-
-		// close output channel
-		close(subscription.outputChannel)
-	}()
-
-	// this never blocks since channel is always buffered, big enough and main routine is blocked
-	for _, initialV := range initial {
-		select {
-		case subscription.outputChannel <- initialV:
-		case <-subscription.ctx.Done():
-			return
-		}
-	}
-
-	// wait for the cancellation of the subscription context
-	<-subscription.ctx.Done()
+	})
 }
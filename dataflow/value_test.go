@@ -4,6 +4,7 @@ import (
 	"github.com/koestler/go-iotdevice/v3/dataflow"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewNumericRegisterValue(t *testing.T) {
@@ -138,3 +139,77 @@ func TestNewNullRegisterValue(t *testing.T) {
 		t.Errorf("expect nil but got %#v", got)
 	}
 }
+
+func TestParseRegisterValue(t *testing.T) {
+	relay := dataflow.NewRegisterStruct("Relays", "CH2", "", dataflow.EnumRegister, map[int]string{0: "open", 1: "closed"}, "", 0, true)
+	setpoint := dataflow.NewRegisterStruct("Settings", "Setpoint", "", dataflow.NumberRegister, nil, "V", 0, true)
+	label := dataflow.NewRegisterStruct("Settings", "Label", "", dataflow.TextRegister, nil, "", 0, true)
+
+	tests := []struct {
+		register dataflow.Register
+		inp      string
+		expect   dataflow.Value
+	}{
+		{relay, "closed", dataflow.NewEnumRegisterValue("dev", relay, 1)},
+		{relay, "0", dataflow.NewEnumRegisterValue("dev", relay, 0)},
+		{setpoint, "13.8", dataflow.NewNumericRegisterValue("dev", setpoint, 13.8)},
+		{label, "on", dataflow.NewTextRegisterValue("dev", label, "on")},
+	}
+	for _, tc := range tests {
+		v, err := dataflow.ParseRegisterValue("dev", tc.register, tc.inp)
+		if err != nil {
+			t.Errorf("did not expect an error for '%s', got: %s", tc.inp, err)
+			continue
+		}
+		if !tc.expect.Equals(v) {
+			t.Errorf("expect '%s' to be parsed to %s but got %s", tc.inp, tc.expect, v)
+		}
+	}
+
+	if _, err := dataflow.ParseRegisterValue("dev", relay, "half"); err == nil {
+		t.Error("expect an error for 'half'")
+	}
+	if _, err := dataflow.ParseRegisterValue("dev", setpoint, "high"); err == nil {
+		t.Error("expect an error for 'high'")
+	}
+}
+
+func TestValueTime(t *testing.T) {
+	before := time.Now()
+	nrv := dataflow.NewNumericRegisterValue("device-name", getTestNumberRegister(), 3.14)
+	after := time.Now()
+
+	if got := nrv.Time(); got.Before(before) || got.After(after) {
+		t.Errorf("expect time to be between %s and %s but got %s", before, after, got)
+	}
+
+	measured := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	withTime := dataflow.WithTime(nrv, measured)
+	if expect, got := measured, withTime.Time(); !expect.Equal(got) {
+		t.Errorf("expect %s but got %s", expect, got)
+	}
+	if !withTime.Equals(nrv) {
+		t.Errorf("expect values only differing in time to be equal")
+	}
+}
+
+func TestValueStorageKeepsLatestTime(t *testing.T) {
+	storage := dataflow.NewValueStorage()
+	defer storage.Shutdown()
+
+	reg := getTestNumberRegister()
+	t0 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	t1 := t0.Add(time.Second)
+
+	storage.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue("device-name", reg, 1), t0))
+	storage.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue("device-name", reg, 1), t1))
+	storage.Wait()
+
+	state := storage.GetState()
+	if expect, got := 1, len(state); expect != got {
+		t.Fatalf("expect %d values but got %d", expect, got)
+	}
+	if expect, got := t1, state[0].Time(); !expect.Equal(got) {
+		t.Errorf("expect time of the latest measurement %s but got %s", expect, got)
+	}
+}
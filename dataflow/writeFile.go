@@ -0,0 +1,39 @@
+package dataflow
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces the file by the given payload such that a crash or a power loss leaves either
+// the old or the new content behind. The payload is written to a temporary file which is synced to disk
+// before it is renamed; the directory is synced afterward to persist the rename.
+func WriteFileAtomic(file string, payload []byte) error {
+	tmpFile := file + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(payload); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpFile, file); err != nil {
+		return err
+	}
+
+	dir, err := os.Open(filepath.Dir(file))
+	if err != nil {
+		return err
+	}
+	defer dir.Close() //nolint:errcheck
+	return dir.Sync()
+}
@@ -0,0 +1,30 @@
+package dataflow_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.json")
+
+	for _, payload := range []string{"first", "second"} {
+		if err := dataflow.WriteFileAtomic(file, []byte(payload)); err != nil {
+			t.Fatalf("did not expect an error, got: %s", err)
+		}
+		if got, err := os.ReadFile(file); err != nil || string(got) != payload {
+			t.Errorf("expect file to contain '%s' but got '%s', %v", payload, got, err)
+		}
+	}
+
+	if _, err := os.Stat(file + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expect the temporary file to be renamed, got: %v", err)
+	}
+
+	if err := dataflow.WriteFileAtomic(filepath.Join(t.TempDir(), "missing", "state.json"), nil); err == nil {
+		t.Error("expect an error when the directory does not exist")
+	}
+}
@@ -0,0 +1,198 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+// Dependency is another device which must be available before a device is started.
+// Optionally, registers can be listed which must be known by the other device.
+type Dependency interface {
+	DeviceName() string
+	RegisterNames() []string
+}
+
+type DependenciesConfig interface {
+	DependsOn() []Dependency
+	DependencyTimeout() time.Duration
+}
+
+// RegisterDbOfDeviceFunc returns the register db of the given device.
+type RegisterDbOfDeviceFunc func(deviceName string) *dataflow.RegisterDb
+
+const dependencyCheckInterval = 100 * time.Millisecond
+
+// Stateful is implemented by devices which must not be restarted when a dependency comes back online since a
+// restart would lose their state, e.g. a running generator. They must follow their dependencies by name.
+type Stateful interface {
+	KeepRunningOnDependencyRestart() bool
+}
+
+// Dependent wraps a device which depends on other devices.
+// Run first waits until all dependencies are available and all required registers exist. When this takes longer
+// than the dependency timeout, the missing dependencies are logged and the device is started anyway.
+// Whenever a dependency comes back online while the device is running, the device is stopped with an error
+// such that the restarter runs it again and the device re-binds to the restarted dependency;
+// except for Stateful devices, which keep running.
+type Dependent struct {
+	Device
+	config       DependenciesConfig
+	stateStorage *dataflow.ValueStorage
+	registerDbOf RegisterDbOfDeviceFunc
+}
+
+// WithDependencies returns the given device wrapped into a Dependent, or the device itself when it has no dependencies.
+func WithDependencies(
+	dev Device,
+	config DependenciesConfig,
+	stateStorage *dataflow.ValueStorage,
+	registerDbOf RegisterDbOfDeviceFunc,
+) Device {
+	if len(config.DependsOn()) < 1 {
+		return dev
+	}
+	return &Dependent{
+		Device:       dev,
+		config:       config,
+		stateStorage: stateStorage,
+		registerDbOf: registerDbOf,
+	}
+}
+
+func (d *Dependent) Run(ctx context.Context) (err error, immediateError bool) {
+	dName := d.Name()
+	deps := d.config.DependsOn()
+
+	if e := WaitForDependencies(ctx, deps, d.config.DependencyTimeout(), d.stateStorage, d.registerDbOf); e != nil {
+		if ctx.Err() != nil {
+			// shutdown
+			return nil, false
+		}
+		log.Printf("device[%s]: start anyway: %s", dName, e)
+	} else if d.Config().LogDebug() {
+		log.Printf("device[%s]: all dependencies are ready", dName)
+	}
+
+	if s, ok := d.Device.(Stateful); ok && s.KeepRunningOnDependencyRestart() {
+		return d.Device.Run(ctx)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	restarted := make(chan string, 1)
+	go func() {
+		// routine will return when runCtx is cancelled
+		online := onlineDependencies(deps, d.stateStorage)
+		subscription := d.stateStorage.SubscribeSendInitial(runCtx, availabilityFilter(deps))
+		for v := range subscription.Drain() {
+			if v.Restored() {
+				continue
+			}
+			depName := v.DeviceName()
+			isOnline := v.Equals(onlineValue)
+			if isOnline && !online[depName] {
+				select {
+				case restarted <- depName:
+				default:
+				}
+				cancel()
+			}
+			online[depName] = isOnline
+		}
+	}()
+
+	err, immediateError = d.Device.Run(runCtx)
+
+	if ctx.Err() == nil {
+		select {
+		case depName := <-restarted:
+			return fmt.Errorf("dependency '%s' restarted", depName), false
+		default:
+		}
+	}
+	return
+}
+
+// WaitForDependencies blocks until all dependencies are available and all required registers exist.
+// It returns an error naming the missing dependencies when the timeout is reached and ctx.Err() when ctx is cancelled.
+func WaitForDependencies(
+	ctx context.Context,
+	deps []Dependency,
+	timeout time.Duration,
+	stateStorage *dataflow.ValueStorage,
+	registerDbOf RegisterDbOfDeviceFunc,
+) error {
+	ticker := time.NewTicker(dependencyCheckInterval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+
+	for {
+		missing := MissingDependencies(deps, stateStorage, registerDbOf)
+		if len(missing) < 1 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("dependencies not ready after %s: %s", timeout, strings.Join(missing, ", "))
+		case <-ticker.C:
+		}
+	}
+}
+
+// MissingDependencies returns a description of every dependency which is not online and of every required register
+// which does not exist.
+func MissingDependencies(
+	deps []Dependency,
+	stateStorage *dataflow.ValueStorage,
+	registerDbOf RegisterDbOfDeviceFunc,
+) (missing []string) {
+	online := onlineDependencies(deps, stateStorage)
+	for _, dep := range deps {
+		depName := dep.DeviceName()
+		if !online[depName] {
+			missing = append(missing, fmt.Sprintf("device '%s' is not online", depName))
+			continue
+		}
+
+		registerDb := registerDbOf(depName)
+		for _, registerName := range dep.RegisterNames() {
+			if _, ok := registerDb.GetByName(registerName); !ok {
+				missing = append(missing, fmt.Sprintf("register '%s' of device '%s' does not exist", registerName, depName))
+			}
+		}
+	}
+	return
+}
+
+var onlineValue = dataflow.NewEnumRegisterValue("", availabilityRegister, 1)
+
+func availabilityFilter(deps []Dependency) dataflow.ValueFilterFunc {
+	names := make(map[string]struct{}, len(deps))
+	for _, dep := range deps {
+		names[dep.DeviceName()] = struct{}{}
+	}
+	return func(v dataflow.Value) bool {
+		if _, ok := names[v.DeviceName()]; !ok {
+			return false
+		}
+		return v.Register().Name() == AvailabilityRegisterName
+	}
+}
+
+// onlineDependencies returns which dependencies are currently online. Restored values are not trusted.
+func onlineDependencies(deps []Dependency, stateStorage *dataflow.ValueStorage) map[string]bool {
+	online := make(map[string]bool, len(deps))
+	for _, v := range stateStorage.GetStateFiltered(availabilityFilter(deps)) {
+		online[v.DeviceName()] = !v.Restored() && v.Equals(onlineValue)
+	}
+	return online
+}
@@ -0,0 +1,184 @@
+package device_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+)
+
+type dependency struct {
+	deviceName    string
+	registerNames []string
+}
+
+func (d dependency) DeviceName() string      { return d.deviceName }
+func (d dependency) RegisterNames() []string { return d.registerNames }
+
+type dependenciesConfig struct {
+	dependsOn []device.Dependency
+	timeout   time.Duration
+}
+
+func (c dependenciesConfig) DependsOn() []device.Dependency   { return c.dependsOn }
+func (c dependenciesConfig) DependencyTimeout() time.Duration { return c.timeout }
+
+type deviceConfig struct {
+	name string
+}
+
+func (c deviceConfig) Name() string                        { return c.name }
+func (c deviceConfig) Filter() dataflow.RegisterFilterConf { return nil }
+func (c deviceConfig) LogDebug() bool                      { return false }
+func (c deviceConfig) LogComDebug() bool                   { return false }
+
+type blockingDevice struct {
+	device.State
+}
+
+func (d *blockingDevice) Model() string { return "Blocking" }
+
+func (d *blockingDevice) Run(ctx context.Context) (err error, immediateError bool) {
+	d.SetAvailable(true)
+	defer d.SetAvailable(false)
+	<-ctx.Done()
+	return nil, false
+}
+
+func TestWaitForDependencies(t *testing.T) {
+	stateStorage := dataflow.NewValueStorage()
+	defer stateStorage.Shutdown()
+
+	dep := &blockingDevice{device.NewState(deviceConfig{"dep"}, stateStorage)}
+	registerDbOf := func(deviceName string) *dataflow.RegisterDb {
+		if deviceName == "dep" {
+			return dep.RegisterDb()
+		}
+		return dataflow.NewRegisterDb()
+	}
+	deps := []device.Dependency{dependency{"dep", []string{"Power"}}}
+
+	if expect, got := []string{"device 'dep' is not online"}, device.MissingDependencies(deps, stateStorage, registerDbOf); !slices.Equal(expect, got) {
+		t.Errorf("expect %v but got %v", expect, got)
+	}
+
+	dep.SetAvailable(true)
+	stateStorage.Wait()
+	if expect, got := []string{"register 'Power' of device 'dep' does not exist"}, device.MissingDependencies(deps, stateStorage, registerDbOf); !slices.Equal(expect, got) {
+		t.Errorf("expect %v but got %v", expect, got)
+	}
+
+	err := device.WaitForDependencies(context.Background(), deps, 150*time.Millisecond, stateStorage, registerDbOf)
+	if err == nil || !strings.Contains(err.Error(), "register 'Power'") {
+		t.Errorf("expect a timeout naming the missing register, got: %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		dep.RegisterDb().Add(dataflow.NewRegisterStruct("Essential", "Power", "", dataflow.NumberRegister, nil, "W", 0, false))
+	}()
+	if err := device.WaitForDependencies(context.Background(), deps, time.Second, stateStorage, registerDbOf); err != nil {
+		t.Errorf("did not expect an error, got: %s", err)
+	}
+}
+
+func TestDependentRestartsWithDependency(t *testing.T) {
+	stateStorage := dataflow.NewValueStorage()
+	defer stateStorage.Shutdown()
+
+	dep := &blockingDevice{device.NewState(deviceConfig{"dep"}, stateStorage)}
+	dep.SetAvailable(true)
+	stateStorage.Wait()
+
+	dev := device.WithDependencies(
+		&blockingDevice{device.NewState(deviceConfig{"dev"}, stateStorage)},
+		dependenciesConfig{dependsOn: []device.Dependency{dependency{deviceName: "dep"}}, timeout: time.Second},
+		stateStorage,
+		func(deviceName string) *dataflow.RegisterDb { return dataflow.NewRegisterDb() },
+	)
+
+	done := make(chan error)
+	go func() {
+		err, _ := dev.Run(context.Background())
+		done <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	dep.SetAvailable(false)
+	dep.SetAvailable(true)
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "'dep' restarted") {
+			t.Errorf("expect a restart error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expect the device to stop when its dependency restarts")
+	}
+}
+
+type statefulDevice struct {
+	blockingDevice
+}
+
+func (d *statefulDevice) KeepRunningOnDependencyRestart() bool { return true }
+
+func TestDependentStatefulKeepsRunning(t *testing.T) {
+	stateStorage := dataflow.NewValueStorage()
+	defer stateStorage.Shutdown()
+
+	dep := &blockingDevice{device.NewState(deviceConfig{"dep"}, stateStorage)}
+	dep.SetAvailable(true)
+	stateStorage.Wait()
+
+	dev := device.WithDependencies(
+		&statefulDevice{blockingDevice{device.NewState(deviceConfig{"dev"}, stateStorage)}},
+		dependenciesConfig{dependsOn: []device.Dependency{dependency{deviceName: "dep"}}, timeout: time.Second},
+		stateStorage,
+		func(deviceName string) *dataflow.RegisterDb { return dataflow.NewRegisterDb() },
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		err, _ := dev.Run(ctx)
+		done <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	dep.SetAvailable(false)
+	dep.SetAvailable(true)
+
+	select {
+	case err := <-done:
+		t.Fatalf("expect a stateful device to keep running, got: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("did not expect an error, got: %s", err)
+	}
+}
+
+func TestDependentShutdown(t *testing.T) {
+	stateStorage := dataflow.NewValueStorage()
+	defer stateStorage.Shutdown()
+
+	dev := device.WithDependencies(
+		&blockingDevice{device.NewState(deviceConfig{"dev"}, stateStorage)},
+		dependenciesConfig{dependsOn: []device.Dependency{dependency{deviceName: "dep"}}, timeout: time.Hour},
+		stateStorage,
+		func(deviceName string) *dataflow.RegisterDb { return dataflow.NewRegisterDb() },
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err, _ := dev.Run(ctx); err != nil {
+		t.Errorf("expect no error on shutdown while waiting, got: %s", err)
+	}
+}
@@ -3,6 +3,7 @@ package device
 import (
 	"context"
 	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"time"
 )
 
 type Config interface {
@@ -60,10 +61,11 @@ func (c *State) RegisterDb() *dataflow.RegisterDb {
 }
 
 func (c *State) SetAvailable(v bool) {
+	now := time.Now()
 	if v {
-		c.stateStorage.Fill(c.availableValue)
+		c.stateStorage.Fill(dataflow.WithTime(c.availableValue, now))
 	} else {
-		c.stateStorage.Fill(c.unavailableValue)
+		c.stateStorage.Fill(dataflow.WithTime(c.unavailableValue, now))
 	}
 }
 
@@ -0,0 +1,108 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+type ReadbackConfig interface {
+	Enabled() bool
+	Retries() int
+	Backoff() time.Duration
+}
+
+// Readback confirms written commands by comparing them to the values read back from the device by the following polls.
+// A command whose value is not read back is written again after a backoff which doubles with every retry.
+// When the retries are exhausted, the command is reported as failed.
+// Readback is not safe for concurrent use; it is meant to be used by the run loop of a device.
+type Readback struct {
+	config         ReadbackConfig
+	commandStorage *dataflow.ValueStorage
+	pending        map[string]*readbackCommand // key: register name
+}
+
+type readbackCommand struct {
+	value   dataflow.Value
+	retries int
+	next    time.Time
+}
+
+var errReadbackStopped = errors.New("device stopped before the value was read back")
+
+func NewReadback(config ReadbackConfig, commandStorage *dataflow.ValueStorage) *Readback {
+	return &Readback{
+		config:         config,
+		commandStorage: commandStorage,
+		pending:        make(map[string]*readbackCommand),
+	}
+}
+
+// Enabled returns true when commands are confirmed by reading them back.
+func (r *Readback) Enabled() bool {
+	return r.config.Enabled()
+}
+
+// Written must be called after a command was successfully written to the device.
+// When readback is disabled, the command is confirmed immediately.
+func (r *Readback) Written(v dataflow.Value, now time.Time) {
+	if !r.config.Enabled() {
+		r.commandStorage.CommandDone(v, nil)
+		return
+	}
+
+	name := v.Register().Name()
+	if prev, ok := r.pending[name]; ok {
+		r.commandStorage.CommandDone(prev.value, errors.New("superseded by a newer command before it was read back"))
+	}
+	r.pending[name] = &readbackCommand{
+		value: v,
+		next:  now.Add(r.config.Backoff()),
+	}
+}
+
+// Observe must be called with every value read from the device; it confirms a pending command of the same value.
+func (r *Readback) Observe(v dataflow.Value) {
+	name := v.Register().Name()
+	if p, ok := r.pending[name]; ok && p.value.Equals(v) {
+		delete(r.pending, name)
+		r.commandStorage.CommandDone(p.value, nil)
+	}
+}
+
+// Retry must be called after every poll. It returns the commands that need to be written again
+// and reports the commands that were not read back after the last retry as failed.
+func (r *Readback) Retry(now time.Time) (retry []dataflow.Value) {
+	names := make([]string, 0, len(r.pending))
+	for name := range r.pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := r.pending[name]
+		if now.Before(p.next) {
+			continue
+		}
+		if p.retries >= r.config.Retries() {
+			delete(r.pending, name)
+			r.commandStorage.CommandDone(p.value, fmt.Errorf("value not read back after %d retries", p.retries))
+			continue
+		}
+		p.retries += 1
+		p.next = now.Add(r.config.Backoff() << p.retries)
+		retry = append(retry, p.value)
+	}
+	return
+}
+
+// Stop reports all commands still waiting to be read back as failed.
+func (r *Readback) Stop() {
+	for name, p := range r.pending {
+		delete(r.pending, name)
+		r.commandStorage.CommandDone(p.value, errReadbackStopped)
+	}
+}
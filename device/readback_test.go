@@ -0,0 +1,93 @@
+package device_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+)
+
+type readbackConfig struct {
+	enabled bool
+	retries int
+	backoff time.Duration
+}
+
+func (c readbackConfig) Enabled() bool          { return c.enabled }
+func (c readbackConfig) Retries() int           { return c.retries }
+func (c readbackConfig) Backoff() time.Duration { return c.backoff }
+
+func TestReadback(t *testing.T) {
+	commandStorage := dataflow.NewValueStorage()
+	defer commandStorage.Shutdown()
+	tracker := dataflow.NewCommandTracker(commandStorage)
+
+	relay := dataflow.NewRegisterStruct("Relays", "CH1", "", dataflow.EnumRegister, map[int]string{0: "open", 1: "closed"}, "", 0, true)
+	command := func(idx int) dataflow.Value {
+		s := tracker.Fill(dataflow.NewEnumRegisterValue("dev", relay, idx))
+		return dataflow.WithCommandId(dataflow.NewEnumRegisterValue("dev", relay, idx), s.Id)
+	}
+	expectState := func(v dataflow.Value, state dataflow.CommandState) {
+		t.Helper()
+		if s, ok := tracker.Get(v.CommandId()); !ok || s.State != state {
+			t.Errorf("expect command %s to be %s, got %s", v, state, s.State)
+		}
+	}
+
+	start := time.Now()
+
+	t.Run("disabled", func(t *testing.T) {
+		rb := device.NewReadback(readbackConfig{enabled: false}, commandStorage)
+		v := command(1)
+		rb.Written(v, start)
+		expectState(v, dataflow.CommandConfirmed)
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		rb := device.NewReadback(readbackConfig{enabled: true, retries: 2, backoff: time.Second}, commandStorage)
+		v := command(1)
+		rb.Written(v, start)
+		expectState(v, dataflow.CommandPending)
+
+		rb.Observe(dataflow.NewEnumRegisterValue("dev", relay, 0))
+		if retry := rb.Retry(start.Add(500 * time.Millisecond)); len(retry) != 0 {
+			t.Errorf("expect no retry within the backoff, got %v", retry)
+		}
+
+		rb.Observe(dataflow.NewEnumRegisterValue("dev", relay, 1))
+		expectState(v, dataflow.CommandConfirmed)
+		if retry := rb.Retry(start.Add(time.Hour)); len(retry) != 0 {
+			t.Errorf("expect no retry of a confirmed command, got %v", retry)
+		}
+	})
+
+	t.Run("retriesExhausted", func(t *testing.T) {
+		rb := device.NewReadback(readbackConfig{enabled: true, retries: 2, backoff: time.Second}, commandStorage)
+		v := command(0)
+		rb.Written(v, start)
+
+		// the first retry is due after the backoff, the second after the doubled backoff
+		if retry := rb.Retry(start.Add(time.Second)); len(retry) != 1 || !retry[0].Equals(v) {
+			t.Errorf("expect the first retry, got %v", retry)
+		}
+		if retry := rb.Retry(start.Add(2 * time.Second)); len(retry) != 0 {
+			t.Errorf("expect no retry within the doubled backoff, got %v", retry)
+		}
+		if retry := rb.Retry(start.Add(3 * time.Second)); len(retry) != 1 {
+			t.Errorf("expect the second retry, got %v", retry)
+		}
+		if retry := rb.Retry(start.Add(7 * time.Second)); len(retry) != 0 {
+			t.Errorf("expect no third retry, got %v", retry)
+		}
+		expectState(v, dataflow.CommandFailed)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		rb := device.NewReadback(readbackConfig{enabled: true, retries: 2, backoff: time.Second}, commandStorage)
+		v := command(1)
+		rb.Written(v, start)
+		rb.Stop()
+		expectState(v, dataflow.CommandFailed)
+	})
+}
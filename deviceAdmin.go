@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/koestler/go-iotdevice/v3/httpServer"
+)
+
+// the reloader implements httpServer.DeviceAdmin; changes made at runtime are lost on the next reload
+
+func (rl *reloader) Devices() []httpServer.DeviceStatus {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	devices := rl.cfg.Devices()
+	ret := make([]httpServer.DeviceStatus, len(devices))
+	for i, d := range devices {
+		ret[i] = httpServer.DeviceStatus{
+			Name:    d.Name(),
+			Stopped: true,
+		}
+
+		dev := rl.devicePool.GetByName(d.Name())
+		if dev == nil {
+			continue
+		}
+		s := dev.Status()
+		ret[i].Model = dev.Service().Model()
+		ret[i].Running = s.Running
+		ret[i].Stopped = s.Stopped
+		ret[i].Restarts = s.Restarts
+		if !s.StartedAt.IsZero() {
+			ret[i].StartedAt = &s.StartedAt
+		}
+		if s.LastError != nil {
+			ret[i].LastError = s.LastError.Error()
+			ret[i].LastErrorAt = &s.LastErrorAt
+		}
+	}
+	return ret
+}
+
+// StartDevice starts a device stopped by StopDevice; running devices are left alone.
+func (rl *reloader) StartDevice(name string) error {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	if err := rl.checkDefined(name); err != nil {
+		return err
+	}
+	if rl.devicePool.GetByName(name) != nil {
+		return nil
+	}
+
+	forwarders := rl.forwardersOf([]string{name}, nil)
+	rl.stopForwarders(forwarders)
+	rl.startDevices([]string{name})
+	rl.startForwarders(forwarders)
+
+	if rl.devicePool.GetByName(name) == nil {
+		return fmt.Errorf("device '%s' failed to start, see log", name)
+	}
+	return nil
+}
+
+// StopDevice stops a device until it is started again or its configuration changes.
+func (rl *reloader) StopDevice(name string) error {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	if err := rl.checkDefined(name); err != nil {
+		return err
+	}
+
+	forwarders := rl.forwardersOf([]string{name}, nil)
+	rl.stopForwarders(forwarders)
+	rl.stopDevices([]string{name})
+	rl.startForwarders(forwarders)
+	return nil
+}
+
+// RestartDevice replaces the device by a newly created one, e.g. to get a hung device going again.
+func (rl *reloader) RestartDevice(name string) error {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	if err := rl.checkDefined(name); err != nil {
+		return err
+	}
+
+	forwarders := rl.forwardersOf([]string{name}, nil)
+	rl.stopForwarders(forwarders)
+	rl.stopDevices([]string{name})
+	rl.startDevices([]string{name})
+	rl.startForwarders(forwarders)
+
+	if rl.devicePool.GetByName(name) == nil {
+		return fmt.Errorf("device '%s' failed to start, see log", name)
+	}
+	return nil
+}
+
+func (rl *reloader) AddDevices(fragment []byte) (restarted []string, err error) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	updated, errs := rl.cfg.WithDevices(fragment, false)
+	if len(errs) > 0 {
+		return nil, rejectConfig("admin", errs)
+	}
+	return rl.apply("admin", updated)
+}
+
+func (rl *reloader) RemoveDevice(name string) (restarted []string, err error) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	updated, errs := rl.cfg.WithoutDevice(name, false)
+	if len(errs) > 0 {
+		return nil, rejectConfig("admin", errs)
+	}
+	return rl.apply("admin", updated)
+}
+
+// checkDefined must be called with the mutex held.
+func (rl *reloader) checkDefined(name string) error {
+	for _, d := range rl.cfg.Devices() {
+		if d.Name() == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("device '%s' is not defined", name)
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"github.com/koestler/go-iotdevice/v3/alarmDevice"
+	"github.com/koestler/go-iotdevice/v3/automationDevice"
+	"github.com/koestler/go-iotdevice/v3/computedDevice"
 	"github.com/koestler/go-iotdevice/v3/config"
 	"github.com/koestler/go-iotdevice/v3/dataflow"
 	"github.com/koestler/go-iotdevice/v3/device"
+	"github.com/koestler/go-iotdevice/v3/energyDevice"
 	"github.com/koestler/go-iotdevice/v3/gensetDevice"
 	"github.com/koestler/go-iotdevice/v3/gpioDevice"
 	"github.com/koestler/go-iotdevice/v3/httpDevice"
@@ -12,17 +16,13 @@ import (
 	"github.com/koestler/go-iotdevice/v3/mqttClient"
 	"github.com/koestler/go-iotdevice/v3/mqttDevice"
 	"github.com/koestler/go-iotdevice/v3/pool"
+	"github.com/koestler/go-iotdevice/v3/replayDevice"
 	"github.com/koestler/go-iotdevice/v3/restarter"
+	"github.com/koestler/go-iotdevice/v3/schedulerDevice"
 	"github.com/koestler/go-iotdevice/v3/victronDevice"
 	"log"
-	"time"
 )
 
-// gensetRunDelay is the delay before starting genset devices.
-// This is used so that input/output devices have a change to start up and fetch their register list before
-// the genset device sets up its bindings.
-const gensetRunDelay = 2 * time.Second
-
 func runDevicePool() *pool.Pool[*restarter.Restarter[device.Device]] {
 	return pool.RunPool[*restarter.Restarter[device.Device]]()
 }
@@ -33,20 +33,39 @@ func runNonMqttGensetDevices(
 	modbusPool *pool.Pool[*modbus.ModbusStruct],
 	stateStorage *dataflow.ValueStorage,
 	commandStorage *dataflow.ValueStorage,
+	selected nameSelection,
+	dependencies bool,
 ) {
+	// without dependencies, the devices are started immediately, e.g. when a single device is read
+	wrap := func(deviceConfig config.DeviceConfig, dev device.Device) device.Device {
+		if !dependencies {
+			return dev
+		}
+		return withDependencies(deviceConfig, dev, devicePool, stateStorage)
+	}
+
 	for _, deviceConfig := range cfg.VictronDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
 		if cfg.LogWorkerStart() {
 			log.Printf("device[%s]: start victron type", deviceConfig.Name())
 		}
 
 		deviceConfig := victronDeviceConfig{deviceConfig}
 		dev := victronDevice.NewDevice(deviceConfig, deviceConfig, stateStorage)
-		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, dev)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, wrap(deviceConfig.DeviceConfig, dev))
 		watchedDev.Run()
 		devicePool.Add(watchedDev)
 	}
 
 	for _, deviceConfig := range cfg.ModbusDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
 		if cfg.LogWorkerStart() {
 			log.Printf("device[%s]: start modbus type", deviceConfig.Name())
 		}
@@ -59,12 +78,17 @@ func runNonMqttGensetDevices(
 		}
 
 		dev := modbusDevice.NewDevice(deviceConfig, deviceConfig, modbusInstance, stateStorage, commandStorage)
-		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, dev)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, wrap(deviceConfig.DeviceConfig, dev))
 		watchedDev.Run()
 		devicePool.Add(watchedDev)
 	}
 
 	for _, deviceConfig := range cfg.GpioDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
 		if cfg.LogWorkerStart() {
 			log.Printf("device[%s]: start gpio type", deviceConfig.Name())
 		}
@@ -76,19 +100,42 @@ func runNonMqttGensetDevices(
 			log.Printf("device[%s]: start failed: %s", deviceConfig.Name(), err)
 			continue
 		}
-		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, dev)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, wrap(deviceConfig.DeviceConfig, dev))
 		watchedDev.Run()
 		devicePool.Add(watchedDev)
 	}
 
 	for _, deviceConfig := range cfg.HttpDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
 		if cfg.LogWorkerStart() {
 			log.Printf("device[%s]: start tearacom type", deviceConfig.Name())
 		}
 
 		deviceConfig := httpDeviceConfig{deviceConfig}
 		dev := httpDevice.NewDevice(deviceConfig, deviceConfig, stateStorage, commandStorage)
-		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, dev)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, wrap(deviceConfig.DeviceConfig, dev))
+		watchedDev.Run()
+		devicePool.Add(watchedDev)
+	}
+
+	for _, deviceConfig := range cfg.ReplayDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
+		if cfg.LogWorkerStart() {
+			log.Printf("device[%s]: start replay type", deviceConfig.Name())
+		}
+
+		deviceConfig := replayDeviceConfig{deviceConfig}
+		dev := replayDevice.NewDevice(deviceConfig, deviceConfig, stateStorage)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, wrap(deviceConfig.DeviceConfig, dev))
 		watchedDev.Run()
 		devicePool.Add(watchedDev)
 	}
@@ -100,15 +147,21 @@ func runMqttDevices(
 	mqttClientPool *pool.Pool[mqttClient.Client],
 	stateStorage *dataflow.ValueStorage,
 	commandStorage *dataflow.ValueStorage,
+	selected nameSelection,
 ) {
 	for _, deviceConfig := range cfg.MqttDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
 		if cfg.LogWorkerStart() {
 			log.Printf("device[%s]: start mqtt type", deviceConfig.Name())
 		}
 
 		deviceConfig := mqttDeviceConfig{deviceConfig, cfg.MqttClients()}
 		dev := mqttDevice.NewDevice(deviceConfig, deviceConfig, stateStorage, commandStorage, mqttClientPool)
-		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, dev)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, withDependencies(deviceConfig.DeviceConfig, dev, devicePool, stateStorage))
 		watchedDev.Run()
 		devicePool.Add(watchedDev)
 	}
@@ -119,8 +172,13 @@ func runGensetDevices(
 	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
 	stateStorage *dataflow.ValueStorage,
 	commandStorage *dataflow.ValueStorage,
+	selected nameSelection,
 ) {
 	for _, deviceConfig := range cfg.GensetDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
 		if cfg.LogWorkerStart() {
 			log.Printf("device[%s]: start genset type", deviceConfig.Name())
 		}
@@ -131,19 +189,175 @@ func runGensetDevices(
 			deviceConfig,
 			stateStorage,
 			commandStorage,
-			func(deviceName string) *dataflow.RegisterDb {
-				return devicePool.GetByName(deviceName).Service().RegisterDb()
-			},
+			registerDbOfDevice(devicePool),
+		)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, withDependencies(deviceConfig.DeviceConfig, dev, devicePool, stateStorage))
+		watchedDev.Run()
+		devicePool.Add(watchedDev)
+	}
+}
+
+func runComputedDevices(
+	cfg *config.Config,
+	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
+	stateStorage *dataflow.ValueStorage,
+	selected nameSelection,
+) {
+	for _, deviceConfig := range cfg.ComputedDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
+		if cfg.LogWorkerStart() {
+			log.Printf("device[%s]: start computed type", deviceConfig.Name())
+		}
+
+		deviceConfig := computedDeviceConfig{deviceConfig}
+		dev := computedDevice.NewDevice(deviceConfig, deviceConfig, stateStorage)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, withDependencies(deviceConfig.DeviceConfig, dev, devicePool, stateStorage))
+		watchedDev.Run()
+		devicePool.Add(watchedDev)
+	}
+}
+
+func runEnergyDevices(
+	cfg *config.Config,
+	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
+	stateStorage *dataflow.ValueStorage,
+	selected nameSelection,
+) {
+	for _, deviceConfig := range cfg.EnergyDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
+		if cfg.LogWorkerStart() {
+			log.Printf("device[%s]: start energy type", deviceConfig.Name())
+		}
+
+		deviceConfig := energyDeviceConfig{deviceConfig}
+		dev := energyDevice.NewDevice(deviceConfig, deviceConfig, stateStorage)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, withDependencies(deviceConfig.DeviceConfig, dev, devicePool, stateStorage))
+		watchedDev.Run()
+		devicePool.Add(watchedDev)
+	}
+}
+
+func runAlarmDevices(
+	cfg *config.Config,
+	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
+	stateStorage *dataflow.ValueStorage,
+	commandStorage *dataflow.ValueStorage,
+	selected nameSelection,
+) {
+	for _, deviceConfig := range cfg.AlarmDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
+		if cfg.LogWorkerStart() {
+			log.Printf("device[%s]: start alarm type", deviceConfig.Name())
+		}
+
+		deviceConfig := alarmDeviceConfig{deviceConfig}
+		dev := alarmDevice.NewDevice(deviceConfig, deviceConfig, stateStorage, commandStorage)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, withDependencies(deviceConfig.DeviceConfig, dev, devicePool, stateStorage))
+		watchedDev.Run()
+		devicePool.Add(watchedDev)
+	}
+}
+
+func runAutomationDevices(
+	cfg *config.Config,
+	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
+	stateStorage *dataflow.ValueStorage,
+	commandStorage *dataflow.ValueStorage,
+	commands *dataflow.CommandTracker,
+	selected nameSelection,
+) {
+	for _, deviceConfig := range cfg.AutomationDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
+		if cfg.LogWorkerStart() {
+			log.Printf("device[%s]: start automation type", deviceConfig.Name())
+		}
+
+		deviceConfig := automationDeviceConfig{deviceConfig}
+		dev := automationDevice.NewDevice(
+			deviceConfig,
+			deviceConfig,
+			stateStorage,
+			commandStorage,
+			commands,
+			registerDbOfDevice(devicePool),
+		)
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, withDependencies(deviceConfig.DeviceConfig, dev, devicePool, stateStorage))
+		watchedDev.Run()
+		devicePool.Add(watchedDev)
+	}
+}
+
+func runSchedulerDevices(
+	cfg *config.Config,
+	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
+	stateStorage *dataflow.ValueStorage,
+	commandStorage *dataflow.ValueStorage,
+	commands *dataflow.CommandTracker,
+	selected nameSelection,
+) {
+	for _, deviceConfig := range cfg.SchedulerDevices() {
+		if !selected(deviceConfig.Name()) {
+			continue
+		}
+
+		if cfg.LogWorkerStart() {
+			log.Printf("device[%s]: start scheduler type", deviceConfig.Name())
+		}
+
+		deviceConfig := schedulerDeviceConfig{deviceConfig}
+		dev := schedulerDevice.NewDevice(
+			deviceConfig,
+			deviceConfig,
+			stateStorage,
+			commandStorage,
+			commands,
+			registerDbOfDevice(devicePool),
 		)
-		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, dev)
-		go func() {
-			time.Sleep(gensetRunDelay)
-			watchedDev.Run()
-		}()
+		setupRegisterTransform(deviceConfig.DeviceConfig, dev)
+		watchedDev := restarter.CreateRestarter[device.Device](deviceConfig, withDependencies(deviceConfig.DeviceConfig, dev, devicePool, stateStorage))
+		watchedDev.Run()
 		devicePool.Add(watchedDev)
 	}
 }
 
+// registerDbOfDevice returns a function looking up the register db of a device.
+// Devices which are not in the pool, e.g. because they failed to start, are reported with an empty register db.
+func registerDbOfDevice(devicePool *pool.Pool[*restarter.Restarter[device.Device]]) func(deviceName string) *dataflow.RegisterDb {
+	return func(deviceName string) *dataflow.RegisterDb {
+		if dev := devicePool.GetByName(deviceName); dev != nil {
+			return dev.Service().RegisterDb()
+		}
+		return dataflow.NewRegisterDb()
+	}
+}
+
+// withDependencies delays the start of the device until its dependencies are ready, see device.Dependent.
+func withDependencies(
+	deviceConfig config.DeviceConfig,
+	dev device.Device,
+	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
+	stateStorage *dataflow.ValueStorage,
+) device.Device {
+	return device.WithDependencies(dev, dependenciesConfig{deviceConfig}, stateStorage, registerDbOfDevice(devicePool))
+}
+
 // the following structs / methods are used to cast config.FilterConfig into dataflow.RegisterFilterConf
 
 type victronDeviceConfig struct {
@@ -162,6 +376,10 @@ func (c modbusDeviceConfig) Filter() dataflow.RegisterFilterConf {
 	return c.ModbusDeviceConfig.Filter()
 }
 
+func (c modbusDeviceConfig) CommandReadback() device.ReadbackConfig {
+	return c.ModbusDeviceConfig.CommandReadback()
+}
+
 type gpioDeviceConfig struct {
 	config.GpioDeviceConfig
 }
@@ -196,6 +414,18 @@ func (c httpDeviceConfig) Filter() dataflow.RegisterFilterConf {
 	return c.HttpDeviceConfig.Filter()
 }
 
+func (c httpDeviceConfig) CommandReadback() device.ReadbackConfig {
+	return c.HttpDeviceConfig.CommandReadback()
+}
+
+type replayDeviceConfig struct {
+	config.ReplayDeviceConfig
+}
+
+func (c replayDeviceConfig) Filter() dataflow.RegisterFilterConf {
+	return c.ReplayDeviceConfig.Filter()
+}
+
 type mqttDeviceConfig struct {
 	config.MqttDeviceConfig
 	mqttClients []config.MqttClientConfig
@@ -231,6 +461,91 @@ func (c gensetDeviceConfig) OutputBindings() []gensetDevice.Binding {
 	return oup
 }
 
+type computedDeviceConfig struct {
+	config.ComputedDeviceConfig
+}
+
+func (c computedDeviceConfig) Filter() dataflow.RegisterFilterConf {
+	return c.ComputedDeviceConfig.Filter()
+}
+
+func (c computedDeviceConfig) Registers() []computedDevice.Register {
+	inp := c.ComputedDeviceConfig.Registers()
+	oup := make([]computedDevice.Register, len(inp))
+	for i, r := range inp {
+		oup[i] = computedDevice.Register(r)
+	}
+	return oup
+}
+
+type energyDeviceConfig struct {
+	config.EnergyDeviceConfig
+}
+
+func (c energyDeviceConfig) Filter() dataflow.RegisterFilterConf {
+	return c.EnergyDeviceConfig.Filter()
+}
+
+func (c energyDeviceConfig) Inputs() []energyDevice.Input {
+	inp := c.EnergyDeviceConfig.Inputs()
+	oup := make([]energyDevice.Input, len(inp))
+	for i, b := range inp {
+		oup[i] = energyDevice.Input(b)
+	}
+	return oup
+}
+
+type alarmDeviceConfig struct {
+	config.AlarmDeviceConfig
+}
+
+func (c alarmDeviceConfig) Filter() dataflow.RegisterFilterConf {
+	return c.AlarmDeviceConfig.Filter()
+}
+
+func (c alarmDeviceConfig) Rules() []alarmDevice.Rule {
+	inp := c.AlarmDeviceConfig.Rules()
+	oup := make([]alarmDevice.Rule, len(inp))
+	for i, r := range inp {
+		oup[i] = alarmDevice.Rule(r)
+	}
+	return oup
+}
+
+type automationDeviceConfig struct {
+	config.AutomationDeviceConfig
+}
+
+func (c automationDeviceConfig) Filter() dataflow.RegisterFilterConf {
+	return c.AutomationDeviceConfig.Filter()
+}
+
+func (c automationDeviceConfig) Rules() []automationDevice.Rule {
+	inp := c.AutomationDeviceConfig.Rules()
+	oup := make([]automationDevice.Rule, len(inp))
+	for i, r := range inp {
+		oup[i] = automationDevice.Rule(r)
+	}
+	return oup
+}
+
+type schedulerDeviceConfig struct {
+	config.SchedulerDeviceConfig
+}
+
+func (c schedulerDeviceConfig) Filter() dataflow.RegisterFilterConf {
+	return c.SchedulerDeviceConfig.Filter()
+}
+
+func (c schedulerDeviceConfig) Schedules() []schedulerDevice.Schedule {
+	inp := c.SchedulerDeviceConfig.Schedules()
+	oup := make([]schedulerDevice.Schedule, len(inp))
+	for i, s := range inp {
+		oup[i] = schedulerDevice.Schedule(s)
+	}
+	return oup
+}
+
 func (c mqttDeviceConfig) MqttClientTopics() map[string][]string {
 	ret := make(map[string][]string)
 
@@ -250,3 +565,16 @@ func (c mqttDeviceConfig) MqttClientTopics() map[string][]string {
 
 	return ret
 }
+
+type dependenciesConfig struct {
+	config.DeviceConfig
+}
+
+func (c dependenciesConfig) DependsOn() []device.Dependency {
+	inp := c.DeviceConfig.DependsOn()
+	oup := make([]device.Dependency, len(inp))
+	for i, d := range inp {
+		oup[i] = device.Dependency(d)
+	}
+	return oup
+}
@@ -0,0 +1,113 @@
+package energyDevice
+
+import (
+	"time"
+)
+
+// counter integrates a power in kW into energy counters in kWh.
+// Total is never reset; Today and ThisMonth are reset on the corresponding calendar boundary.
+type counter struct {
+	Total     float64 `json:"Total"`
+	Today     float64 `json:"Today"`
+	ThisMonth float64 `json:"ThisMonth"`
+	Day       string  `json:"Day"`
+	Month     string  `json:"Month"`
+
+	// the last sample is not persisted; after a restart the integration starts over
+	lastPower  float64
+	lastSample time.Time
+	lastTime   time.Time // the counters are integrated up to this time
+	hasLast    bool
+}
+
+const (
+	dayLayout   = "2006-01-02"
+	monthLayout = "2006-01"
+)
+
+// add integrates up to t using the trapezoidal rule and holds the given power from then on.
+// It returns false when the sample is older than the last one and was therefore ignored.
+func (c *counter) add(power float64, t time.Time, maxGap time.Duration) bool {
+	if c.hasLast && !t.After(c.lastSample) {
+		return false
+	}
+
+	// between two samples the power changes linearly;
+	// a sample measured before the last advance holds its power from then on
+	c.integrate(t, (c.lastPower+power)/2, maxGap)
+
+	c.lastPower = power
+	c.lastSample = t
+	if !c.hasLast || t.After(c.lastTime) {
+		c.lastTime = t
+	}
+	c.hasLast = true
+	return true
+}
+
+// advance integrates the last power up to t; the power is held until the next sample.
+// It returns true if a counter changed.
+func (c *counter) advance(t time.Time, maxGap time.Duration) (changed bool) {
+	return c.integrate(t, c.lastPower, maxGap)
+}
+
+// integrate adds the given average power from the time integrated so far up to t.
+// An interval longer than maxGap means the integration itself was stalled; nothing is added for it.
+func (c *counter) integrate(t time.Time, power float64, maxGap time.Duration) (changed bool) {
+	if !c.hasLast || !t.After(c.lastTime) {
+		return c.rollover(t)
+	}
+
+	from := c.lastTime
+	c.lastTime = t
+	if t.Sub(from) > maxGap {
+		return c.rollover(t)
+	}
+
+	energy := power * t.Sub(from).Hours()
+	c.Total += energy
+
+	// only the part after the boundary belongs to the new day / month
+	today := energy * shareAfter(startOfDay(t), from, t)
+	thisMonth := energy * shareAfter(startOfMonth(t), from, t)
+	changed = c.rollover(t)
+	c.Today += today
+	c.ThisMonth += thisMonth
+	return changed || energy != 0
+}
+
+// interrupt stops the integration until the next sample is added, e.g. because the source is unavailable.
+func (c *counter) interrupt() {
+	c.hasLast = false
+}
+
+// rollover resets Today / ThisMonth when t lies in another day / month; it returns true if anything was reset.
+func (c *counter) rollover(t time.Time) (changed bool) {
+	if day := t.Format(dayLayout); day != c.Day {
+		c.Day = day
+		c.Today = 0
+		changed = true
+	}
+	if month := t.Format(monthLayout); month != c.Month {
+		c.Month = month
+		c.ThisMonth = 0
+		changed = true
+	}
+	return
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// shareAfter returns the fraction of the interval from..to that lies after the boundary.
+func shareAfter(boundary, from, to time.Time) float64 {
+	if !boundary.After(from) {
+		return 1
+	}
+	return float64(to.Sub(boundary)) / float64(to.Sub(from))
+}
@@ -0,0 +1,126 @@
+package energyDevice
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func expectEnergy(t *testing.T, name string, expect, got float64) {
+	t.Helper()
+	if math.Abs(expect-got) > 1e-9 {
+		t.Errorf("expect %s to be %f kWh but got %f kWh", name, expect, got)
+	}
+}
+
+func TestCounterTrapezoid(t *testing.T) {
+	start := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	c := counter{}
+
+	c.add(1, start, time.Hour)
+	expectEnergy(t, "Total", 0, c.Total)
+	if expect, got := "2024-03-10", c.Day; expect != got {
+		t.Errorf("expect Day to be %s but got %s", expect, got)
+	}
+
+	// from 1 kW to 3 kW within 30 minutes: 2 kW on average
+	c.add(3, start.Add(30*time.Minute), time.Hour)
+	expectEnergy(t, "Total", 1, c.Total)
+	expectEnergy(t, "Today", 1, c.Today)
+	expectEnergy(t, "ThisMonth", 1, c.ThisMonth)
+
+	// out of order samples are ignored
+	if c.add(100, start.Add(10*time.Minute), time.Hour) {
+		t.Error("expect an older sample to be ignored")
+	}
+	expectEnergy(t, "Total", 1, c.Total)
+
+	c.add(3, start.Add(90*time.Minute), time.Hour)
+	expectEnergy(t, "Total", 4, c.Total)
+}
+
+func TestCounterRamp(t *testing.T) {
+	start := time.Date(2024, 3, 10, 6, 0, 0, 0, time.UTC)
+	c := counter{}
+
+	// a linear ramp from 0 kW to 4 kW within one hour sampled every 15 minutes, e.g. a PV morning
+	for i := 0; i <= 4; i++ {
+		c.add(float64(i), start.Add(time.Duration(i)*15*time.Minute), time.Hour)
+	}
+	expectEnergy(t, "Total", 2, c.Total)
+
+	// and back down to 0 kW
+	for i := 1; i <= 4; i++ {
+		c.add(float64(4-i), start.Add(time.Hour+time.Duration(i)*15*time.Minute), time.Hour)
+	}
+	expectEnergy(t, "Total", 4, c.Total)
+}
+
+func TestCounterConstantPower(t *testing.T) {
+	start := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	c := counter{}
+
+	// no new sample for 30 minutes, much longer than maxGap: the power is held
+	c.add(2, start, 5*time.Minute)
+	for i := 1; i <= 30; i++ {
+		if !c.advance(start.Add(time.Duration(i)*time.Minute), 5*time.Minute) {
+			t.Errorf("expect the counter to change after %d minutes", i)
+		}
+	}
+	expectEnergy(t, "Total", 1, c.Total)
+
+	// the held power is only ramped to the next sample after the last advance
+	c.add(4, start.Add(33*time.Minute), 5*time.Minute)
+	expectEnergy(t, "Total", 1.15, c.Total)
+	c.advance(start.Add(36*time.Minute), 5*time.Minute)
+	expectEnergy(t, "Total", 1.35, c.Total)
+
+	// a sample measured before the last advance is used from then on
+	c.add(0, start.Add(35*time.Minute), 5*time.Minute)
+	c.advance(start.Add(39*time.Minute), 5*time.Minute)
+	expectEnergy(t, "Total", 1.35, c.Total)
+}
+
+func TestCounterGaps(t *testing.T) {
+	start := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	c := counter{}
+
+	c.add(2, start, 5*time.Minute)
+	c.add(2, start.Add(3*time.Minute), 5*time.Minute)
+	expectEnergy(t, "Total", 0.1, c.Total)
+
+	// more than maxGap: nothing is added for the gap
+	c.add(2, start.Add(30*time.Minute), 5*time.Minute)
+	expectEnergy(t, "Total", 0.1, c.Total)
+
+	// interrupted: the next sample starts a new integration
+	c.interrupt()
+	c.add(2, start.Add(33*time.Minute), 5*time.Minute)
+	expectEnergy(t, "Total", 0.1, c.Total)
+
+	c.add(2, start.Add(36*time.Minute), 5*time.Minute)
+	expectEnergy(t, "Total", 0.2, c.Total)
+}
+
+func TestCounterRollover(t *testing.T) {
+	c := counter{}
+
+	// 1 kW for 1 hour around midnight: half of it belongs to the new day and month
+	c.add(1, time.Date(2024, 3, 31, 23, 30, 0, 0, time.UTC), 2*time.Hour)
+	c.add(1, time.Date(2024, 4, 1, 0, 30, 0, 0, time.UTC), 2*time.Hour)
+	expectEnergy(t, "Total", 1, c.Total)
+	expectEnergy(t, "Today", 0.5, c.Today)
+	expectEnergy(t, "ThisMonth", 0.5, c.ThisMonth)
+
+	// within the same month: only the daily counter is reset
+	c.add(1, time.Date(2024, 4, 1, 23, 30, 0, 0, time.UTC), 24*time.Hour)
+	if c.rollover(time.Date(2024, 4, 1, 23, 59, 0, 0, time.UTC)) {
+		t.Error("did not expect a rollover within the same day")
+	}
+	if !c.rollover(time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expect a rollover on a new day")
+	}
+	expectEnergy(t, "Total", 24, c.Total)
+	expectEnergy(t, "Today", 0, c.Today)
+	expectEnergy(t, "ThisMonth", 23.5, c.ThisMonth)
+}
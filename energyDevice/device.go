@@ -0,0 +1,303 @@
+package energyDevice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+)
+
+// integrateInterval defines how often the power held after the last sample is integrated and the daily / monthly
+// counters are checked for a calendar boundary when no samples arrive. The storage does not forward unchanged values,
+// hence a constant power is only integrated by this interval.
+const integrateInterval = 10 * time.Second
+
+type Config interface {
+	Inputs() []Input
+	MaxGap() time.Duration
+	File() string
+	WriteInterval() time.Duration
+}
+
+type Input interface {
+	Name() string
+	DeviceName() string
+	RegisterName() string
+}
+
+type DeviceStruct struct {
+	device.State
+	energyConfig Config
+
+	// counters are kept across restarts of Run and only loaded from the file once
+	counters map[string]*counter
+	loaded   bool
+}
+
+func NewDevice(
+	deviceConfig device.Config,
+	energyConfig Config,
+	stateStorage *dataflow.ValueStorage,
+) *DeviceStruct {
+	counters := make(map[string]*counter)
+	for _, inp := range energyConfig.Inputs() {
+		counters[inp.Name()] = &counter{}
+	}
+
+	return &DeviceStruct{
+		State: device.NewState(
+			deviceConfig,
+			stateStorage,
+		),
+		energyConfig: energyConfig,
+		counters:     counters,
+	}
+}
+
+// inputRegisters are the registers of this device published for one input.
+type inputRegisters struct {
+	total, today, thisMonth dataflow.RegisterStruct
+}
+
+func (d *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool) {
+	dName := d.Config().Name()
+	ss := d.StateStorage()
+
+	if !d.loaded {
+		if err := d.load(); err != nil {
+			return fmt.Errorf("energyDevice[%s]: cannot load counters: %s", dName, err), true
+		}
+		d.loaded = true
+	}
+
+	// setup registers
+	inputs := d.energyConfig.Inputs()
+	registers := make(map[string]inputRegisters, len(inputs))
+	for i, inp := range inputs {
+		registers[inp.Name()] = addToRegisterDb(d.State.RegisterDb(), inp.Name(), i) //nolint:staticcheck
+	}
+
+	publish := func(name string, t time.Time) {
+		c := d.counters[name]
+		r := registers[name]
+		ss.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue(dName, r.total, c.Total), t))
+		ss.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue(dName, r.today, c.Today), t))
+		ss.Fill(dataflow.WithTime(dataflow.NewNumericRegisterValue(dName, r.thisMonth, c.ThisMonth), t))
+	}
+
+	// send connected now, disconnected when this routine stops
+	d.SetAvailable(true)
+	defer func() {
+		d.SetAvailable(false)
+	}()
+
+	now := time.Now()
+	for _, inp := range inputs {
+		c := d.counters[inp.Name()]
+		c.interrupt()
+		c.rollover(now)
+		publish(inp.Name(), now)
+	}
+
+	// subscribe to the power registers and the availability of their devices
+	filter := func(v dataflow.Value) bool {
+		for _, inp := range inputs {
+			if v.DeviceName() != inp.DeviceName() {
+				continue
+			}
+			if rn := v.Register().Name(); rn == inp.RegisterName() || rn == device.AvailabilityRegisterName {
+				return true
+			}
+		}
+		return false
+	}
+	sub := ss.SubscribeSendInitialWithPolicy(ctx, filter, dataflow.OverflowCoalesce)
+
+	// every integration step must be shorter than MaxGap
+	integrateTicker := time.NewTicker(min(integrateInterval, d.energyConfig.MaxGap()/2))
+	defer integrateTicker.Stop()
+
+	var writeTick <-chan time.Time
+	if len(d.energyConfig.File()) > 0 {
+		writeTicker := time.NewTicker(d.energyConfig.WriteInterval())
+		defer writeTicker.Stop()
+		writeTick = writeTicker.C
+		defer d.write()
+	}
+
+	values := sub.Drain()
+	for {
+		select {
+		case v, ok := <-values:
+			if !ok {
+				// the subscription is closed when ctx is cancelled
+				return nil, false
+			}
+			d.handleValue(v, inputs, publish)
+		case t := <-integrateTicker.C:
+			d.integrate(t, inputs, ss.GetStateFiltered(filter), publish)
+		case <-writeTick:
+			d.write()
+		}
+	}
+}
+
+func (d *DeviceStruct) handleValue(v dataflow.Value, inputs []Input, publish func(name string, t time.Time)) {
+	if v.Restored() {
+		// never integrate stale values
+		return
+	}
+
+	for _, inp := range inputs {
+		if v.DeviceName() != inp.DeviceName() {
+			continue
+		}
+		c := d.counters[inp.Name()]
+
+		if v.Register().Name() == device.AvailabilityRegisterName {
+			if ev, ok := v.(dataflow.EnumRegisterValue); ok && ev.Value() == device.AvailabilityOfflineValue {
+				c.interrupt()
+			}
+			continue
+		}
+
+		if v.Register().Name() != inp.RegisterName() {
+			continue
+		}
+
+		power, ok := powerInKw(v)
+		if !ok {
+			// the register was removed or is not numeric; do not integrate across this gap
+			c.interrupt()
+			continue
+		}
+
+		if c.add(power, v.Time(), d.energyConfig.MaxGap()) {
+			publish(inp.Name(), v.Time())
+		}
+	}
+}
+
+// integrate adds the power held since the last sample to the counters. The current state is used to find out
+// whether the source still measures: the storage updates the measurement time of unchanged values without
+// forwarding them. Nothing is added while the source is unavailable or its last measurement is older than MaxGap.
+func (d *DeviceStruct) integrate(t time.Time, inputs []Input, state []dataflow.Value, publish func(name string, t time.Time)) {
+	maxGap := d.energyConfig.MaxGap()
+
+	for _, inp := range inputs {
+		c := d.counters[inp.Name()]
+
+		power, measured, ok := sourcePower(inp, state)
+		if !ok || t.Sub(measured) > maxGap {
+			c.interrupt()
+		} else if !c.hasLast {
+			// the source is measuring again, e.g. with the same power as before it was unavailable
+			c.add(power, measured, maxGap)
+		}
+
+		if c.advance(t, maxGap) {
+			publish(inp.Name(), t)
+		}
+	}
+}
+
+// sourcePower returns the current power of the given input; ok is false while its device is unavailable.
+func sourcePower(inp Input, state []dataflow.Value) (power float64, measured time.Time, ok bool) {
+	for _, v := range state {
+		if v.DeviceName() != inp.DeviceName() {
+			continue
+		}
+		if v.Register().Name() == device.AvailabilityRegisterName {
+			if ev, isEnum := v.(dataflow.EnumRegisterValue); isEnum && ev.Value() == device.AvailabilityOfflineValue {
+				return 0, time.Time{}, false
+			}
+			continue
+		}
+		if v.Register().Name() == inp.RegisterName() && !v.Restored() {
+			power, ok = powerInKw(v)
+			measured = v.Time()
+		}
+	}
+	return
+}
+
+// powerInKw returns the power of the given value in kW; registers with the unit kW are used as is,
+// all others are expected to be in W.
+func powerInKw(v dataflow.Value) (float64, bool) {
+	nv, ok := v.(dataflow.NumericRegisterValue)
+	if !ok {
+		return 0, false
+	}
+	if strings.EqualFold(nv.Register().Unit(), "kW") {
+		return nv.Value(), true
+	}
+	return nv.Value() / 1000, true
+}
+
+// load reads the counters from the file. A missing file is not considered an error.
+func (d *DeviceStruct) load() error {
+	file := d.energyConfig.File()
+	if len(file) < 1 {
+		return nil
+	}
+
+	payload, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot read file: %w", err)
+	}
+
+	var persisted map[string]counter
+	if err := json.Unmarshal(payload, &persisted); err != nil {
+		return fmt.Errorf("cannot parse file '%s': %w", file, err)
+	}
+
+	for name, c := range persisted {
+		if _, ok := d.counters[name]; !ok {
+			// the input was removed from the configuration
+			continue
+		}
+		d.counters[name] = &c
+	}
+
+	if d.Config().LogDebug() {
+		log.Printf("energyDevice[%s]: loaded counters from '%s'", d.Name(), file)
+	}
+
+	return nil
+}
+
+func (d *DeviceStruct) write() {
+	file := d.energyConfig.File()
+
+	persisted := make(map[string]counter, len(d.counters))
+	for name, c := range d.counters {
+		persisted[name] = *c
+	}
+	payload, err := json.Marshal(persisted)
+	if err != nil {
+		log.Printf("energyDevice[%s]: cannot generate snapshot: %s", d.Name(), err)
+		return
+	}
+
+	if err := dataflow.WriteFileAtomic(file, payload); err != nil {
+		log.Printf("energyDevice[%s]: cannot write file: %s", d.Name(), err)
+		return
+	}
+
+	if d.Config().LogDebug() {
+		log.Printf("energyDevice[%s]: wrote counters to '%s'", d.Name(), file)
+	}
+}
+
+func (d *DeviceStruct) Model() string {
+	return "Energy Integrator"
+}
@@ -0,0 +1,25 @@
+package energyDevice
+
+import (
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+func addToRegisterDb(rdb *dataflow.RegisterDb, inputName string, inputIdx int) inputRegisters {
+	sort := inputIdx * 10
+	r := inputRegisters{
+		total: dataflow.NewRegisterStruct(
+			inputName, inputName+"Total", inputName+" Total Energy",
+			dataflow.NumberRegister, nil, "kWh", sort, false,
+		),
+		today: dataflow.NewRegisterStruct(
+			inputName, inputName+"Today", inputName+" Energy Today",
+			dataflow.NumberRegister, nil, "kWh", sort+1, false,
+		),
+		thisMonth: dataflow.NewRegisterStruct(
+			inputName, inputName+"ThisMonth", inputName+" Energy This Month",
+			dataflow.NumberRegister, nil, "kWh", sort+2, false,
+		),
+	}
+	rdb.AddStruct(r.total, r.today, r.thisMonth)
+	return r
+}
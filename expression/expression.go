@@ -0,0 +1,471 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Reference points to a register of a device used as an input of an expression.
+// It is written as device.Register or, when the names contain other characters than letters, digits and underscores,
+// as {device-name.Register}.
+type Reference struct {
+	DeviceName   string
+	RegisterName string
+}
+
+func (r Reference) String() string {
+	return r.DeviceName + "." + r.RegisterName
+}
+
+// LookupFunc returns the current value of the given reference; ok is false when no value is available.
+type LookupFunc func(ref Reference) (value float64, ok bool)
+
+// Expression is a parsed arithmetic / boolean expression.
+// Booleans are represented as numbers: false is 0 and everything else is true; comparisons return 0 or 1.
+type Expression struct {
+	source     string
+	root       node
+	references []Reference
+}
+
+// Parse compiles the given source; supported are numbers, true / false, references, the operators
+// + - * / == != < <= > >= && || ! and parentheses as well as the functions min, max and abs.
+func Parse(source string) (*Expression, error) {
+	p := parser{input: source}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("expression is empty")
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", t.text, t.pos)
+	}
+
+	return &Expression{
+		source:     source,
+		root:       root,
+		references: p.references,
+	}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// References returns all distinct registers used by the expression in order of their first appearance.
+func (e *Expression) References() []Reference {
+	return e.references
+}
+
+// Eval computes the expression. It fails when an input is not available or the result is not a finite number.
+func (e *Expression) Eval(lookup LookupFunc) (float64, error) {
+	v, err := e.root.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("result is not a finite number")
+	}
+	return v, nil
+}
+
+type node interface {
+	eval(lookup LookupFunc) (float64, error)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(LookupFunc) (float64, error) {
+	return float64(n), nil
+}
+
+type referenceNode Reference
+
+func (n referenceNode) eval(lookup LookupFunc) (float64, error) {
+	v, ok := lookup(Reference(n))
+	if !ok {
+		return 0, fmt.Errorf("%s is not available", Reference(n))
+	}
+	return v, nil
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n unaryNode) eval(lookup LookupFunc) (float64, error) {
+	v, err := n.operand.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == "!" {
+		return boolToNumber(v == 0), nil
+	}
+	return -v, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n binaryNode) eval(lookup LookupFunc) (float64, error) {
+	l, err := n.left.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.right.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "==":
+		return boolToNumber(l == r), nil
+	case "!=":
+		return boolToNumber(l != r), nil
+	case "<":
+		return boolToNumber(l < r), nil
+	case "<=":
+		return boolToNumber(l <= r), nil
+	case ">":
+		return boolToNumber(l > r), nil
+	case ">=":
+		return boolToNumber(l >= r), nil
+	case "&&":
+		return boolToNumber(l != 0 && r != 0), nil
+	case "||":
+		return boolToNumber(l != 0 || r != 0), nil
+	default:
+		return 0, fmt.Errorf("unknown operator '%s'", n.op)
+	}
+}
+
+type functionNode struct {
+	name string
+	args []node
+}
+
+var functionArity = map[string]int{
+	"min": -1,
+	"max": -1,
+	"abs": 1,
+}
+
+func (n functionNode) eval(lookup LookupFunc) (float64, error) {
+	values := make([]float64, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(lookup)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = v
+	}
+
+	switch n.name {
+	case "min":
+		ret := values[0]
+		for _, v := range values[1:] {
+			ret = math.Min(ret, v)
+		}
+		return ret, nil
+	case "max":
+		ret := values[0]
+		for _, v := range values[1:] {
+			ret = math.Max(ret, v)
+		}
+		return ret, nil
+	default:
+		return math.Abs(values[0]), nil
+	}
+}
+
+func boolToNumber(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenReference
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type parser struct {
+	input      string
+	tokens     []token
+	idx        int
+	references []Reference
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "<", ">", "!", "(", ")", ","}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (p *parser) tokenize() error {
+	in := p.input
+	for i := 0; i < len(in); {
+		c := rune(in[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			j := i
+			for j < len(in) && (unicode.IsDigit(rune(in[j])) || in[j] == '.') {
+				j++
+			}
+			// exponent like 1E6 or 1.5e-3
+			if j < len(in) && (in[j] == 'e' || in[j] == 'E') {
+				k := j + 1
+				if k < len(in) && (in[k] == '+' || in[k] == '-') {
+					k++
+				}
+				if k < len(in) && unicode.IsDigit(rune(in[k])) {
+					for k < len(in) && unicode.IsDigit(rune(in[k])) {
+						k++
+					}
+					j = k
+				}
+			}
+			p.tokens = append(p.tokens, token{tokenNumber, in[i:j], i})
+			i = j
+		case c == '{':
+			j := strings.IndexByte(in[i:], '}')
+			if j < 0 {
+				return fmt.Errorf("missing '}' for reference at position %d", i)
+			}
+			p.tokens = append(p.tokens, token{tokenReference, in[i+1 : i+j], i})
+			i += j + 1
+		case isIdentRune(c):
+			j := i
+			for j < len(in) && (isIdentRune(rune(in[j])) || in[j] == '.') {
+				j++
+			}
+			text := in[i:j]
+			kind := tokenIdent
+			if strings.Contains(text, ".") {
+				kind = tokenReference
+			}
+			p.tokens = append(p.tokens, token{kind, text, i})
+			i = j
+		default:
+			found := false
+			for _, op := range operators {
+				if strings.HasPrefix(in[i:], op) {
+					p.tokens = append(p.tokens, token{tokenOperator, op, i})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("unexpected character '%c' at position %d", c, i)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) peek() token {
+	if p.idx < len(p.tokens) {
+		return p.tokens[p.idx]
+	}
+	return token{kind: tokenEnd, text: "end of expression", pos: len(p.input)}
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if p.idx < len(p.tokens) {
+		p.idx++
+	}
+	return t
+}
+
+func (p *parser) acceptOperator(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.idx++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expectOperator(op string) error {
+	if _, ok := p.acceptOperator(op); !ok {
+		t := p.peek()
+		return fmt.Errorf("expected '%s' but got '%s' at position %d", op, t.text, t.pos)
+	}
+	return nil
+}
+
+type parseFunc func() (node, error)
+
+// parseBinary parses a left associative chain of the given operators.
+func (p *parser) parseBinary(operand parseFunc, ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOperator(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *parser) parseComparison() (node, error) {
+	return p.parseBinary(p.parseSum, "==", "!=", "<=", ">=", "<", ">")
+}
+
+func (p *parser) parseSum() (node, error) {
+	return p.parseBinary(p.parseProduct, "+", "-")
+}
+
+func (p *parser) parseProduct() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/")
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.acceptOperator("-", "!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s' at position %d", t.text, t.pos)
+		}
+		return numberNode(v), nil
+	case tokenReference:
+		return p.reference(t)
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return numberNode(1), nil
+		case "false":
+			return numberNode(0), nil
+		}
+		if _, ok := functionArity[t.text]; ok {
+			return p.parseFunction(t)
+		}
+		return nil, fmt.Errorf("unknown identifier '%s' at position %d, references must be written as device.Register", t.text, t.pos)
+	case tokenOperator:
+		if t.text == "(" {
+			n, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOperator(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected '%s' at position %d", t.text, t.pos)
+}
+
+func (p *parser) parseFunction(name token) (node, error) {
+	if err := p.expectOperator("("); err != nil {
+		return nil, err
+	}
+
+	var args []node
+	if _, ok := p.acceptOperator(")"); !ok {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.acceptOperator(","); !ok {
+				break
+			}
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if arity := functionArity[name.text]; (arity < 0 && len(args) < 1) || (arity >= 0 && len(args) != arity) {
+		return nil, fmt.Errorf("wrong number of arguments for %s at position %d", name.text, name.pos)
+	}
+
+	return functionNode{name: name.text, args: args}, nil
+}
+
+func (p *parser) reference(t token) (node, error) {
+	deviceName, registerName, ok := strings.Cut(t.text, ".")
+	if !ok || len(deviceName) < 1 || len(registerName) < 1 {
+		return nil, fmt.Errorf("invalid reference '%s' at position %d, expected device.Register", t.text, t.pos)
+	}
+
+	ref := Reference{DeviceName: deviceName, RegisterName: registerName}
+	if !slices.Contains(p.references, ref) {
+		p.references = append(p.references, ref)
+	}
+	return referenceNode(ref), nil
+}
@@ -0,0 +1,117 @@
+package expression
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	inputs := map[Reference]float64{
+		{"bmv0", "BatteryVoltage"}: 12.5,
+		{"bmv0", "CurrentHighRes"}: -4,
+		{"mppt-0", "PanelPower"}:   100,
+		{"mppt-1", "PanelPower"}:   50.5,
+		{"gpio0", "Switch"}:        1,
+	}
+	lookup := func(ref Reference) (float64, bool) {
+		v, ok := inputs[ref]
+		return v, ok
+	}
+
+	tests := []struct {
+		source string
+		expect float64
+	}{
+		{"bmv0.BatteryVoltage * bmv0.CurrentHighRes", -50},
+		{"{mppt-0.PanelPower} + {mppt-1.PanelPower}", 150.5},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"12 / 4 / 3", 1},
+		{"-bmv0.CurrentHighRes", 4},
+		{"1E3 + 1.5e-1", 1000.15},
+		{"abs(bmv0.CurrentHighRes)", 4},
+		{"min(3, 1, 2)", 1},
+		{"max({mppt-0.PanelPower}, {mppt-1.PanelPower})", 100},
+		{"bmv0.BatteryVoltage < 12", 0},
+		{"bmv0.BatteryVoltage >= 12.5", 1},
+		{"bmv0.BatteryVoltage > 12 && gpio0.Switch", 1},
+		{"bmv0.BatteryVoltage > 13 || !gpio0.Switch", 0},
+		{"!(1 == 2)", 1},
+		{"true != false", 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.source, func(t *testing.T) {
+			e, err := Parse(tc.source)
+			if err != nil {
+				t.Fatalf("did not expect an error, got: %s", err)
+			}
+			got, err := e.Eval(lookup)
+			if err != nil {
+				t.Fatalf("did not expect an error, got: %s", err)
+			}
+			if tc.expect != got {
+				t.Errorf("expect %f but got %f", tc.expect, got)
+			}
+		})
+	}
+
+	t.Run("missing input", func(t *testing.T) {
+		e, err := Parse("bmv0.Unknown + 1")
+		if err != nil {
+			t.Fatalf("did not expect an error, got: %s", err)
+		}
+		if _, err := e.Eval(lookup); err == nil {
+			t.Error("expect an error")
+		}
+	})
+
+	t.Run("division by zero", func(t *testing.T) {
+		e, err := Parse("1 / (gpio0.Switch - 1)")
+		if err != nil {
+			t.Fatalf("did not expect an error, got: %s", err)
+		}
+		if _, err := e.Eval(lookup); err == nil {
+			t.Error("expect an error")
+		}
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, source := range []string{
+		"",
+		"1 +",
+		"(1 + 2",
+		"1 2",
+		"Voltage * 2",
+		"{bmv0} + 1",
+		"{bmv0.Voltage",
+		"1 # 2",
+		"abs(1, 2)",
+		"min()",
+		"unknown(1)",
+	} {
+		t.Run(source, func(t *testing.T) {
+			if _, err := Parse(source); err == nil {
+				t.Errorf("expect an error for '%s'", source)
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	e, err := Parse("bmv0.Voltage * bmv0.Current + {mppt-0.Power} - bmv0.Voltage")
+	if err != nil {
+		t.Fatalf("did not expect an error, got: %s", err)
+	}
+
+	expect := []Reference{
+		{"bmv0", "Voltage"},
+		{"bmv0", "Current"},
+		{"mppt-0", "Power"},
+	}
+	if got := e.References(); !reflect.DeepEqual(expect, got) {
+		t.Errorf("expect %v but got %v", expect, got)
+	}
+}
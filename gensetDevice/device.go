@@ -161,6 +161,10 @@ func (d *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool)
 			defer shutdownWg.Done()
 			// routine will return when ctx of the subscription is cancelled
 			for v := range sub.Drain() {
+				if v.Restored() {
+					// never feed stale values into the controller
+					continue
+				}
 				setter(d.controller, v)
 			}
 		}()
@@ -186,6 +190,7 @@ func (d *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool)
 				for v := range sub.Drain() {
 					log.Printf("gensetDevice[%s]: command %v", dName, v)
 					setter(d.controller, v)
+					d.commandStorage.CommandDone(v, nil)
 				}
 			}()
 		}
@@ -257,6 +262,10 @@ func (d *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool)
 			defer shutdownWg.Done()
 
 			for v := range sub.Drain() {
+				if v.Restored() {
+					// only forward outputs set by the running controller
+					continue
+				}
 				r, ok := getRegister()
 				if !ok {
 					log.Printf("gensetDevice[%s]: output register %s not found", dName, registerName)
@@ -279,6 +288,12 @@ func (d *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool)
 	return nil, false
 }
 
+// KeepRunningOnDependencyRestart prevents a reconnecting io device from restarting the controller, which would stop
+// a running generator. The bindings follow the io devices by name and therefore survive their restarts.
+func (d *DeviceStruct) KeepRunningOnDependencyRestart() bool {
+	return true
+}
+
 func (d *DeviceStruct) Model() string {
 	return "Genset Controller"
 }
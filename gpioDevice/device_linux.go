@@ -2,6 +2,7 @@ package gpioDevice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"slices"
@@ -293,18 +294,21 @@ func (d *DeviceStruct) execCommand(chip *gpiocdev.Chip, oupRegisters map[string]
 	reg, ok := oupRegisters[value.Register().Name()]
 	if !ok {
 		log.Printf("gpioDevice[%s]: register ignored: %s", dName, value.Register().Name())
+		d.commandStorage.CommandDone(value, fmt.Errorf("register %s is not an output", value.Register().Name()))
 		return
 	}
 
 	enumValue, ok := value.(dataflow.EnumRegisterValue)
 	if !ok {
 		// ignore non enum values
+		d.commandStorage.CommandDone(value, errors.New("expect an enum value"))
 		return
 	}
 
 	v := enumValue.EnumIdx()
 	if !isValidValue(v) {
 		log.Printf("gpioDevice[%s]: invalid value %d for register %s", dName, v, reg)
+		d.commandStorage.CommandDone(value, fmt.Errorf("invalid value %d", v))
 		return
 	}
 
@@ -312,9 +316,11 @@ func (d *DeviceStruct) execCommand(chip *gpiocdev.Chip, oupRegisters map[string]
 		log.Printf("gpioDevice[%s]: write register %s, value=%d", dName, reg, v)
 	}
 
+	d.commandStorage.CommandSent(value)
 	l, err := chip.RequestLine(reg.offset, gpiocdev.AsOutput(0))
 	if err != nil {
 		log.Printf("gpioDevice[%s]: request line failed: %s", dName, err)
+		d.commandStorage.CommandDone(value, err)
 		return
 	}
 	defer func() {
@@ -324,6 +330,7 @@ func (d *DeviceStruct) execCommand(chip *gpiocdev.Chip, oupRegisters map[string]
 	}()
 
 	err = l.SetValue(v)
+	d.commandStorage.CommandDone(value, err)
 	if err != nil {
 		log.Printf("gpioDevice[%s]: set register %s, value=%d failed: %s", dName, reg, v, err)
 		return
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/koestler/go-iotdevice/v3/config"
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"log"
+)
+
+// runHistory starts recording the numeric values of the state storage; it returns nil when the history is disabled.
+func runHistory(cfg *config.Config, devices *runningDevices, stateStorage *dataflow.ValueStorage) *dataflow.History {
+	historyCfg := cfg.History()
+	if !historyCfg.Enabled() {
+		return nil
+	}
+
+	history := dataflow.NewHistory(dataflow.HistoryConfig{
+		RawRetention:     historyCfg.RawRetention(),
+		MinuteRetention:  historyCfg.MinuteRetention(),
+		QuarterRetention: historyCfg.QuarterRetention(),
+		// same as persistence: configured devices only and never the availability
+		Filter:        persistenceValueFilter(devices),
+		File:          historyCfg.File(),
+		WriteInterval: historyCfg.WriteInterval(),
+		LogDebug:      historyCfg.LogDebug(),
+	}, stateStorage)
+
+	if file := historyCfg.File(); len(file) > 0 {
+		if err := history.Load(); err != nil {
+			log.Printf("history: cannot load history from '%s': %s", file, err)
+		}
+	}
+
+	if cfg.LogWorkerStart() {
+		log.Printf(
+			"history: start: raw=%s, 1m=%s, 15m=%s",
+			historyCfg.RawRetention(), historyCfg.MinuteRetention(), historyCfg.QuarterRetention(),
+		)
+	}
+
+	history.Run()
+	return history
+}
@@ -20,6 +20,7 @@ type Config interface {
 	Username() string
 	Password() string
 	PollInterval() time.Duration
+	CommandReadback() device.ReadbackConfig
 }
 
 type DeviceStruct struct {
@@ -28,6 +29,7 @@ type DeviceStruct struct {
 	registerFilter dataflow.RegisterFilterFunc
 
 	commandStorage *dataflow.ValueStorage
+	readback       *device.Readback
 
 	httpClient  *http.Client
 	pollRequest *http.Request
@@ -50,6 +52,7 @@ func NewDevice(
 		httpConfig:     teracomConfig,
 		registerFilter: dataflow.RegisterFilter(deviceConfig.Filter()),
 		commandStorage: commandStorage,
+		readback:       device.NewReadback(teracomConfig.CommandReadback(), commandStorage),
 
 		httpClient: &http.Client{
 			// this tool is designed to serve devices running on the local network
@@ -76,6 +79,9 @@ func (ds *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool
 		log.Printf("httpDevice[%s]: start polling, interval=%s", ds.Name(), ds.httpConfig.PollInterval())
 	}
 
+	// commands not yet read back when this routine stops are reported as failed
+	defer ds.readback.Stop()
+
 	execPoll := func() error {
 		if err := ds.poll(); err != nil {
 			return fmt.Errorf("httpDevice[%s]: error: %s", ds.Name(), err)
@@ -90,15 +96,16 @@ func (ds *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool
 		return err, true
 	}
 
+	// setup subscription to listen for updates of writable registers
+	// before announcing the availability such that no command sent on availability is lost
+	_, commandSubscription := ds.commandStorage.SubscribeReturnInitial(ctx, dataflow.DeviceNonNullValueFilter(ds.Config().Name()))
+
 	// send connected now, disconnected when this routine stops
 	ds.SetAvailable(true)
 	defer func() {
 		ds.SetAvailable(false)
 	}()
 
-	// setup subscription to listen for updates of writable registers
-	_, commandSubscription := ds.commandStorage.SubscribeReturnInitial(ctx, dataflow.DeviceNonNullValueFilter(ds.Config().Name()))
-
 	execCommand := func(value dataflow.Value) {
 		if ds.Config().LogDebug() {
 			log.Printf(
@@ -106,43 +113,14 @@ func (ds *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool
 				ds.Name(), value.String(),
 			)
 		}
-		if request, onSuccess, err := ds.impl.CommandValueRequest(value); err != nil {
-			log.Printf(
-				"httpDevice[%s]: command request genration failed: %s",
-				ds.Name(), err,
-			)
-		} else {
-			request.URL = ds.httpConfig.Url().JoinPath(request.URL.String())
-			request.SetBasicAuth(ds.httpConfig.Username(), ds.httpConfig.Password())
-			if resp, err := ds.httpClient.Do(request); err != nil {
-				log.Printf(
-					"httpDevice[%s]: command request failed: %s",
-					ds.Name(), err,
-				)
-			} else {
-				// ready and discard response body
-				defer resp.Body.Close() //nolint:errcheck
-
-				if resp.StatusCode != http.StatusOK {
-					log.Printf(
-						"httpDevice[%s]: command request failed with code: %d",
-						ds.Config().Name(), resp.StatusCode,
-					)
-					return
-				}
-
-				if _, err = io.ReadAll(resp.Body); err != nil {
-					log.Printf(
-						"httpDevice[%s]: command cannot read body: %s",
-						ds.Config().Name(), err,
-					)
-					return
-				}
 
-				if ds.Config().LogDebug() {
-					log.Printf("httpDevice[%s]: command request successful", ds.Config().Name())
-				}
-				onSuccess()
+		if err := ds.sendCommand(value); err != nil {
+			ds.commandStorage.CommandDone(value, err)
+			log.Printf("httpDevice[%s]: %s", ds.Name(), err)
+		} else {
+			ds.readback.Written(value, time.Now())
+			if ds.Config().LogDebug() {
+				log.Printf("httpDevice[%s]: command request successful", ds.Config().Name())
 			}
 		}
 
@@ -160,6 +138,12 @@ func (ds *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool
 			if err := execPoll(); err != nil {
 				return err, false
 			}
+			for _, value := range ds.readback.Retry(time.Now()) {
+				log.Printf("httpDevice[%s]: command %s not read back, send again", ds.Name(), value.String())
+				if err := ds.sendCommand(value); err != nil {
+					log.Printf("httpDevice[%s]: %s", ds.Name(), err)
+				}
+			}
 		case value := <-commandSubscription.Drain():
 			if value != nil {
 				execCommand(value)
@@ -257,3 +241,42 @@ func (ds *DeviceStruct) addIgnoreRegister(
 func (ds *DeviceStruct) Model() string {
 	return ds.httpConfig.Kind().String()
 }
+
+// sendCommand executes the http request of the given command and returns an error if it was not successful.
+func (ds *DeviceStruct) sendCommand(value dataflow.Value) error {
+	request, onSuccess, err := ds.impl.CommandValueRequest(value)
+	if err != nil {
+		return fmt.Errorf("command request genration failed: %w", err)
+	}
+
+	request.URL = ds.httpConfig.Url().JoinPath(request.URL.String())
+	request.SetBasicAuth(ds.httpConfig.Username(), ds.httpConfig.Password())
+
+	ds.commandStorage.CommandSent(value)
+	resp, err := ds.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("command request failed: %w", err)
+	}
+	// ready and discard response body
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("command request failed with code: %d", resp.StatusCode)
+	}
+
+	if _, err = io.ReadAll(resp.Body); err != nil {
+		return fmt.Errorf("command cannot read body: %w", err)
+	}
+
+	if !ds.readback.Enabled() {
+		// with readback, the state is only updated by the next polls
+		onSuccess()
+	}
+	return nil
+}
+
+// fill stores a value read from the device and confirms a pending command of the same value.
+func (ds *DeviceStruct) fill(v dataflow.Value) {
+	ds.readback.Observe(v)
+	ds.StateStorage().Fill(v)
+}
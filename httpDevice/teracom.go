@@ -218,7 +218,7 @@ func (c *TeracomDevice) text(category, registerName, description, value string)
 	if register == nil {
 		return
 	}
-	c.ds.StateStorage().Fill(dataflow.NewTextRegisterValue(c.ds.Name(), register, value))
+	c.ds.fill(dataflow.NewTextRegisterValue(c.ds.Name(), register, value))
 }
 
 func (c *TeracomDevice) number(category, registerName, description, unit string, value string) {
@@ -238,7 +238,7 @@ func (c *TeracomDevice) number(category, registerName, description, unit string,
 	if register == nil {
 		return
 	}
-	c.ds.StateStorage().Fill(dataflow.NewNumericRegisterValue(c.ds.Name(), register, floatValue))
+	c.ds.fill(dataflow.NewNumericRegisterValue(c.ds.Name(), register, floatValue))
 }
 
 func (c *TeracomDevice) enum(
@@ -260,7 +260,7 @@ func (c *TeracomDevice) enum(
 		return -1
 	}(strValue)
 
-	c.ds.StateStorage().Fill(dataflow.NewEnumRegisterValue(c.ds.Name(), register, enumIdx))
+	c.ds.fill(dataflow.NewEnumRegisterValue(c.ds.Name(), register, enumIdx))
 }
 
 func (c *TeracomDevice) relay(
@@ -295,7 +295,7 @@ func (c *TeracomDevice) alarm(category, registerName, description string, strVal
 		enumIdx = 1
 	}
 
-	c.ds.StateStorage().Fill(dataflow.NewEnumRegisterValue(c.ds.Name(), register, enumIdx))
+	c.ds.fill(dataflow.NewEnumRegisterValue(c.ds.Name(), register, enumIdx))
 }
 
 func (c *TeracomDevice) extractRegistersAndValues(s teracomStatusStruct) {
@@ -15,6 +15,10 @@ func runHttpServer(
 	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
 	stateStorage *dataflow.ValueStorage,
 	commandStorage *dataflow.ValueStorage,
+	commands *dataflow.CommandTracker,
+	history *dataflow.History,
+	reload httpServer.ReloadFunc,
+	deviceAdmin httpServer.DeviceAdmin,
 ) *httpServer.HttpServer {
 	httpServerCfg := cfg.HttpServer()
 	if !httpServerCfg.Enabled() {
@@ -25,28 +29,43 @@ func runHttpServer(
 		log.Printf("httpServer: start: bind=%s, port=%d", httpServerCfg.Bind(), httpServerCfg.Port())
 	}
 
-	return httpServer.Run(
-		&httpServer.Environment{
-			Config: httpServerConfig{
-				cfg.HttpServer(),
-				cfg.LogConfig(),
-			},
-			ProjectTitle: cfg.ProjectTitle(),
-			Views: func(inp []config.ViewConfig) (oup []httpServer.ViewConfig) {
-				oup = make([]httpServer.ViewConfig, len(inp))
-				for i, r := range inp {
-					oup[i] = viewConfig{r}
-				}
-				return oup
-			}(cfg.Views()),
-			Authentication: cfg.Authentication(),
-			RegisterDbOfDevice: func(deviceName string) *dataflow.RegisterDb {
-				return devicePool.GetByName(deviceName).Service().RegisterDb()
-			},
-			StateStorage:   stateStorage,
-			CommandStorage: commandStorage,
+	return httpServer.Run(httpServerEnvironment(
+		cfg, devicePool, stateStorage, commandStorage, commands, history, reload, deviceAdmin,
+	))
+}
+
+func httpServerEnvironment(
+	cfg *config.Config,
+	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
+	stateStorage *dataflow.ValueStorage,
+	commandStorage *dataflow.ValueStorage,
+	commands *dataflow.CommandTracker,
+	history *dataflow.History,
+	reload httpServer.ReloadFunc,
+	deviceAdmin httpServer.DeviceAdmin,
+) *httpServer.Environment {
+	return &httpServer.Environment{
+		Config: httpServerConfig{
+			cfg.HttpServer(),
+			cfg.LogConfig(),
 		},
-	)
+		ProjectTitle: cfg.ProjectTitle(),
+		Views: func(inp []config.ViewConfig) (oup []httpServer.ViewConfig) {
+			oup = make([]httpServer.ViewConfig, len(inp))
+			for i, r := range inp {
+				oup[i] = viewConfig{r}
+			}
+			return oup
+		}(cfg.Views()),
+		Authentication:     cfg.Authentication(),
+		RegisterDbOfDevice: registerDbOfDevice(devicePool),
+		StateStorage:       stateStorage,
+		CommandStorage:     commandStorage,
+		Commands:           commands,
+		History:            history,
+		Reload:             reload,
+		DeviceAdmin:        deviceAdmin,
+	}
 }
 
 type httpServerConfig struct {
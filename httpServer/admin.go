@@ -0,0 +1,197 @@
+package httpServer
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DeviceAdmin stops, starts, adds and removes devices at runtime.
+type DeviceAdmin interface {
+	Devices() []DeviceStatus
+	StartDevice(name string) error
+	StopDevice(name string) error
+	RestartDevice(name string) error
+	// AddDevices adds or replaces the devices of a yaml fragment using the sections of the configuration file.
+	AddDevices(fragment []byte) (restarted []string, err error)
+	RemoveDevice(name string) (restarted []string, err error)
+}
+
+// DeviceStatus describes a configured device and the state of its restarter.
+type DeviceStatus struct {
+	Name        string     `json:"name" example:"bmv0"`
+	Model       string     `json:"model,omitempty" example:"BMV-702"`
+	Running     bool       `json:"running"`
+	Stopped     bool       `json:"stopped"`
+	Restarts    int        `json:"restarts"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	LastError   string     `json:"lastError,omitempty" example:"connection refused"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+}
+
+// setupDeviceAdmin godoc
+// @Summary List devices
+// @Description Lists all configured devices and whether they are running, stopped or waiting to be restarted.
+// @Description The admin endpoints are only available for the AdminUsers of the authentication.
+// @Produce json
+// @success 200 {array} DeviceStatus
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/devices [get]
+// @Security ApiKeyAuth
+func setupDeviceAdmin(mux *http.ServeMux, env *Environment) {
+	if env.DeviceAdmin == nil || !env.Authentication.Enabled() {
+		return
+	}
+
+	routes := []struct {
+		pattern string
+		handler http.HandlerFunc
+		what    string
+	}{
+		{"GET /api/v2/admin/devices", deviceListHandler(env), "list devices"},
+		{"PUT /api/v2/admin/devices", deviceAddHandler(env), "add devices"},
+		{"DELETE /api/v2/admin/devices/{deviceName}", deviceRemoveHandler(env), "remove device"},
+		{"POST /api/v2/admin/devices/{deviceName}/start", deviceActionHandler(env, env.DeviceAdmin.StartDevice), "start device"},
+		{"POST /api/v2/admin/devices/{deviceName}/stop", deviceActionHandler(env, env.DeviceAdmin.StopDevice), "stop device"},
+		{"POST /api/v2/admin/devices/{deviceName}/restart", deviceActionHandler(env, env.DeviceAdmin.RestartDevice), "restart device"},
+	}
+
+	for _, route := range routes {
+		mux.HandleFunc(route.pattern, authJwtMiddleware(env, adminMiddleware(env, route.handler)))
+		if env.Config.LogConfig() {
+			log.Printf("httpServer: %s -> %s", route.pattern, route.what)
+		}
+	}
+}
+
+func adminMiddleware(env *Environment, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isAdmin(env, r) {
+			jsonErrorResponse(w, http.StatusForbidden, errors.New("User is not allowed here"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func deviceListHandler(env *Environment) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		jsonGetResponse(w, r, env.DeviceAdmin.Devices())
+	}
+}
+
+// deviceAddHandler godoc
+// @Summary Add devices
+// @Description Adds the devices given in the same yaml (or json) format as used by the device sections of the
+// @Description configuration file, e.g. `{"HttpDevices": {"tcw241": {"Url": "http://tcw241/", "Kind": "Teracom"}}}`.
+// @Description Existing devices of the same name are replaced. The configuration file is not changed;
+// @Description the next reload restores the devices defined there.
+// @Accept json
+// @Produce json
+// @success 200 {object} reloadResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /admin/devices [put]
+// @Security ApiKeyAuth
+func deviceAddHandler(env *Environment) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fragment, err := io.ReadAll(r.Body)
+		if err != nil {
+			jsonErrorResponse(w, http.StatusUnprocessableEntity, errors.New("Invalid body provided"))
+			return
+		}
+
+		restarted, err := env.DeviceAdmin.AddDevices(fragment)
+		if err != nil {
+			jsonErrorResponse(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		reloadedResponse(w, r, restarted)
+	}
+}
+
+// deviceRemoveHandler godoc
+// @Summary Remove device
+// @Description Stops the device and removes it from the running configuration. Views and mqtt forwarders
+// @Description referencing the device must be changed first. The configuration file is not changed.
+// @Param deviceName path string true "Device name as provided by the list devices endpoint"
+// @Produce json
+// @success 200 {object} reloadResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /admin/devices/{deviceName} [delete]
+// @Security ApiKeyAuth
+func deviceRemoveHandler(env *Environment) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deviceName := r.PathValue("deviceName")
+		if !deviceExists(env, deviceName) {
+			jsonErrorResponse(w, http.StatusNotFound, errors.New("Device not found"))
+			return
+		}
+
+		restarted, err := env.DeviceAdmin.RemoveDevice(deviceName)
+		if err != nil {
+			jsonErrorResponse(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		reloadedResponse(w, r, restarted)
+	}
+}
+
+// deviceActionHandler godoc
+// @Summary Start, stop or restart device
+// @Description Stopped devices stay stopped until they are started again or their configuration changes.
+// @Description Restarting stops and starts the device, e.g. to power-cycle a device that stopped responding.
+// @Param deviceName path string true "Device name as provided by the list devices endpoint"
+// @Param action path string true "start, stop or restart"
+// @Produce json
+// @success 200 {object} DeviceStatus
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /admin/devices/{deviceName}/{action} [post]
+// @Security ApiKeyAuth
+func deviceActionHandler(env *Environment, action func(name string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deviceName := r.PathValue("deviceName")
+		if !deviceExists(env, deviceName) {
+			jsonErrorResponse(w, http.StatusNotFound, errors.New("Device not found"))
+			return
+		}
+
+		if err := action(deviceName); err != nil {
+			jsonErrorResponse(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		for _, s := range env.DeviceAdmin.Devices() {
+			if s.Name == deviceName {
+				w.Header().Set("Cache-Control", "no-cache")
+				jsonGetResponse(w, r, s)
+				return
+			}
+		}
+	}
+}
+
+func deviceExists(env *Environment, deviceName string) bool {
+	for _, s := range env.DeviceAdmin.Devices() {
+		if s.Name == deviceName {
+			return true
+		}
+	}
+	return false
+}
+
+func reloadedResponse(w http.ResponseWriter, r *http.Request, restarted []string) {
+	if restarted == nil {
+		restarted = []string{}
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	jsonGetResponse(w, r, reloadResponse{Restarted: restarted})
+}
@@ -10,5 +10,10 @@ func addApiV2Routes(mux *http.ServeMux, env *Environment) {
 	setupRegisters(mux, env)
 	setupValuesGetJson(mux, env)
 	setupValuesPatch(mux, env)
+	setupCommandGet(mux, env)
+	setupHistory(mux, env)
+	setupOverflowStats(mux, env)
 	setupDocs(mux, env)
+	setupConfigReload(mux, env)
+	setupDeviceAdmin(mux, env)
 }
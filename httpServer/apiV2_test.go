@@ -3,11 +3,13 @@ package httpServer
 import (
 	"bytes"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -96,12 +98,16 @@ type mockAuthenticationConfig struct {
 	jwtSecret         []byte
 	jwtValidityPeriod time.Duration
 	htaccessFile      string
+	adminUsers        []string
 }
 
 func (m *mockAuthenticationConfig) Enabled() bool                    { return m.enabled }
 func (m *mockAuthenticationConfig) JwtSecret() []byte                { return m.jwtSecret }
 func (m *mockAuthenticationConfig) JwtValidityPeriod() time.Duration { return m.jwtValidityPeriod }
 func (m *mockAuthenticationConfig) HtaccessFile() string             { return m.htaccessFile }
+func (m *mockAuthenticationConfig) IsAdmin(user string) bool {
+	return slices.Contains(m.adminUsers, user)
+}
 
 // setupTestEnvironment creates a test environment with router
 func setupTestEnvironment(t *testing.T) *Environment {
@@ -178,6 +184,7 @@ func setupTestEnvironment(t *testing.T) *Environment {
 		jwtSecret:         []byte("test-secret"),
 		jwtValidityPeriod: 1 * time.Hour,
 		htaccessFile:      setupTestHtaccessFile(t),
+		adminUsers:        []string{testUser},
 	}
 
 	// Create register database
@@ -203,6 +210,7 @@ func setupTestEnvironment(t *testing.T) *Environment {
 		true, // writable
 	))
 
+	commandStorage := dataflow.NewValueStorage()
 	env := &Environment{
 		Config:         config,
 		ProjectTitle:   "Test Project",
@@ -212,7 +220,8 @@ func setupTestEnvironment(t *testing.T) *Environment {
 			return registerDb
 		},
 		StateStorage:   dataflow.NewValueStorage(),
-		CommandStorage: dataflow.NewValueStorage(),
+		CommandStorage: commandStorage,
+		Commands:       dataflow.NewCommandTracker(commandStorage),
 	}
 
 	return env
@@ -564,6 +573,25 @@ func TestValuesGetEndpoint(t *testing.T) {
 		assert.Equal(t, 25.5, response["Temperature"])
 	})
 
+	t.Run("okWithTime", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v2/views/public/devices/dev0/values?withTime=true", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+
+		var response valuesWithTime1DResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err, "Response should be valid JSON")
+
+		// Check if the value and its time are present
+		assert.Contains(t, response, "Temperature")
+		assert.Equal(t, 25.5, response["Temperature"].Value)
+		_, err = time.Parse(time.RFC3339Nano, string(response["Temperature"].Time))
+		assert.NoError(t, err, "Time should be in RFC3339 format")
+		assert.False(t, response["Temperature"].Restored, "A measured value must not be marked as restored")
+	})
+
 	t.Run("okPrivate", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/api/v2/views/private/devices/dev2/values", nil)
 		req.Header.Set("Authorization", token)
@@ -618,6 +646,29 @@ func TestValuesPatchEndpoint(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+
+		var response map[string]commandResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		command, ok := response["Setpoint"]
+		assert.True(t, ok, "Expected a command for Setpoint")
+		assert.NotEmpty(t, command.Id)
+		assert.Equal(t, "pending", command.State)
+
+		// the status is available at the commands endpoint
+		req, _ = http.NewRequest("GET", "/api/v2/views/public/devices/dev0/commands/"+command.Id, nil)
+		req.Header.Set("Authorization", token)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+		assert.Contains(t, w.Body.String(), command.Id)
+
+		req, _ = http.NewRequest("GET", "/api/v2/views/public/devices/dev0/commands/unknown", nil)
+		req.Header.Set("Authorization", token)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, "Expected status 404 Not Found")
 	})
 
 	t.Run("ForbiddenWithoutAuth", func(t *testing.T) {
@@ -683,3 +734,304 @@ func TestValuesPatchEndpoint(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code, "Expected status 404 Not Found")
 	})
 }
+
+// TestOverflowStatsEndpoint tests GET /api/v2/stats/overflow
+func TestOverflowStatsEndpoint(t *testing.T) {
+	env := setupTestEnvironment(t)
+	router := setupRouter(t, env)
+
+	t.Run("content", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v2/stats/overflow", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+
+		var response overflowStatsResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err, "Response should be valid JSON")
+
+		assert.Equal(t, dataflow.OverflowStats{}, response.StateStorage)
+		assert.Equal(t, dataflow.OverflowStats{}, response.CommandStorage)
+		assert.Equal(t, []string{"dev0", "dev1"}, slices.Sorted(maps.Keys(response.RegisterDbs)))
+	})
+
+	t.Run("contentAuthenticated", func(t *testing.T) {
+		token := setupToken(t, env)
+		req, _ := http.NewRequest("GET", "/api/v2/stats/overflow", nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+
+		var response overflowStatsResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err, "Response should be valid JSON")
+
+		assert.Equal(t, []string{"dev0", "dev1", "dev2"}, slices.Sorted(maps.Keys(response.RegisterDbs)))
+	})
+}
+
+// TestHistoryEndpoint tests GET /api/v2/views/{viewName}/devices/{deviceName}/history
+func TestHistoryEndpoint(t *testing.T) {
+	env := setupTestEnvironment(t)
+	env.History = dataflow.NewHistory(dataflow.HistoryConfig{
+		RawRetention:     time.Hour,
+		MinuteRetention:  time.Hour,
+		QuarterRetention: time.Hour,
+		Filter:           dataflow.AllValueFilter,
+	}, env.StateStorage)
+	router := setupRouter(t, env)
+
+	// Add values to the history
+	t0 := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	for _, devName := range []string{"dev0", "dev2"} {
+		for _, reg := range env.RegisterDbOfDevice(devName).GetAll() {
+			env.History.Add(dataflow.WithTime(dataflow.NewNumericRegisterValue(devName, reg, 20), t0))
+			env.History.Add(dataflow.WithTime(dataflow.NewNumericRegisterValue(devName, reg, 22), t0.Add(30*time.Second)))
+			env.History.Add(dataflow.WithTime(dataflow.NewNumericRegisterValue(devName, reg, 30), t0.Add(time.Minute)))
+		}
+	}
+
+	t.Run("okRaw", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v2/views/public/devices/dev0/history?register=Temperature", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+
+		var response map[string][]rawHistoryPointResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err, "Response should be valid JSON")
+
+		assert.Equal(t, []string{"Temperature"}, slices.Sorted(maps.Keys(response)))
+		assert.Equal(t, []rawHistoryPointResponse{
+			{Time: "2024-01-02T03:04:00Z", Value: 20},
+			{Time: "2024-01-02T03:04:30Z", Value: 22},
+			{Time: "2024-01-02T03:05:00Z", Value: 30},
+		}, response["Temperature"])
+	})
+
+	t.Run("okMinute", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v2/views/public/devices/dev0/history?resolution=1m&to=2024-01-02T03:04:59Z", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+
+		var response map[string][]aggregateHistoryPointResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err, "Response should be valid JSON")
+
+		assert.Equal(t, []string{"Setpoint", "Temperature"}, slices.Sorted(maps.Keys(response)))
+		assert.Equal(t, []aggregateHistoryPointResponse{
+			{Time: "2024-01-02T03:04:00Z", Min: 20, Max: 22, Avg: 21, Count: 2},
+		}, response["Temperature"])
+	})
+
+	t.Run("invalidResolution", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v2/views/public/devices/dev0/history?resolution=1h", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Expected status 422 for an invalid resolution")
+	})
+
+	t.Run("UnauthorizedPrivate", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v2/views/private/devices/dev2/history", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, "Expected status 403 Forbidden for private view without token")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		router := setupRouter(t, setupTestEnvironment(t))
+		req, _ := http.NewRequest("GET", "/api/v2/views/public/devices/dev0/history", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, "Expected status 404 when the history is disabled")
+	})
+}
+
+// TestConfigReloadEndpoint tests POST /api/v2/config/reload
+func TestConfigReloadEndpoint(t *testing.T) {
+	env := setupTestEnvironment(t)
+	var reloadErr error
+	env.Reload = func() ([]string, error) {
+		if reloadErr != nil {
+			return nil, reloadErr
+		}
+		return []string{"device:dev0"}, nil
+	}
+	router := setupRouter(t, env)
+	token := setupToken(t, env)
+
+	t.Run("ok", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v2/config/reload", nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+
+		var response reloadResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, []string{"device:dev0"}, response.Restarted)
+	})
+
+	t.Run("invalidConfig", func(t *testing.T) {
+		reloadErr = fmt.Errorf("config: Devices->dev0->Kind='Foo' is invalid")
+		defer func() { reloadErr = nil }()
+
+		req, _ := http.NewRequest("POST", "/api/v2/config/reload", nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Expected status 422 Unprocessable Entity")
+		assert.Contains(t, w.Body.String(), "Kind='Foo'")
+	})
+
+	t.Run("ForbiddenWithoutAuth", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v2/config/reload", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, "Expected status 403 Forbidden without authentication")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		router := setupRouter(t, setupTestEnvironment(t))
+		req, _ := http.NewRequest("POST", "/api/v2/config/reload", nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, "Expected status 404 when no reload function is given")
+	})
+}
+
+// mockDeviceAdmin implements the DeviceAdmin interface for testing
+type mockDeviceAdmin struct {
+	devices []DeviceStatus
+	actions []string
+}
+
+func (m *mockDeviceAdmin) Devices() []DeviceStatus { return m.devices }
+func (m *mockDeviceAdmin) StartDevice(name string) error {
+	m.actions = append(m.actions, "start:"+name)
+	return nil
+}
+func (m *mockDeviceAdmin) StopDevice(name string) error {
+	m.actions = append(m.actions, "stop:"+name)
+	return nil
+}
+func (m *mockDeviceAdmin) RestartDevice(name string) error {
+	m.actions = append(m.actions, "restart:"+name)
+	return nil
+}
+func (m *mockDeviceAdmin) AddDevices(fragment []byte) ([]string, error) {
+	if !bytes.Contains(fragment, []byte("HttpDevices")) {
+		return nil, fmt.Errorf("only device sections like HttpDevices are allowed")
+	}
+	return []string{"device:tcw241"}, nil
+}
+func (m *mockDeviceAdmin) RemoveDevice(name string) ([]string, error) {
+	m.actions = append(m.actions, "remove:"+name)
+	return []string{"device:" + name}, nil
+}
+
+// TestDeviceAdminEndpoints tests the /api/v2/admin/devices endpoints
+func TestDeviceAdminEndpoints(t *testing.T) {
+	env := setupTestEnvironment(t)
+	admin := &mockDeviceAdmin{
+		devices: []DeviceStatus{{Name: "dev0", Model: "Mock", Running: true}},
+	}
+	env.DeviceAdmin = admin
+	router := setupRouter(t, env)
+	token := setupToken(t, env)
+
+	t.Run("list", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v2/admin/devices", nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+
+		var response []DeviceStatus
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, admin.devices, response)
+	})
+
+	t.Run("actions", func(t *testing.T) {
+		admin.actions = nil
+		for _, action := range []string{"stop", "start", "restart"} {
+			req, _ := http.NewRequest("POST", "/api/v2/admin/devices/dev0/"+action, nil)
+			req.Header.Set("Authorization", token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK for "+action)
+			assert.Contains(t, w.Body.String(), `"name":"dev0"`)
+		}
+		assert.Equal(t, []string{"stop:dev0", "start:dev0", "restart:dev0"}, admin.actions)
+	})
+
+	t.Run("unknownDevice", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v2/admin/devices/unknown/restart", nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, "Expected status 404 Not Found")
+	})
+
+	t.Run("add", func(t *testing.T) {
+		body := []byte(`{"HttpDevices": {"tcw241": {"Url": "http://tcw241/", "Kind": "Teracom"}}}`)
+		req, _ := http.NewRequest("PUT", "/api/v2/admin/devices", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+		assert.Contains(t, w.Body.String(), "device:tcw241")
+
+		req, _ = http.NewRequest("PUT", "/api/v2/admin/devices", bytes.NewBuffer([]byte(`{"ProjectTitle": "foo"}`)))
+		req.Header.Set("Authorization", token)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Expected status 422 Unprocessable Entity")
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		admin.actions = nil
+		req, _ := http.NewRequest("DELETE", "/api/v2/admin/devices/dev0", nil)
+		req.Header.Set("Authorization", token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status 200 OK")
+		assert.Equal(t, []string{"remove:dev0"}, admin.actions)
+	})
+
+	t.Run("ForbiddenForNonAdmin", func(t *testing.T) {
+		otherToken, err := createJwtToken(env.Authentication, "other")
+		assert.NoError(t, err)
+
+		for _, tkn := range []string{"", otherToken} {
+			req, _ := http.NewRequest("POST", "/api/v2/admin/devices/dev0/restart", nil)
+			if len(tkn) > 0 {
+				req.Header.Set("Authorization", tkn)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code, "Expected status 403 Forbidden")
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package httpServer
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/pkg/errors"
+)
+
+type commandResponse struct {
+	Id       string        `json:"id" example:"3f2a9c1e5b7d4a60"`
+	Register string        `json:"register" example:"Relay"`
+	Value    valueResponse `json:"value"`
+	State    string        `json:"state" example:"confirmed"` // one of pending, sent, confirmed, failed
+	Error    string        `json:"error,omitempty"`
+	Created  timeResponse  `json:"created" example:"2024-01-02T03:04:05.678+01:00"`
+	Updated  timeResponse  `json:"updated" example:"2024-01-02T03:04:05.912+01:00"`
+}
+
+// commands1DResponse contains the status of each command sent by a PATCH request; the key is the register name.
+type commands1DResponse map[string]commandResponse
+
+// setupCommandGet godoc
+// @Summary Get command status
+// @Description Outputs the status of a command sent by PATCH /values or the mqtt command topic.
+// @Description A command is pending until the device picks it up, then it is sent and finally confirmed or failed.
+// @Description The status is kept for one hour after its last change.
+// @Param viewName path string true "View name as provided by the config endpoint"
+// @Param deviceName path string true "Device name as provided in devices array of the config endpoint"
+// @Param commandId path string true "Id as returned by PATCH /values"
+// @Produce json
+// @success 200 {object} commandResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /views/{viewName}/devices/{deviceName}/commands/{commandId} [get]
+// @Security ApiKeyAuth
+func setupCommandGet(mux *http.ServeMux, env *Environment) {
+	for _, view := range env.Views {
+		for _, dn := range view.Devices() {
+			pattern := "GET /api/v2/views/" + view.Name() + "/devices/" + dn.Name() + "/commands/{commandId}"
+			handler := commandGetHandler(env, view, dn.Name())
+
+			mux.HandleFunc(pattern, authJwtMiddleware(env, handler))
+			if env.Config.LogConfig() {
+				log.Printf("httpServer: %s -> serve command status", pattern)
+			}
+		}
+	}
+}
+
+func commandGetHandler(env *Environment, view ViewConfig, deviceName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// check authorization
+		if !isViewAuthenticated(view, r, false) {
+			jsonErrorResponse(w, http.StatusForbidden, errors.New("User is not allowed here"))
+			return
+		}
+
+		id := r.PathValue("commandId")
+		status, ok := env.Commands.Get(id)
+		if !ok || status.DeviceName != deviceName {
+			jsonErrorResponse(w, http.StatusNotFound, fmt.Errorf("command %s not found", id))
+			return
+		}
+
+		jsonGetResponse(w, r, createCommandResponse(status))
+	}
+}
+
+func createCommandResponse(s dataflow.CommandStatus) commandResponse {
+	return commandResponse{
+		Id:       s.Id,
+		Register: s.RegisterName,
+		Value:    s.Value,
+		State:    string(s.State),
+		Error:    s.Error,
+		Created:  getTimeResponse(s.Created),
+		Updated:  getTimeResponse(s.Updated),
+	}
+}
+
+func append2DCommandResponse(response map[string]map[string]commandResponse, s dataflow.CommandStatus) {
+	if _, ok := response[s.DeviceName]; !ok {
+		response[s.DeviceName] = make(map[string]commandResponse)
+	}
+	response[s.DeviceName][s.Id] = createCommandResponse(s)
+}
@@ -0,0 +1,141 @@
+package httpServer
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/pkg/errors"
+)
+
+type rawHistoryPointResponse struct {
+	Time  timeResponse `json:"time" example:"2024-01-02T03:04:05.678+01:00"`
+	Value float64      `json:"value" example:"12.3"`
+}
+
+type aggregateHistoryPointResponse struct {
+	Time  timeResponse `json:"time" example:"2024-01-02T03:00:00Z"`
+	Min   float64      `json:"min" example:"12.1"`
+	Max   float64      `json:"max" example:"12.6"`
+	Avg   float64      `json:"avg" example:"12.3"`
+	Count int          `json:"count" example:"60"`
+}
+
+// history1DResponse contains a list of points per register name;
+// the points are rawHistoryPointResponse or aggregateHistoryPointResponse depending on the resolution.
+type history1DResponse map[string]interface{}
+
+// setupHistory godoc
+// @Summary List history
+// @Description Outputs the short-term history of all numeric registers of a device.
+// @Description Raw values as well as 1-minute and 15-minute aggregates (min/max/avg) are available.
+// @Description How long they are kept is defined in the History section of the configuration.
+// @Param viewName path string true "View name as provided by the config endpoint"
+// @Param deviceName path string true "Device name as provided in devices array of the config endpoint"
+// @Param resolution query string false "One of raw, 1m, 15m; default raw"
+// @Param from query string false "Only return points at or after this time (RFC3339)"
+// @Param to query string false "Only return points at or before this time (RFC3339)"
+// @Param register query []string false "Only return the given registers" collectionFormat(multi)
+// @Produce json
+// @success 200 {object} history1DResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /views/{viewName}/devices/{deviceName}/history [get]
+// @Security ApiKeyAuth
+func setupHistory(mux *http.ServeMux, env *Environment) {
+	if env.History == nil {
+		return
+	}
+
+	for _, view := range env.Views {
+		for _, vd := range view.Devices() {
+			pattern := "GET /api/v2/views/" + view.Name() + "/devices/" + vd.Name() + "/history"
+			filter := getViewValueFilter([]ViewDeviceConfig{vd})
+			handler := historyHandler(env, view, filter)
+
+			mux.HandleFunc(pattern, authJwtMiddleware(env, gzipMiddleware(handler)))
+			if env.Config.LogConfig() {
+				log.Printf("httpServer: %s -> serve history", pattern)
+			}
+		}
+	}
+}
+
+func historyHandler(env *Environment, view ViewConfig, filter dataflow.ValueFilterFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// check authorization
+		if !isViewAuthenticated(view, r, true) {
+			jsonErrorResponse(w, http.StatusForbidden, errors.New("User is not allowed here"))
+			return
+		}
+
+		query := r.URL.Query()
+
+		resolution := dataflow.HistoryRaw
+		if s := query.Get("resolution"); len(s) > 0 {
+			var ok bool
+			if resolution, ok = dataflow.HistoryResolutionFromString(s); !ok {
+				jsonErrorResponse(w, http.StatusUnprocessableEntity, fmt.Errorf("invalid resolution='%s', use raw, 1m or 15m", s))
+				return
+			}
+		}
+
+		var from, to time.Time
+		for _, p := range []struct {
+			name string
+			t    *time.Time
+		}{{"from", &from}, {"to", &to}} {
+			if s := query.Get(p.name); len(s) > 0 {
+				t, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					jsonErrorResponse(w, http.StatusUnprocessableEntity, fmt.Errorf("invalid %s='%s', use RFC3339", p.name, s))
+					return
+				}
+				*p.t = t
+			}
+		}
+
+		registerFilter := filter
+		if registers := query["register"]; len(registers) > 0 {
+			registerFilter = func(v dataflow.Value) bool {
+				return slices.Contains(registers, v.Register().Name()) && filter(v)
+			}
+		}
+
+		series := env.History.Query(registerFilter, resolution, from, to)
+		w.Header().Set("Cache-Control", "no-cache")
+		jsonGetResponse(w, r, compile1DHistoryResponse(series, resolution))
+	}
+}
+
+func compile1DHistoryResponse(series []dataflow.HistorySeries, resolution dataflow.HistoryResolution) (response history1DResponse) {
+	response = make(history1DResponse, len(series))
+	for _, s := range series {
+		if resolution == dataflow.HistoryRaw {
+			points := make([]rawHistoryPointResponse, len(s.Points))
+			for i, p := range s.Points {
+				points[i] = rawHistoryPointResponse{
+					Time:  getTimeResponse(p.Time),
+					Value: p.Avg,
+				}
+			}
+			response[s.Register.Name()] = points
+		} else {
+			points := make([]aggregateHistoryPointResponse, len(s.Points))
+			for i, p := range s.Points {
+				points[i] = aggregateHistoryPointResponse{
+					Time:  getTimeResponse(p.Time),
+					Min:   p.Min,
+					Max:   p.Max,
+					Avg:   p.Avg,
+					Count: p.Count,
+				}
+			}
+			response[s.Register.Name()] = points
+		}
+	}
+	return
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/koestler/go-iotdevice/v3/dataflow"
@@ -15,10 +16,15 @@ import (
 type HttpServer struct {
 	config Config
 	server *http.Server
+	mux    atomic.Pointer[http.ServeMux]
 }
 
 type RegisterDbOfDeviceFunc func(deviceName string) *dataflow.RegisterDb
 
+// ReloadFunc reads the configuration file again and applies the changes.
+// It returns the names of the devices, mqtt clients and other parts which were restarted.
+type ReloadFunc func() (restarted []string, err error)
+
 type Environment struct {
 	Config             Config
 	ProjectTitle       string
@@ -27,6 +33,10 @@ type Environment struct {
 	RegisterDbOfDevice RegisterDbOfDeviceFunc
 	StateStorage       *dataflow.ValueStorage
 	CommandStorage     *dataflow.ValueStorage
+	Commands           *dataflow.CommandTracker
+	History            *dataflow.History // optional, nil when the history is disabled
+	Reload             ReloadFunc        // optional, nil disables the config reload endpoint
+	DeviceAdmin        DeviceAdmin       // optional, nil disables the device admin endpoints
 }
 
 type Config interface {
@@ -63,20 +73,24 @@ type AuthenticationConfig interface {
 	JwtSecret() []byte
 	JwtValidityPeriod() time.Duration
 	HtaccessFile() string
+	IsAdmin(user string) bool
 }
 
 func Run(env *Environment) (httpServer *HttpServer) {
 	cfg := env.Config
 
-	rootMux := http.NewServeMux()
-	addApiV2Routes(rootMux, env)
-	setupFrontend(rootMux, env.Config, env.Views)
-	setupValuesWs(rootMux, env)
+	httpServer = &HttpServer{
+		config: cfg,
+	}
+	httpServer.mux.Store(newRootMux(env))
 
 	server := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", cfg.Bind(), cfg.Port()),
-		Handler: loggingMiddleware(rootMux),
+		Addr: fmt.Sprintf("%s:%d", cfg.Bind(), cfg.Port()),
+		Handler: loggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			httpServer.mux.Load().ServeHTTP(w, r)
+		})),
 	}
+	httpServer.server = server
 
 	go func() {
 		if cfg.LogDebug() {
@@ -87,10 +101,23 @@ func Run(env *Environment) (httpServer *HttpServer) {
 		}
 	}()
 
-	return &HttpServer{
-		config: cfg,
-		server: server,
-	}
+	return
+}
+
+// Reload sets up all routes again using the given environment, e.g. after the views were changed.
+// Requests already being served, including open websocket connections, continue to use the old environment.
+// The Config of the environment is ignored; changing it requires to restart the server.
+func (s *HttpServer) Reload(env *Environment) {
+	env.Config = s.config
+	s.mux.Store(newRootMux(env))
+}
+
+func newRootMux(env *Environment) *http.ServeMux {
+	rootMux := http.NewServeMux()
+	addApiV2Routes(rootMux, env)
+	setupFrontend(rootMux, env.Config, env.Views)
+	setupValuesWs(rootMux, env)
+	return rootMux
 }
 
 func (s *HttpServer) Shutdown() {
@@ -63,6 +63,12 @@ type contextKey string
 
 const authUserKey contextKey = "AuthUser"
 
+// isAdmin returns true when the request was made by a logged-in user listed in the AdminUsers.
+func isAdmin(env *Environment, r *http.Request) bool {
+	user, _ := r.Context().Value(authUserKey).(string)
+	return len(user) > 0 && env.Authentication.IsAdmin(user)
+}
+
 func isViewAuthenticated(view ViewConfig, r *http.Request, allowAnonymous bool) bool {
 	user := ""
 	if val := r.Context().Value(authUserKey); val != nil {
@@ -19,6 +19,10 @@ type registerResponse struct {
 	Sort        int            `json:"sort" example:"100"`
 	Writable    bool           `json:"commandable" example:"false"` // json is kept at commandable for compatibility reasons
 	// consider changing when going to majer version 4
+	Min       *float64 `json:"min,omitempty" example:"0"`
+	Max       *float64 `json:"max,omitempty" example:"100"`
+	Step      *float64 `json:"step,omitempty" example:"0.5"`
+	Precision *int     `json:"precision,omitempty" example:"1"`
 }
 
 const RegistersExpires = 10 * time.Second
@@ -69,6 +73,7 @@ func registersHandler(env *Environment, view ViewConfig, deviceName string, filt
 }
 
 func createRegisterResponse(r dataflow.Register) registerResponse {
+	limits := r.Limits()
 	return registerResponse{
 		Category:    r.Category(),
 		Name:        r.Name(),
@@ -78,6 +83,10 @@ func createRegisterResponse(r dataflow.Register) registerResponse {
 		Unit:        r.Unit(),
 		Sort:        r.Sort(),
 		Writable:    r.Writable(),
+		Min:         limits.Min,
+		Max:         limits.Max,
+		Step:        limits.Step,
+		Precision:   limits.Precision,
 	}
 }
 
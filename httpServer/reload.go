@@ -0,0 +1,44 @@
+package httpServer
+
+import (
+	"log"
+	"net/http"
+)
+
+type reloadResponse struct {
+	Restarted []string `json:"restarted" example:"device:bmv0,mqttClient:local"`
+}
+
+// setupConfigReload godoc
+// @Summary Reload configuration
+// @Description Reads the configuration file again and restarts only the devices, mqtt clients, forwarders,
+// @Description views and routes affected by the changes. An invalid configuration is rejected and the
+// @Description running configuration is kept. Only available for the AdminUsers of the authentication.
+// @Produce json
+// @success 200 {object} reloadResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Router /config/reload [post]
+// @Security ApiKeyAuth
+func setupConfigReload(mux *http.ServeMux, env *Environment) {
+	if env.Reload == nil || !env.Authentication.Enabled() {
+		return
+	}
+
+	mux.HandleFunc("POST /api/v2/config/reload", authJwtMiddleware(env, adminMiddleware(env, configReloadHandler(env))))
+	if env.Config.LogConfig() {
+		log.Printf("httpServer: POST /api/v2/config/reload -> reload configuration")
+	}
+}
+
+func configReloadHandler(env *Environment) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		restarted, err := env.Reload()
+		if err != nil {
+			jsonErrorResponse(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		reloadedResponse(w, r, restarted)
+	}
+}
@@ -0,0 +1,57 @@
+package httpServer
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+type overflowStatsResponse struct {
+	StateStorage   dataflow.OverflowStats            `json:"stateStorage"`
+	CommandStorage dataflow.OverflowStats            `json:"commandStorage"`
+	RegisterDbs    map[string]dataflow.OverflowStats `json:"registerDbs"`
+}
+
+// setupOverflowStats godoc
+// @Summary Subscription overflow statistics
+// @Description Counts how many values / registers were dropped because a subscriber (e.g. a websocket client
+// @Description or an mqtt forwarder) was too slow and how many subscribers were disconnected for this reason.
+// @Description The register databases are only listed for devices of views the user is allowed to see.
+// @Produce json
+// @success 200 {object} overflowStatsResponse
+// @Router /stats/overflow [get]
+func setupOverflowStats(mux *http.ServeMux, env *Environment) {
+	mux.HandleFunc("GET /api/v2/stats/overflow", authJwtMiddleware(env, gzipMiddleware(overflowStatsHandler(env))))
+	if env.Config.LogConfig() {
+		log.Printf("httpServer: GET /api/v2/stats/overflow -> serve overflow stats")
+	}
+}
+
+func overflowStatsHandler(env *Environment) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := overflowStatsResponse{
+			StateStorage:   env.StateStorage.OverflowStats(),
+			CommandStorage: env.CommandStorage.OverflowStats(),
+			RegisterDbs:    make(map[string]dataflow.OverflowStats),
+		}
+
+		for _, v := range env.Views {
+			// do not leak the device names of protected views
+			if !isViewAuthenticated(v, r, true) {
+				continue
+			}
+			for _, vd := range v.Devices() {
+				if _, ok := response.RegisterDbs[vd.Name()]; ok {
+					continue
+				}
+				if rdb := env.RegisterDbOfDevice(vd.Name()); rdb != nil {
+					response.RegisterDbs[vd.Name()] = rdb.OverflowStats()
+				}
+			}
+		}
+
+		w.Header().Set("Cache-Control", "no-cache")
+		jsonGetResponse(w, r, response)
+	}
+}
@@ -3,7 +3,9 @@ package httpServer
 import (
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"time"
 
 	"github.com/koestler/go-iotdevice/v3/dataflow"
 	"github.com/pkg/errors"
@@ -12,13 +14,28 @@ import (
 type valueResponse interface{}
 type values1DResponse map[string]valueResponse
 
+// timeResponse is the measurement time of a value in RFC3339 format including fractional seconds.
+type timeResponse string
+
+type valueWithTimeResponse struct {
+	Value valueResponse `json:"value"`
+	Time  timeResponse  `json:"time,omitempty" example:"2024-01-02T03:04:05.678+01:00"`
+	// Restored is set for values restored from disk until the device delivers a fresh value
+	Restored bool `json:"restored,omitempty"`
+}
+type valuesWithTime1DResponse map[string]valueWithTimeResponse
+
 // setupValuesGetJson godoc
 // @Summary List values
 // @Description Outputs the latest values of all the registers of a device.
+// @Description When withTime=true is given, each value is returned as an object containing the value and its measurement time.
+// @Description Such objects also mark values restored from disk that were not yet confirmed by the device.
 // @Param viewName path string true "View name as provided by the config endpoint"
 // @Param deviceName path string true "Device name as provided in devices array of the config endpoint"
+// @Param withTime query bool false "Include the measurement time of each value"
 // @Produce json
 // @success 200 {object} values1DResponse
+// @success 200 {object} valuesWithTime1DResponse
 // @Failure 404 {object} ErrorResponse
 // @Router /views/{viewName}/devices/{deviceName}/values [get]
 // @Security ApiKeyAuth
@@ -47,6 +64,10 @@ func valuesGetJsonHandler(env *Environment, view ViewConfig, filter dataflow.Val
 			return
 		}
 		values := env.StateStorage.GetStateFiltered(filter)
+		if r.URL.Query().Get("withTime") == "true" {
+			jsonGetResponse(w, r, compile1DValueWithTimeResponse(values))
+			return
+		}
 		jsonGetResponse(w, r, compile1DValueResponse(values))
 	}
 }
@@ -54,11 +75,15 @@ func valuesGetJsonHandler(env *Environment, view ViewConfig, filter dataflow.Val
 // setupValuesPatch godoc
 // @Summary Set value
 // @Description Sets a writable register to a certain value.
+// @Description Values outside of the min / max / step limits of the register or unknown enum indexes are rejected;
+// @Description more decimals than given by the precision are accepted.
+// @Description Returns the id and status of the command sent for each register; see the commands endpoint.
 // @Param viewName path string true "View name as provided by the config endpoint"
 // @Param deviceName path string true "Device name as provided in devices array of the config endpoint"
 // @Produce json
-// @success 200
+// @success 200 {object} commands1DResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
 // @Router /views/{viewName}/devices/{deviceName}/values [patch]
 // @Security ApiKeyAuth
 func setupValuesPatch(mux *http.ServeMux, env *Environment) {
@@ -107,38 +132,49 @@ func valuesPatchHandler(env *Environment, view ViewConfig, deviceName string) ht
 				jsonErrorResponse(w, http.StatusUnprocessableEntity, fmt.Errorf("expect type of %s to be a %s", registerName, t))
 			}
 
+			var input dataflow.Value
 			switch register.RegisterType() {
 			case dataflow.TextRegister:
 				if v, ok := value.(string); ok {
-					inputs = append(inputs, dataflow.NewTextRegisterValue(deviceName, register, v))
+					input = dataflow.NewTextRegisterValue(deviceName, register, v)
 				} else {
 					invalidType("string")
 					return
 				}
 			case dataflow.NumberRegister:
 				if v, ok := value.(float64); ok {
-					inputs = append(inputs, dataflow.NewNumericRegisterValue(deviceName, register, v))
+					input = dataflow.NewNumericRegisterValue(deviceName, register, v)
 				} else {
 					invalidType("float")
 					return
 				}
 
 			case dataflow.EnumRegister:
-				if v, ok := value.(float64); ok {
-					inputs = append(inputs, dataflow.NewEnumRegisterValue(deviceName, register, int(v)))
+				if v, ok := value.(float64); ok && v == math.Trunc(v) {
+					input = dataflow.NewEnumRegisterValue(deviceName, register, int(v))
 				} else {
-					invalidType("float")
+					invalidType("integer")
 					return
 				}
+			default:
+				continue
 			}
+
+			// check the value against the enum and the limits of the register
+			if err := dataflow.ValidateCommand(input); err != nil {
+				jsonErrorResponse(w, http.StatusUnprocessableEntity, err)
+				return
+			}
+			inputs = append(inputs, input)
 		}
 
 		// all ok, send inputs to storage
+		response := make(commands1DResponse, len(inputs))
 		for _, inp := range inputs {
-			env.CommandStorage.Fill(inp)
+			response[inp.Register().Name()] = createCommandResponse(env.Commands.Fill(inp))
 		}
 
-		w.WriteHeader(http.StatusOK)
+		jsonGetResponse(w, r, response)
 	}
 }
 
@@ -150,6 +186,25 @@ func compile1DValueResponse(values []dataflow.Value) (response values1DResponse)
 	return
 }
 
+func compile1DValueWithTimeResponse(values []dataflow.Value) (response valuesWithTime1DResponse) {
+	response = make(map[string]valueWithTimeResponse, len(values))
+	for _, value := range values {
+		response[value.Register().Name()] = valueWithTimeResponse{
+			Value:    value.GenericValue(),
+			Time:     getTimeResponse(value.Time()),
+			Restored: value.Restored(),
+		}
+	}
+	return
+}
+
+func getTimeResponse(t time.Time) timeResponse {
+	if t.IsZero() {
+		return ""
+	}
+	return timeResponse(t.Format(time.RFC3339Nano))
+}
+
 func append2DValueResponse(response map[string]map[string]valueResponse, value dataflow.Value) {
 	d0 := value.DeviceName()
 	d1 := value.Register().Name()
@@ -161,6 +216,17 @@ func append2DValueResponse(response map[string]map[string]valueResponse, value d
 	response[d0][d1] = value.GenericValue()
 }
 
+func append2DTimeResponse(response map[string]map[string]timeResponse, value dataflow.Value) {
+	d0 := value.DeviceName()
+	d1 := value.Register().Name()
+
+	if _, ok := response[d0]; !ok {
+		response[d0] = make(map[string]timeResponse)
+	}
+
+	response[d0][d1] = getTimeResponse(value.Time())
+}
+
 func getViewValueFilter(viewDevices []ViewDeviceConfig) dataflow.ValueFilterFunc {
 	filters := make(map[string]dataflow.ValueFilterFunc)
 	for _, vd := range viewDevices {
@@ -17,10 +17,13 @@ import (
 const wsSendTimeout = 5 * time.Second
 const wsSendInterval = 250 * time.Millisecond
 
-// registers / values maps use deviceName as the first dimension and registerName as the second dimension.
+// registers / values / times maps use deviceName as the first dimension and registerName as the second dimension.
+// commands map uses deviceName as the first dimension and the command id as the second dimension.
 type outputMessage struct {
 	Registers map[string]map[string]registerResponse `json:"registers,omitempty"`
 	Values    map[string]map[string]valueResponse    `json:"values,omitempty"`
+	Times     map[string]map[string]timeResponse     `json:"times,omitempty"`
+	Commands  map[string]map[string]commandResponse  `json:"commands,omitempty"`
 }
 
 type authMessage struct {
@@ -30,6 +33,7 @@ type authMessage struct {
 // setupViewWs godoc
 // @Summary Realtime values websocket
 // @Description Websocket that sends all registers and values initially and sends updates of changed values subsequently.
+// @Description Status changes of commands sent to the devices of the view are sent as well.
 // @Param viewName path string true "View name as provided by the config endpoint"
 // @Produce json
 // @success 200 {array} outputMessage
@@ -52,6 +56,10 @@ func setupValuesWs(mux *http.ServeMux, env *Environment) {
 func wsHandler(env *Environment, view ViewConfig, pattern string) http.HandlerFunc {
 	logPrefix := fmt.Sprintf("httpServer: %s", pattern)
 	viewFilter := getViewValueFilter(view.Devices())
+	viewDevices := make(map[string]struct{}, len(view.Devices()))
+	for _, d := range view.Devices() {
+		viewDevices[d.Name()] = struct{}{}
+	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		var websocketAcceptOptions = websocket.AcceptOptions{
@@ -92,7 +100,7 @@ func wsHandler(env *Environment, view ViewConfig, pattern string) http.HandlerFu
 		defer senderCancel()
 
 		startValueSenderOnce := sync.OnceFunc(func() {
-			startValuesSender(senderCtx, env, viewFilter, conn, logPrefix)
+			startValuesSender(senderCtx, env, viewFilter, viewDevices, conn, logPrefix)
 		})
 
 		if view.IsPublic() {
@@ -130,6 +138,7 @@ func startValuesSender(
 	ctx context.Context,
 	env *Environment,
 	viewFilter dataflow.ValueFilterFunc,
+	viewDevices map[string]struct{},
 	conn *websocket.Conn,
 	logPrefix string,
 
@@ -142,11 +151,27 @@ func startValuesSender(
 	pv := &packedValues{
 		registers: make(map[string]map[string]registerResponse),
 		values:    make(map[string]map[string]valueResponse),
+		times:     make(map[string]map[string]timeResponse),
+		commands:  make(map[string]map[string]commandResponse),
 	}
 
+	// subscribe to the status changes of commands
+	go func() {
+		updates := env.Commands.Subscribe(ctx, func(s dataflow.CommandStatus) bool {
+			_, ok := viewDevices[s.DeviceName]
+			return ok
+		})
+		for s := range updates {
+			pv.mu.Lock()
+			append2DCommandResponse(pv.commands, s)
+			pv.mu.Unlock()
+		}
+	}()
+
 	// subscribe to the storage and update the packet values
 	go func() {
-		subscription := env.StateStorage.SubscribeSendInitial(ctx, viewFilter)
+		// a client that cannot keep up is disconnected; it receives the full state again when it reconnects
+		subscription := env.StateStorage.SubscribeSendInitialWithDeadband(ctx, viewFilter, dataflow.OverflowDisconnect)
 
 		sentRegisters := make(map[string]map[string]registerResponse)
 
@@ -159,8 +184,16 @@ func startValuesSender(
 				append2DRegisterResponse(pv.registers, v)
 			}
 			append2DValueResponse(pv.values, v)
+			append2DTimeResponse(pv.times, v)
 			pv.mu.Unlock()
 		}
+
+		if ctx.Err() == nil {
+			log.Printf("%s: client too slow, disconnect after %d dropped values", logPrefix, subscription.Dropped())
+			if err := conn.Close(websocket.StatusTryAgainLater, "too slow"); err != nil && env.Config.LogDebug() {
+				log.Printf("%s: error during close: %s", logPrefix, err)
+			}
+		}
 	}()
 
 	// send the packet values to the websocket connection
@@ -202,22 +235,28 @@ type packedValues struct {
 	mu        sync.Mutex
 	registers map[string]map[string]registerResponse
 	values    map[string]map[string]valueResponse
+	times     map[string]map[string]timeResponse
+	commands  map[string]map[string]commandResponse
 }
 
 func (pv *packedValues) encodeAndReset() (msg []byte, err error) {
 	pv.mu.Lock()
 	defer pv.mu.Unlock()
 
-	if len(pv.registers) == 0 && len(pv.values) == 0 {
+	if len(pv.registers) == 0 && len(pv.values) == 0 && len(pv.commands) == 0 {
 		return nil, nil // nothing to send
 	}
 
 	defer clear(pv.registers)
 	defer clear(pv.values)
+	defer clear(pv.times)
+	defer clear(pv.commands)
 
 	om := outputMessage{
 		Registers: pv.registers,
 		Values:    pv.values,
+		Times:     pv.times,
+		Commands:  pv.commands,
 	}
 
 	return json.Marshal(om)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/jessevdk/go-flags"
 	"github.com/koestler/go-iotdevice/v3/config"
+	"github.com/koestler/go-iotdevice/v3/dataflow"
 )
 
 // is set through linker by build.sh
@@ -17,23 +19,31 @@ var buildVersion string
 var buildTime string
 
 type CmdOptions struct {
-	Version    bool           `long:"version" description:"Print the build version and timestamp"`
-	Config     flags.Filename `short:"c" long:"config" description:"Config File in yaml format" default:"./config.yaml"`
-	DryRun     bool           `short:"d" long:"dry-run" description:"Read and check the config and exit."`
-	CpuProfile flags.Filename `long:"cpuprofile" description:"write cpu profile to <file>"`
-	MemProfile flags.Filename `long:"memprofile" description:"write memory profile to <file>"`
+	Version     bool           `long:"version" description:"Print the build version and timestamp"`
+	PrintSchema bool           `long:"print-schema" description:"Print a JSON Schema of the config file and exit"`
+	Config      flags.Filename `short:"c" long:"config" description:"Config File in yaml format" default:"./config.yaml"`
+	DryRun      bool           `short:"d" long:"dry-run" description:"Read and check the config and exit."`
+	CpuProfile  flags.Filename `long:"cpuprofile" description:"write cpu profile to <file>"`
+	MemProfile  flags.Filename `long:"memprofile" description:"write memory profile to <file>"`
+
+	Scan ScanCommand `command:"scan" description:"Find VE.Direct and Modbus devices on the serial ports and print their configuration"`
+	Read ReadCommand `command:"read" description:"Start a single device, print the values of its registers and exit"`
+
+	command string // the name of the given subcommand, empty when running the server
 }
 
 const (
 	ExitSuccess         = 0
 	ExitDueToCmdOptions = 1
 	ExitDueToConfig     = 2
+	ExitDueToDevice     = 3
 )
 
 func getCmdOptions() (cmdOptions CmdOptions, cmdName string) {
 	// parse command line options
 	parser := flags.NewParser(&cmdOptions, flags.Default)
 	parser.Usage = "[-c <path to yaml config file>]"
+	parser.SubcommandsOptional = true
 	if _, err := parser.Parse(); err != nil {
 		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
 			os.Exit(ExitSuccess)
@@ -52,6 +62,20 @@ func getCmdOptions() (cmdOptions CmdOptions, cmdName string) {
 		os.Exit(ExitSuccess)
 	}
 
+	if cmdOptions.PrintSchema {
+		schema, err := config.JsonSchema()
+		if err != nil {
+			log.Printf("config: cannot generate schema: %s", err)
+			os.Exit(ExitDueToConfig)
+		}
+		fmt.Println(string(schema))
+		os.Exit(ExitSuccess)
+	}
+
+	if parser.Active != nil {
+		cmdOptions.command = parser.Active.Name
+	}
+
 	return cmdOptions, parser.Name
 }
 
@@ -65,7 +89,8 @@ func getConfig(cmdOptions CmdOptions, cmdName string) *config.Config {
 		os.Exit(ExitDueToConfig)
 	}
 
-	if cfg.LogConfig() {
+	// the read command prints values only; its output must not be buried in the config
+	if cfg.LogConfig() && cmdOptions.command != "read" {
 		if err := cfg.PrintConfig(); err != nil {
 			log.Printf("config: cannot print: %s", err)
 		}
@@ -77,12 +102,22 @@ func getConfig(cmdOptions CmdOptions, cmdName string) *config.Config {
 func main() {
 	// read cmd parameters and configuration file; on error: os.Exit
 	cmdOptions, cmdName := getCmdOptions()
+	if cmdOptions.command == "scan" {
+		os.Exit(runScan(cmdOptions.Scan))
+	}
+
 	cfg := getConfig(cmdOptions, cmdName)
+	if cmdOptions.command == "read" {
+		os.Exit(runRead(cfg, cmdOptions.Read))
+	}
 	if cmdOptions.DryRun {
 		os.Exit(ExitSuccess)
 		return
 	}
 
+	// explain which glob / regexp filter entry decided about a register
+	dataflow.LogFilterPatterns(cfg.LogConfig())
+
 	// call defer statements before os.Exit
 	exitCode := func() (exitCode int) {
 		if cfg.LogWorkerStart() {
@@ -102,6 +137,13 @@ func main() {
 		stateStorage := runStorage(stateStorageLogPrefix)
 		defer stateStorage.Shutdown()
 
+		// do not publish small numeric changes
+		setupDeadband(cfg, stateStorage)
+
+		// remove values not updated within their MaxAge
+		expirerCtx, expirerCancel := context.WithCancel(context.Background())
+		runValueExpirer(expirerCtx, cfg, stateStorage)
+
 		commandStorageLogPrefix := ""
 		if cfg.LogCommandStorageDebug() {
 			commandStorageLogPrefix = "commandStorage"
@@ -109,8 +151,37 @@ func main() {
 		commandStorage := runStorage(commandStorageLogPrefix)
 		defer commandStorage.Shutdown()
 
+		// assign an id to every command and keep track of its state
+		commands := dataflow.NewCommandTracker(commandStorage)
+
+		// convert values of devices with register transforms into the configured units
+		setupTransform(cfg, stateStorage, commandStorage)
+
+		// the persistence, the history and the recorder follow the devices of the running configuration
+		devices := newRunningDevices(cfg)
+
+		// restore persisted values and commands
+		persisters, persistedCommands := runPersistence(cfg, devices, stateStorage, commandStorage)
+		defer func() {
+			for _, p := range persisters {
+				p.Shutdown()
+			}
+		}()
+
+		// record a short-term history of all numeric values
+		history := runHistory(cfg, devices, stateStorage)
+		if history != nil {
+			defer history.Shutdown()
+		}
+
+		// append all values to a recording which can be replayed by replay devices
+		recorder := runRecorder(cfg, devices, stateStorage)
+		if recorder != nil {
+			defer recorder.Shutdown()
+		}
+
 		// start modbus device handlers
-		modbusPool := runModbus(cfg)
+		modbusPool := runModbus(cfg, selectAll)
 		defer modbusPool.Shutdown()
 
 		// start device pool
@@ -118,27 +189,73 @@ func main() {
 		defer devicePool.Shutdown()
 
 		// start non mqtt devices
-		runNonMqttGensetDevices(cfg, devicePool, modbusPool, stateStorage, commandStorage)
+		runNonMqttGensetDevices(cfg, devicePool, modbusPool, stateStorage, commandStorage, selectAll, true)
 
 		// start mqtt client pool
 		mqttClientPool := runMqttClient(cfg)
 		defer mqttClientPool.Shutdown()
 
 		// start mqtt clients
-		runMqttDevices(cfg, devicePool, mqttClientPool, stateStorage, commandStorage)
+		runMqttDevices(cfg, devicePool, mqttClientPool, stateStorage, commandStorage, selectAll)
 
 		// start mqtt forwarders
-		runMqttForwarders(cfg, devicePool, mqttClientPool, stateStorage, commandStorage)
+		forwarderStop := runMqttForwarders(cfg, devicePool, mqttClientPool, stateStorage, commands, selectAll)
 
 		// start genset devices
-		runGensetDevices(cfg, devicePool, stateStorage, commandStorage)
+		runGensetDevices(cfg, devicePool, stateStorage, commandStorage, selectAll)
 
-		// start http server
-		httpServer := runHttpServer(cfg, devicePool, stateStorage, commandStorage)
-		if httpServer != nil {
-			defer httpServer.Shutdown()
+		// start computed devices
+		runComputedDevices(cfg, devicePool, stateStorage, selectAll)
+
+		// start energy devices
+		runEnergyDevices(cfg, devicePool, stateStorage, selectAll)
+
+		// start alarm devices
+		runAlarmDevices(cfg, devicePool, stateStorage, commandStorage, selectAll)
+
+		// start automation devices
+		runAutomationDevices(cfg, devicePool, stateStorage, commandStorage, commands, selectAll)
+
+		// start scheduler devices
+		runSchedulerDevices(cfg, devicePool, stateStorage, commandStorage, commands, selectAll)
+
+		// send persisted commands to the devices once they are available
+		replayCtx, replayCancel := context.WithCancel(context.Background())
+		defer replayCancel()
+		replayCommands(replayCtx, cfg, devicePool, commandStorage, persistedCommands)
+
+		// apply configuration changes on SIGHUP or when requested through the http api
+		rl := &reloader{
+			cmdName:        cmdName,
+			configPath:     string(cmdOptions.Config),
+			cfg:            cfg,
+			stateStorage:   stateStorage,
+			commandStorage: commandStorage,
+			commands:       commands,
+			history:        history,
+			devices:        devices,
+			modbusPool:     modbusPool,
+			devicePool:     devicePool,
+			mqttClientPool: mqttClientPool,
+			expirerCancel:  expirerCancel,
+			forwarderStop:  forwarderStop,
 		}
 
+		// start http server
+		rl.runHttpServer()
+		defer rl.Shutdown()
+
+		// setup SIGHUP handler
+		reloadSignal := make(chan os.Signal, 1)
+		signal.Notify(reloadSignal, syscall.SIGHUP)
+		go func() {
+			for range reloadSignal {
+				log.Printf("main: reload configuration; caught signal: SIGHUP")
+				// errors are logged by Reload; the running configuration is kept
+				_, _ = rl.Reload()
+			}
+		}()
+
 		// setup SIGTERM, SIGINT handlers
 		gracefulStop := make(chan os.Signal, 1)
 		signal.Notify(gracefulStop, syscall.SIGTERM)
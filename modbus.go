@@ -9,11 +9,16 @@ import (
 
 func runModbus(
 	cfg *config.Config,
+	selected nameSelection,
 ) (modbusPool *pool.Pool[*modbus.ModbusStruct]) {
 	// run pool
 	modbusPool = pool.RunPool[*modbus.ModbusStruct]()
 
 	for _, mbCfg := range cfg.Modbus() {
+		if !selected(mbCfg.Name()) {
+			continue
+		}
+
 		if cfg.LogWorkerStart() {
 			log.Printf(
 				"modbus[%s]: start: device='%s', baudRate=%d, readTimeout=%s",
@@ -17,6 +17,7 @@ type Config interface {
 	RelayOpenLabel(name string) string
 	RelayClosedLabel(name string) string
 	PollInterval() time.Duration
+	CommandReadback() device.ReadbackConfig
 }
 
 type Modbus interface {
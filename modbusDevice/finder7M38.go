@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"github.com/koestler/go-iotdevice/v3/dataflow"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -185,18 +186,20 @@ var invalidEnum = map[int]string{
 	1: "invalid",
 }
 
+var finderModelNumberRegister = NewFinderRegister(
+	"Device Info",
+	"ModelNumber",
+	"Model Number",
+	FinderTStr16,
+	30001, 30008,
+	nil, -1,
+	"",
+	600,
+)
+
 var RegisterList7M38 = func() []FinderRegister {
 	productRegisters := []FinderRegister{
-		NewFinderRegister(
-			"Device Info",
-			"ModelNumber",
-			"Model Number",
-			FinderTStr16,
-			30001, 30008,
-			nil, -1,
-			"",
-			600,
-		),
+		finderModelNumberRegister,
 		NewFinderRegister(
 			"Device Info",
 			"SerialNumber",
@@ -425,6 +428,24 @@ func FinderReadInputRegisters(c *DeviceStruct, register FinderRegister) (respons
 }
 
 func FinderReadInputRegistersRaw(c *DeviceStruct, register FinderRegister) (response []byte, err error) {
+	begin := time.Now()
+	response, err = finderReadInputRegisters(c.modbus.WriteRead, c.modbusConfig.Address(), register)
+	if c.Config().LogDebug() {
+		log.Printf("FinderReadInputRegisters: callFunction: took=%s", time.Since(begin))
+	}
+	return
+}
+
+// FinderReadModelNumber reads the model number of the device, e.g. to find Finder devices on a bus.
+func FinderReadModelNumber(writeRead WriteReadBusFunc, deviceAddress byte) (model string, err error) {
+	response, err := finderReadInputRegisters(writeRead, deviceAddress, finderModelNumberRegister)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(response), "\x00 "), nil
+}
+
+func finderReadInputRegisters(writeRead WriteReadBusFunc, deviceAddress byte, register FinderRegister) (response []byte, err error) {
 	var requestPayload bytes.Buffer
 
 	// write starting register
@@ -441,18 +462,13 @@ func FinderReadInputRegistersRaw(c *DeviceStruct, register FinderRegister) (resp
 	// finder registers are 16 bit wide
 	responsePayloadLength := register.CountBytes()
 
-	begin := time.Now()
 	response, err = callFunction(
-		c.modbus.WriteRead,
-		c.modbusConfig.Address(),
+		writeRead,
+		deviceAddress,
 		FinderFunctionReadInputRegisters,
 		requestPayload.Bytes(),
 		1+responsePayloadLength, // 1 byte for byte count + payload
 	)
-	if c.Config().LogDebug() {
-		log.Printf("FinderReadInputRegisters: callFunction: took=%s", time.Since(begin))
-	}
-
 	if err != nil {
 		return
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
 	"github.com/pkg/errors"
 	"log"
 	"regexp"
@@ -26,19 +27,24 @@ func runWaveshareRtuRelay8(ctx context.Context, c *DeviceStruct) (err error, imm
 	registers = dataflow.FilterRegisters(registers, c.Config().Filter())
 	c.RegisterDb().AddStruct(registers...)
 
-	if err := c.execPoll(registers); err != nil {
+	// commands not yet read back when this routine stops are reported as failed
+	readback := device.NewReadback(c.modbusConfig.CommandReadback(), c.commandStorage)
+	defer readback.Stop()
+
+	if err := c.execPoll(registers, readback); err != nil {
 		return err, true
 	}
 
+	// setup subscription to listen for updates of writable registers
+	// before announcing the availability such that no command sent on availability is lost
+	_, commandSubscription := c.commandStorage.SubscribeReturnInitial(ctx, dataflow.DeviceNonNullValueFilter(c.Config().Name()))
+
 	// send connected now, disconnected when this routine stops
 	c.SetAvailable(true)
 	defer func() {
 		c.SetAvailable(false)
 	}()
 
-	// setup subscription to listen for updates of writable registers
-	_, commandSubscription := c.commandStorage.SubscribeReturnInitial(ctx, dataflow.DeviceNonNullValueFilter(c.Config().Name()))
-
 	ticker := time.NewTicker(c.modbusConfig.PollInterval())
 	defer ticker.Stop()
 	for {
@@ -46,16 +52,22 @@ func runWaveshareRtuRelay8(ctx context.Context, c *DeviceStruct) (err error, imm
 		case <-ctx.Done():
 			return nil, false
 		case <-ticker.C:
-			if err := c.execPoll(registers); err != nil {
+			if err := c.execPoll(registers, readback); err != nil {
 				return err, false
 			}
+			for _, value := range readback.Retry(time.Now()) {
+				log.Printf("waveshareDevice[%s]: command %s not read back, write again", c.Name(), value.String())
+				if err := c.writeRelay(value); err != nil {
+					log.Printf("waveshareDevice[%s]: command retry failed: %s", c.Name(), err)
+				}
+			}
 		case value := <-commandSubscription.Drain():
-			c.execCommand(value)
+			c.execCommand(value, readback)
 		}
 	}
 }
 
-func (c *DeviceStruct) execPoll(registers []dataflow.RegisterStruct) error {
+func (c *DeviceStruct) execPoll(registers []dataflow.RegisterStruct, readback *device.Readback) error {
 	start := time.Now()
 
 	// fetch registers
@@ -72,11 +84,9 @@ func (c *DeviceStruct) execPoll(registers []dataflow.RegisterStruct) error {
 			}
 		}
 
-		c.StateStorage().Fill(dataflow.NewEnumRegisterValue(
-			c.Name(),
-			register,
-			value,
-		))
+		v := dataflow.NewEnumRegisterValue(c.Name(), register, value)
+		readback.Observe(v)
+		c.StateStorage().Fill(v)
 	}
 
 	if c.Config().LogDebug() {
@@ -90,7 +100,7 @@ func (c *DeviceStruct) execPoll(registers []dataflow.RegisterStruct) error {
 	return nil
 }
 
-func (c *DeviceStruct) execCommand(value dataflow.Value) {
+func (c *DeviceStruct) execCommand(value dataflow.Value, readback *device.Readback) {
 	if c.Config().LogDebug() {
 		log.Printf(
 			"waveshareDevice[%s]: value command: %s",
@@ -98,10 +108,46 @@ func (c *DeviceStruct) execCommand(value dataflow.Value) {
 		)
 	}
 
+	// reset the command; this allows the same command (e.g. toggle) to be sent again
+	defer c.commandStorage.Fill(dataflow.NewNullRegisterValue(c.Config().Name(), value.Register()))
+
+	c.commandStorage.CommandSent(value)
+	if err := c.writeRelay(value); err != nil {
+		c.commandStorage.CommandDone(value, err)
+		log.Printf(
+			"waveshareDevice[%s]: command request genration failed: %s",
+			c.Config().Name(), err,
+		)
+		return
+	}
+
+	readback.Written(value, time.Now())
+	if readback.Enabled() {
+		// the state is updated and the command confirmed by the next polls
+		if c.Config().LogDebug() {
+			log.Printf("waveshareDevice[%s]: command request successful, wait for readback", c.Config().Name())
+		}
+		return
+	}
+
+	// set the current state immediately after a successful write
+	c.StateStorage().Fill(dataflow.NewEnumRegisterValue(
+		c.Name(),
+		value.Register(),
+		value.(dataflow.EnumRegisterValue).EnumIdx(),
+	))
+
+	if c.Config().LogDebug() {
+		log.Printf("waveshareDevice[%s]: command request successful", c.Config().Name())
+	}
+}
+
+// writeRelay opens or closes the relay given by the register of the enum value.
+func (c *DeviceStruct) writeRelay(value dataflow.Value) error {
 	enumValue, ok := value.(dataflow.EnumRegisterValue)
 	if !ok {
 		// unable to handle non enum value
-		return
+		return errors.New("expect an enum value")
 	}
 
 	var command Command
@@ -111,11 +157,11 @@ func (c *DeviceStruct) execCommand(value dataflow.Value) {
 	case 1:
 		command = RelayClose
 	default:
-		return
+		return fmt.Errorf("invalid enum index %d", enumValue.EnumIdx())
 	}
 
-	var relayNr uint16
-	if address, err := waveshareRtuRelay8RegisterAddress(value.Register()); err != nil {
+	address, err := waveshareRtuRelay8RegisterAddress(value.Register())
+	if err != nil {
 		if c.Config().LogDebug() {
 			log.Printf("waveshareDevice[%s]: cannot get register address, register=%v, err: %s",
 				c.Config().Name(),
@@ -123,10 +169,9 @@ func (c *DeviceStruct) execCommand(value dataflow.Value) {
 			)
 		}
 		// unknown register
-		return
-	} else {
-		relayNr = uint16(address)
+		return err
 	}
+	relayNr := uint16(address)
 
 	if c.Config().LogDebug() {
 		log.Printf(
@@ -135,26 +180,7 @@ func (c *DeviceStruct) execCommand(value dataflow.Value) {
 		)
 	}
 
-	if err := WaveshareWriteRelay(c.modbus.WriteRead, c.modbusConfig.Address(), relayNr, command); err != nil {
-		log.Printf(
-			"waveshareDevice[%s]: command request genration failed: %s",
-			c.Config().Name(), err,
-		)
-	} else {
-		// set the current state immediately after a successful write
-		c.StateStorage().Fill(dataflow.NewEnumRegisterValue(
-			c.Name(),
-			value.Register(),
-			enumValue.EnumIdx(),
-		))
-
-		if c.Config().LogDebug() {
-			log.Printf("waveshareDevice[%s]: command request successful", c.Config().Name())
-		}
-	}
-
-	// reset the command; this allows the same command (e.g. toggle) to be sent again
-	c.commandStorage.Fill(dataflow.NewNullRegisterValue(c.Config().Name(), value.Register()))
+	return WaveshareWriteRelay(c.modbus.WriteRead, c.modbusConfig.Address(), relayNr, command)
 }
 
 func (c *DeviceStruct) getWaveshareRtuRelay8Registers() (registers []dataflow.RegisterStruct) {
@@ -14,8 +14,20 @@ func runMqttClient(
 ) (mqttClientPool *pool.Pool[mqttClient.Client]) {
 	// run pool
 	mqttClientPool = pool.RunPool[mqttClient.Client]()
+	startMqttClients(cfg, mqttClientPool, selectAll)
+	return
+}
 
+func startMqttClients(
+	cfg *config.Config,
+	mqttClientPool *pool.Pool[mqttClient.Client],
+	selected nameSelection,
+) {
 	for _, c := range cfg.MqttClients() {
+		if !selected(c.Name()) {
+			continue
+		}
+
 		if cfg.LogWorkerStart() {
 			log.Printf(
 				"mqttClient[%s]: start: Broker='%s', ClientId='%s'",
@@ -28,8 +40,6 @@ func runMqttClient(
 		client.Run()
 		mqttClientPool.Add(client)
 	}
-
-	return
 }
 
 type mqttClientConfig struct {
@@ -72,6 +82,10 @@ func (c forwarderConfig) Command() mqttForwarders.MqttSectionConfig {
 	return forwarderMqttSectionConfig{c.MqttClientConfig.Command()}
 }
 
+func (c forwarderConfig) CommandResponse() mqttForwarders.MqttSectionConfig {
+	return forwarderMqttSectionConfig{c.MqttClientConfig.CommandResponse()}
+}
+
 type forwarderMqttSectionConfig struct {
 	config.MqttSectionConfig
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/eclipse/paho.golang/paho"
 	"github.com/koestler/go-iotdevice/v3/queue"
 	"log"
+	"slices"
 	"sync"
 )
 
@@ -16,6 +17,7 @@ type ClientStruct struct {
 
 	subscriptionsMutex sync.RWMutex
 	subscriptions      []subscription
+	lastSubscriptionId uint64
 
 	cliCfg         autopaho.ClientConfig
 	cm             *autopaho.ConnectionManager
@@ -27,6 +29,7 @@ type ClientStruct struct {
 }
 
 type subscription struct {
+	id             uint64
 	subscribeTopic string
 	messageHandler MessageHandler
 }
@@ -39,7 +42,9 @@ func (c *ClientStruct) GetCtx() context.Context {
 	return c.ctx
 }
 
-func (c *ClientStruct) AddRoute(subscribeTopic string, messageHandler MessageHandler) {
+// AddRoute calls the message handler for every message received on the given topic.
+// The returned function removes the route again; the topic is unsubscribed when no other route uses it.
+func (c *ClientStruct) AddRoute(subscribeTopic string, messageHandler MessageHandler) (removeRoute func()) {
 	s := subscription{subscribeTopic: subscribeTopic}
 
 	if c.cfg.LogMessages() {
@@ -62,11 +67,22 @@ func (c *ClientStruct) AddRoute(subscribeTopic string, messageHandler MessageHan
 
 	c.subscriptionsMutex.Lock()
 	defer c.subscriptionsMutex.Unlock()
+	c.lastSubscriptionId += 1
+	s.id = c.lastSubscriptionId
+	subscribed := c.isSubscribedUnlocked(s.subscribeTopic)
 	c.subscriptions = append(c.subscriptions, s)
+	removeRoute = func() {
+		c.removeRoute(s.id)
+	}
+
+	if subscribed {
+		// the topic is already routed to all its handlers
+		return
+	}
 
 	// add route
 	c.router.RegisterHandler(s.subscribeTopic, func(p *paho.Publish) {
-		s.messageHandler(Message{
+		c.route(s.subscribeTopic, Message{
 			topic:   p.Topic,
 			payload: p.Payload,
 		})
@@ -80,6 +96,67 @@ func (c *ClientStruct) AddRoute(subscribeTopic string, messageHandler MessageHan
 			}
 		}(),
 	})
+	return
+}
+
+// route passes the message to all handlers added for the given subscribe topic.
+func (c *ClientStruct) route(subscribeTopic string, message Message) {
+	c.subscriptionsMutex.RLock()
+	handlers := make([]MessageHandler, 0, 1)
+	for _, s := range c.subscriptions {
+		if s.subscribeTopic == subscribeTopic {
+			handlers = append(handlers, s.messageHandler)
+		}
+	}
+	c.subscriptionsMutex.RUnlock()
+
+	for _, h := range handlers {
+		h(message)
+	}
+}
+
+func (c *ClientStruct) removeRoute(id uint64) {
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	idx := slices.IndexFunc(c.subscriptions, func(s subscription) bool {
+		return s.id == id
+	})
+	if idx < 0 {
+		return
+	}
+	topic := c.subscriptions[idx].subscribeTopic
+	c.subscriptions = slices.Delete(c.subscriptions, idx, idx+1)
+
+	if c.isSubscribedUnlocked(topic) {
+		return
+	}
+
+	c.router.UnregisterHandler(topic)
+
+	// unsubscribe in a separate routine since it blocks while the connection is down
+	go func() {
+		if _, err := c.cm.Unsubscribe(c.ctx, &paho.Unsubscribe{Topics: []string{topic}}); err != nil && c.cfg.LogDebug() {
+			log.Printf("mqttClient[%s]: cannot unsubscribe from topic=%s: %s", c.cfg.Name(), topic, err)
+		}
+	}()
+}
+
+// isSubscribedUnlocked must be called with the subscriptionsMutex held.
+func (c *ClientStruct) isSubscribedUnlocked(subscribeTopic string) bool {
+	return slices.ContainsFunc(c.subscriptions, func(s subscription) bool {
+		return s.subscribeTopic == subscribeTopic
+	})
+}
+
+// subscribeTopicsUnlocked returns every topic once; it must be called with the subscriptionsMutex held.
+func (c *ClientStruct) subscribeTopicsUnlocked() (topics []string) {
+	for _, s := range c.subscriptions {
+		if !slices.Contains(topics, s.subscribeTopic) {
+			topics = append(topics, s.subscribeTopic)
+		}
+	}
+	return
 }
 
 func (s subscription) pahoOptions() paho.SubscribeOptions {
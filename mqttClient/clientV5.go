@@ -86,12 +86,12 @@ func (c *ClientStruct) onConnectionUp() func(*autopaho.ConnectionManager, *paho.
 		defer c.subscriptionsMutex.RUnlock()
 
 		// subscribe topics
-		if len(c.subscriptions) > 0 {
+		if topics := c.subscribeTopicsUnlocked(); len(topics) > 0 {
 			if _, err := cm.Subscribe(c.ctx, &paho.Subscribe{
 				Subscriptions: func() (ret []paho.SubscribeOptions) {
-					ret = make([]paho.SubscribeOptions, 0, len(c.subscriptions))
-					for _, s := range c.subscriptions {
-						ret = append(ret, s.pahoOptions())
+					ret = make([]paho.SubscribeOptions, 0, len(topics))
+					for _, topic := range topics {
+						ret = append(ret, subscription{subscribeTopic: topic}.pahoOptions())
 					}
 					return
 				}(),
@@ -41,7 +41,7 @@ type Client interface {
 	Run()
 	Shutdown()
 	Publish(topic string, payload []byte, qos byte, retain bool)
-	AddRoute(subscribeTopic string, messageHandler MessageHandler)
+	AddRoute(subscribeTopic string, messageHandler MessageHandler) (removeRoute func())
 }
 
 type MessageHandler func(Message)
@@ -32,6 +32,9 @@ type DeviceStruct struct {
 
 	avail     map[string]bool
 	availLock sync.Mutex
+
+	removeRoutes     []func()
+	removeRoutesLock sync.Mutex
 }
 
 func NewDevice(
@@ -66,6 +69,9 @@ func (c *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool)
 		return
 	}
 
+	// remove all routes such that a restarted or replaced device does not receive the messages twice
+	defer c.removeAllRoutes()
+
 	for mqttClientName, topics := range mCfg.MqttClientTopics() {
 		mc := c.mqttClientPool.GetByName(mqttClientName)
 		if mc == nil {
@@ -77,7 +83,7 @@ func (c *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool)
 				log.Printf("mqttDevice[%s]->mqttClient[%s]: subscribe to topic=%s", c.Name(), mc.Name(), topic)
 			}
 
-			mc.AddRoute(topic, func(m mqttClient.Message) {
+			c.addRoute(mc, topic, func(m mqttClient.Message) {
 				// parse struct message
 				structMessage, err := parseStructPayload(m.Payload())
 				if err != nil {
@@ -117,6 +123,27 @@ func (c *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool)
 	return nil, false
 }
 
+// addRoute adds a route to the mqtt client which is removed again when Run returns.
+func (c *DeviceStruct) addRoute(mc mqttClient.Client, topic string, messageHandler mqttClient.MessageHandler) {
+	removeRoute := mc.AddRoute(topic, messageHandler)
+
+	c.removeRoutesLock.Lock()
+	defer c.removeRoutesLock.Unlock()
+	c.removeRoutes = append(c.removeRoutes, removeRoute)
+}
+
+func (c *DeviceStruct) removeAllRoutes() {
+	c.removeRoutesLock.Lock()
+	defer c.removeRoutesLock.Unlock()
+	for _, removeRoute := range c.removeRoutes {
+		removeRoute()
+	}
+	c.removeRoutes = nil
+
+	// the next run subscribes to the availability, telemetry and realtime topics again
+	c.subscriptionSetup.Store(false)
+}
+
 func parseStructPayload(payload []byte) (msg mqttForwarders.StructureMessage, err error) {
 	err = json.Unmarshal(payload, &msg)
 	return
@@ -151,7 +178,7 @@ func (c *DeviceStruct) setupAvailabilitySubscription(mc mqttClient.Client, avail
 			log.Printf("mqttDevice[%s]->mqttClient[%s]: subscribe to topic=%s", c.Name(), mc.Name(), topic)
 		}
 
-		mc.AddRoute(topic, func(m mqttClient.Message) {
+		c.addRoute(mc, topic, func(m mqttClient.Message) {
 			if c.Config().LogDebug() {
 				log.Printf("mqttDevice[%s]->mqttClient[%s]: received availability topic=%s, msg=%s",
 					c.Name(), mc.Name(), m.Topic(), m.Payload(),
@@ -185,7 +212,7 @@ func (c *DeviceStruct) setupTelemetrySubscription(mc mqttClient.Client, topic st
 		log.Printf("mqttDevice[%s]->mqttClient[%s]: subscribe to topic=%s", c.Name(), mc.Name(), topic)
 	}
 
-	mc.AddRoute(topic, func(m mqttClient.Message) {
+	c.addRoute(mc, topic, func(m mqttClient.Message) {
 		telemetryMessage, err := parseTelemetryPayload(m.Payload())
 		if err != nil {
 			log.Printf("mqttDevice[%s]->mqttClient[%s]: cannot parse telemetry payload: %s", c.Name(), mc.Name(), err)
@@ -198,15 +225,15 @@ func (c *DeviceStruct) setupTelemetrySubscription(mc mqttClient.Client, topic st
 
 			case dataflow.NumberRegister:
 				if v, ok := telemetryMessage.NumericValues[register.Name()]; ok {
-					c.StateStorage().Fill(dataflow.NewNumericRegisterValue(c.Name(), register, v.Value))
+					c.fillWithTime(dataflow.NewNumericRegisterValue(c.Name(), register, v.Value), v.Time)
 				}
 			case dataflow.TextRegister:
 				if v, ok := telemetryMessage.TextValues[register.Name()]; ok {
-					c.StateStorage().Fill(dataflow.NewTextRegisterValue(c.Name(), register, v.Value))
+					c.fillWithTime(dataflow.NewTextRegisterValue(c.Name(), register, v.Value), v.Time)
 				}
 			case dataflow.EnumRegister:
 				if v, ok := telemetryMessage.EnumValues[register.Name()]; ok {
-					c.StateStorage().Fill(dataflow.NewEnumRegisterValue(c.Name(), register, v.EnumIdx))
+					c.fillWithTime(dataflow.NewEnumRegisterValue(c.Name(), register, v.EnumIdx), v.Time)
 				}
 			default:
 				if c.Config().LogDebug() {
@@ -233,7 +260,7 @@ func (c *DeviceStruct) setupRealtimeSubscription(mc mqttClient.Client, topicTemp
 		return
 	}
 
-	mc.AddRoute(topic, func(m mqttClient.Message) {
+	c.addRoute(mc, topic, func(m mqttClient.Message) {
 		if len(m.Payload()) < 1 {
 			// ignore empty messages; those are used to remove retained messages
 			return
@@ -264,23 +291,37 @@ func (c *DeviceStruct) setupRealtimeSubscription(mc mqttClient.Client, topicTemp
 			return
 		}
 
+		if realtimeMessage.NumericValue == nil && realtimeMessage.TextValue == nil && realtimeMessage.EnumIdx == nil {
+			// a message without a value is sent when the value expired on the sending instance
+			c.fillWithTime(dataflow.NewNullRegisterValue(c.Name(), register), realtimeMessage.Time)
+			return
+		}
+
 		switch register.RegisterType() {
 		case dataflow.NumberRegister:
 			if v := realtimeMessage.NumericValue; v != nil {
-				c.StateStorage().Fill(dataflow.NewNumericRegisterValue(c.Name(), register, *v))
+				c.fillWithTime(dataflow.NewNumericRegisterValue(c.Name(), register, *v), realtimeMessage.Time)
 			}
 		case dataflow.TextRegister:
 			if v := realtimeMessage.TextValue; v != nil {
-				c.StateStorage().Fill(dataflow.NewTextRegisterValue(c.Name(), register, *v))
+				c.fillWithTime(dataflow.NewTextRegisterValue(c.Name(), register, *v), realtimeMessage.Time)
 			}
 		case dataflow.EnumRegister:
 			if v := realtimeMessage.EnumIdx; v != nil {
-				c.StateStorage().Fill(dataflow.NewEnumRegisterValue(c.Name(), register, *v))
+				c.fillWithTime(dataflow.NewEnumRegisterValue(c.Name(), register, *v), realtimeMessage.Time)
 			}
 		}
 	})
 }
 
+// fillWithTime keeps the measurement time of the sending instance if it is given in the message.
+func (c *DeviceStruct) fillWithTime(value dataflow.Value, valueTime string) {
+	if t, ok := mqttForwarders.ParseValueTime(valueTime); ok {
+		value = dataflow.WithTime(value, t)
+	}
+	c.StateStorage().Fill(value)
+}
+
 func (c *DeviceStruct) runCommandForwarder(
 	ctx context.Context,
 	mc mqttClient.Client,
@@ -322,8 +363,11 @@ func (c *DeviceStruct) runCommandForwarder(
 			log.Printf("mqttDevice[%s]->mqttClient[%s]: cannot generate command message: %s",
 				c.Name(), mc.Name(), err,
 			)
+			c.commandStorage.CommandDone(command, err)
 		} else {
 			mc.Publish(topic, payload, 1, false)
+			// the remote instance does not confirm the command; it stays sent
+			c.commandStorage.CommandSent(command)
 		}
 
 		// reset the command; this allows the same command (e.g. toggle) to be sent again
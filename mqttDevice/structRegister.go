@@ -40,3 +40,12 @@ func (s StructRegister) Sort() int {
 func (s StructRegister) Writable() bool {
 	return s.StructRegister.Writable
 }
+
+func (s StructRegister) Limits() dataflow.RegisterLimits {
+	return dataflow.RegisterLimits{
+		Min:       s.StructRegister.Min,
+		Max:       s.StructRegister.Max,
+		Step:      s.StructRegister.Step,
+		Precision: s.StructRegister.Precision,
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+
 	"github.com/koestler/go-iotdevice/v3/config"
 	"github.com/koestler/go-iotdevice/v3/dataflow"
 	"github.com/koestler/go-iotdevice/v3/device"
@@ -10,16 +12,48 @@ import (
 	"github.com/koestler/go-iotdevice/v3/restarter"
 )
 
+// runMqttForwarders starts the forwarders of the selected mqtt clients.
+// It returns a function per client name to stop its forwarders again.
 func runMqttForwarders(
 	cfg *config.Config,
 	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
 	mqttClientPool *pool.Pool[mqttClient.Client],
 	stateStorage *dataflow.ValueStorage,
-	commandStorage *dataflow.ValueStorage,
-) {
+	commands *dataflow.CommandTracker,
+	selected nameSelection,
+) (stop map[string]context.CancelFunc) {
+	stop = make(map[string]context.CancelFunc)
 	for _, c := range cfg.MqttClients() {
+		if !selected(c.Name()) {
+			continue
+		}
+
 		forwarderCfg := forwarderConfig{c}
 		client := mqttClientPool.GetByName(c.Name())
-		go mqttForwarders.RunMqttForwarders(forwarderCfg, client, devicePool, stateStorage, commandStorage)
+		ctx, cancel := context.WithCancel(client.GetCtx())
+		stop[c.Name()] = cancel
+		go mqttForwarders.RunMqttForwarders(ctx, forwarderCfg, client, devicePool, stateStorage, commands)
+	}
+	return
+}
+
+// forwardedDevices returns the names of all devices any forwarder of the given mqtt client sends values of.
+func forwardedDevices(c config.MqttClientConfig) (names []string) {
+	for _, s := range []config.MqttSectionConfig{
+		c.AvailabilityDevice(),
+		c.Structure(),
+		c.Telemetry(),
+		c.Realtime(),
+		c.HomeassistantDiscovery(),
+		c.Command(),
+		c.CommandResponse(),
+	} {
+		if !s.Enabled() {
+			continue
+		}
+		for _, d := range s.Devices() {
+			names = append(names, d.Name())
+		}
 	}
+	return
 }
@@ -9,6 +9,8 @@ import (
 )
 
 type CommandMessage struct {
+	// Id is optional; when given, it is used as the id of the command in the command responses
+	Id           string   `json:"Id,omitempty"`
 	NumericValue *float64 `json:"NumVal,omitempty"`
 	TextValue    *string  `json:"TextVal,omitempty"`
 	EnumIdx      *int     `json:"EnumIdx,omitempty"`
@@ -19,11 +21,11 @@ func runCommandForwarder(
 	cfg Config,
 	dev device.Device,
 	mc mqttClient.Client,
-	commandStorage *dataflow.ValueStorage,
+	commands *dataflow.CommandTracker,
 	filterConf dataflow.RegisterFilterConf,
 ) {
 	filter := createWritableAndRegisterValueFilter(filterConf)
-	go commandRoutine(ctx, cfg, dev, mc, commandStorage, filter)
+	go commandRoutine(ctx, cfg, dev, mc, commands, filter)
 }
 
 func commandRoutine(
@@ -31,17 +33,27 @@ func commandRoutine(
 	cfg Config,
 	dev device.Device,
 	mc mqttClient.Client,
-	commandStorage *dataflow.ValueStorage,
+	commands *dataflow.CommandTracker,
 	filter dataflow.RegisterFilterFunc,
 ) {
 	regSubscription := dev.RegisterDb().Subscribe(ctx, filter)
 
+	// unsubscribe when the forwarder is stopped, e.g. after the configuration was reloaded
+	var removeRoutes []func()
+	defer func() {
+		for _, removeRoute := range removeRoutes {
+			removeRoute()
+		}
+	}()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case reg := <-regSubscription:
-			setupCommandSubscription(cfg, dev, mc, commandStorage, reg)
+			if removeRoute := setupCommandSubscription(cfg, dev, mc, commands, reg); removeRoute != nil {
+				removeRoutes = append(removeRoutes, removeRoute)
+			}
 		}
 	}
 }
@@ -50,9 +62,9 @@ func setupCommandSubscription(
 	cfg Config,
 	dev device.Device,
 	mc mqttClient.Client,
-	commandStorage *dataflow.ValueStorage,
+	commands *dataflow.CommandTracker,
 	register dataflow.Register,
-) {
+) (removeRoute func()) {
 	topic := cfg.CommandTopic(dev.Name(), register.Name())
 	logDebug := cfg.LogDebug()
 
@@ -66,47 +78,50 @@ func setupCommandSubscription(
 	register, ok := registerDb.GetByName(register.Name())
 	if !ok {
 		log.Printf("mqttDevice[%s]->mqttClient[%s]->command: unknown register, registerName=%s", mc.Name(), deviceName, register.Name())
-		return
+		return nil
 	}
 
-	mc.AddRoute(topic, func(m mqttClient.Message) {
+	return mc.AddRoute(topic, func(m mqttClient.Message) {
 		msg, err := parseCommandMessagePayload(m.Payload())
 		if err != nil {
 			log.Printf("mqttDevice[%s]->mqttClient[%s]->command: cannod parse message: %s", mc.Name(), dev.Name(), err)
 			return
 		}
 
+		var rv dataflow.Value
 		switch register.RegisterType() {
 		case dataflow.NumberRegister:
 			if v := msg.NumericValue; v != nil {
-				rv := dataflow.NewNumericRegisterValue(deviceName, register, *v)
-				commandStorage.Fill(rv)
-				if logDebug {
-					log.Printf("mqttDevice[%s]->mqttClient[%s]->command: send NumericValue deviceName=%s: %s", mc.Name(), dev.Name(), deviceName, rv.String())
-				}
-				return
+				rv = dataflow.NewNumericRegisterValue(deviceName, register, *v)
 			}
 		case dataflow.TextRegister:
 			if v := msg.TextValue; v != nil {
-				rv := dataflow.NewTextRegisterValue(deviceName, register, *v)
-				commandStorage.Fill(rv)
-				if logDebug {
-					log.Printf("mqttDevice[%s]->mqttClient[%s]->command: send TextValue deviceName=%s: %s", mc.Name(), dev.Name(), deviceName, rv.String())
-				}
-				return
+				rv = dataflow.NewTextRegisterValue(deviceName, register, *v)
 			}
 		case dataflow.EnumRegister:
 			if v := msg.EnumIdx; v != nil {
-				rv := dataflow.NewEnumRegisterValue(deviceName, register, *v)
-				commandStorage.Fill(rv)
-				if logDebug {
-					log.Printf("mqttDevice[%s]->mqttClient[%s]->command: send EnumValue deviceName=%s: %s", mc.Name(), dev.Name(), deviceName, rv.String())
-				}
-				return
+				rv = dataflow.NewEnumRegisterValue(deviceName, register, *v)
 			}
 		}
 
-		log.Printf("mqttDevice[%s]->mqttClient[%s]->command: invalid command message: %#v", mc.Name(), dev.Name(), msg)
+		if rv == nil {
+			log.Printf("mqttDevice[%s]->mqttClient[%s]->command: invalid command message: %#v", mc.Name(), dev.Name(), msg)
+			return
+		}
+
+		// check the value against the enum and the limits of the register
+		if err := dataflow.ValidateCommand(rv); err != nil {
+			log.Printf("mqttDevice[%s]->mqttClient[%s]->command: rejected command: %s", mc.Name(), dev.Name(), err)
+			return
+		}
+
+		if len(msg.Id) > 0 {
+			rv = dataflow.WithCommandId(rv, msg.Id)
+		}
+		status := commands.Fill(rv)
+		if logDebug {
+			log.Printf("mqttDevice[%s]->mqttClient[%s]->command: send deviceName=%s, id=%s: %s", mc.Name(), dev.Name(), deviceName, status.Id, rv.String())
+		}
 	})
 }
 
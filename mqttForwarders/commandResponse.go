@@ -0,0 +1,62 @@
+package mqttForwarders
+
+import (
+	"context"
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/mqttClient"
+	"log"
+	"time"
+)
+
+type CommandResponseMessage struct {
+	Id    string `json:"Id"`
+	State string `json:"State"`
+	Error string `json:"Err,omitempty"`
+	Time  string `json:"Time"`
+}
+
+func runCommandResponseForwarder(
+	ctx context.Context,
+	cfg Config,
+	deviceName string,
+	mc mqttClient.Client,
+	commands *dataflow.CommandTracker,
+) {
+	mCfg := cfg.CommandResponse()
+
+	if cfg.LogDebug() {
+		log.Printf(
+			"mqttClient[%s]->device[%s]->commandResponse: start sending status of commands",
+			mc.Name(), deviceName,
+		)
+	}
+
+	updates := commands.Subscribe(ctx, func(s dataflow.CommandStatus) bool {
+		return s.DeviceName == deviceName
+	})
+
+	go func() {
+		// routine will return when ctx is cancelled
+		for s := range updates {
+			msg := CommandResponseMessage{
+				Id:    s.Id,
+				State: string(s.State),
+				Error: s.Error,
+				Time:  s.Updated.Format(time.RFC3339Nano),
+			}
+
+			if payload, err := json.Marshal(msg); err != nil {
+				log.Printf("mqttClient[%s]->device[%s]->commandResponse: cannot generate message: %s",
+					mc.Name(), deviceName, err,
+				)
+			} else {
+				mc.Publish(
+					cfg.CommandResponseTopic(deviceName, s.RegisterName),
+					payload,
+					mCfg.Qos(),
+					mCfg.Retain(),
+				)
+			}
+		}
+	}()
+}
@@ -1,6 +1,7 @@
 package mqttForwarders
 
 import (
+	"context"
 	"github.com/koestler/go-iotdevice/v3/dataflow"
 	"github.com/koestler/go-iotdevice/v3/device"
 	"github.com/koestler/go-iotdevice/v3/mqttClient"
@@ -34,6 +35,9 @@ type Config interface {
 	Command() MqttSectionConfig
 	CommandTopic(deviceName, registerName string) string
 
+	CommandResponse() MqttSectionConfig
+	CommandResponseTopic(deviceName, registerName string) string
+
 	LogDebug() bool
 }
 
@@ -50,17 +54,19 @@ type MqttDeviceSectionConfig interface {
 	Filter() dataflow.RegisterFilterConf
 }
 
+// RunMqttForwarders starts all forwarders of the given client. They stop when ctx is cancelled.
 func RunMqttForwarders(
+	ctx context.Context,
 	cfg Config,
 	mc mqttClient.Client,
 	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
 	stateStorage *dataflow.ValueStorage,
-	commandStorage *dataflow.ValueStorage,
+	commands *dataflow.CommandTracker,
 ) {
 	if sCfg := cfg.HomeassistantDiscovery(); sCfg.Enabled() {
 		for _, deviceConfig := range cfg.HomeassistantDiscovery().Devices() {
 			if dev := devicePool.GetByName(deviceConfig.Name()); dev != nil {
-				runHomeassistantDiscoveryForwarder(mc.GetCtx(), cfg, dev.Service(), mc, deviceConfig.Filter())
+				runHomeassistantDiscoveryForwarder(ctx, cfg, dev.Service(), mc, deviceConfig.Filter())
 			} else {
 				log.Printf("RunMqttForwarders: dev=%s not found", deviceConfig.Name())
 			}
@@ -69,12 +75,16 @@ func RunMqttForwarders(
 
 	// delay first avail / struct / realtime message to give hass time to first process the discovery message and then
 	// get the initial state from the realtime message
-	time.Sleep(time.Second)
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(time.Second):
+	}
 
 	if sCfg := cfg.AvailabilityDevice(); sCfg.Enabled() {
 		for _, deviceConfig := range sCfg.Devices() {
 			if dev := devicePool.GetByName(deviceConfig.Name()); dev != nil {
-				runAvailabilityForwarder(mc.GetCtx(), cfg, dev.Service(), mc)
+				runAvailabilityForwarder(ctx, cfg, dev.Service(), mc)
 			} else {
 				log.Printf("RunMqttForwarders: dev=%s not found", deviceConfig.Name())
 			}
@@ -84,7 +94,7 @@ func RunMqttForwarders(
 	if sCfg := cfg.Structure(); sCfg.Enabled() {
 		for _, deviceConfig := range sCfg.Devices() {
 			if dev := devicePool.GetByName(deviceConfig.Name()); dev != nil {
-				runStructureForwarder(mc.GetCtx(), cfg, dev.Service(), mc, deviceConfig.Filter())
+				runStructureForwarder(ctx, cfg, dev.Service(), mc, deviceConfig.Filter())
 			} else {
 				log.Printf("RunMqttForwarders: dev=%s not found", deviceConfig.Name())
 			}
@@ -94,7 +104,7 @@ func RunMqttForwarders(
 	if sCfg := cfg.Telemetry(); sCfg.Enabled() {
 		for _, deviceConfig := range sCfg.Devices() {
 			if dev := devicePool.GetByName(deviceConfig.Name()); dev != nil {
-				runTelemetryForwarder(mc.GetCtx(), cfg, dev.Service(), mc, stateStorage, deviceConfig.Filter())
+				runTelemetryForwarder(ctx, cfg, dev.Service(), mc, stateStorage, deviceConfig.Filter())
 			} else {
 				log.Printf("RunMqttForwarders: dev=%s not found", deviceConfig.Name())
 			}
@@ -104,7 +114,7 @@ func RunMqttForwarders(
 	if sCfg := cfg.Realtime(); sCfg.Enabled() {
 		for _, deviceConfig := range sCfg.Devices() {
 			if dev := devicePool.GetByName(deviceConfig.Name()); dev != nil {
-				runRealtimeForwarder(mc.GetCtx(), cfg, dev.Service(), mc, stateStorage, deviceConfig.Filter())
+				runRealtimeForwarder(ctx, cfg, dev.Service(), mc, stateStorage, deviceConfig.Filter())
 			} else {
 				log.Printf("RunMqttForwarders: dev=%s not found", deviceConfig.Name())
 			}
@@ -114,10 +124,16 @@ func RunMqttForwarders(
 	if sCfg := cfg.Command(); sCfg.Enabled() {
 		for _, deviceConfig := range cfg.Command().Devices() {
 			if dev := devicePool.GetByName(deviceConfig.Name()); dev != nil {
-				runCommandForwarder(mc.GetCtx(), cfg, dev.Service(), mc, commandStorage, deviceConfig.Filter())
+				runCommandForwarder(ctx, cfg, dev.Service(), mc, commands, deviceConfig.Filter())
 			} else {
 				log.Printf("RunMqttForwarders: dev=%s not found", deviceConfig.Name())
 			}
 		}
 	}
+
+	if sCfg := cfg.CommandResponse(); sCfg.Enabled() {
+		for _, deviceConfig := range sCfg.Devices() {
+			runCommandResponseForwarder(ctx, cfg, deviceConfig.Name(), mc, commands)
+		}
+	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/koestler/go-iotdevice/v3/mqttClient"
 	"golang.org/x/exp/maps"
 	"log"
+	"math"
 	"slices"
 	"strconv"
 	"strings"
@@ -30,6 +31,19 @@ type homeassistantDiscoverySensorMessage struct {
 	StateTopic        string `json:"stat_t"`
 	ValueTemplate     string `json:"val_tpl"`
 	UnitOfMeasurement string `json:"unit_of_meas,omitempty"`
+	DisplayPrecision  *int   `json:"sug_dsp_prc,omitempty"`
+}
+
+type homeassistantDiscoveryNumberMessage struct {
+	homeassistantDiscoveryBaseMessage
+	CommandTopic      string   `json:"cmd_t"`
+	CommandTemplate   string   `json:"cmd_tpl"`
+	StateTopic        string   `json:"stat_t"`
+	ValueTemplate     string   `json:"val_tpl"`
+	UnitOfMeasurement string   `json:"unit_of_meas,omitempty"`
+	Min               float64  `json:"min"`
+	Max               float64  `json:"max"`
+	Step              *float64 `json:"step,omitempty"`
 }
 
 type homeassistantDiscoverySwitchMessage struct {
@@ -173,12 +187,24 @@ func publishHomeassistantDiscoveryMessage(
 
 	switch register.RegisterType() {
 	case dataflow.NumberRegister:
-		topic, msg = getHomeassistantDiscoverySensorMessage(
-			cfg,
-			deviceName,
-			register,
-			"{{ value_json.NumVal }}",
-		)
+		// home assistant assumes a range of 1 to 100 when none is given; hence only registers with a
+		// complete range are announced as a number, all others as a read-only sensor
+		if limits := register.Limits(); commandFilter(register) && limits.Min != nil && limits.Max != nil {
+			topic, msg = getHomeassistantDiscoveryNumberMessage(
+				cfg,
+				deviceName,
+				register,
+				"{{ value_json.NumVal }}",
+				`{"NumVal": {{ value }}}`,
+			)
+		} else {
+			topic, msg = getHomeassistantDiscoverySensorMessage(
+				cfg,
+				deviceName,
+				register,
+				"{{ value_json.NumVal }}",
+			)
+		}
 	case dataflow.TextRegister:
 		topic, msg = getHomeassistantDiscoverySensorMessage(
 			cfg,
@@ -258,6 +284,41 @@ func getHomeassistantDiscoverySensorMessage(
 		StateTopic:                        cfg.RealtimeTopic(deviceName, register.Name()),
 		ValueTemplate:                     valueTemplate,
 		UnitOfMeasurement:                 register.Unit(),
+		DisplayPrecision:                  register.Limits().Precision,
+	}
+
+	return
+}
+
+func getHomeassistantDiscoveryNumberMessage(
+	cfg Config,
+	deviceName string,
+	register dataflow.Register,
+	valueTemplate,
+	commandTemplate string,
+) (topic string, msg homeassistantDiscoveryNumberMessage) {
+	uniqueId, base := getHomeassistantDiscoveryBaseMessage(cfg, deviceName, register)
+
+	topic = cfg.HomeassistantDiscoveryTopic("number", cfg.ClientId(), uniqueId)
+
+	limits := register.Limits()
+	step := limits.Step
+	if step == nil && limits.Precision != nil {
+		// without an explicit step, allow any value with the given number of decimals
+		s := math.Pow10(-*limits.Precision)
+		step = &s
+	}
+
+	msg = homeassistantDiscoveryNumberMessage{
+		homeassistantDiscoveryBaseMessage: base,
+		CommandTopic:                      cfg.CommandTopic(deviceName, register.Name()),
+		CommandTemplate:                   commandTemplate,
+		StateTopic:                        cfg.RealtimeTopic(deviceName, register.Name()),
+		ValueTemplate:                     valueTemplate,
+		UnitOfMeasurement:                 register.Unit(),
+		Min:                               *limits.Min,
+		Max:                               *limits.Max,
+		Step:                              step,
 	}
 
 	return
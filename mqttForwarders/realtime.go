@@ -13,6 +13,9 @@ type RealtimeMessage struct {
 	NumericValue *float64 `json:"NumVal,omitempty"`
 	TextValue    *string  `json:"TextVal,omitempty"`
 	EnumIdx      *int     `json:"EnumIdx,omitempty"`
+	Time         string   `json:"Time,omitempty"`
+	// Restored is set for values restored from disk until the device delivers a fresh value
+	Restored bool `json:"Restored,omitempty"`
 }
 
 func runRealtimeForwarder(
@@ -56,7 +59,8 @@ func realtimeImmediateModeRoutine(
 		)
 	}
 
-	subscription := storage.SubscribeSendInitial(ctx, filter)
+	// a stuck mqtt connection must not stall the storage; only the latest value per register is kept then
+	subscription := storage.SubscribeSendInitialWithDeadband(ctx, filter, dataflow.OverflowCoalesce)
 	// for loop ends when subscription is canceled and closes its output chan
 	for value := range subscription.Drain() {
 		publishRealtimeMessage(cfg, mc, dev.Name(), value)
@@ -85,7 +89,7 @@ func realtimeDelayedUpdateModeRoutine(
 
 	updates := make(map[string]dataflow.Value)
 
-	subscription := storage.SubscribeSendInitial(ctx, filter)
+	subscription := storage.SubscribeSendInitialWithDeadband(ctx, filter, dataflow.OverflowCoalesce)
 	for {
 		select {
 		case <-ctx.Done():
@@ -139,7 +143,10 @@ func publishRealtimeMessage(cfg Config, mc mqttClient.Client, devName string, va
 }
 
 func convertValueToRealtimeMessage(value dataflow.Value) interface{} {
-	ret := RealtimeMessage{}
+	ret := RealtimeMessage{
+		Time:     formatValueTime(value.Time()),
+		Restored: value.Restored(),
+	}
 
 	if numeric, ok := value.(dataflow.NumericRegisterValue); ok {
 		v := numeric.Value()
@@ -154,3 +161,21 @@ func convertValueToRealtimeMessage(value dataflow.Value) interface{} {
 
 	return ret
 }
+
+// formatValueTime returns the measurement time in RFC3339 format including fractional seconds
+// or an empty string if the time is unknown.
+func formatValueTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// ParseValueTime parses the Time field of realtime and telemetry messages; ok is false if no valid time is given.
+func ParseValueTime(s string) (t time.Time, ok bool) {
+	if len(s) < 1 {
+		return
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
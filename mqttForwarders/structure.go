@@ -20,6 +20,10 @@ type StructRegister struct {
 	Unit        string         `json:"Unit,omitempty" example:"W"`
 	Sort        int            `json:"Sort" example:"100"`
 	Writable    bool           `json:"Cmnd" example:"false"`
+	Min         *float64       `json:"Min,omitempty"`
+	Max         *float64       `json:"Max,omitempty"`
+	Step        *float64       `json:"Step,omitempty"`
+	Precision   *int           `json:"Prec,omitempty"`
 }
 
 type StructureMessage struct {
@@ -225,6 +229,7 @@ func applyWritableFiltersToRegisters(cfg Config, devName string, inp []dataflow.
 }
 
 func NewStructRegister(reg dataflow.Register) StructRegister {
+	limits := reg.Limits()
 	return StructRegister{
 		Category:    reg.Category(),
 		Name:        reg.Name(),
@@ -234,5 +239,9 @@ func NewStructRegister(reg dataflow.Register) StructRegister {
 		Unit:        reg.Unit(),
 		Sort:        reg.Sort(),
 		Writable:    reg.Writable(),
+		Min:         limits.Min,
+		Max:         limits.Max,
+		Step:        limits.Step,
+		Precision:   limits.Precision,
 	}
 }
@@ -23,12 +23,16 @@ type NumericTelemetryValue struct {
 	Description string  `json:"Desc"`
 	Value       float64 `json:"Val"`
 	Unit        string  `json:"Unit,omitempty"`
+	Time        string  `json:"Time,omitempty"`
+	Restored    bool    `json:"Restored,omitempty"`
 }
 
 type TextTelemetryValue struct {
 	Category    string `json:"Cat"`
 	Description string `json:"Desc"`
 	Value       string `json:"Val"`
+	Time        string `json:"Time,omitempty"`
+	Restored    bool   `json:"Restored,omitempty"`
 }
 
 type EnumTelemetryValue struct {
@@ -36,6 +40,8 @@ type EnumTelemetryValue struct {
 	Description string `json:"Desc"`
 	EnumIdx     int    `json:"Idx"`
 	Value       string `json:"Val"`
+	Time        string `json:"Time,omitempty"`
+	Restored    bool   `json:"Restored,omitempty"`
 }
 
 func runTelemetryForwarder(
@@ -134,6 +140,8 @@ func convertValuesToNumericTelemetryValues(values []dataflow.Value) (ret map[str
 				Description: reg.Description(),
 				Value:       numeric.Value(),
 				Unit:        reg.Unit(),
+				Time:        formatValueTime(value.Time()),
+				Restored:    value.Restored(),
 			}
 		}
 	}
@@ -151,6 +159,8 @@ func convertValuesToTextTelemetryValues(values []dataflow.Value) (ret map[string
 				Category:    reg.Category(),
 				Description: reg.Description(),
 				Value:       text.Value(),
+				Time:        formatValueTime(value.Time()),
+				Restored:    value.Restored(),
 			}
 		}
 	}
@@ -169,6 +179,8 @@ func convertValuesToEnumTelemetryValues(values []dataflow.Value) (ret map[string
 				Description: reg.Description(),
 				EnumIdx:     enum.EnumIdx(),
 				Value:       enum.Value(),
+				Time:        formatValueTime(value.Time()),
+				Restored:    value.Restored(),
 			}
 		}
 	}
@@ -0,0 +1,97 @@
+package namePattern
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Pattern matches register or category names.
+// An entry enclosed in slashes like /^CH[3-8]$/ is a regular expression,
+// an entry containing *, ? or [ is a glob pattern like *Minimum (see path.Match)
+// and any other entry matches the name literally.
+type Pattern struct {
+	entry string
+	glob  bool
+	re    *regexp.Regexp
+}
+
+func Compile(entry string) (p Pattern, err error) {
+	p.entry = entry
+
+	if len(entry) > 1 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+		if p.re, err = regexp.Compile(entry[1 : len(entry)-1]); err != nil {
+			err = fmt.Errorf("invalid regular expression: %s", err)
+		}
+		return
+	}
+
+	if strings.ContainsAny(entry, "*?[") {
+		p.glob = true
+		if _, e := path.Match(entry, ""); e != nil {
+			err = fmt.Errorf("invalid glob pattern: %s", e)
+		}
+	}
+
+	return
+}
+
+func (p Pattern) String() string {
+	return p.entry
+}
+
+// Literal returns true when the entry is neither a glob pattern nor a regular expression.
+func (p Pattern) Literal() bool {
+	return !p.glob && p.re == nil
+}
+
+func (p Pattern) Match(name string) bool {
+	switch {
+	case p.re != nil:
+		return p.re.MatchString(name)
+	case p.glob:
+		ok, _ := path.Match(p.entry, name)
+		return ok
+	default:
+		return p.entry == name
+	}
+}
+
+// List matches names against a list of entries. Literal entries are looked up first,
+// the patterns are tried in the order they are given.
+type List struct {
+	literals map[string]Pattern
+	patterns []Pattern
+}
+
+// CompileList compiles all entries. Invalid entries are left out and returned as errors.
+func CompileList(entries []string) (l List, err []error) {
+	l.literals = make(map[string]Pattern, len(entries))
+	for i, entry := range entries {
+		p, e := Compile(entry)
+		if e != nil {
+			err = append(err, fmt.Errorf("[%d]='%s': %s", i, entry, e))
+			continue
+		}
+		if p.Literal() {
+			l.literals[entry] = p
+		} else {
+			l.patterns = append(l.patterns, p)
+		}
+	}
+	return
+}
+
+// Match returns the first entry matching the given name.
+func (l List) Match(name string) (p Pattern, ok bool) {
+	if p, ok = l.literals[name]; ok {
+		return
+	}
+	for _, p = range l.patterns {
+		if p.Match(name) {
+			return p, true
+		}
+	}
+	return Pattern{}, false
+}
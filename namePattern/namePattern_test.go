@@ -0,0 +1,62 @@
+package namePattern
+
+import "testing"
+
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		entry string
+		name  string
+		match bool
+	}{
+		{"Temperature", "Temperature", true},
+		{"Temperature", "AuxTemperature", false},
+		{"CH[3-8]", "CH3", true},
+		{"CH[3-8]", "CH2", false},
+		{"*Minimum", "MainVoltageMinimum", true},
+		{"*Minimum", "MainVoltageMaximum", false},
+		{"Power?", "Power1", true},
+		{"/^(Min|Max)/", "MinVoltage", true},
+		{"/^(Min|Max)/", "VoltageMin", false},
+		{"/Voltage$/", "MainVoltage", true},
+		{"/", "/", true},
+	}
+
+	for _, tc := range tests {
+		p, err := Compile(tc.entry)
+		if err != nil {
+			t.Errorf("did not expect an error for '%s', got: %s", tc.entry, err)
+			continue
+		}
+		if expect, got := tc.match, p.Match(tc.name); expect != got {
+			t.Errorf("expect '%s' matching '%s' to be %t but got %t", tc.entry, tc.name, expect, got)
+		}
+	}
+}
+
+func TestInvalidPattern(t *testing.T) {
+	for _, entry := range []string{"/(unclosed/", "CH[3-"} {
+		if _, err := Compile(entry); err == nil {
+			t.Errorf("expect an error for '%s'", entry)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	l, err := CompileList([]string{"CH[3-8]", "CH4", "/^AUX/", "/(/"})
+	if expect, got := 1, len(err); expect != got {
+		t.Fatalf("expect %d error but got %d: %v", expect, got, err)
+	}
+
+	if p, ok := l.Match("CH4"); !ok || !p.Literal() {
+		t.Errorf("expect CH4 to match the literal entry, got '%s'", p)
+	}
+	if p, ok := l.Match("CH5"); !ok || p.String() != "CH[3-8]" {
+		t.Errorf("expect CH5 to match 'CH[3-8]', got '%s'", p)
+	}
+	if p, ok := l.Match("AUX1"); !ok || p.String() != "/^AUX/" {
+		t.Errorf("expect AUX1 to match '/^AUX/', got '%s'", p)
+	}
+	if _, ok := l.Match("CH1"); ok {
+		t.Error("did not expect CH1 to match")
+	}
+}
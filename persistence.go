@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"github.com/koestler/go-iotdevice/v3/config"
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+	"github.com/koestler/go-iotdevice/v3/pool"
+	"github.com/koestler/go-iotdevice/v3/restarter"
+	"log"
+	"sync/atomic"
+)
+
+// runPersistence restores the state storage and loads the persisted commands.
+// The loaded commands are returned such that they can be replayed once the devices are available.
+func runPersistence(
+	cfg *config.Config,
+	devices *runningDevices,
+	stateStorage *dataflow.ValueStorage,
+	commandStorage *dataflow.ValueStorage,
+) (persisters []*dataflow.Persister, commands []dataflow.Value) {
+	persistenceCfg := cfg.Persistence()
+	if !persistenceCfg.Enabled() {
+		return
+	}
+
+	filter := persistenceValueFilter(devices)
+
+	if file := persistenceCfg.StateFile(); len(file) > 0 {
+		persister := dataflow.NewPersister(dataflow.PersisterConfig{
+			File:     file,
+			Interval: persistenceCfg.WriteInterval(),
+			Filter:   filter,
+			LogDebug: persistenceCfg.LogDebug(),
+		}, stateStorage)
+		if values, err := persister.Restore(); err != nil {
+			log.Printf("persistence: cannot restore state from '%s': %s", file, err)
+		} else if cfg.LogWorkerStart() {
+			log.Printf("persistence: restored %d values from '%s'", len(values), file)
+		}
+		persister.Run()
+		persisters = append(persisters, persister)
+	}
+
+	if file := persistenceCfg.CommandFile(); len(file) > 0 {
+		persister := dataflow.NewPersister(dataflow.PersisterConfig{
+			File:       file,
+			Interval:   persistenceCfg.WriteInterval(),
+			Filter:     filter,
+			KeepOnNull: true,
+			LogDebug:   persistenceCfg.LogDebug(),
+		}, commandStorage)
+		if values, err := persister.Load(); err != nil {
+			log.Printf("persistence: cannot load commands from '%s': %s", file, err)
+		} else {
+			if cfg.LogWorkerStart() {
+				log.Printf("persistence: loaded %d commands from '%s'", len(values), file)
+			}
+			commands = values
+		}
+		persister.Run()
+		persisters = append(persisters, persister)
+	}
+
+	return
+}
+
+// runningDevices holds the device names of the running configuration; the reloader updates it such that
+// the persistence, the history and the recorder include devices added at runtime.
+type runningDevices struct {
+	names atomic.Pointer[map[string]struct{}]
+}
+
+func newRunningDevices(cfg *config.Config) *runningDevices {
+	d := &runningDevices{}
+	d.set(cfg)
+	return d
+}
+
+func (d *runningDevices) set(cfg *config.Config) {
+	names := make(map[string]struct{})
+	for _, dev := range cfg.Devices() {
+		names[dev.Name()] = struct{}{}
+	}
+	d.names.Store(&names)
+}
+
+func (d *runningDevices) contains(name string) bool {
+	_, ok := (*d.names.Load())[name]
+	return ok
+}
+
+// persistenceValueFilter only persists values of configured devices and never persists the availability
+// since it must always reflect the current state of the device.
+func persistenceValueFilter(devices *runningDevices) dataflow.ValueFilterFunc {
+	return func(value dataflow.Value) bool {
+		if !devices.contains(value.DeviceName()) {
+			return false
+		}
+		return value.Register().Name() != device.AvailabilityRegisterName
+	}
+}
+
+// replayCommands sends the persisted commands to each device as soon as it becomes available for the first time.
+func replayCommands(
+	ctx context.Context,
+	cfg *config.Config,
+	devicePool *pool.Pool[*restarter.Restarter[device.Device]],
+	commandStorage *dataflow.ValueStorage,
+	commands []dataflow.Value,
+) {
+	commandsByDevice := make(map[string][]dataflow.Value)
+	for _, c := range commands {
+		commandsByDevice[c.DeviceName()] = append(commandsByDevice[c.DeviceName()], c)
+	}
+
+	for deviceName, deviceCommands := range commandsByDevice {
+		dev := devicePool.GetByName(deviceName)
+		if dev == nil {
+			log.Printf("persistence: device[%s]: cannot replay commands: device unavailable", deviceName)
+			continue
+		}
+
+		go func() {
+			availCtx, cancel := context.WithCancel(ctx)
+			availChan := dev.Service().SubscribeAvailableSendInitial(availCtx)
+			defer func() {
+				cancel()
+				// drain until the subscription routine has closed the channel
+				for range availChan {
+				}
+			}()
+
+			for avail := range availChan {
+				if !avail {
+					continue
+				}
+				for _, c := range deviceCommands {
+					if cfg.LogWorkerStart() {
+						log.Printf("persistence: device[%s]: replay command: %s", deviceName, c)
+					}
+					commandStorage.Fill(c)
+				}
+				return
+			}
+		}()
+	}
+}
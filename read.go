@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/config"
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+)
+
+type ReadCommand struct {
+	Device  string        `long:"device" required:"true" description:"Name of the device to read"`
+	Format  string        `long:"format" default:"table" choice:"table" choice:"json" choice:"csv" description:"Output format"`
+	Timeout time.Duration `long:"timeout" default:"30s" description:"How long to wait for a complete poll of the device"`
+}
+
+// readRow is one register of the output; the json keys match the mqtt telemetry messages.
+type readRow struct {
+	Category    string
+	Register    string
+	Description string
+	Value       interface{}
+	EnumIdx     *int `json:",omitempty"`
+	Unit        string
+	Time        string
+}
+
+const readCheckInterval = 100 * time.Millisecond
+
+// runRead starts a single device without http server and mqtt clients, waits for one complete poll
+// and prints all its registers.
+func runRead(cfg *config.Config, opts ReadCommand) int {
+	name := opts.Device
+	buses, ok := readableDevice(cfg, name)
+	if !ok {
+		log.Printf("read: device '%s' not found; supported are Victron, Modbus, Gpio and Http devices", name)
+		return ExitDueToCmdOptions
+	}
+
+	stateStorage := runStorage("")
+	defer stateStorage.Shutdown()
+	commandStorage := runStorage("")
+	defer commandStorage.Shutdown()
+
+	// print the values in the same units as the server does
+	setupTransform(cfg, stateStorage, commandStorage)
+
+	modbusPool := runModbus(cfg, selectNames(buses))
+	defer modbusPool.Shutdown()
+
+	devicePool := runDevicePool()
+	defer devicePool.Shutdown()
+
+	// the dependencies are not started, hence the device must not wait for them
+	runNonMqttGensetDevices(cfg, devicePool, modbusPool, stateStorage, commandStorage, selectNames([]string{name}), false)
+
+	watchedDev := devicePool.GetByName(name)
+	if watchedDev == nil {
+		// the reason is logged by runNonMqttGensetDevices
+		return ExitDueToDevice
+	}
+
+	values, complete := waitForPoll(watchedDev.Service(), stateStorage, opts.Timeout)
+	if len(values) < 1 {
+		log.Printf("read[%s]: no values received within %s", name, opts.Timeout)
+		return ExitDueToDevice
+	}
+	if !complete {
+		log.Printf("read[%s]: not all registers received within %s", name, opts.Timeout)
+	}
+
+	if err := printReadRows(opts.Format, readRows(watchedDev.Service().RegisterDb(), values)); err != nil {
+		log.Printf("read[%s]: cannot print: %s", name, err)
+		return ExitDueToCmdOptions
+	}
+	return ExitSuccess
+}
+
+// readableDevice returns whether the device exists and can be run on its own, e.g. without mqtt client,
+// and the modbus buses it needs.
+func readableDevice(cfg *config.Config, name string) (buses []string, ok bool) {
+	for _, d := range cfg.VictronDevices() {
+		if d.Name() == name {
+			return nil, true
+		}
+	}
+	for _, d := range cfg.ModbusDevices() {
+		if d.Name() == name {
+			return []string{d.Bus()}, true
+		}
+	}
+	for _, d := range cfg.GpioDevices() {
+		if d.Name() == name {
+			return nil, true
+		}
+	}
+	for _, d := range cfg.HttpDevices() {
+		if d.Name() == name {
+			return nil, true
+		}
+	}
+	for _, d := range cfg.ReplayDevices() {
+		if d.Name() == name {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// waitForPoll blocks until the device is available and a value of every known register is received.
+// When the timeout is reached, the values received so far are returned and complete is false.
+func waitForPoll(dev device.Device, stateStorage *dataflow.ValueStorage, timeout time.Duration) (values map[string]dataflow.Value, complete bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	availChan := dev.SubscribeAvailableSendInitial(ctx)
+	available := false
+
+	ticker := time.NewTicker(readCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		values = make(map[string]dataflow.Value)
+		for _, v := range stateStorage.GetStateFiltered(dataflow.DeviceNonNullValueFilter(dev.Name())) {
+			if v.Register().Name() != device.AvailabilityRegisterName {
+				values[v.Register().Name()] = v
+			}
+		}
+
+		if available && len(values) > 0 {
+			complete = true
+			for _, reg := range dev.RegisterDb().GetAll() {
+				if _, ok := values[reg.Name()]; !ok && reg.Name() != device.AvailabilityRegisterName {
+					complete = false
+					break
+				}
+			}
+			if complete {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case avail, ok := <-availChan:
+			if !ok {
+				availChan = nil
+				continue
+			}
+			available = avail
+		case <-ticker.C:
+		}
+	}
+}
+
+func readRows(registerDb *dataflow.RegisterDb, values map[string]dataflow.Value) (rows []readRow) {
+	registers := registerDb.GetAll()
+	dataflow.SortRegisterStructs(registers)
+
+	for _, reg := range registers {
+		value, ok := values[reg.Name()]
+		if !ok {
+			continue
+		}
+
+		row := readRow{
+			Category:    reg.Category(),
+			Register:    reg.Name(),
+			Description: reg.Description(),
+			Value:       value.GenericValue(),
+			Unit:        reg.Unit(),
+			Time:        value.Time().Format(time.RFC3339Nano),
+		}
+		if enum, ok := value.(dataflow.EnumRegisterValue); ok {
+			idx := enum.EnumIdx()
+			row.Value = enum.Value()
+			row.EnumIdx = &idx
+		}
+		rows = append(rows, row)
+	}
+	return
+}
+
+func printReadRows(format string, rows []readRow) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write([]string{"Category", "Register", "Description", "Value", "Unit", "Time"})
+		for _, r := range rows {
+			_ = w.Write([]string{r.Category, r.Register, r.Description, formatReadValue(r.Value), r.Unit, r.Time})
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "CATEGORY\tREGISTER\tDESCRIPTION\tVALUE\tUNIT")
+		for _, r := range rows {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Category, r.Register, r.Description, formatReadValue(r.Value), r.Unit)
+		}
+		return w.Flush()
+	}
+}
+
+func formatReadValue(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
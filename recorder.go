@@ -0,0 +1,43 @@
+package main
+
+import (
+	"github.com/koestler/go-iotdevice/v3/config"
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"log"
+	"slices"
+)
+
+// runRecorder starts appending the values of the state storage to the recording; it returns nil when the recorder is disabled.
+func runRecorder(cfg *config.Config, devices *runningDevices, stateStorage *dataflow.ValueStorage) *dataflow.Recorder {
+	recorderCfg := cfg.Recorder()
+	if !recorderCfg.Enabled() {
+		return nil
+	}
+
+	// same as persistence but optionally limited to the given devices
+	filter := persistenceValueFilter(devices)
+	if recorded := recorderCfg.Devices(); len(recorded) > 0 {
+		configured := filter
+		filter = func(value dataflow.Value) bool {
+			return slices.Contains(recorded, value.DeviceName()) && configured(value)
+		}
+	}
+
+	recorder := dataflow.NewRecorder(dataflow.RecorderConfig{
+		File:          recorderCfg.File(),
+		FlushInterval: recorderCfg.FlushInterval(),
+		Filter:        filter,
+		LogDebug:      recorderCfg.LogDebug(),
+	}, stateStorage)
+
+	if err := recorder.Run(); err != nil {
+		log.Printf("recorder: cannot start: %s", err)
+		return nil
+	}
+
+	if cfg.LogWorkerStart() {
+		log.Printf("recorder: start: file=%s", recorderCfg.File())
+	}
+
+	return recorder
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/koestler/go-iotdevice/v3/config"
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+	"github.com/koestler/go-iotdevice/v3/httpServer"
+	"github.com/koestler/go-iotdevice/v3/modbus"
+	"github.com/koestler/go-iotdevice/v3/mqttClient"
+	"github.com/koestler/go-iotdevice/v3/pool"
+	"github.com/koestler/go-iotdevice/v3/restarter"
+)
+
+// nameSelection decides which devices or mqtt clients are started.
+type nameSelection func(name string) bool
+
+func selectAll(string) bool {
+	return true
+}
+
+func selectNames(names []string) nameSelection {
+	return func(name string) bool {
+		return slices.Contains(names, name)
+	}
+}
+
+// reloader reads the configuration file again and restarts only the devices, mqtt clients, forwarders
+// and http routes affected by the changes.
+type reloader struct {
+	cmdName    string
+	configPath string
+
+	cfg            *config.Config
+	stateStorage   *dataflow.ValueStorage
+	commandStorage *dataflow.ValueStorage
+	commands       *dataflow.CommandTracker
+	history        *dataflow.History
+	devices        *runningDevices
+	modbusPool     *pool.Pool[*modbus.ModbusStruct]
+	devicePool     *pool.Pool[*restarter.Restarter[device.Device]]
+	mqttClientPool *pool.Pool[mqttClient.Client]
+
+	expirerCancel  context.CancelFunc
+	forwarderStop  map[string]context.CancelFunc
+	httpServer     *httpServer.HttpServer
+	httpRestart    chan struct{}
+	shutdownCtx    context.Context
+	shutdownCancel context.CancelFunc
+
+	mutex sync.Mutex
+}
+
+// runHttpServer starts the http server and a routine restarting it whenever a reload changed its configuration.
+func (rl *reloader) runHttpServer() {
+	rl.shutdownCtx, rl.shutdownCancel = context.WithCancel(context.Background())
+	rl.httpRestart = make(chan struct{}, 1)
+
+	rl.mutex.Lock()
+	rl.httpServer = rl.newHttpServer()
+	rl.mutex.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-rl.shutdownCtx.Done():
+				return
+			case <-rl.httpRestart:
+			}
+
+			// the reload might have been triggered by a request to the server itself; restart it after the
+			// reload has returned such that the graceful shutdown does not wait for this very request
+			rl.mutex.Lock()
+			old := rl.httpServer
+			rl.httpServer = nil
+			rl.mutex.Unlock()
+
+			if old != nil {
+				old.Shutdown()
+			}
+
+			rl.mutex.Lock()
+			rl.httpServer = rl.newHttpServer()
+			rl.mutex.Unlock()
+		}
+	}()
+}
+
+// newHttpServer must be called with the mutex held.
+func (rl *reloader) newHttpServer() *httpServer.HttpServer {
+	return runHttpServer(
+		rl.cfg, rl.devicePool, rl.stateStorage, rl.commandStorage, rl.commands, rl.history, rl.Reload, rl,
+	)
+}
+
+func (rl *reloader) Shutdown() {
+	rl.shutdownCancel()
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	if rl.httpServer != nil {
+		rl.httpServer.Shutdown()
+	}
+	rl.expirerCancel()
+}
+
+// Reload reads the configuration file again and applies the changes. When the file is invalid or contains
+// changes which can only be applied by restarting the process, the running configuration is kept.
+func (rl *reloader) Reload() (restarted []string, err error) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	updated, errs := config.ReadConfigFile(rl.cmdName, rl.configPath, false)
+	if len(errs) > 0 {
+		return nil, rejectConfig("reload", errs)
+	}
+	updated.KeepGenerated(*rl.cfg)
+
+	return rl.apply("reload", updated)
+}
+
+// rejectConfig logs the errors of an invalid configuration and returns them as one error.
+func rejectConfig(logPrefix string, errs []error) error {
+	for _, e := range errs {
+		log.Printf("%s: config: error: %v", logPrefix, e)
+	}
+	err := fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	log.Printf("%s: rejected: %s", logPrefix, err)
+	return err
+}
+
+// apply replaces the running configuration by updated and restarts everything affected.
+// It must be called with the mutex held.
+func (rl *reloader) apply(logPrefix string, updated config.Config) (restarted []string, err error) {
+	diff := config.Compare(*rl.cfg, updated)
+	if len(diff.Restart) > 0 {
+		err = fmt.Errorf("changing %s requires a restart", strings.Join(diff.Restart, ", "))
+		log.Printf("%s: rejected: %s", logPrefix, err)
+		return nil, err
+	}
+	if diff.Empty() {
+		log.Printf("%s: configuration unchanged", logPrefix)
+		return nil, nil
+	}
+
+	cfg := &updated
+	rl.cfg = cfg
+	rl.devices.set(cfg)
+
+	if cfg.LogConfig() {
+		if err := cfg.PrintConfig(); err != nil {
+			log.Printf("config: cannot print: %s", err)
+		}
+	}
+
+	forwarders := rl.forwardersOf(diff.Devices, slices.Concat(diff.MqttClients, diff.MqttForwarders))
+
+	// stop everything affected
+	rl.stopForwarders(forwarders)
+	rl.stopDevices(diff.Devices)
+	for _, mc := range rl.mqttClientPool.GetByNames(diff.MqttClients) {
+		if cfg.LogWorkerStart() {
+			log.Printf("mqttClient[%s]: stop", mc.Name())
+		}
+		rl.mqttClientPool.Remove(mc)
+		mc.Shutdown()
+	}
+
+	// the settings of the storages are cheap to set up again for all devices
+	dataflow.LogFilterPatterns(cfg.LogConfig())
+	setupDeadband(cfg, rl.stateStorage)
+	setupTransform(cfg, rl.stateStorage, rl.commandStorage)
+	rl.expirerCancel()
+	var expirerCtx context.Context
+	expirerCtx, rl.expirerCancel = context.WithCancel(context.Background())
+	runValueExpirer(expirerCtx, cfg, rl.stateStorage)
+
+	// start the new or changed mqtt clients and devices in the same order as on startup
+	startMqttClients(cfg, rl.mqttClientPool, selectNames(diff.MqttClients))
+	rl.startDevices(diff.Devices)
+	rl.startForwarders(forwarders)
+
+	if diff.HttpServer {
+		select {
+		case rl.httpRestart <- struct{}{}:
+		default:
+			// a restart is already pending; it will use the latest configuration
+		}
+	} else if diff.HttpRoutes && rl.httpServer != nil {
+		rl.httpServer.Reload(httpServerEnvironment(
+			cfg, rl.devicePool, rl.stateStorage, rl.commandStorage, rl.commands, rl.history, rl.Reload, rl,
+		))
+	}
+
+	for _, name := range diff.Devices {
+		restarted = append(restarted, "device:"+name)
+	}
+	for _, name := range diff.MqttClients {
+		restarted = append(restarted, "mqttClient:"+name)
+	}
+	for _, name := range forwarders {
+		restarted = append(restarted, "mqttForwarders:"+name)
+	}
+	if diff.HttpServer {
+		restarted = append(restarted, "httpServer")
+	} else if diff.HttpRoutes {
+		restarted = append(restarted, "httpRoutes")
+	}
+
+	log.Printf("%s: applied; restarted: %s", logPrefix, strings.Join(restarted, ", "))
+	return restarted, nil
+}
+
+// forwardersOf returns the given mqtt clients and all clients forwarding one of the given devices.
+// The forwarders hold on to the device they forward; they must be restarted whenever the device is replaced.
+// It must be called with the mutex held.
+func (rl *reloader) forwardersOf(devices, mqttClients []string) (forwarders []string) {
+	forwarders = slices.Clone(mqttClients)
+	for _, c := range rl.cfg.MqttClients() {
+		if slices.ContainsFunc(forwardedDevices(c), func(name string) bool {
+			return slices.Contains(devices, name)
+		}) {
+			forwarders = append(forwarders, c.Name())
+		}
+	}
+	slices.Sort(forwarders)
+	return slices.Compact(forwarders)
+}
+
+func (rl *reloader) stopForwarders(mqttClients []string) {
+	for _, name := range mqttClients {
+		if stop, ok := rl.forwarderStop[name]; ok {
+			stop()
+			delete(rl.forwarderStop, name)
+		}
+	}
+}
+
+func (rl *reloader) startForwarders(mqttClients []string) {
+	maps.Copy(rl.forwarderStop, runMqttForwarders(
+		rl.cfg, rl.devicePool, rl.mqttClientPool, rl.stateStorage, rl.commands, selectNames(mqttClients),
+	))
+}
+
+func (rl *reloader) stopDevices(devices []string) {
+	for _, dev := range rl.devicePool.GetByNames(devices) {
+		if rl.cfg.LogWorkerStart() {
+			log.Printf("device[%s]: stop", dev.Name())
+		}
+		rl.devicePool.Remove(dev)
+		dev.Shutdown()
+	}
+}
+
+// startDevices starts the given devices of the running configuration in the same order as on startup.
+func (rl *reloader) startDevices(devices []string) {
+	cfg := rl.cfg
+	selected := selectNames(devices)
+	runNonMqttGensetDevices(cfg, rl.devicePool, rl.modbusPool, rl.stateStorage, rl.commandStorage, selected, true)
+	runMqttDevices(cfg, rl.devicePool, rl.mqttClientPool, rl.stateStorage, rl.commandStorage, selected)
+	runGensetDevices(cfg, rl.devicePool, rl.stateStorage, rl.commandStorage, selected)
+	runComputedDevices(cfg, rl.devicePool, rl.stateStorage, selected)
+	runEnergyDevices(cfg, rl.devicePool, rl.stateStorage, selected)
+	runAlarmDevices(cfg, rl.devicePool, rl.stateStorage, rl.commandStorage, selected)
+	runAutomationDevices(cfg, rl.devicePool, rl.stateStorage, rl.commandStorage, rl.commands, selected)
+	runSchedulerDevices(cfg, rl.devicePool, rl.stateStorage, rl.commandStorage, rl.commands, selected)
+}
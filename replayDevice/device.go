@@ -0,0 +1,142 @@
+package replayDevice
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+)
+
+type Config interface {
+	// File is a recording written by the recorder.
+	File() string
+	// DeviceName selects the device of the recording to replay.
+	DeviceName() string
+	// Speed is the factor the recording is accelerated by.
+	Speed() float64
+	// Loop restarts the recording once its end is reached.
+	Loop() bool
+}
+
+type DeviceStruct struct {
+	device.State
+	replayConfig   Config
+	registerFilter dataflow.RegisterFilterFunc
+}
+
+func NewDevice(
+	deviceConfig device.Config,
+	replayConfig Config,
+	stateStorage *dataflow.ValueStorage,
+) *DeviceStruct {
+	return &DeviceStruct{
+		State: device.NewState(
+			deviceConfig,
+			stateStorage,
+		),
+		replayConfig:   replayConfig,
+		registerFilter: dataflow.RegisterFilter(deviceConfig.Filter()),
+	}
+}
+
+func (d *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool) {
+	dName := d.Name()
+	file := d.replayConfig.File()
+
+	all, err := dataflow.LoadRecording(file)
+	if err != nil {
+		return fmt.Errorf("replayDevice[%s]: cannot load recording: %s", dName, err), true
+	}
+	if len(all) == 0 {
+		return fmt.Errorf("replayDevice[%s]: recording %s is empty", dName, file), true
+	}
+
+	// the whole recording defines the timing such that multiple devices replaying the same file stay in sync
+	start := all[0].Time()
+	duration := all[len(all)-1].Time().Sub(start)
+
+	values := make([]dataflow.Value, 0, len(all))
+	for _, v := range all {
+		if v.DeviceName() != d.replayConfig.DeviceName() {
+			continue
+		}
+		// the availability is given by this device
+		if v.Register().Name() == device.AvailabilityRegisterName {
+			continue
+		}
+		if !d.registerFilter(v.Register()) {
+			continue
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("replayDevice[%s]: recording %s contains no values of device=%s",
+			dName, file, d.replayConfig.DeviceName()), true
+	}
+
+	if d.Config().LogDebug() {
+		log.Printf("replayDevice[%s]: replay %d values of %s, duration=%s, speed=%g, loop=%t",
+			dName, len(values), file, duration, d.replayConfig.Speed(), d.replayConfig.Loop())
+	}
+
+	// send connected now, disconnected when this routine stops
+	d.SetAvailable(true)
+	defer func() {
+		d.SetAvailable(false)
+	}()
+
+	scale := func(t time.Duration) time.Duration {
+		return time.Duration(float64(t) / d.replayConfig.Speed())
+	}
+
+	ss := d.StateStorage()
+	for {
+		loopStart := time.Now()
+		for _, v := range values {
+			if !sleepUntil(ctx, loopStart.Add(scale(v.Time().Sub(start)))) {
+				return nil, false
+			}
+			reg := dataflow.NewRegisterStructByInterface(v.Register())
+			d.RegisterDb().AddStruct(reg)
+			ss.Fill(dataflow.NewRegisterValue(dName, reg, v))
+		}
+
+		// a recording without duration would loop without pause
+		if !d.replayConfig.Loop() || duration <= 0 {
+			break
+		}
+		if !sleepUntil(ctx, loopStart.Add(scale(duration))) {
+			return nil, false
+		}
+		if d.Config().LogDebug() {
+			log.Printf("replayDevice[%s]: restart replay", dName)
+		}
+	}
+
+	// keep the last values available until the device is stopped
+	<-ctx.Done()
+	return nil, false
+}
+
+// sleepUntil returns false if the context is cancelled before t.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (d *DeviceStruct) Model() string {
+	return "Replay Device"
+}
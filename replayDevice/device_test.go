@@ -0,0 +1,105 @@
+package replayDevice
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+type testDeviceConfig struct {
+	name string
+}
+
+func (c testDeviceConfig) Name() string                        { return c.name }
+func (c testDeviceConfig) Filter() dataflow.RegisterFilterConf { return testFilterConf{} }
+func (c testDeviceConfig) LogDebug() bool                      { return false }
+func (c testDeviceConfig) LogComDebug() bool                   { return false }
+
+type testFilterConf struct{}
+
+func (testFilterConf) IncludeRegisters() []string  { return nil }
+func (testFilterConf) SkipRegisters() []string     { return []string{"Skipped"} }
+func (testFilterConf) IncludeCategories() []string { return nil }
+func (testFilterConf) SkipCategories() []string    { return nil }
+func (testFilterConf) DefaultInclude() bool        { return true }
+
+type testReplayConfig struct {
+	file string
+}
+
+func (c testReplayConfig) File() string       { return c.file }
+func (c testReplayConfig) DeviceName() string { return "bmv0" }
+func (c testReplayConfig) Speed() float64     { return 10 }
+func (c testReplayConfig) Loop() bool         { return true }
+
+const testRecording = `{"Time":"2024-01-02T15:04:05Z","Device":"bmv0","Name":"Available","Register":{"Cat":"Availability","Name":"Available","Desc":"Available","Type":"enum","Enum":{"0":"No","1":"Yes"},"Unit":"","Sort":-1,"Cmnd":false},"EnumIdx":1}
+{"Time":"2024-01-02T15:04:05Z","Device":"bmv0","Name":"Voltage","Register":{"Cat":"Essential","Name":"Voltage","Desc":"Voltage","Type":"number","Unit":"V","Sort":0,"Cmnd":false},"NumVal":12.5}
+{"Time":"2024-01-02T15:04:05Z","Device":"bmv0","Name":"Skipped","Register":{"Cat":"Essential","Name":"Skipped","Desc":"","Type":"number","Unit":"","Sort":1,"Cmnd":false},"NumVal":1}
+{"Time":"2024-01-02T15:04:05.5Z","Device":"bmv1","Name":"Voltage","Register":{"Cat":"Essential","Name":"Voltage","Desc":"Voltage","Type":"number","Unit":"V","Sort":0,"Cmnd":false},"NumVal":24}
+{"Time":"2024-01-02T15:04:06Z","Device":"bmv0","Name":"Voltage","NumVal":12.6}
+`
+
+func TestDevice(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "recording.jsonl")
+	if err := os.WriteFile(file, []byte(testRecording), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := dataflow.NewValueStorage()
+	dev := NewDevice(testDeviceConfig{"replay0"}, testReplayConfig{file}, storage)
+
+	subCtx, subCancel := context.WithCancel(context.Background())
+	defer subCancel()
+	sub := storage.SubscribeSendInitial(subCtx, func(v dataflow.Value) bool {
+		return v.DeviceName() == "replay0"
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err, _ := dev.Run(ctx); err != nil {
+			t.Errorf("did not expect an error, got: %s", err)
+		}
+	}()
+
+	next := func(expect string) {
+		t.Helper()
+		select {
+		case v := <-sub.Drain():
+			if got := v.String(); expect != got {
+				t.Errorf("expect '%s' but got '%s'", expect, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expect '%s' but got nothing", expect)
+		}
+	}
+
+	// the recording is replayed ten times faster and restarts after 100ms
+	next("Available=1:online")
+	next("Voltage=12.500000V")
+	next("Voltage=12.600000V")
+	next("Voltage=12.500000V")
+	next("Voltage=12.600000V")
+
+	regs := dev.RegisterDb().GetAll()
+	if expect, got := 2, len(regs); expect != got {
+		t.Errorf("expect %d registers but got %d: %v", expect, got, regs)
+	}
+
+	cancel()
+	<-done
+	for {
+		v := <-sub.Drain()
+		if v.Register().Name() == "Available" {
+			if expect, got := "Available=0:offline", v.String(); expect != got {
+				t.Errorf("expect '%s' but got '%s'", expect, got)
+			}
+			break
+		}
+	}
+}
@@ -19,10 +19,22 @@ type Restartable interface {
 	Run(ctx context.Context) (err error, immediateError bool)
 }
 
+// Status describes what the restarter is currently doing.
+type Status struct {
+	Running     bool // true while the service is running, false while waiting for the next restart or after Shutdown
+	Stopped     bool // true after Shutdown was called
+	Restarts    int  // how many times the service was restarted after an error
+	StartedAt   time.Time
+	LastError   error
+	LastErrorAt time.Time
+}
+
 type Restarter[S Restartable] struct {
-	config    Config
-	service   S
-	isRunning bool
+	config  Config
+	service S
+
+	status      Status
+	statusMutex sync.RWMutex
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -61,7 +73,19 @@ func (w *Restarter[S]) Run() {
 			}
 
 			start := time.Now()
+			w.updateStatus(func(s *Status) {
+				s.Running = true
+				s.StartedAt = start
+			})
 			err, immediateError := w.service.Run(w.ctx)
+			w.updateStatus(func(s *Status) {
+				s.Running = false
+				if err != nil {
+					s.LastError = err
+					s.LastErrorAt = time.Now()
+					s.Restarts += 1
+				}
+			})
 			if err == nil {
 				// shutdown
 				return
@@ -92,6 +116,9 @@ func (w *Restarter[S]) Run() {
 func (w *Restarter[S]) Shutdown() {
 	w.cancel()
 	w.wg.Wait()
+	w.updateStatus(func(s *Status) {
+		s.Stopped = true
+	})
 }
 
 func (w *Restarter[S]) GetCtx() context.Context {
@@ -107,7 +134,19 @@ func (w *Restarter[S]) Service() S {
 }
 
 func (w *Restarter[S]) IsRunning() bool {
-	return w.isRunning
+	return w.Status().Running
+}
+
+func (w *Restarter[S]) Status() Status {
+	w.statusMutex.RLock()
+	defer w.statusMutex.RUnlock()
+	return w.status
+}
+
+func (w *Restarter[S]) updateStatus(update func(s *Status)) {
+	w.statusMutex.Lock()
+	defer w.statusMutex.Unlock()
+	update(&w.status)
 }
 
 func (w *Restarter[S]) getRestartInterval(errorsInARow int) time.Duration {
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/modbus"
+	"github.com/koestler/go-iotdevice/v3/modbusDevice"
+	"github.com/koestler/go-iotdevice/v3/types"
+	"github.com/koestler/go-iotdevice/v3/victronDevice"
+	"gopkg.in/yaml.v3"
+)
+
+type ScanCommand struct {
+	Modbus      bool          `long:"modbus" description:"Probe the Modbus addresses 1-247 of all ports without a VE.Direct device"`
+	BaudRate    int           `long:"baud-rate" default:"9600" description:"Baud rate used to probe Modbus devices"`
+	ReadTimeout time.Duration `long:"read-timeout" default:"100ms" description:"How long to wait for the response of a Modbus device"`
+	Args        struct {
+		Ports []string `positional-arg-name:"port" description:"Serial ports to probe; default all found in /dev/serial/by-id/ and /dev/tty{USB,ACM}*"`
+	} `positional-args:"yes"`
+}
+
+type scanModbusDevice struct {
+	kind    types.ModbusDeviceKind
+	address byte
+	model   string
+}
+
+// runScan probes serial ports for VE.Direct and Modbus devices and prints the configuration sections for the found ones.
+func runScan(opts ScanCommand) int {
+	ports := opts.Args.Ports
+	if len(ports) < 1 {
+		ports = serialPorts()
+	}
+	if len(ports) < 1 {
+		log.Printf("scan: no serial ports found")
+		return ExitSuccess
+	}
+
+	victronDevices := yamlMapping()
+	modbusBuses := yamlMapping()
+	modbusDevices := yamlMapping()
+
+	for _, port := range ports {
+		log.Printf("scan[%s]: try VE.Direct", port)
+		if product, err := victronDevice.Probe(port); err == nil {
+			log.Printf("scan[%s]: found %s", port, product)
+			name := fmt.Sprintf("victron%d", len(victronDevices.Content)/2)
+			addYamlEntry(victronDevices, name, product, yamlMapping(
+				"Device", port,
+				"Kind", types.VictronVedirectKind.String(),
+			))
+			continue
+		} else if !opts.Modbus {
+			log.Printf("scan[%s]: no VE.Direct device: %s", port, err)
+			continue
+		}
+
+		found := scanModbus(port, opts.BaudRate, opts.ReadTimeout)
+		if len(found) < 1 {
+			continue
+		}
+
+		bus := fmt.Sprintf("bus%d", len(modbusBuses.Content)/2)
+		addYamlEntry(modbusBuses, bus, "", yamlMapping(
+			"Device", port,
+			"BaudRate", fmt.Sprint(opts.BaudRate),
+		))
+		for _, d := range found {
+			name := fmt.Sprintf("modbus%d", len(modbusDevices.Content)/2)
+			addYamlEntry(modbusDevices, name, d.model, yamlMapping(
+				"Bus", bus,
+				"Kind", d.kind.String(),
+				"Address", fmt.Sprintf("0x%02X", d.address),
+			))
+		}
+	}
+
+	snippet := yamlMapping()
+	for _, section := range []struct {
+		name    string
+		entries *yaml.Node
+	}{
+		{"VictronDevices", victronDevices},
+		{"Modbus", modbusBuses},
+		{"ModbusDevices", modbusDevices},
+	} {
+		if len(section.entries.Content) > 0 {
+			addYamlEntry(snippet, section.name, "", section.entries)
+		}
+	}
+	if len(snippet.Content) < 1 {
+		log.Printf("scan: no devices found")
+		return ExitSuccess
+	}
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(snippet); err != nil {
+		log.Printf("scan: cannot encode yaml: %s", err)
+		return ExitDueToCmdOptions
+	}
+	return ExitSuccess
+}
+
+// serialPorts lists the serial ports by their stable /dev/serial/by-id/ path when available.
+func serialPorts() (ports []string) {
+	byId, _ := filepath.Glob("/dev/serial/by-id/*")
+	linked := make(map[string]struct{}, len(byId))
+	for _, p := range byId {
+		if target, err := filepath.EvalSymlinks(p); err == nil {
+			linked[target] = struct{}{}
+		}
+		ports = append(ports, p)
+	}
+
+	usb, _ := filepath.Glob("/dev/ttyUSB*")
+	acm, _ := filepath.Glob("/dev/ttyACM*")
+	for _, p := range append(usb, acm...) {
+		if _, ok := linked[p]; !ok {
+			ports = append(ports, p)
+		}
+	}
+	return
+}
+
+// scanModbus probes all addresses of the bus for the supported kinds of Modbus devices.
+func scanModbus(port string, baudRate int, readTimeout time.Duration) (found []scanModbusDevice) {
+	mb, err := modbus.New(scanModbusConfig{device: port, baudRate: baudRate, readTimeout: readTimeout})
+	if err != nil {
+		log.Printf("scan[%s]: %s", port, err)
+		return
+	}
+	defer mb.Shutdown()
+
+	log.Printf("scan[%s]: probe Modbus addresses 1-247 at %d baud", port, baudRate)
+	for address := byte(1); address <= 247; address++ {
+		if version, err := modbusDevice.WaveshareReadSoftwareRevision(mb.WriteRead, address); err == nil {
+			log.Printf("scan[%s]: found %s at address 0x%02X", port, types.ModbusWaveshareRtuRelay8Kind, address)
+			found = append(found, scanModbusDevice{types.ModbusWaveshareRtuRelay8Kind, address, "software " + version})
+		} else if model, err := modbusDevice.FinderReadModelNumber(mb.WriteRead, address); err == nil {
+			log.Printf("scan[%s]: found %s at address 0x%02X", port, types.ModbusFinder7M38Kind, address)
+			found = append(found, scanModbusDevice{types.ModbusFinder7M38Kind, address, model})
+		}
+	}
+	return
+}
+
+type scanModbusConfig struct {
+	device      string
+	baudRate    int
+	readTimeout time.Duration
+}
+
+func (c scanModbusConfig) Name() string {
+	return "scan"
+}
+
+func (c scanModbusConfig) Device() string {
+	return c.device
+}
+
+func (c scanModbusConfig) BaudRate() int {
+	return c.baudRate
+}
+
+func (c scanModbusConfig) ReadTimeout() time.Duration {
+	return c.readTimeout
+}
+
+func (c scanModbusConfig) LogDebug() bool {
+	return false
+}
+
+// yamlMapping creates a mapping node of the given key value pairs.
+func yamlMapping(keyValues ...string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		addYamlEntry(n, keyValues[i], "", &yaml.Node{Kind: yaml.ScalarNode, Value: keyValues[i+1]})
+	}
+	return n
+}
+
+func addYamlEntry(mapping *yaml.Node, key, comment string, value *yaml.Node) {
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key, LineComment: strings.TrimSpace(comment)},
+		value,
+	)
+}
@@ -0,0 +1,182 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed cron expression with the five fields minute, hour, day of month, month and day of week.
+// Every field supports *, lists (1,15), ranges (1-5) and steps (*/15, 0-30/10). Months and days of week
+// can also be given by their english three letter abbreviations; Sunday is 0 or 7.
+// As usual, when both day of month and day of week are restricted, a day matching either of them matches.
+type Cron struct {
+	source  string
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: []string{
+		"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec",
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: []string{
+		"sun", "mon", "tue", "wed", "thu", "fri", "sat",
+	}}
+)
+
+func ParseCron(source string) (c *Cron, err error) {
+	fields := strings.Fields(source)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expect 5 fields but got %d", len(fields))
+	}
+
+	c = &Cron{source: source}
+	if c.minute, _, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if c.hour, _, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if c.dom, c.domStar, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if c.month, _, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if c.dow, c.dowStar, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if c.dow&(1<<7) != 0 {
+		// 7 is an alias for sunday
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+func (c *Cron) String() string {
+	return c.source
+}
+
+// Next returns the first time strictly after t matching the expression, in the location of t.
+// The zero time is returned when there is no such time within the next five years, e.g. for 0 0 30 2 *.
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// parse returns the set bits of the allowed values and whether the field is unrestricted.
+func (f cronField) parse(source string) (bits uint64, star bool, err error) {
+	star = source == "*"
+	for _, part := range strings.Split(source, ",") {
+		b, e := f.parsePart(part)
+		if e != nil {
+			return 0, false, fmt.Errorf("invalid %s '%s': %s", f.name, part, e)
+		}
+		bits |= b
+	}
+	return
+}
+
+func (f cronField) parsePart(part string) (bits uint64, err error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+			return 0, errors.New("invalid step")
+		}
+	}
+
+	var from, to int
+	if rangePart == "*" {
+		from, to = f.min, f.max
+	} else if fromPart, toPart, isRange := strings.Cut(rangePart, "-"); isRange {
+		if from, err = f.value(fromPart); err != nil {
+			return 0, err
+		}
+		if to, err = f.value(toPart); err != nil {
+			return 0, err
+		}
+		if to < from {
+			return 0, errors.New("range end before start")
+		}
+	} else {
+		if from, err = f.value(rangePart); err != nil {
+			return 0, err
+		}
+		to = from
+		if hasStep {
+			// 5/15 means every 15 starting at 5
+			to = f.max
+		}
+	}
+
+	for v := from; v <= to; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if len(name) > 0 && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New("not a number")
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("out of range %d-%d", f.min, f.max)
+	}
+	return v, nil
+}
@@ -0,0 +1,35 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/types"
+)
+
+// Schedule computes the time slots of a cron or sun based schedule.
+type Schedule interface {
+	// Next returns the first slot strictly after t; the zero time means there is none.
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// SunSchedule has a slot at every sunrise or sunset, moved by the offset.
+type SunSchedule struct {
+	Sun    Sun
+	Event  types.SunEvent
+	Offset time.Duration
+}
+
+func (s SunSchedule) Next(t time.Time) time.Time {
+	return s.Sun.Next(s.Event, s.Offset, t)
+}
+
+func (s SunSchedule) String() string {
+	if s.Offset == 0 {
+		return s.Event.String()
+	}
+	if s.Offset > 0 {
+		return s.Event.String() + "+" + s.Offset.String()
+	}
+	return s.Event.String() + s.Offset.String()
+}
@@ -0,0 +1,91 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/types"
+)
+
+func TestCron(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	start := time.Date(2024, 6, 1, 12, 30, 15, 0, loc) // a saturday
+
+	tests := []struct {
+		source string
+		expect time.Time
+	}{
+		{"0 5 * * *", time.Date(2024, 6, 2, 5, 0, 0, 0, loc)},
+		{"*/15 * * * *", time.Date(2024, 6, 1, 12, 45, 0, 0, loc)},
+		{"30 12 * * *", time.Date(2024, 6, 2, 12, 30, 0, 0, loc)},
+		{"0 10 * * 0", time.Date(2024, 6, 2, 10, 0, 0, 0, loc)},
+		{"0 10 * * 7", time.Date(2024, 6, 2, 10, 0, 0, 0, loc)},
+		{"0 10 * * mon-fri", time.Date(2024, 6, 3, 10, 0, 0, 0, loc)},
+		{"0 0 1 jan *", time.Date(2025, 1, 1, 0, 0, 0, 0, loc)},
+		{"0 8 15 * 1", time.Date(2024, 6, 3, 8, 0, 0, 0, loc)},
+		{"5-10/5 22 * * *", time.Date(2024, 6, 1, 22, 5, 0, 0, loc)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tc := range tests {
+		c, err := ParseCron(tc.source)
+		if err != nil {
+			t.Errorf("did not expect an error for '%s', got: %s", tc.source, err)
+			continue
+		}
+		if got := c.Next(start); !tc.expect.Equal(got) {
+			t.Errorf("expect next of '%s' to be %s but got %s", tc.source, tc.expect, got)
+		}
+	}
+
+	if c, err := ParseCron("0 0 30 2 *"); err != nil {
+		t.Errorf("did not expect an error, got: %s", err)
+	} else if got := c.Next(start); !got.IsZero() {
+		t.Errorf("expect no next time for the 30th of february, got %s", got)
+	}
+}
+
+func TestInvalidCron(t *testing.T) {
+	for _, source := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		if _, err := ParseCron(source); err == nil {
+			t.Errorf("expect an error for '%s'", source)
+		}
+	}
+}
+
+func TestSun(t *testing.T) {
+	cest := time.FixedZone("CEST", 2*3600)
+	bern := Sun{Latitude: 46.95, Longitude: 7.45}
+
+	expectAbout := func(t *testing.T, expect, got time.Time) {
+		t.Helper()
+		if d := got.Sub(expect); d < -3*time.Minute || d > 3*time.Minute {
+			t.Errorf("expect about %s but got %s", expect, got)
+		}
+	}
+
+	day := time.Date(2024, 6, 21, 0, 0, 0, 0, cest)
+	if got, ok := bern.Event(types.SunEventSunrise, day); !ok {
+		t.Error("expect a sunrise")
+	} else {
+		expectAbout(t, time.Date(2024, 6, 21, 5, 35, 0, 0, cest), got)
+	}
+	if got, ok := bern.Event(types.SunEventSunset, day); !ok {
+		t.Error("expect a sunset")
+	} else {
+		expectAbout(t, time.Date(2024, 6, 21, 21, 28, 0, 0, cest), got)
+	}
+
+	// after today's sunset minus 30 minutes, the next event is tomorrow's
+	next := bern.Next(types.SunEventSunset, -30*time.Minute, time.Date(2024, 6, 21, 21, 0, 0, 0, cest))
+	expectAbout(t, time.Date(2024, 6, 22, 20, 58, 0, 0, cest), next)
+
+	// no sunrise in tromsø during the polar night
+	tromso := Sun{Latitude: 69.65, Longitude: 18.96}
+	if _, ok := tromso.Event(types.SunEventSunrise, time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC)); ok {
+		t.Error("did not expect a sunrise during the polar night")
+	}
+	if next := tromso.Next(types.SunEventSunrise, 0, time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC)); next.Month() != time.January {
+		t.Errorf("expect the first sunrise in january, got %s", next)
+	}
+}
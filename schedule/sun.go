@@ -0,0 +1,71 @@
+package schedule
+
+import (
+	"math"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/types"
+)
+
+// Sun computes sunrise and sunset locally using the sunrise equation; the result is accurate to about a minute.
+type Sun struct {
+	Latitude  float64 // degrees, north positive
+	Longitude float64 // degrees, east positive
+}
+
+const (
+	julianUnixEpoch = 2440587.5
+	julian2000      = 2451545.0
+	degree          = math.Pi / 180
+)
+
+// Event returns the time of sunrise or sunset on the given day in the location of date.
+// ok is false when the sun does not rise or set on that day, e.g. during the polar night.
+func (s Sun) Event(event types.SunEvent, date time.Time) (t time.Time, ok bool) {
+	// the day number since 2000-01-01 of the date at midnight UTC
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	n := math.Ceil(float64(midnight.Unix())/86400 + julianUnixEpoch - julian2000 + 0.0008)
+
+	meanSolarNoon := n - s.Longitude/360
+	m := math.Mod(357.5291+0.98560028*meanSolarNoon, 360)
+	c := 1.9148*math.Sin(m*degree) + 0.02*math.Sin(2*m*degree) + 0.0003*math.Sin(3*m*degree)
+	lambda := math.Mod(m+c+180+102.9372, 360)
+	transit := julian2000 + meanSolarNoon + 0.0053*math.Sin(m*degree) - 0.0069*math.Sin(2*lambda*degree)
+
+	sinDeclination := math.Sin(lambda*degree) * math.Sin(23.4397*degree)
+	cosDeclination := math.Cos(math.Asin(sinDeclination))
+	cosHourAngle := (math.Sin(-0.833*degree) - math.Sin(s.Latitude*degree)*sinDeclination) /
+		(math.Cos(s.Latitude*degree) * cosDeclination)
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return time.Time{}, false
+	}
+	hourAngle := math.Acos(cosHourAngle) / degree
+
+	var julian float64
+	switch event {
+	case types.SunEventSunrise:
+		julian = transit - hourAngle/360
+	case types.SunEventSunset:
+		julian = transit + hourAngle/360
+	default:
+		return time.Time{}, false
+	}
+
+	seconds := (julian - julianUnixEpoch) * 86400
+	return time.Unix(0, int64(seconds*1e9)).In(date.Location()), true
+}
+
+// Next returns the first sunrise or sunset plus the offset strictly after t.
+// The zero time is returned when there is no such event within the next year.
+func (s Sun) Next(event types.SunEvent, offset time.Duration, t time.Time) time.Time {
+	day := t.Add(-offset)
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, t.Location())
+	for i := -1; i <= 366; i++ {
+		if e, ok := s.Event(event, day.AddDate(0, 0, i)); ok {
+			if e = e.Add(offset); e.After(t) {
+				return e
+			}
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,247 @@
+package schedulerDevice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+	"github.com/koestler/go-iotdevice/v3/schedule"
+	"github.com/koestler/go-iotdevice/v3/types"
+)
+
+// tickInterval defines how often due and pending runs are checked.
+const tickInterval = time.Second
+
+type Config interface {
+	Latitude() float64
+	Longitude() float64
+	Schedules() []Schedule
+}
+
+type Schedule interface {
+	Name() string
+	Cron() string
+	Sun() types.SunEvent
+	Offset() time.Duration
+	DeviceName() string
+	RegisterName() string
+	Value() string
+	MaxDelay() time.Duration
+	Enabled() bool
+	Category() string
+	Description() string
+	Sort() int
+}
+
+type RegisterDbOfDeviceFunc func(deviceName string) *dataflow.RegisterDb
+
+type DeviceStruct struct {
+	device.State
+	schedulerConfig Config
+
+	commandStorage     *dataflow.ValueStorage
+	commands           *dataflow.CommandTracker
+	registerDbOfDevice RegisterDbOfDeviceFunc
+
+	// enabled is kept across restarts of Run
+	enabled []bool
+}
+
+func NewDevice(
+	deviceConfig device.Config,
+	schedulerConfig Config,
+	stateStorage *dataflow.ValueStorage,
+	commandStorage *dataflow.ValueStorage,
+	commands *dataflow.CommandTracker,
+	registerDbOfDevice RegisterDbOfDeviceFunc,
+) *DeviceStruct {
+	schedules := schedulerConfig.Schedules()
+	enabled := make([]bool, len(schedules))
+	for i, s := range schedules {
+		enabled[i] = s.Enabled()
+	}
+
+	return &DeviceStruct{
+		State: device.NewState(
+			deviceConfig,
+			stateStorage,
+		),
+		schedulerConfig:    schedulerConfig,
+		commandStorage:     commandStorage,
+		commands:           commands,
+		registerDbOfDevice: registerDbOfDevice,
+		enabled:            enabled,
+	}
+}
+
+func (d *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool) {
+	dName := d.Config().Name()
+	ss := d.StateStorage()
+	now := time.Now()
+
+	schedules := d.schedulerConfig.Schedules()
+	slots := make([]*slot, len(schedules))
+	registers := make([]scheduleRegisters, len(schedules))
+	targets := make(map[string]struct{})
+	for i, s := range schedules {
+		var sched schedule.Schedule
+		if len(s.Cron()) > 0 {
+			if sched, err = schedule.ParseCron(s.Cron()); err != nil {
+				return fmt.Errorf("schedulerDevice[%s]: %s: invalid cron expression: %s", dName, s.Name(), err), true
+			}
+		} else {
+			sched = schedule.SunSchedule{
+				Sun:    schedule.Sun{Latitude: d.schedulerConfig.Latitude(), Longitude: d.schedulerConfig.Longitude()},
+				Event:  s.Sun(),
+				Offset: s.Offset(),
+			}
+		}
+
+		slots[i] = &slot{schedule: sched, maxDelay: s.MaxDelay(), enabled: d.enabled[i]}
+		slots[i].reset(now)
+		registers[i] = addToRegisterDb(d.State.RegisterDb(), s) //nolint:staticcheck
+		targets[s.DeviceName()] = struct{}{}
+	}
+
+	publish := func(i int) {
+		r := registers[i]
+		ss.Fill(dataflow.NewEnumRegisterValue(dName, r.enabled, enableIdx(slots[i].enabled)))
+		if next := slots[i].next; next.IsZero() {
+			ss.Fill(dataflow.NewNullRegisterValue(dName, r.nextRun))
+		} else {
+			ss.Fill(dataflow.NewTextRegisterValue(dName, r.nextRun, next.Format(time.RFC3339)))
+		}
+	}
+
+	// send connected now, disconnected when this routine stops
+	d.SetAvailable(true)
+	defer func() {
+		d.SetAvailable(false)
+	}()
+
+	for i := range slots {
+		publish(i)
+		if d.Config().LogDebug() {
+			log.Printf("schedulerDevice[%s]: %s: %s, next run at %s", dName, schedules[i].Name(), slots[i].schedule, slots[i].next)
+		}
+	}
+
+	// subscribe to the availability of the target devices
+	available := make(map[string]bool)
+	sub := ss.SubscribeSendInitialWithPolicy(ctx, func(v dataflow.Value) bool {
+		_, ok := targets[v.DeviceName()]
+		return ok && v.Register().Name() == device.AvailabilityRegisterName
+	}, dataflow.OverflowCoalesce)
+
+	_, commandSub := d.commandStorage.SubscribeReturnInitial(ctx, dataflow.DeviceNonNullValueFilter(dName))
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	values := sub.Drain()
+	commands := commandSub.Drain()
+	for {
+		select {
+		case v, ok := <-values:
+			if !ok {
+				// the subscription is closed when ctx is cancelled
+				return nil, false
+			}
+			if v.Restored() {
+				continue
+			}
+			ev, ok := v.(dataflow.EnumRegisterValue)
+			available[v.DeviceName()] = ok && ev.Value() == device.AvailabilityOnlineValue
+		case t := <-ticker.C:
+			for i, s := range slots {
+				if s.advance(t) {
+					publish(i)
+				}
+				d.execPending(schedules[i], s, t, available[schedules[i].DeviceName()])
+			}
+		case v, ok := <-commands:
+			if !ok {
+				return nil, false
+			}
+			d.execEnable(v, schedules, slots, publish)
+		}
+	}
+}
+
+// execPending sends the value of a pending run once the target device and its register are available.
+func (d *DeviceStruct) execPending(s Schedule, sl *slot, now time.Time, deviceAvailable bool) {
+	dName := d.Config().Name()
+
+	var register dataflow.Register
+	available := false
+	if deviceAvailable {
+		register, available = d.registerDbOfDevice(s.DeviceName()).GetByName(s.RegisterName())
+	}
+
+	run, ok, missed := sl.take(now, available)
+	if missed {
+		log.Printf("schedulerDevice[%s]: %s: skip run of %s, target was not available within %s",
+			dName, s.Name(), run.Format(time.RFC3339), s.MaxDelay(),
+		)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	v, err := dataflow.ParseRegisterValue(s.DeviceName(), register, s.Value())
+	if err == nil {
+		err = dataflow.ValidateCommand(v)
+	}
+	if err != nil {
+		log.Printf("schedulerDevice[%s]: %s: cannot run: %s", dName, s.Name(), err)
+		return
+	}
+
+	status := d.commands.Fill(v)
+	log.Printf("schedulerDevice[%s]: %s: run of %s, id=%s: %s", dName, s.Name(), run.Format(time.RFC3339), status.Id, v)
+}
+
+func (d *DeviceStruct) execEnable(v dataflow.Value, schedules []Schedule, slots []*slot, publish func(i int)) {
+	// reset the command; the enabled state is published in the state storage
+	defer d.commandStorage.Fill(dataflow.NewNullRegisterValue(d.Config().Name(), v.Register()))
+
+	ev, ok := v.(dataflow.EnumRegisterValue)
+	if !ok {
+		d.commandStorage.CommandDone(v, errors.New("not an enum value"))
+		return
+	}
+
+	for i, s := range schedules {
+		if s.Name()+"Enabled" != v.Register().Name() {
+			continue
+		}
+		enabled := ev.EnumIdx() == enableEnabled
+		if slots[i].enabled != enabled {
+			d.enabled[i] = enabled
+			slots[i].enabled = enabled
+			slots[i].reset(time.Now())
+			log.Printf("schedulerDevice[%s]: %s: %s", d.Config().Name(), s.Name(), enableEnum[ev.EnumIdx()])
+			publish(i)
+		}
+		d.commandStorage.CommandDone(v, nil)
+		return
+	}
+
+	d.commandStorage.CommandDone(v, fmt.Errorf("unknown register %s", v.Register().Name()))
+}
+
+func enableIdx(enabled bool) int {
+	if enabled {
+		return enableEnabled
+	}
+	return enableDisabled
+}
+
+func (d *DeviceStruct) Model() string {
+	return "Scheduler"
+}
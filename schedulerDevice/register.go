@@ -0,0 +1,35 @@
+package schedulerDevice
+
+import (
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+)
+
+const (
+	enableDisabled = 0
+	enableEnabled  = 1
+)
+
+var enableEnum = map[int]string{
+	enableDisabled: "disabled",
+	enableEnabled:  "enabled",
+}
+
+// scheduleRegisters are the registers of this device published for one schedule.
+type scheduleRegisters struct {
+	enabled, nextRun dataflow.RegisterStruct
+}
+
+func addToRegisterDb(rdb *dataflow.RegisterDb, s Schedule) scheduleRegisters {
+	r := scheduleRegisters{
+		enabled: dataflow.NewRegisterStruct(
+			s.Category(), s.Name()+"Enabled", s.Description()+" Enabled",
+			dataflow.EnumRegister, enableEnum, "", s.Sort()*10, true,
+		),
+		nextRun: dataflow.NewRegisterStruct(
+			s.Category(), s.Name()+"NextRun", s.Description()+" Next Run",
+			dataflow.TextRegister, nil, "", s.Sort()*10+1, false,
+		),
+	}
+	rdb.AddStruct(r.enabled, r.nextRun)
+	return r
+}
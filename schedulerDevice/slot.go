@@ -0,0 +1,58 @@
+package schedulerDevice
+
+import (
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/schedule"
+)
+
+// slot tracks the next and the pending run of one schedule. A run becomes pending once its time has come
+// and stays pending until the target is available; when it is missed by more than maxDelay, it is skipped.
+type slot struct {
+	schedule schedule.Schedule
+	maxDelay time.Duration
+	enabled  bool
+
+	next    time.Time
+	pending time.Time
+}
+
+// reset computes the next run after now and forgets a pending one.
+func (s *slot) reset(now time.Time) {
+	s.pending = time.Time{}
+	s.next = time.Time{}
+	if s.enabled {
+		s.next = s.schedule.Next(now)
+	}
+}
+
+// advance makes the runs whose time has come pending. It returns true when the next run changed.
+func (s *slot) advance(now time.Time) bool {
+	changed := false
+	for !s.next.IsZero() && !now.Before(s.next) {
+		// a newer run replaces a pending one
+		s.pending = s.next
+		s.next = s.schedule.Next(s.next)
+		changed = true
+	}
+	return changed
+}
+
+// take returns the pending run when it is to be executed now, i.e. when the target is available.
+// A run missed by more than maxDelay is dropped and returned with missed set. Runs only become pending
+// on a tick, hence a delay of up to one tickInterval counts as on time.
+func (s *slot) take(now time.Time, available bool) (run time.Time, ok, missed bool) {
+	if s.pending.IsZero() {
+		return
+	}
+	run = s.pending
+	if now.Sub(run) > s.maxDelay+tickInterval {
+		s.pending = time.Time{}
+		return run, false, true
+	}
+	if !available {
+		return run, false, false
+	}
+	s.pending = time.Time{}
+	return run, true, false
+}
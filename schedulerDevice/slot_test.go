@@ -0,0 +1,96 @@
+package schedulerDevice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/koestler/go-iotdevice/v3/schedule"
+)
+
+func TestSlot(t *testing.T) {
+	start := time.Date(2024, 6, 1, 4, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time {
+		return start.Add(d)
+	}
+
+	cron, err := schedule.ParseCron("0 5 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newSlot := func() *slot {
+		s := &slot{schedule: cron, maxDelay: 10 * time.Minute, enabled: true}
+		s.reset(start)
+		return s
+	}
+
+	t.Run("run", func(t *testing.T) {
+		s := newSlot()
+		if expect, got := at(time.Hour), s.next; !expect.Equal(got) {
+			t.Errorf("expect next run at %s but got %s", expect, got)
+		}
+		if s.advance(at(59 * time.Minute)) {
+			t.Error("did not expect a change before the next run")
+		}
+		if !s.advance(at(time.Hour)) {
+			t.Error("expect a change once the run is due")
+		}
+		if expect, got := at(25*time.Hour), s.next; !expect.Equal(got) {
+			t.Errorf("expect next run at %s but got %s", expect, got)
+		}
+		if run, ok, _ := s.take(at(time.Hour), true); !ok || !run.Equal(at(time.Hour)) {
+			t.Errorf("expect the run to be taken, got %s %t", run, ok)
+		}
+		if _, ok, _ := s.take(at(time.Hour), true); ok {
+			t.Error("did not expect a second run")
+		}
+	})
+
+	t.Run("retryWhenAvailable", func(t *testing.T) {
+		s := newSlot()
+		s.advance(at(time.Hour))
+		if _, ok, missed := s.take(at(time.Hour), false); ok || missed {
+			t.Error("expect the run to stay pending while the target is unavailable")
+		}
+		if _, ok, _ := s.take(at(time.Hour+5*time.Minute), true); !ok {
+			t.Error("expect the run once the target is available")
+		}
+	})
+
+	t.Run("missed", func(t *testing.T) {
+		s := newSlot()
+		s.advance(at(time.Hour))
+		if _, ok, missed := s.take(at(time.Hour+11*time.Minute), true); ok || !missed {
+			t.Error("expect the run to be missed")
+		}
+		if _, ok, missed := s.take(at(time.Hour+12*time.Minute), true); ok || missed {
+			t.Error("expect nothing pending after a missed run")
+		}
+	})
+
+	t.Run("noMaxDelay", func(t *testing.T) {
+		s := newSlot()
+		s.maxDelay = 0
+		// the run becomes pending at the first tick after its time
+		s.advance(at(time.Hour + 300*time.Millisecond))
+		if _, ok, missed := s.take(at(time.Hour+300*time.Millisecond), true); !ok || missed {
+			t.Error("expect the run to be taken on the tick after its time")
+		}
+		s.advance(at(25*time.Hour + 300*time.Millisecond))
+		if _, ok, missed := s.take(at(25*time.Hour+2*time.Second), true); ok || !missed {
+			t.Error("expect the run to be missed when it is late by more than a tick")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s := newSlot()
+		s.enabled = false
+		s.reset(start)
+		if !s.next.IsZero() {
+			t.Errorf("expect no next run, got %s", s.next)
+		}
+		s.advance(at(2 * time.Hour))
+		if _, ok, _ := s.take(at(2*time.Hour), true); ok {
+			t.Error("did not expect a run while disabled")
+		}
+	})
+}
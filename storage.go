@@ -2,16 +2,177 @@ package main
 
 import (
 	"context"
+	"github.com/koestler/go-iotdevice/v3/config"
 	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/device"
+	"log"
+	"time"
 )
 
+// valueExpireInterval defines how often the state storage is checked for values older than their MaxAge.
+const valueExpireInterval = time.Second
+
 func runStorage(logPrefix string) *dataflow.ValueStorage {
 	valueStorage := dataflow.NewValueStorage()
 
 	if len(logPrefix) > 0 {
-		subscription := valueStorage.SubscribeSendInitial(context.Background(), dataflow.AllValueFilter)
+		// a slow terminal must not stall the storage; the log then skips values
+		subscription := valueStorage.SubscribeSendInitialWithPolicy(context.Background(), dataflow.AllValueFilter, dataflow.OverflowDropOldest)
 		go dataflow.SinkLog(logPrefix, subscription.Drain())
 	}
 
 	return valueStorage
 }
+
+// runValueExpirer removes values from the state storage which were not updated within the MaxAge of their device.
+func runValueExpirer(ctx context.Context, cfg *config.Config, stateStorage *dataflow.ValueStorage) {
+	devices := make(map[string]config.DeviceConfig)
+	enabled := false
+	for _, d := range cfg.Devices() {
+		devices[d.Name()] = d
+		if d.MaxAge() > 0 || len(d.RegisterMaxAge()) > 0 {
+			enabled = true
+		}
+	}
+
+	if !enabled {
+		return
+	}
+
+	maxAge := func(v dataflow.Value) time.Duration {
+		d, ok := devices[v.DeviceName()]
+		if !ok {
+			return 0
+		}
+		registerName := v.Register().Name()
+		if registerName == device.AvailabilityRegisterName {
+			// the availability is maintained by the device itself
+			return 0
+		}
+		return d.MaxAgeOf(registerName)
+	}
+
+	onExpire := func(v dataflow.Value) {
+		if d, ok := devices[v.DeviceName()]; ok && d.LogDebug() {
+			log.Printf("device[%s]: value expired: %s, measured at %s", v.DeviceName(), v, v.Time().Format(time.RFC3339))
+		}
+	}
+
+	dataflow.RunValueExpirer(ctx, stateStorage, valueExpireInterval, maxAge, onExpire)
+}
+
+// setupDeadband configures the deadband used by the mqtt realtime forwarders and the websocket.
+func setupDeadband(cfg *config.Config, stateStorage *dataflow.ValueStorage) {
+	devices := make(map[string]config.DeadbandConfig)
+	for _, d := range cfg.Devices() {
+		if d.Deadband().Enabled() {
+			devices[d.Name()] = d.Deadband()
+		}
+	}
+
+	if len(devices) < 1 {
+		// reset a deadband set up by the configuration used before a reload
+		stateStorage.SetDeadband(nil)
+		return
+	}
+
+	stateStorage.SetDeadband(func(v dataflow.Value) (deadband dataflow.Deadband, ok bool) {
+		d, ok := devices[v.DeviceName()]
+		if !ok {
+			return
+		}
+		reg := v.Register()
+		db, ok := d.Of(reg.Category(), reg.Name())
+		if !ok {
+			return
+		}
+		return dataflow.Deadband{
+			Absolute:   db.Absolute(),
+			Relative:   db.Relative(),
+			MaxSilence: d.MaxSilence(),
+		}, true
+	})
+}
+
+func transformOf(tc config.RegisterTransformConfig) dataflow.Transform {
+	return dataflow.Transform{
+		Scale:     tc.Scale(),
+		Offset:    tc.Offset(),
+		Unit:      tc.Unit(),
+		Precision: tc.Precision(),
+		Enum:      tc.Enum(),
+	}
+}
+
+// transformsOf returns the transforms of the device's registers keyed by register name.
+// Registers having limits but no transform get a transform leaving the values unchanged.
+func transformsOf(deviceConfig config.DeviceConfig) map[string]dataflow.Transform {
+	transforms := make(map[string]dataflow.Transform, len(deviceConfig.Transform())+len(deviceConfig.Limits()))
+	for registerName, tc := range deviceConfig.Transform() {
+		transforms[registerName] = transformOf(tc)
+	}
+	for registerName, lc := range deviceConfig.Limits() {
+		t, ok := transforms[registerName]
+		if !ok {
+			t = dataflow.Transform{Scale: 1, Precision: -1}
+		}
+		t.Limits = dataflow.RegisterLimits{
+			Min:  lc.Min(),
+			Max:  lc.Max(),
+			Step: lc.Step(),
+		}
+		transforms[registerName] = t
+	}
+	return transforms
+}
+
+// setupTransform configures the state storage to convert values into the units configured by the register transforms.
+// Commands are given in the converted units; the command storage converts them back for the devices.
+func setupTransform(cfg *config.Config, stateStorage, commandStorage *dataflow.ValueStorage) {
+	devices := make(map[string]map[string]dataflow.Transform)
+	for _, d := range cfg.Devices() {
+		if transforms := transformsOf(d); len(transforms) > 0 {
+			devices[d.Name()] = transforms
+		}
+	}
+
+	if len(devices) < 1 {
+		stateStorage.SetTransform(nil)
+		commandStorage.SetTransform(nil)
+		return
+	}
+
+	lookup := func(v dataflow.Value) (dataflow.Transform, bool) {
+		t, ok := devices[v.DeviceName()][v.Register().Name()]
+		return t, ok
+	}
+
+	stateStorage.SetTransform(func(v dataflow.Value) dataflow.Value {
+		if t, ok := lookup(v); ok {
+			return t.Apply(v)
+		}
+		return v
+	})
+	commandStorage.SetTransform(func(v dataflow.Value) dataflow.Value {
+		if t, ok := lookup(v); ok {
+			return t.Revert(v)
+		}
+		return v
+	})
+}
+
+// setupRegisterTransform makes the RegisterDb of the device return its registers with the converted unit, enum
+// and limits.
+func setupRegisterTransform(deviceConfig config.DeviceConfig, dev device.Device) {
+	transforms := transformsOf(deviceConfig)
+	if len(transforms) < 1 {
+		return
+	}
+
+	dev.RegisterDb().SetTransform(func(reg dataflow.RegisterStruct) dataflow.RegisterStruct {
+		if t, ok := transforms[reg.Name()]; ok {
+			return t.Register(reg)
+		}
+		return reg
+	})
+}
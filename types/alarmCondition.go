@@ -0,0 +1,34 @@
+package types
+
+type AlarmCondition int
+
+const (
+	AlarmConditionUndefined AlarmCondition = iota
+	AlarmConditionBelow
+	AlarmConditionAbove
+)
+
+// AlarmConditions lists all conditions which can be configured.
+var AlarmConditions = []AlarmCondition{AlarmConditionBelow, AlarmConditionAbove}
+
+func (c AlarmCondition) String() string {
+	switch c {
+	case AlarmConditionBelow:
+		return "Below"
+	case AlarmConditionAbove:
+		return "Above"
+	default:
+		return "Undefined"
+	}
+}
+
+func AlarmConditionFromString(s string) AlarmCondition {
+	switch s {
+	case "Below":
+		return AlarmConditionBelow
+	case "Above":
+		return AlarmConditionAbove
+	default:
+		return AlarmConditionUndefined
+	}
+}
@@ -0,0 +1,34 @@
+package types
+
+type ComputedRegisterType int
+
+const (
+	ComputedRegisterUndefinedType ComputedRegisterType = iota
+	ComputedRegisterNumberType
+	ComputedRegisterBoolType
+)
+
+// ComputedRegisterTypes lists all types which can be configured.
+var ComputedRegisterTypes = []ComputedRegisterType{ComputedRegisterNumberType, ComputedRegisterBoolType}
+
+func (rt ComputedRegisterType) String() string {
+	switch rt {
+	case ComputedRegisterNumberType:
+		return "Number"
+	case ComputedRegisterBoolType:
+		return "Bool"
+	default:
+		return "Undefined"
+	}
+}
+
+func ComputedRegisterTypeFromString(s string) ComputedRegisterType {
+	switch s {
+	case "Number":
+		return ComputedRegisterNumberType
+	case "Bool":
+		return ComputedRegisterBoolType
+	default:
+		return ComputedRegisterUndefinedType
+	}
+}
@@ -8,6 +8,9 @@ const (
 	HttpShellyEm3Kind
 )
 
+// HttpDeviceKinds lists all kinds which can be configured.
+var HttpDeviceKinds = []HttpDeviceKind{HttpTeracomKind, HttpShellyEm3Kind}
+
 func (dk HttpDeviceKind) String() string {
 	switch dk {
 	case HttpTeracomKind:
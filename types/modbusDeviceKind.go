@@ -8,6 +8,9 @@ const (
 	ModbusFinder7M38Kind
 )
 
+// ModbusDeviceKinds lists all kinds which can be configured.
+var ModbusDeviceKinds = []ModbusDeviceKind{ModbusWaveshareRtuRelay8Kind, ModbusFinder7M38Kind}
+
 func (dk ModbusDeviceKind) String() string {
 	switch dk {
 	case ModbusWaveshareRtuRelay8Kind:
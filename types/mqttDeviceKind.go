@@ -7,6 +7,9 @@ const (
 	MqttDeviceGoIotdeviceV3Kind
 )
 
+// MqttDeviceKinds lists all kinds which can be configured.
+var MqttDeviceKinds = []MqttDeviceKind{MqttDeviceGoIotdeviceV3Kind}
+
 func (dk MqttDeviceKind) String() string {
 	switch dk {
 	case MqttDeviceGoIotdeviceV3Kind:
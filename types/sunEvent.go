@@ -0,0 +1,34 @@
+package types
+
+type SunEvent int
+
+const (
+	SunEventUndefined SunEvent = iota
+	SunEventSunrise
+	SunEventSunset
+)
+
+// SunEvents lists all events which can be configured.
+var SunEvents = []SunEvent{SunEventSunrise, SunEventSunset}
+
+func (e SunEvent) String() string {
+	switch e {
+	case SunEventSunrise:
+		return "Sunrise"
+	case SunEventSunset:
+		return "Sunset"
+	default:
+		return "Undefined"
+	}
+}
+
+func SunEventFromString(s string) SunEvent {
+	switch s {
+	case "Sunrise":
+		return SunEventSunrise
+	case "Sunset":
+		return SunEventSunset
+	default:
+		return SunEventUndefined
+	}
+}
@@ -7,8 +7,12 @@ const (
 	VictronRandomBmvKind
 	VictronRandomSolarKind
 	VictronVedirectKind
+	VictronReplayKind
 )
 
+// VictronDeviceKinds lists all kinds which can be configured.
+var VictronDeviceKinds = []VictronDeviceKind{VictronRandomBmvKind, VictronRandomSolarKind, VictronVedirectKind, VictronReplayKind}
+
 func (dk VictronDeviceKind) String() string {
 	switch dk {
 	case VictronRandomBmvKind:
@@ -17,6 +21,8 @@ func (dk VictronDeviceKind) String() string {
 		return "RandomSolar"
 	case VictronVedirectKind:
 		return "Vedirect"
+	case VictronReplayKind:
+		return "Replay"
 	default:
 		return "Undefined"
 	}
@@ -32,5 +38,8 @@ func VictronDeviceKindFromString(s string) VictronDeviceKind {
 	if s == "Vedirect" {
 		return VictronVedirectKind
 	}
+	if s == "Replay" {
+		return VictronReplayKind
+	}
 	return VictronUndefinedKind
 }
@@ -0,0 +1,139 @@
+package vedirectReplay
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Direction int
+
+const (
+	Tx Direction = iota // sent to the device
+	Rx                  // received from the device
+)
+
+// Entry is one line of an io log.
+type Entry struct {
+	Time      time.Time
+	Direction Direction
+	Data      []byte
+}
+
+// exchange is a request frame sent to the device and everything the device sent until the next request.
+type exchange struct {
+	request  []byte
+	response []byte
+	delay    time.Duration
+}
+
+// ReadLogFile reads an io log as written by the IoLog option of the Victron devices.
+func ReadLogFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	entries, err := ReadLog(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// ReadLog parses an io log. Every line consists of the timestamp, the direction and the quoted data, e.g.
+// `2024/01/02 15:04:05.123456 TX: ":154\n"` or `2024/01/02 15:04:05.140211 RX: ":51641F9\n"`.
+// This is the format written by vedirectapi.NewFileLogger; victronDevice.TestIoLogRoundTrip checks that both match.
+// Lines not matching this format, e.g. a line truncated when the device was stopped, are ignored.
+func ReadLog(r io.Reader) (entries []Entry, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if e, ok := parseLine(scanner.Text()); ok {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(entries) < 1 {
+		return nil, fmt.Errorf("no VE.Direct io found")
+	}
+	return
+}
+
+const logTimeLayout = "2006/01/02 15:04:05.000000"
+
+func parseLine(line string) (e Entry, ok bool) {
+	if len(line) < len(logTimeLayout)+1 {
+		return
+	}
+	t, err := time.ParseInLocation(logTimeLayout, line[:len(logTimeLayout)], time.Local)
+	if err != nil {
+		return
+	}
+	e.Time = t
+
+	direction, quoted, found := strings.Cut(line[len(logTimeLayout)+1:], ": ")
+	if !found {
+		return
+	}
+	switch direction {
+	case "TX":
+		e.Direction = Tx
+	case "RX":
+		e.Direction = Rx
+	default:
+		return
+	}
+
+	data, err := strconv.Unquote(quoted)
+	if err != nil {
+		return
+	}
+	e.Data = []byte(data)
+	return e, len(e.Data) > 0
+}
+
+// groupExchanges groups the entries into request frames and the responses received after them.
+// Data received before the first request is dropped.
+func groupExchanges(entries []Entry) (ret []exchange) {
+	var request []byte
+	var requestTime time.Time
+	for _, e := range entries {
+		if e.Direction == Tx {
+			if len(request) < 1 {
+				requestTime = e.Time
+			}
+			request = append(request, e.Data...)
+			// a frame can be logged in several writes
+			for {
+				frame, after, found := bytes.Cut(request, []byte("\n"))
+				if !found {
+					break
+				}
+				if f := bytes.TrimSpace(frame); len(f) > 0 {
+					ret = append(ret, exchange{request: f})
+				}
+				request = after
+			}
+			continue
+		}
+
+		if len(ret) < 1 {
+			continue
+		}
+		last := &ret[len(ret)-1]
+		if len(last.response) < 1 {
+			last.delay = e.Time.Sub(requestTime)
+		}
+		last.response = append(last.response, e.Data...)
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package vedirectReplay
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadLogFile(t *testing.T) {
+	entries, err := ReadLogFile(filepath.Join("testdata", "bmv.log"))
+	if err != nil {
+		t.Fatalf("did not expect an error, got: %s", err)
+	}
+	// the last line is truncated and ignored
+	if expect, got := 9, len(entries); expect != got {
+		t.Fatalf("expect %d entries but got %d", expect, got)
+	}
+
+	if expect, got := Tx, entries[0].Direction; expect != got {
+		t.Errorf("expect direction %d but got %d", expect, got)
+	}
+	if expect, got := ":154\n", string(entries[0].Data); expect != got {
+		t.Errorf("expect data %q but got %q", expect, got)
+	}
+	if expect, got := Rx, entries[1].Direction; expect != got {
+		t.Errorf("expect direction %d but got %d", expect, got)
+	}
+	if expect, got := ":51641F9\n", string(entries[1].Data); expect != got {
+		t.Errorf("expect data %q but got %q", expect, got)
+	}
+	if expect, got := 40*time.Millisecond, entries[1].Time.Sub(entries[0].Time); expect != got {
+		t.Errorf("expect %s between the entries but got %s", expect, got)
+	}
+
+	ex := groupExchanges(entries)
+	if expect, got := 4, len(ex); expect != got {
+		t.Fatalf("expect %d exchanges but got %d", expect, got)
+	}
+	if expect, got := ":78DED00D4", string(ex[1].request); expect != got {
+		t.Errorf("expect request %q but got %q", expect, got)
+	}
+	if expect, got := ":78DED00D104FF\n", string(ex[1].response); expect != got {
+		t.Errorf("expect the response chunks to be joined to %q but got %q", expect, got)
+	}
+	if expect, got := 30*time.Millisecond, ex[1].delay; expect != got {
+		t.Errorf("expect delay %s but got %s", expect, got)
+	}
+}
+
+func TestReadLogWithoutIo(t *testing.T) {
+	if _, err := ReadLog(strings.NewReader("no io here\n")); err == nil {
+		t.Errorf("expect an error for a log without io")
+	}
+}
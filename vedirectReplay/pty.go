@@ -0,0 +1,12 @@
+//go:build !linux
+
+package vedirectReplay
+
+import (
+	"errors"
+	"os"
+)
+
+func openPty() (master, slave *os.File, name string, err error) {
+	return nil, nil, "", errors.New("replay is only supported on linux")
+}
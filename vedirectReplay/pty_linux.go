@@ -0,0 +1,58 @@
+package vedirectReplay
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPty opens a new pseudo terminal and configures it like a serial port in raw mode.
+// The master is opened non-blocking such that closing it interrupts a pending read.
+func openPty() (master, slave *os.File, name string, err error) {
+	masterFd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return
+	}
+	master = os.NewFile(uintptr(masterFd), "/dev/ptmx")
+	defer func() {
+		if err != nil {
+			_ = master.Close()
+		}
+	}()
+
+	if err = unix.IoctlSetPointerInt(masterFd, unix.TIOCSPTLCK, 0); err != nil {
+		return
+	}
+	n, err := unix.IoctlGetUint32(masterFd, unix.TIOCGPTN)
+	if err != nil {
+		return
+	}
+	name = fmt.Sprintf("/dev/pts/%d", n)
+
+	slaveFd, err := unix.Open(name, unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return
+	}
+	if err = makeRaw(slaveFd); err != nil {
+		_ = unix.Close(slaveFd)
+		return
+	}
+	slave = os.NewFile(uintptr(slaveFd), name)
+	return
+}
+
+func makeRaw(fd int) error {
+	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return err
+	}
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB
+	t.Cflag |= unix.CS8
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+	return unix.IoctlSetTermios(fd, unix.TCSETS, t)
+}
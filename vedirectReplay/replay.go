@@ -0,0 +1,152 @@
+// Package vedirectReplay plays a VE.Direct io log back through a pseudo terminal.
+// The pseudo terminal behaves like the serial port of the recorded device: every request frame written to it
+// is answered by the response recorded after the same request.
+package vedirectReplay
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+type Replay struct {
+	path      string
+	exchanges []exchange
+	speed     float64
+
+	master *os.File
+	slave  *os.File
+	name   string
+
+	// pos is the index of the exchange after the last answered request
+	pos       int
+	unmatched map[string]struct{}
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+}
+
+// Open reads the io log and starts replaying it. Name returns the serial port to connect to.
+// Responses are delayed by the recorded delay divided by speed; speed 0 answers immediately.
+func Open(path string, speed float64) (*Replay, error) {
+	if speed < 0 {
+		return nil, fmt.Errorf("speed must be >= 0, got %f", speed)
+	}
+
+	entries, err := ReadLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ex := groupExchanges(entries)
+	if len(ex) < 1 {
+		return nil, fmt.Errorf("%s: no request found", path)
+	}
+
+	master, slave, name, err := openPty()
+	if err != nil {
+		return nil, fmt.Errorf("cannot open pseudo terminal: %w", err)
+	}
+
+	r := &Replay{
+		path:      path,
+		exchanges: ex,
+		speed:     speed,
+		master:    master,
+		slave:     slave,
+		name:      name,
+		unmatched: make(map[string]struct{}),
+		closed:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go r.serve()
+	return r, nil
+}
+
+// Name returns the path of the pseudo terminal, e.g. /dev/pts/3.
+func (r *Replay) Name() string {
+	return r.name
+}
+
+// Close stops the replay and removes the pseudo terminal.
+func (r *Replay) Close() (err error) {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+		err = r.master.Close()
+		// the slave is kept open while replaying such that reading the master does not fail between two clients
+		if e := r.slave.Close(); err == nil {
+			err = e
+		}
+		<-r.done
+	})
+	return
+}
+
+func (r *Replay) serve() {
+	defer close(r.done)
+
+	var buf []byte
+	chunk := make([]byte, 256)
+	for {
+		n, err := r.master.Read(chunk)
+		if err != nil {
+			return
+		}
+		buf = append(buf, chunk[:n]...)
+
+		for {
+			frame, after, found := bytes.Cut(buf, []byte("\n"))
+			if !found {
+				break
+			}
+			buf = after
+			if f := bytes.TrimSpace(frame); len(f) > 0 && !r.answer(f) {
+				return
+			}
+		}
+	}
+}
+
+// answer writes the response recorded for the request; it returns false when the replay is closed.
+func (r *Replay) answer(request []byte) bool {
+	ex, ok := r.next(request)
+	if !ok {
+		if _, logged := r.unmatched[string(request)]; !logged {
+			r.unmatched[string(request)] = struct{}{}
+			log.Printf("vedirectReplay[%s]: no response recorded for %s", r.path, request)
+		}
+		return true
+	}
+
+	if r.speed > 0 && ex.delay > 0 {
+		select {
+		case <-r.closed:
+			return false
+		case <-time.After(time.Duration(float64(ex.delay) / r.speed)):
+		}
+	}
+
+	if len(ex.response) > 0 {
+		if _, err := r.master.Write(ex.response); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// next finds the next exchange with the given request; the log is replayed in a loop.
+func (r *Replay) next(request []byte) (ex exchange, ok bool) {
+	l := len(r.exchanges)
+	for i := range l {
+		idx := (r.pos + i) % l
+		if bytes.Equal(r.exchanges[idx].request, request) {
+			r.pos = (idx + 1) % l
+			return r.exchanges[idx], true
+		}
+	}
+	return
+}
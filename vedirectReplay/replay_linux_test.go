@@ -0,0 +1,51 @@
+package vedirectReplay
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReplay(t *testing.T) {
+	r, err := Open(filepath.Join("testdata", "bmv.log"), 0)
+	if err != nil {
+		t.Fatalf("cannot open replay: %s", err)
+	}
+	defer r.Close() //nolint:errcheck
+
+	port, err := os.OpenFile(r.Name(), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("cannot open %s: %s", r.Name(), err)
+	}
+	defer port.Close() //nolint:errcheck
+	reader := bufio.NewReader(port)
+
+	request := func(req, expect string) {
+		t.Helper()
+		if _, err := port.WriteString(req); err != nil {
+			t.Fatal(err)
+		}
+		if err := port.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("expect a response to %q, got: %s", req, err)
+		}
+		if expect != got {
+			t.Errorf("expect response %q to %q but got %q", expect, req, got)
+		}
+	}
+
+	// the values change as recorded and the log is replayed in a loop
+	request(":78DED00D4\n", ":78DED00D104FF\n")
+	request(":154\n", ":51641F9\n")
+	request(":78DED00D4\n", ":78DED00D204FE\n")
+	request(":78DED00D4\n", ":78DED00D104FF\n")
+
+	if err := r.Close(); err != nil {
+		t.Errorf("did not expect an error on close, got: %s", err)
+	}
+}
@@ -15,6 +15,7 @@ type Config interface {
 	Kind() types.VictronDeviceKind
 	IoLog() string
 	PollInterval() time.Duration
+	ReplaySpeed() float64
 }
 
 type DeviceStruct struct {
@@ -41,7 +42,9 @@ func NewDevice(
 func (c *DeviceStruct) Run(ctx context.Context) (err error, immediateError bool) {
 	switch c.victronConfig.Kind() {
 	case types.VictronVedirectKind:
-		return runVedirect(ctx, c, c.StateStorage())
+		return runVedirect(ctx, c, c.StateStorage(), c.victronConfig.Device())
+	case types.VictronReplayKind:
+		return runReplay(ctx, c, c.StateStorage())
 	case types.VictronRandomBmvKind:
 		rl := veregister.NewRegisterList()
 		veregister.AppendBmv(&rl)
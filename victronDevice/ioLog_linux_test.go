@@ -0,0 +1,71 @@
+package victronDevice
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/koestler/go-iotdevice/v3/vedirectReplay"
+	"github.com/koestler/go-victron/vedirect"
+	"github.com/koestler/go-victron/vedirectapi"
+)
+
+// TestIoLogRoundTrip writes an io log using vedirectapi.NewFileLogger and reads it again as a replay does.
+func TestIoLogRoundTrip(t *testing.T) {
+	replay, err := vedirectReplay.Open(filepath.Join("..", "vedirectReplay", "testdata", "bmv.log"), 0)
+	if err != nil {
+		t.Fatalf("cannot open replay: %s", err)
+	}
+	defer replay.Close() //nolint:errcheck
+
+	ioLog := filepath.Join(t.TempDir(), "io.log")
+	logger, err := vedirectapi.NewFileLogger(ioLog)
+	if err != nil {
+		t.Fatalf("cannot create the file logger: %s", err)
+	}
+
+	// the recording only answers pings and register gets; connecting may fail, but all io until then is logged
+	if api, err := vedirectapi.NewSerialRegisterApi(replay.Name(), vedirect.Config{IoLogger: logger}); err == nil {
+		api.Close() //nolint:errcheck
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("cannot close the file logger: %s", err)
+	}
+
+	content, err := os.ReadFile(ioLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := vedirectReplay.ReadLog(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("cannot read the io log: %s\n%s", err, content)
+	}
+
+	// every line written by the logger must be understood
+	lines := 0
+	for _, l := range strings.Split(string(content), "\n") {
+		if len(strings.TrimSpace(l)) > 0 {
+			lines++
+		}
+	}
+	if expect, got := lines, len(entries); expect != got {
+		t.Errorf("expect %d entries, one per line, but got %d:\n%s", expect, got, content)
+	}
+
+	var tx, rx []byte
+	for _, e := range entries {
+		if e.Direction == vedirectReplay.Tx {
+			tx = append(tx, e.Data...)
+		} else {
+			rx = append(rx, e.Data...)
+		}
+	}
+	if !bytes.Contains(tx, []byte(":154\n")) {
+		t.Errorf("expect the ping request to be logged as sent, got %q", tx)
+	}
+	if !bytes.Contains(rx, []byte(":51641F9\n")) {
+		t.Errorf("expect the ping response to be logged as received, got %q", rx)
+	}
+}
@@ -0,0 +1,24 @@
+package victronDevice
+
+import (
+	"log"
+
+	"github.com/koestler/go-victron/vedirect"
+	"github.com/koestler/go-victron/vedirectapi"
+)
+
+// Probe opens the serial port, executes the VE.Direct handshake and returns the product of the connected device,
+// e.g. to find out which serial port belongs to which device.
+func Probe(device string) (product string, err error) {
+	api, err := vedirectapi.NewSerialRegisterApi(device, vedirect.Config{})
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := api.Close(); err != nil {
+			log.Printf("victronDevice: probe %s: Close failed: %s", device, err)
+		}
+	}()
+
+	return api.Product.String(), nil
+}
@@ -0,0 +1,25 @@
+package victronDevice
+
+import (
+	"context"
+	"log"
+
+	"github.com/koestler/go-iotdevice/v3/dataflow"
+	"github.com/koestler/go-iotdevice/v3/vedirectReplay"
+)
+
+// runReplay plays the io log given as Device back through a pseudo terminal and reads it like a real device.
+func runReplay(ctx context.Context, c *DeviceStruct, output dataflow.Fillable) (err error, immediateError bool) {
+	replay, err := vedirectReplay.Open(c.victronConfig.Device(), c.victronConfig.ReplaySpeed())
+	if err != nil {
+		return err, true
+	}
+	defer func() {
+		if err := replay.Close(); err != nil {
+			log.Printf("device[%s]: replay: Close failed: %s", c.Name(), err)
+		}
+	}()
+
+	log.Printf("device[%s]: replay %s on %s, speed=%g", c.Name(), c.victronConfig.Device(), replay.Name(), c.victronConfig.ReplaySpeed())
+	return runVedirect(ctx, c, output, replay.Name())
+}
@@ -13,7 +13,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func runVedirect(ctx context.Context, c *DeviceStruct, output dataflow.Fillable) (err error, immediateError bool) {
+func runVedirect(ctx context.Context, c *DeviceStruct, output dataflow.Fillable, port string) (err error, immediateError bool) {
 	log.Printf("device[%s]: start vedirect source", c.Name())
 
 	vedirectConfig := vedirect.Config{}
@@ -35,7 +35,7 @@ func runVedirect(ctx context.Context, c *DeviceStruct, output dataflow.Fillable)
 		}
 	}
 
-	api, err := vedirectapi.NewSerialRegisterApi(c.victronConfig.Device(), vedirectConfig)
+	api, err := vedirectapi.NewSerialRegisterApi(port, vedirectConfig)
 	if err != nil {
 		return err, true
 	}